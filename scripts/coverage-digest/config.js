@@ -24,22 +24,307 @@ export const config = {
       ? process.env.DIGEST_CC.split(',').map(e => e.trim()).filter(Boolean)
       : [],
     replyTo: process.env.DIGEST_RECIPIENT || 'leonardo@praetorian.com',
+    // Excerpts in the email are cut at a sentence end within this many characters
+    excerptChars: parseInt(process.env.EMAIL_EXCERPT_CHARS || '280', 10),
+    // Items rendered in full (0 for no cap); the rest are listed by title
+    maxItems: parseInt(process.env.EMAIL_MAX_ITEMS || '25', 10),
+  },
+
+  // Amazon SES delivery (utils/ses-sender.js). Credentials come from the
+  // standard AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN.
+  ses: {
+    region: process.env.SES_REGION || process.env.AWS_REGION || 'us-east-1',
+    from: process.env.SES_FROM || '',
+    to: process.env.SES_TO
+      ? process.env.SES_TO.split(',').map(e => e.trim()).filter(Boolean)
+      : [],
+    replyTo: process.env.SES_REPLY_TO || '',
+    // Dry runs write the MIME message here instead of sending it
+    dryRunDir: process.env.SES_DRY_RUN_DIR || join(__dirname, 'state', 'ses-outbox'),
+  },
+
+  // Amazon S3 (utils/s3.js), for the seen-items store (SEEN_STORE_BACKEND=s3)
+  // and the digest archive; credentials as for SES. Keys go under `prefix`,
+  // encrypted with the KMS key when one is given. `endpoint` is for an
+  // S3-compatible service instead of AWS.
+  s3: {
+    bucket: process.env.S3_BUCKET || '',
+    region: process.env.S3_REGION || process.env.AWS_REGION || 'us-east-1',
+    prefix: process.env.S3_PREFIX || '',
+    kmsKeyId: process.env.S3_KMS_KEY_ID || '',
+    endpoint: process.env.S3_ENDPOINT || '',
+    // Archive each published digest (markdown and JSON) when a bucket is set
+    archiveDigests: process.env.S3_ARCHIVE_DIGESTS !== 'false',
   },
 
   // Logo URL for email template
   logoUrl: process.env.LOGO_URL || 'https://raw.githubusercontent.com/LeoDPraetorian/praetorian-coverage-digest/main/scripts/coverage-digest/assets/logo-white.png',
 
+  // Outbound HTTP for feeds and senders (utils/http-client.js).
+  // Per-source overrides are keyed by feed or publisher name, e.g.
+  //   'Dark Reading': { proxy: 'http://scraper:8080', headers: { ... }, timeoutMs: 30000 }
+  http: {
+    // Identifies the bot to publishers, with a URL to reach us; its product
+    // token ("PraetorianCoverageDigest") is our name in robots.txt
+    userAgent: process.env.HTTP_USER_AGENT || 'PraetorianCoverageDigest/1.0 (+https://github.com/LeoDPraetorian/praetorian-coverage-digest)',
+    timeoutMs: parseInt(process.env.HTTP_TIMEOUT_MS || '15000', 10),
+    proxy: process.env.HTTP_PROXY_URL || '',
+    // GET retries on network errors, timeouts, 429 and 5xx (utils/retry.js)
+    retry: {
+      attempts: parseInt(process.env.HTTP_RETRY_ATTEMPTS || '3', 10),
+      baseDelayMs: parseInt(process.env.HTTP_RETRY_BASE_DELAY_MS || '1000', 10),
+      maxDelayMs: 30000,
+    },
+    sources: {},
+  },
+
+  // Outbound digest webhooks (notify-webhooks.js). DIGEST_WEBHOOKS is a JSON
+  // array of { "name", "url", "secret" }; each endpoint gets its own secret.
+  digestWebhooks: {
+    endpoints: process.env.DIGEST_WEBHOOKS ? JSON.parse(process.env.DIGEST_WEBHOOKS) : [],
+    maxAttempts: parseInt(process.env.DIGEST_WEBHOOK_MAX_ATTEMPTS || '4', 10),
+    backoffMs: parseInt(process.env.DIGEST_WEBHOOK_BACKOFF_MS || '2000', 10),
+    outbox: join(__dirname, '..', 'coverage-tracker', 'webhook-outbox.json'),
+  },
+
+  // Microsoft Teams incoming webhook (publish-teams.js). The digest goes out
+  // as Adaptive Cards listing up to maxItems items, split into several
+  // messages where one would pass Teams' 28 KB limit.
+  teams: {
+    webhookUrl: process.env.TEAMS_WEBHOOK_URL || '',
+    maxItems: parseInt(process.env.TEAMS_MAX_ITEMS || '10', 10),
+    excerptChars: parseInt(process.env.TEAMS_EXCERPT_CHARS || '300', 10),
+    maxPayloadBytes: 28 * 1024,
+    // Teams answers 429 when a webhook posts too fast; back off and retry
+    retry: { attempts: 5, baseDelayMs: 1000, maxDelayMs: 60000 },
+  },
+
+  // GitHub issue backup record (publish-issue.js). When an open digest
+  // issue for the same date already exists, the dedupe strategy decides
+  // what happens: "skip" leaves it alone, "update" merges the new items
+  // into its body (keeping human edits), "comment" appends them as a comment.
+  digestIssue: {
+    label: 'coverage-digest',
+    dedupeStrategy: ['skip', 'update', 'comment'].includes(process.env.DIGEST_ISSUE_DEDUPE)
+      ? process.env.DIGEST_ISSUE_DEDUPE
+      : 'update',
+    // "daily" files an issue per digest; "weekly" keeps one issue per ISO
+    // week up to date, merging into its body on each run
+    mode: process.env.DIGEST_ISSUE_MODE === 'weekly' ? 'weekly' : 'daily',
+    // "flat" lists items newest first; "by-tool" groups them per tool
+    layout: process.env.DIGEST_ISSUE_LAYOUT === 'by-tool' ? 'by-tool' : 'flat',
+    // Excerpts in the issue are cut at a sentence end within this many characters
+    excerptChars: parseInt(process.env.DIGEST_ISSUE_EXCERPT_CHARS || '500', 10),
+    // Per-publication counts in the issue and weekly rollup (Sources section)
+    sources: process.env.DIGEST_ISSUE_SOURCES === 'true',
+    sourceRows: 15,
+    // The pipeline's per-source run summary, folded into a <details> block
+    runSummary: process.env.DIGEST_ISSUE_RUN_SUMMARY === 'true',
+    // Items rendered in full (0 for no cap); the rest are listed by title,
+    // as a plain list or folded into a <details> block
+    maxItems: parseInt(process.env.DIGEST_ISSUE_MAX_ITEMS || '25', 10),
+    overflow: process.env.DIGEST_ISSUE_OVERFLOW === 'details' ? 'details' : 'list',
+    // Labels routing the issue: `toolLabelPrefix` plus each tool its items
+    // mention ("coverage:brutus"), and `negativeLabel` when any item is
+    // negative. Missing ones are created in these colors.
+    toolLabelPrefix: 'coverage:',
+    negativeLabel: process.env.DIGEST_ISSUE_NEGATIVE_LABEL || 'severity:negative',
+    labelColors: { tool: '11C3DB', negative: 'E63948' },
+    // Who the issue is assigned to per tool mentioned, as JSON: GitHub
+    // logins, or "org/team" for a team's members, e.g.
+    // {"Brutus": ["alice"], "Augustus": "praetorian-inc/ai-research"}
+    assignees: process.env.DIGEST_ISSUE_ASSIGNEES ? JSON.parse(process.env.DIGEST_ISSUE_ASSIGNEES) : {},
+  },
+
+  // Webhook receiver (serve-webhook.js)
+  webhook: {
+    token: process.env.WEBHOOK_TOKEN || '',
+    port: parseInt(process.env.WEBHOOK_PORT || '8787', 10),
+    maxBodyBytes: parseInt(process.env.WEBHOOK_MAX_BODY_BYTES || '65536', 10),
+    // Where accepted submissions wait for the pipeline: file
+    // (paths.webhookInbox, when the pipeline runs on the receiver's host)
+    // or s3 (S3_BUCKET, when it runs anywhere else, e.g. on Actions)
+    inbox: process.env.WEBHOOK_INBOX || 'file',
+  },
+
+  // Per-tool press pages (generate-press-pages.js)
+  pressPages: {
+    outputDir: process.env.PRESS_PAGES_DIR || join(__dirname, '..', '..', 'docs', 'press'),
+    frontMatter: process.env.PRESS_FRONT_MATTER === 'jekyll' ? 'jekyll' : 'hugo',
+    layout: process.env.PRESS_LAYOUT || 'press',
+    excludeDead: process.env.PRESS_EXCLUDE_DEAD !== 'false',
+    excludeNegative: process.env.PRESS_EXCLUDE_NEGATIVE === 'true',
+    // Per-tool overrides, e.g. { 'Brutus': { excludeNegative: true } }
+    pages: {},
+  },
+
+  // Atom feed of recent coverage (generate-feed.js)
+  feed: {
+    path: process.env.FEED_PATH || join(__dirname, '..', '..', 'docs', 'coverage.atom'),
+    maxEntries: parseInt(process.env.FEED_MAX_ENTRIES || '50', 10),
+    // Public URL of the feed, advertised as its rel="self" link
+    url: process.env.FEED_URL || '',
+    title: 'Praetorian in the News',
+  },
+
+  // Sentiment classification (utils/sentiment.js). Set SENTIMENT_CLASSIFIER
+  // to a module path (relative to this directory) to replace the built-in
+  // lexicon classifier, e.g. an LLM-backed one.
+  sentiment: {
+    classifier: process.env.SENTIMENT_CLASSIFIER || '',
+  },
+
+  // Alerts on negative coverage (utils/alerts.js). With ALERT_WEBHOOK_URL
+  // set (a Slack incoming webhook, or anything taking the same JSON), each
+  // new negative item is posted there once, as soon as it's classified.
+  alerts: {
+    webhookUrl: process.env.ALERT_WEBHOOK_URL || '',
+  },
+
+  // Reading article pages (monitors/html-article.js), for URL-only manual
+  // submissions and to fill in excerpts and dates other sources left out
+  scrape: {
+    enrich: process.env.SCRAPE_ENRICH !== 'false',
+    maxBytes: parseInt(process.env.SCRAPE_MAX_BYTES || '2000000', 10),
+    concurrency: parseInt(process.env.SCRAPE_CONCURRENCY || '4', 10),
+    // Read every new item's page and flag the ones behind a paywall
+    paywalls: process.env.SCRAPE_PAYWALLS !== 'false',
+    // Follow new items' links through redirects to the article's
+    // canonical URL before dedup; off, links are only cleaned
+    resolveUrls: process.env.SCRAPE_RESOLVE_URLS !== 'false',
+  },
+
+  // Crawl etiquette for article pages (utils/crawler.js): robots.txt is
+  // followed, and each host gets one request at a time, minIntervalMs
+  // apart or its Crawl-delay if longer. A host asking for a Crawl-delay
+  // over maxCrawlDelayMs isn't crawled.
+  crawl: {
+    robots: process.env.CRAWL_RESPECT_ROBOTS !== 'false',
+    minIntervalMs: parseInt(process.env.CRAWL_MIN_INTERVAL_MS || '2000', 10),
+    maxCrawlDelayMs: 60000,
+  },
+
+  // Wayback Machine archive links (utils/archive.js). Each new item gets an
+  // existing snapshot, or a fresh one from Save Page Now (SPN2) when there
+  // is none. SPN2 keys are optional; anonymous captures are rate-limited
+  // harder. Skip for a run with --skip-archive.
+  archive: {
+    enabled: process.env.ARCHIVE_LINKS !== 'false',
+    capture: process.env.ARCHIVE_CAPTURE !== 'false',
+    accessKey: process.env.SPN2_ACCESS_KEY || '',
+    secretKey: process.env.SPN2_SECRET_KEY || '',
+    concurrency: parseInt(process.env.ARCHIVE_CONCURRENCY || '4', 10),
+    // SPN2 captures are polled this many times, this far apart
+    pollAttempts: parseInt(process.env.ARCHIVE_POLL_ATTEMPTS || '6', 10),
+    pollIntervalMs: parseInt(process.env.ARCHIVE_POLL_INTERVAL_MS || '5000', 10),
+  },
+
+  // Digest validation (utils/validate.js). In "best-effort" mode invalid
+  // items are logged and dropped; in "strict" mode they fail the run.
+  validation: {
+    mode: process.env.DIGEST_VALIDATION === 'strict' ? 'strict' : 'best-effort',
+    maxExcerptLength: parseInt(process.env.MAX_EXCERPT_LENGTH || '500', 10),
+  },
+
+  // Syndicated copies (utils/syndication.js): items published within
+  // windowHours of each other whose titles are at least `threshold` alike
+  // (0-1) are listed once, as the earliest original outlet's, with the
+  // other outlets named on it
+  syndication: {
+    enabled: process.env.SYNDICATION_DEDUP !== 'false',
+    threshold: parseFloat(process.env.SYNDICATION_THRESHOLD || '0.8'),
+    windowHours: parseInt(process.env.SYNDICATION_WINDOW_HOURS || '48', 10),
+  },
+
+  // Mention confidence (utils/confidence.js): items a monitor found are
+  // scored 0-1 on how sure we are they're about Praetorian. Those scoring
+  // at least `high` go in the digest; those from `low` up to `high` go
+  // under Needs Review for someone to confirm, and the rest are left out
+  // and logged. A context term within proximityWords words of the tool's
+  // name counts in full. securityOutlets are publications that count as
+  // security outlets besides the RSS feeds below.
+  confidence: {
+    high: parseFloat(process.env.CONFIDENCE_HIGH || '0.5'),
+    low: parseFloat(process.env.CONFIDENCE_LOW || '0.2'),
+    proximityWords: parseInt(process.env.CONFIDENCE_PROXIMITY_WORDS || '20', 10),
+    securityOutlets: (process.env.SECURITY_OUTLETS || '').split(',').map(s => s.trim()).filter(Boolean),
+  },
+
+  // Relevance of items found by the monitors (utils/relevance.js): whether
+  // an article is about us or only names us, as a roundup of fifty vendors
+  // does. Items scoring under `threshold` are kept out of the digest and
+  // counted in a note; the JSON output lists them with each signal's score.
+  // lowTrustDomains are aggregators whose items count as from no outlet;
+  // ownLinks the sites (a domain, optionally with a path) that are ours.
+  relevance: {
+    enabled: process.env.RELEVANCE_FILTER !== 'false',
+    threshold: parseFloat(process.env.RELEVANCE_THRESHOLD || '0.25'),
+    lowTrustDomains: (process.env.RELEVANCE_LOW_TRUST_DOMAINS
+      || 'securityboulevard.com,flipboard.com,feedly.com,inoreader.com,newsbreak.com,ground.news')
+      .split(',').map(s => s.trim()).filter(Boolean),
+    ownLinks: ['praetorian.com', 'github.com/praetorian-inc'],
+  },
+
+  // Items send-daily-digest.js has sent (utils/seen-store.js). A sent item
+  // is kept out of digests for suppressDays after it was last reported (0
+  // for always), then may come back marked previously covered; entries are
+  // forgotten retentionDays after that (0 keeps them all)
+  seenStore: {
+    // Where the store is kept: file (config.paths.seenStore) or s3
+    backend: process.env.SEEN_STORE_BACKEND || 'file',
+    suppressDays: parseInt(process.env.SEEN_SUPPRESS_DAYS || '0', 10),
+    retentionDays: parseInt(process.env.SEEN_RETENTION_DAYS || '0', 10),
+  },
+
+  // Locale for rendered digest chrome (see locales/)
+  locale: process.env.DIGEST_LOCALE || 'en',
+
+  // Language of each new item's title and excerpt (utils/language.js).
+  // Items in a language other than `primary` are included like any other,
+  // left out (exclude), or listed in a section of their own (section).
+  language: {
+    primary: process.env.DIGEST_PRIMARY_LANGUAGE || 'en',
+    foreign: process.env.FOREIGN_LANGUAGE_ITEMS || 'include',
+  },
+  // IANA time zone the digest's day is reckoned in: the date in headers and
+  // subjects, "today"/"yesterday" labels, and the new-items cutoff window
+  timeZone: process.env.DIGEST_TIMEZONE || 'UTC',
+
   // Behavior
-  skipIfEmpty: process.env.SKIP_IF_EMPTY !== 'false',
+  // Items published more than this many days before they are first seen
+  // are left out of the digest, even if they fall inside the lookback window.
+  maxItemAgeDays: parseInt(process.env.MAX_ITEM_AGE_DAYS || '7', 10),
+  // List an article already sent that moved to a new URL under Updated
+  // Coverage; otherwise the tracker just follows it to the new URL
+  noteMovedUrls: process.env.NOTE_MOVED_URLS === 'true',
+  // Item order in the digest: date (newest first), source, or tool (utils/sort.js)
+  sortOrder: ['date', 'source', 'tool'].includes(process.env.DIGEST_SORT) ? process.env.DIGEST_SORT : 'date',
+  // What to publish when there are no new items: skip, compact (a note
+  // naming the last item), or full (utils/empty-digest.js). Without
+  // EMPTY_DIGEST, SKIP_IF_EMPTY=false means full.
+  emptyDigest: ['skip', 'compact', 'full'].includes(process.env.EMPTY_DIGEST)
+    ? process.env.EMPTY_DIGEST
+    : process.env.SKIP_IF_EMPTY === 'false' ? 'full' : 'skip',
   dryRun: process.env.DRY_RUN === 'true',
 
   // Paths
   paths: {
     root: __dirname,
     state: join(__dirname, 'state'),
+    webhookAuditLog: join(__dirname, 'state', 'webhook-audit.log'),
+    webhookInbox: join(__dirname, 'state', 'webhook-inbox'),
     templates: __dirname,
     manualSubmissions: join(__dirname, '..', 'coverage-tracker', 'manual-submissions.json'),
     coverageTracker: join(__dirname, '..', 'coverage-tracker', 'coverage-tracker.json'),
+    // ETag / Last-Modified per feed URL, for conditional requests
+    feedValidators: join(__dirname, '..', 'coverage-tracker', 'feed-validators.json'),
+    // Tools announced on the blog that aren't configured yet (utils/tool-discovery.js)
+    pendingTools: join(__dirname, '..', 'coverage-tracker', 'pending-tools.json'),
+    // Per-source counts and timings from the last pipeline run
+    sourceRun: join(__dirname, 'state', 'source-run.json'),
+    // Items send-daily-digest.js has sent, and the state file it replaced
+    seenStore: join(__dirname, 'state', 'seen-items.json'),
+    digestState: join(__dirname, 'state', 'digest-state.json'),
   },
 
   // Praetorian tools to monitor
@@ -49,7 +334,71 @@ export const config = {
     'Snowcat', 'Chariot', 'GoKart',
   ],
 
-  // RSS feeds to monitor (cybersecurity publications)
+  // Tool renames and retirements, keyed by current tool name.
+  //   renamed_from:     former names, undated (see toolRules formerNames);
+  //                     mentions and stored items are attributed to the
+  //                     current name
+  //   deprecated_since: YYYY-MM-DD; after this the tool's search terms are
+  //                     dropped and it is left out of action items, but
+  //                     incidental mentions are still tagged
+  // e.g. 'Gato-X': { renamed_from: ['Gato X'] }, 'Nero': { deprecated_since: '2026-06-01' }
+  toolRegistry: {},
+
+  // A tool name counts as a mention only alongside one of these, or one of
+  // the tool's own context terms (toolRules), in the same item, so an
+  // article about Julius Caesar isn't tagged Julius
+  toolContext: ['Praetorian'],
+  // How each tool's mentions are recognized (utils/tools.js). Per tool:
+  //   aliases:        other spellings of the name; tagged, counted and
+  //                   shown as the tool's own name. No two tools may
+  //                   claim the same alias.
+  //   formerNames:    [{ name, until }], names the tool went by until
+  //                   `until` (YYYY-MM-DD, optional); stored items and
+  //                   stats fold them into the current name. A former
+  //                   name another tool has since taken means the renamed
+  //                   tool only on items dated before `until`.
+  //   context:        terms, besides toolContext, that show an item means
+  //                   the tool
+  //   requireContext: false for a name nothing else goes by
+  //   caseSensitive:  match only as written or in capitals; the default
+  //                   for names of up to six characters
+  // A tool listed here needn't be in `tools`. TOOL_RULES (JSON) adds to or
+  // replaces these, per tool.
+  toolRules: {
+    Brutus: {
+      context: ['credential', 'credentials', 'brute force', 'brute-force', 'brute forcing', 'password spraying', 'SSH', 'authentication'],
+    },
+    Augustus: {
+      context: ['LLM', 'LLMs', 'large language model', 'large language models', 'prompt injection', 'jailbreak', 'jailbreaks', 'red teaming'],
+    },
+    Julius: {
+      context: ['LLM', 'LLMs', 'fingerprinting', 'fingerprint', 'fingerprints', 'Ollama', 'model server', 'inference server'],
+    },
+    'Nosey Parker': { aliases: ['NoseyParker', 'nosey-parker'], requireContext: false },
+    FingerprintX: { requireContext: false },
+    Konstellation: { requireContext: false },
+    'Gato-X': { requireContext: false },
+    ...JSON.parse(process.env.TOOL_RULES || '{}'),
+  },
+
+  // New tools announced on the Praetorian Blog ("Introducing <Name>",
+  // "<Name>: Open Source"). A name not configured above is kept pending in
+  // paths.pendingTools and listed under Action Needed until it is added
+  // to `tools` or toolRules, or dismissed with TOOL_DISCOVERY_STOPWORDS.
+  toolDiscovery: {
+    enabled: process.env.TOOL_DISCOVERY !== 'false',
+    // Tag pending names right away, their items' confidence multiplied by
+    // pendingWeight
+    matchPending: process.env.MATCH_PENDING_TOOLS === 'true',
+    pendingWeight: parseFloat(process.env.PENDING_TOOL_WEIGHT || '0.5'),
+    // Words never taken as (part of) a tool name, besides the built-in ones
+    stopwords: (process.env.TOOL_DISCOVERY_STOPWORDS || '').split(',').map(s => s.trim()).filter(Boolean),
+  },
+
+  // RSS feeds to monitor (cybersecurity publications).
+  // Set `bootstrap: true` on a newly added feed to absorb its back catalogue
+  // into the tracker as already-seen (status "archived") instead of
+  // publishing it. Items inside the digest window are still published.
   rssFeeds: [
     {
       name: 'Help Net Security',
@@ -78,6 +427,30 @@ export const config = {
     },
   ],
 
+  // Canonical publisher names, ahead of the built-in ones in
+  // utils/publishers.js. Items are stored under `name` when their URL is on
+  // one of `domains` (a domain may carry a path, "example.com/blog") or
+  // their source string matches an alias. PUBLISHER_NAMES adds a JSON
+  // object of domain -> name, e.g. {"news.example.com": "Example News"}.
+  publishers: [
+    ...Object.entries(JSON.parse(process.env.PUBLISHER_NAMES || '{}')).map(([domain, name]) => ({ name, domains: [domain] })),
+  ],
+
+  // Which coverage sources run (monitors/registry.js). SOURCES lists them
+  // by name, replacing each source's own on/off setting; SOURCE_OPTIONS is
+  // a JSON object of per-source options, e.g. {"hacker-news": {"minPoints": 25}};
+  // SOURCE_MODULES adds sources from modules outside this repo. Sources
+  // run `concurrency` at a time, each given up on after timeoutMs.
+  sources: {
+    enabled: process.env.SOURCES
+      ? process.env.SOURCES.split(',').map(s => s.trim()).filter(Boolean)
+      : null,
+    options: JSON.parse(process.env.SOURCE_OPTIONS || '{}'),
+    modules: (process.env.SOURCE_MODULES || '').split(',').map(s => s.trim()).filter(Boolean),
+    concurrency: parseInt(process.env.SOURCE_CONCURRENCY || '4', 10),
+    timeoutMs: parseInt(process.env.SOURCE_TIMEOUT_MS || '60000', 10),
+  },
+
   // Google Alerts RSS feeds (user adds their own)
   googleAlertsFeeds: process.env.GOOGLE_ALERTS_RSS_URLS
     ? process.env.GOOGLE_ALERTS_RSS_URLS.split(',').map(url => ({
@@ -87,6 +460,53 @@ export const config = {
       }))
     : [],
 
+  // Google News search (monitors/google-news.js): one "<tool> Praetorian"
+  // query per tool, delayMs apart so Google doesn't block the runner
+  googleNews: {
+    enabled: process.env.GOOGLE_NEWS_ENABLED === 'true',
+    delayMs: parseInt(process.env.GOOGLE_NEWS_DELAY_MS || '3000', 10),
+    hl: 'en-US',
+    gl: 'US',
+    ceid: 'US:en',
+  },
+
+  // Commercial news search API (monitors/news-api.js); set a provider
+  // (currently only "bing") and its key to turn it on
+  newsApi: {
+    provider: process.env.NEWS_API_PROVIDER || '',
+    apiKey: process.env.NEWS_API_KEY || '',
+    market: process.env.NEWS_API_MARKET || 'en-US',
+    maxPages: 5,
+  },
+
+  // Mastodon (monitors/mastodon.js): status searches and hashtag timelines
+  // on one instance; on when both the instance and a token are set
+  mastodon: {
+    instance: process.env.MASTODON_INSTANCE || '',
+    accessToken: process.env.MASTODON_ACCESS_TOKEN || '',
+    hashtags: (process.env.MASTODON_HASHTAGS ?? 'infosec').split(',').map(s => s.trim().replace(/^#/, '')).filter(Boolean),
+    maxPages: 5,
+  },
+
+  // Podcast episodes (monitors/podcasts.js), from PodcastIndex when keyed,
+  // else the iTunes Search API. Directories list episodes late, so the
+  // search reaches extraLookbackDays past the usual window.
+  podcasts: {
+    enabled: process.env.PODCASTS_ENABLED === 'true',
+    extraLookbackDays: parseInt(process.env.PODCAST_EXTRA_LOOKBACK_DAYS || '14', 10),
+    podcastIndexKey: process.env.PODCASTINDEX_API_KEY || '',
+    podcastIndexSecret: process.env.PODCASTINDEX_API_SECRET || '',
+  },
+
+  // Hacker News search (monitors/hacker-news.js): stories about each tool
+  // and about Praetorian, through the Algolia API
+  hackerNews: {
+    enabled: process.env.HACKER_NEWS_ENABLED === 'true',
+    minPoints: parseInt(process.env.HACKER_NEWS_MIN_POINTS || '10', 10),
+    hitsPerPage: 100,
+    maxPages: 5,
+  },
+
   // Search terms for filtering RSS items
   searchTerms: [
     'praetorian',
@@ -104,3 +524,31 @@ export const config = {
     'gato-x',
   ],
 };
+
+assertToolNames(config.tools, config.toolRules);
+
+/**
+ * Fail at load on tool rules that can't be told apart: an alias (or tool
+ * name) claimed by two tools, in any case, or a formerNames `until` that
+ * isn't a date.
+ */
+function assertToolNames(tools, rules) {
+  const owners = new Map();
+  const claim = (name, tool) => {
+    const key = name.trim().toLowerCase().replace(/\s+/g, ' ');
+    const owner = owners.get(key);
+    if (owner && owner !== tool) {
+      throw new Error(`Tool alias "${name}" is claimed by both ${owner} and ${tool} (config.toolRules / TOOL_RULES)`);
+    }
+    owners.set(key, tool);
+  };
+  for (const tool of new Set([...tools, ...Object.keys(rules)])) claim(tool, tool);
+  for (const [tool, rule] of Object.entries(rules)) {
+    for (const alias of rule.aliases || []) claim(alias, tool);
+    for (const { name, until } of rule.formerNames || []) {
+      if (until && Number.isNaN(new Date(until).getTime())) {
+        throw new Error(`Former name "${name}" of ${tool} has an invalid until date "${until}" (expected YYYY-MM-DD)`);
+      }
+    }
+  }
+}
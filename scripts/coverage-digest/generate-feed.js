@@ -0,0 +1,61 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - Atom Feed
+ *
+ * Writes the coverage stream as an Atom feed, for readers who would rather
+ * subscribe than follow the digest issues. The feed holds the most recent
+ * FEED_MAX_ENTRIES items from the whole tracker, not just today's digest.
+ * Items that fail the digest's validation (utils/validate.js) are left
+ * out, or fail the run with DIGEST_VALIDATION=strict.
+ * Output is deterministic, and the file is only rewritten when it changes.
+ *
+ * Usage:
+ *   node generate-feed.js              # Write the feed to FEED_PATH
+ *   node generate-feed.js --dry-run    # Report whether it would change
+ *   node generate-feed.js --stdout     # Print the feed instead
+ */
+
+import { readFile, writeFile, mkdir } from 'fs/promises';
+import { dirname } from 'path';
+import { config } from './config.js';
+import { loadTracker } from './utils/tracker.js';
+import { renderAtomFeed } from './utils/atom.js';
+import { publishableTrackerItems } from './utils/validate.js';
+
+const isDryRun = process.argv.includes('--dry-run');
+const toStdout = process.argv.includes('--stdout');
+
+async function main() {
+  const tracker = await loadTracker(config.paths.coverageTracker);
+  const feed = renderAtomFeed(publishableTrackerItems(tracker));
+
+  if (toStdout) {
+    process.stdout.write(feed);
+    return;
+  }
+
+  const { path } = config.feed;
+  let existing = null;
+  try {
+    existing = await readFile(path, 'utf-8');
+  } catch (err) {
+    if (err.code !== 'ENOENT') throw err;
+  }
+
+  const entries = feed.split('<entry>').length - 1;
+  if (existing === feed) {
+    console.log(`Feed unchanged: ${path} (${entries} entries)`);
+    return;
+  }
+  console.log(`${existing === null ? 'create' : 'update'} ${path} (${entries} entries)${isDryRun ? ' [dry run]' : ''}`);
+  if (!isDryRun) {
+    await mkdir(dirname(path), { recursive: true });
+    await writeFile(path, feed);
+  }
+}
+
+main().catch(err => {
+  console.error('\nFATAL:', err.message);
+  process.exit(1);
+});
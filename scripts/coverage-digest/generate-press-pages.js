@@ -0,0 +1,70 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - Per-Tool Press Pages
+ *
+ * Generates one markdown page per tool from the coverage tracker, listing
+ * that tool's coverage grouped by month (newest first). Output has
+ * Hugo- or Jekyll-compatible front matter and is fully deterministic:
+ * re-running with an unchanged tracker produces no diff, and files are
+ * only rewritten when their content changes.
+ *
+ * Usage:
+ *   node generate-press-pages.js                 # Write pages to PRESS_PAGES_DIR
+ *   node generate-press-pages.js --dry-run       # Report what would change
+ */
+
+import { readFile, writeFile, mkdir } from 'fs/promises';
+import { join } from 'path';
+import { config } from './config.js';
+import { loadTracker } from './utils/tracker.js';
+import { pressPageItems, renderToolPage, slugify } from './utils/press-pages.js';
+
+const isDryRun = process.argv.includes('--dry-run');
+
+async function main() {
+  const { outputDir } = config.pressPages;
+  const tracker = await loadTracker(config.paths.coverageTracker);
+  console.log(`Loaded tracker: ${tracker.length} items`);
+  console.log(`Output: ${outputDir} (${config.pressPages.frontMatter} front matter)\n`);
+
+  if (!isDryRun) {
+    await mkdir(outputDir, { recursive: true });
+  }
+
+  let written = 0;
+  let unchanged = 0;
+
+  for (const tool of config.tools) {
+    const items = pressPageItems(tracker, tool);
+    if (items.length === 0) continue;
+
+    const path = join(outputDir, `${slugify(tool)}.md`);
+    const content = renderToolPage(tool, items);
+
+    let existing = null;
+    try {
+      existing = await readFile(path, 'utf-8');
+    } catch (err) {
+      if (err.code !== 'ENOENT') throw err;
+    }
+
+    if (existing === content) {
+      unchanged++;
+      continue;
+    }
+
+    console.log(`  ${existing === null ? 'create' : 'update'} ${path} (${items.length} items)`);
+    if (!isDryRun) {
+      await writeFile(path, content);
+    }
+    written++;
+  }
+
+  console.log(`\nPress pages: ${written} ${isDryRun ? 'would change' : 'written'}, ${unchanged} unchanged`);
+}
+
+main().catch(err => {
+  console.error('\nFATAL:', err.message);
+  process.exit(1);
+});
@@ -0,0 +1,212 @@
+import Parser from 'rss-parser';
+import { config } from '../config.js';
+import { clientFor } from '../utils/http-client.js';
+import { detectTools, isDeprecated } from '../utils/tools.js';
+import { normalizeUrl } from '../utils/tracker.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+import { parseFeedDate } from './rss-feeds.js';
+import { registerSource } from './registry.js';
+
+const SOURCE = 'Google News';
+const SEARCH_URL = 'https://news.google.com/rss/search';
+const GOOGLE_NEWS_HOST = 'news.google.com';
+
+// <source url="https://publisher.example">Publisher</source> on each item
+const parser = new Parser({ customFields: { item: ['source'] } });
+
+/**
+ * The Google News query for a tool: the name, quoted when it is more than
+ * one word or has punctuation ("Nosey Parker", "Gato-X"), plus Praetorian.
+ */
+export function searchQuery(tool) {
+  const name = tool.replace(/"/g, '').trim();
+  return `${/^[\p{L}\p{N}]+$/u.test(name) ? name : `"${name}"`} Praetorian`;
+}
+
+/**
+ * Search Google News for each active tool plus "Praetorian" and return the
+ * results as coverage items. Result links point at news.google.com; each
+ * is resolved to the publisher's URL first, and a result that can't be
+ * resolved is skipped. An article found by several queries is returned
+ * once, tagged with the tools its headline mentions (utils/tools.js
+ * detectTools), not the tool whose query found it.
+ *
+ * @param {Date} since - Only return items published after this date
+ * @param {Array} [failures] - Receives { source, error } for each failed query
+ * @param {Object} [options]
+ *   delayMs - pause between requests to Google (default: config.googleNews.delayMs)
+ *   tools   - tools to search for (default: config.tools, less retired ones)
+ *   client  - HTTP client (default: the "Google News" client)
+ *   sleep   - delay function, for tests
+ *   memo    - the run's query memo (utils/query-memo.js; default: a new one)
+ *   signal  - aborts the requests in flight (the run's, once the source times out)
+ * @returns {Promise<Array>} Array of coverage items
+ */
+export async function checkGoogleNews(since, failures = [], {
+  delayMs = config.googleNews.delayMs,
+  tools = config.tools.filter(tool => !isDeprecated(tool)),
+  client = clientFor(SOURCE),
+  sleep = delay,
+  memo = createQueryMemo(),
+  signal = null,
+} = {}) {
+  console.log('Searching Google News...');
+
+  // Every request to Google waits out the delay since the previous one
+  let last = null;
+  const throttled = async (url, init) => {
+    if (last !== null) await sleep(Math.max(0, delayMs - (Date.now() - last)));
+    try {
+      return await client.fetch(url, { ...init, signal });
+    } finally {
+      last = Date.now();
+    }
+  };
+
+  const resolved = new Map();
+  const byUrl = new Map();
+  let unresolved = 0;
+
+  for (const tool of tools) {
+    const query = searchQuery(tool);
+    let results;
+    try {
+      results = await memo('google-news', query, since, () => search(throttled, query));
+    } catch (err) {
+      console.warn(`  Warning: Google News search for ${query} failed: ${err.message}`);
+      failures.push({ source: SOURCE, error: `${query}: ${err.message}` });
+      continue;
+    }
+
+    for (const result of results) {
+      const pubDate = parseFeedDate(result.pubDate) || parseFeedDate(result.isoDate);
+      if (pubDate && since && pubDate < since) continue;
+
+      // The same result often comes back for several tools
+      if (!resolved.has(result.link)) {
+        resolved.set(result.link, await resolveArticleUrl(throttled, result.link).catch(() => null));
+      }
+      const url = resolved.get(result.link);
+      if (!url) {
+        unresolved++;
+        continue;
+      }
+
+      const key = normalizeUrl(url);
+      const existing = byUrl.get(key);
+      if (existing) {
+        if (!existing.matchedTerms.includes(query)) existing.matchedTerms.push(query);
+        continue;
+      }
+
+      const { publisher, title } = splitTitle(result);
+      const toolsMentioned = detectTools(title);
+      byUrl.set(key, {
+        source: publisher || SOURCE,
+        sourceType: 'rss',
+        discoveredBy: 'google-news',
+        icon: '📰',
+        title: title || 'Untitled',
+        url,
+        date: pubDate ? pubDate.toISOString() : new Date().toISOString(),
+        undated: !pubDate,
+        // The result's description is just the headline again
+        excerpt: '',
+        toolsMentioned,
+        untagged: toolsMentioned.length === 0,
+        matchedTerms: [query],
+        bootstrap: false,
+        raw: {
+          guid: url,
+        },
+      });
+    }
+  }
+
+  const items = [...byUrl.values()];
+  console.log(`  Found ${items.length} article(s) across ${tools.length} Google News queries`);
+  if (unresolved > 0) {
+    console.warn(`  Warning: Skipped ${unresolved} Google News result(s) whose article URL couldn't be resolved`);
+  }
+  return items;
+}
+
+/**
+ * Resolve a Google News result link to the article's own URL. Older
+ * links carry the URL base64-encoded in the article id; newer ones only
+ * give it up from the news.google.com page (a redirect, or the page's
+ * data-n-au attribute). Returns null if neither works.
+ */
+export async function resolveArticleUrl(fetch, link) {
+  let parsed;
+  try {
+    parsed = new URL(link);
+  } catch {
+    return null;
+  }
+  if (parsed.hostname !== GOOGLE_NEWS_HOST) return link;
+
+  const id = parsed.pathname.split('/').pop();
+  const embedded = Buffer.from(id, 'base64url').toString('latin1').match(/https?:\/\/[\x21-\x7e]+/);
+  if (embedded) return embedded[0];
+
+  // HEAD isn't followed, so a redirect shows its Location (unless it's
+  // to another Google page, e.g. the cookie consent screen)
+  const head = await fetch(link, { method: 'HEAD' });
+  const location = head.headers?.location && new URL(head.headers.location, link);
+  if (location && !/(^|\.)google\.com$/.test(location.hostname)) {
+    return location.toString();
+  }
+  const page = await fetch(link);
+  if (!page.ok) return null;
+  const attribute = (await page.text()).match(/data-n-au="([^"]+)"/);
+  return attribute ? decodeEntities(attribute[1]) : null;
+}
+
+async function search(fetch, query) {
+  const { hl, gl, ceid } = config.googleNews;
+  const params = new URLSearchParams({ q: query, hl, gl, ceid });
+  const res = await fetch(`${SEARCH_URL}?${params}`);
+  if (!res.ok) {
+    throw new Error(`HTTP ${res.status}`);
+  }
+  return (await parser.parseString(await res.text())).items || [];
+}
+
+// Result titles read "Headline - Publisher"; the <source> element names
+// the publisher too
+function splitTitle(result) {
+  const title = (result.title || '').replace(/\s+/g, ' ').trim();
+  const source = result.source;
+  const publisher = (typeof source === 'string' ? source : source?._ || '').trim();
+  if (publisher && title.endsWith(` - ${publisher}`)) {
+    return { publisher, title: title.slice(0, -` - ${publisher}`.length) };
+  }
+  const dash = title.lastIndexOf(' - ');
+  if (!publisher && dash > 0) {
+    return { publisher: title.slice(dash + 3), title: title.slice(0, dash) };
+  }
+  return { publisher, title };
+}
+
+function decodeEntities(text) {
+  return text
+    .replace(/&quot;/g, '"')
+    .replace(/&#39;/g, "'")
+    .replace(/&lt;/g, '<')
+    .replace(/&gt;/g, '>')
+    .replace(/&amp;/g, '&');
+}
+
+function delay(ms) {
+  return new Promise(resolve => setTimeout(resolve, ms));
+}
+
+registerSource({
+  name: 'google-news',
+  get defaults() {
+    return { delayMs: config.googleNews.delayMs };
+  },
+  enabled: () => config.googleNews.enabled,
+  fetch: (since, failures, { delayMs }, { memo, signal }) => checkGoogleNews(since, failures, { delayMs, memo, signal }),
+});
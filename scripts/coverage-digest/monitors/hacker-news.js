@@ -0,0 +1,160 @@
+import { config } from '../config.js';
+import { clientFor } from '../utils/http-client.js';
+import { detectTools, isDeprecated } from '../utils/tools.js';
+import { normalizeUrl } from '../utils/tracker.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+import { registerSource } from './registry.js';
+
+const SOURCE = 'Hacker News';
+const SEARCH_URL = 'https://hn.algolia.com/api/v1/search';
+const ITEM_URL = 'https://news.ycombinator.com/item?id=';
+
+/**
+ * Search Hacker News (through the Algolia API) for stories about each
+ * active tool and about Praetorian, created after `since` and with at
+ * least `minPoints` points. Each story becomes an item linking to the
+ * story's URL, or to the HN thread for text posts, with a `hackerNews`
+ * record ({ id, points, comments, url }) where `url` is the thread.
+ *
+ * A story found by several queries is returned once. It's tagged with the
+ * tools its title mentions (utils/tools.js detectTools), not the query
+ * that found it: a search for "Brutus" also finds Shakespeare. Use
+ * foldHackerNews() to turn stories about an
+ * article that's already tracked into annotations on that article.
+ *
+ * @param {Date} since - Only return stories created after this date
+ * @param {Array} [failures] - Receives { source, error } for each failed query
+ * @param {Object} [options]
+ *   minPoints - leave out stories with fewer points (default: config.hackerNews.minPoints)
+ *   tools     - tools to search for (default: config.tools, less retired ones)
+ *   client    - HTTP client (default: the "Hacker News" client)
+ *   memo      - the run's query memo (utils/query-memo.js; default: a new one)
+ *   signal    - aborts the requests in flight (the run's, once the source times out)
+ * @returns {Promise<Array>} Array of coverage items
+ */
+export async function checkHackerNews(since, failures = [], {
+  minPoints = config.hackerNews.minPoints,
+  tools = config.tools.filter(tool => !isDeprecated(tool)),
+  client = clientFor(SOURCE),
+  memo = createQueryMemo(),
+  signal = null,
+} = {}) {
+  console.log('Searching Hacker News...');
+
+  const numericFilters = [`points>=${minPoints}`];
+  if (since) numericFilters.push(`created_at_i>${Math.floor(since.getTime() / 1000)}`);
+
+  const byUrl = new Map();
+  for (const query of [...tools, 'praetorian']) {
+    let stories;
+    try {
+      stories = await memo('hacker-news', `${query} (${minPoints}+ points)`, since, () => search(client, query, numericFilters.join(','), signal));
+    } catch (err) {
+      console.warn(`  Warning: Hacker News search for "${query}" failed: ${err.message}`);
+      failures.push({ source: SOURCE, error: `"${query}": ${err.message}` });
+      continue;
+    }
+
+    for (const story of stories) {
+      const thread = `${ITEM_URL}${story.objectID}`;
+      const url = story.url || thread;
+      if (byUrl.has(normalizeUrl(url))) continue;
+
+      const title = (story.title || '').replace(/\s+/g, ' ').trim();
+      const toolsMentioned = detectTools(title);
+      byUrl.set(normalizeUrl(url), {
+        source: SOURCE,
+        sourceType: 'rss',
+        discoveredBy: 'hacker-news',
+        icon: '🟧',
+        title: title || 'Untitled',
+        url,
+        date: story.created_at || new Date(story.created_at_i * 1000).toISOString(),
+        excerpt: '',
+        toolsMentioned,
+        untagged: toolsMentioned.length === 0,
+        matchedTerms: [query],
+        bootstrap: false,
+        hackerNews: {
+          id: story.objectID,
+          points: story.points || 0,
+          comments: story.num_comments || 0,
+          url: thread,
+        },
+        raw: {
+          guid: thread,
+        },
+      });
+    }
+  }
+
+  const items = [...byUrl.values()];
+  console.log(`  Found ${items.length} stor${items.length === 1 ? 'y' : 'ies'} with ${minPoints}+ points`);
+  return items;
+}
+
+/**
+ * Fold Hacker News stories about articles that were found elsewhere into
+ * those articles, so a discussion doesn't show up as a second item. A
+ * story whose URL matches another discovered item sets that item's
+ * `hackerNews`; one matching an item already in the tracker updates its
+ * `hacker_news` in place. Returns the stories that matched nothing, to be
+ * tracked as items of their own.
+ */
+export function foldHackerNews(stories, discovered, tracker) {
+  const found = new Map(discovered.map(item => [normalizeUrl(item.url), item]));
+  const tracked = new Map(tracker.map(item => [normalizeUrl(item.url), item]));
+  const rest = [];
+
+  for (const story of stories) {
+    const key = normalizeUrl(story.url);
+    const item = found.get(key);
+    if (item) {
+      item.hackerNews = story.hackerNews;
+    } else if (tracked.has(key)) {
+      tracked.get(key).hacker_news = story.hackerNews;
+    } else {
+      rest.push(story);
+    }
+  }
+  return rest;
+}
+
+// Every page of results for a query; Algolia pages are 0-based
+async function search(client, query, numericFilters, signal) {
+  const stories = [];
+  for (let page = 0; page < config.hackerNews.maxPages; page++) {
+    const params = new URLSearchParams({
+      query,
+      tags: 'story',
+      numericFilters,
+      hitsPerPage: String(config.hackerNews.hitsPerPage),
+      page: String(page),
+    });
+    const res = await client.fetch(`${SEARCH_URL}?${params}`, { signal });
+    if (!res.ok) {
+      throw new Error(`HTTP ${res.status}`);
+    }
+    const body = await res.json();
+    stories.push(...(body.hits || []));
+    if (page + 1 >= (body.nbPages || 0)) break;
+  }
+  return stories;
+}
+
+registerSource({
+  name: 'hacker-news',
+  get defaults() {
+    return { minPoints: config.hackerNews.minPoints };
+  },
+  enabled: () => config.hackerNews.enabled,
+  fetch: (since, failures, { minPoints }, { memo, signal }) => checkHackerNews(since, failures, { minPoints, memo, signal }),
+  // A thread about an article we already have annotates it instead
+  reconcile(stories, others, { tracker = [] }) {
+    const rest = foldHackerNews(stories, others, tracker);
+    if (rest.length < stories.length) {
+      console.log(`  ${stories.length - rest.length} Hacker News discussion(s) annotate items already found`);
+    }
+    return rest;
+  },
+});
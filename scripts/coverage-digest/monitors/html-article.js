@@ -0,0 +1,547 @@
+import { config } from '../config.js';
+import { cleanUrl, declaredCanonical } from '../utils/canonical-url.js';
+import { sharedCrawler } from '../utils/crawler.js';
+import { excerpt, splitSentences } from '../utils/excerpt.js';
+import { canonicalTools, hasContextTerm, sharedToolDetector } from '../utils/tools.js';
+import { normalizeUrl } from '../utils/tracker.js';
+import { parseFeedDate } from './rss-feeds.js';
+
+// Meta tags that hold a publish date, in order of trust
+const DATE_META = [
+  'article:published_time', 'og:published_time', 'datepublished', 'publishdate', 'pubdate',
+  'parsely-pub-date', 'sailthru.date', 'dc.date.issued', 'dc.date', 'date',
+];
+// A date in an article's path: /2026/02/13/, /2026-02-13-slug, /20260213/
+const URL_DATE = /\/((?:19|20)\d{2})([/-])(\d{1,2})\2(\d{1,2})(?=[/-]|\.html?$|$)|\/((?:19|20)\d{2})(\d{2})(\d{2})(?=\/|$)/;
+// A lede is a real paragraph: long enough, and mostly not links
+const MIN_LEDE_CHARS = 80;
+const MAX_LINK_DENSITY = 0.5;
+// Paragraphs in page furniture rather than the article body
+const FURNITURE = /comment|promo|share|social|related|sidebar|newsletter|subscribe|byline|caption|credit|cookie|consent|footer|disclaimer/i;
+// Scripts of the common paywall providers
+const PAYWALL_SCRIPTS = [
+  ['Piano', /tinypass\.com|cdn\.piano\.io|experience\.piano\.io/i],
+  ['Zephr', /zephr/i],
+  ['Poool', /poool\.fr/i],
+  ['Pelcro', /pelcro\.com/i],
+  ['LaterPay', /laterpay/i],
+  ['Memberful', /memberful\.com/i],
+  ['Wallkit', /wallkit\.net/i],
+];
+// Consent management platforms, whose interstitials stand in for the
+// article in some regions
+const CONSENT_MARKERS = /cookiebot|cookielaw\.org|onetrust|didomi|sourcepoint|sp_message|quantcast\.mgr|trustarc|usercentrics|consentmanager|fundingchoicesmessages/i;
+const CONSENT_TITLE = /before you continue|cookie consent|we value your privacy|your privacy choices|privacy settings/i;
+// Less text than this on a page with a consent platform means the
+// platform's interstitial is all there is
+const CONSENT_WALL_CHARS = 200;
+// A feed description at least this long, and longer than the page's
+// whole article text, means the page is holding the article back
+const MIN_DESCRIPTION_CHARS = 200;
+// Article text kept on an item, for its article hash (utils/tracker.js
+// articleHash reads the first 500 characters)
+const BODY_TEXT_CHARS = 2000;
+// Most characters of an item's mention context
+const MENTION_CONTEXT_CHARS = 300;
+// Redirects followed when resolving a link, as http-client.js follows
+const MAX_REDIRECTS = 5;
+// Resolved links, by cleaned URL, for the rest of the run
+const resolutions = new Map();
+// Phones without cookies are often let past consent interstitials
+const MOBILE_USER_AGENT = 'Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1';
+const NAMED_ENTITIES = {
+  amp: '&', lt: '<', gt: '>', quot: '"', apos: "'", nbsp: ' ', ndash: '–', mdash: '—',
+  lsquo: '‘', rsquo: '’', ldquo: '“', rdquo: '”', hellip: '…', copy: '©', reg: '®', trade: '™',
+};
+
+/**
+ * Read an article page for sources that have no feed or API: fetch it
+ * (the body capped at config.scrape.maxBytes, decoded in its declared
+ * charset) and extract its metadata with extractArticle(). Throws if the
+ * page can't be fetched or isn't HTML; a page robots.txt keeps us from
+ * throws an error with `disallowed: true`, and an HTTP 402 or 403 one
+ * with `paywalled: true`.
+ *
+ * A GDPR consent interstitial in place of the article is fetched once
+ * more as a phone without cookies, which is often let through; if that
+ * gets the interstitial again, it throws rather than take the consent
+ * page for the article.
+ *
+ * @param {string} url - Article URL
+ * @param {Object} [options]
+ *   client   - HTTP client (default: the run's polite crawler, utils/crawler.js)
+ *   maxBytes - most of the page to read (default: config.scrape.maxBytes)
+ *   signal   - aborts the request in flight
+ * @returns {Promise<Object>} { url, title, date, siteName, excerpt, bodyText, links, bodyLength, paywall, consentWall }
+ */
+export async function scrapeArticle(url, {
+  client = sharedCrawler(),
+  maxBytes = config.scrape.maxBytes,
+  signal = null,
+} = {}) {
+  const page = await readPage(client, url, {}, maxBytes, signal);
+  if (!page.consentWall) return page;
+
+  const retried = await readPage(client, url, { 'User-Agent': `${MOBILE_USER_AGENT} ${config.http.userAgent}` }, maxBytes, signal);
+  if (retried.consentWall) {
+    throw new Error(`Consent wall at ${new URL(retried.url).host}`);
+  }
+  return retried;
+}
+
+/**
+ * Extract an article's metadata from its HTML:
+ *
+ *   title    og:title, else twitter:title, else <title>
+ *   date     article:published_time, else JSON-LD datePublished, else the
+ *            usual date meta tags or a <time datetime>, else a date in the
+ *            URL's path (/2026/02/13/); ISO 8601, or null
+ *   siteName og:site_name, else the host name without "www."
+ *   excerpt  the lede: the first substantial paragraph of the article
+ *            body (inside <article> or <main> when there is one), skipping
+ *            navigation, captions, share bars and the like
+ *   bodyText    the start of the article text: its paragraphs, as above,
+ *               joined with spaces
+ *   articleText the whole article text, as bodyText
+ *   links       where the article text links to: absolute http(s) URLs,
+ *               each once, in order
+ *   bodyLength  characters of article text on the page
+ *   paywall     why the page looks paywalled (isAccessibleForFree: false
+ *               in JSON-LD, a "locked" content tier, or a paywall
+ *               provider's script), or null
+ *   consentWall whether the page is a consent interstitial rather than
+ *               the article: a consent.* or guce.* host, or a consent
+ *               platform's page with next to no text
+ *   canonicalUrl the URL the page names as its own (rel=canonical, then
+ *               og:url), cleaned; null when there's none to trust
+ *
+ * Missing fields are '' (date: null).
+ */
+export function extractArticle(html, url) {
+  const meta = readMeta(html);
+  const titleTag = html.match(/<title\b[^>]*>([\s\S]*?)<\/title>/i)?.[1];
+
+  const published = parseFeedDate(meta['article:published_time'])
+    || parseFeedDate(jsonLdValues(html, 'datePublished').find(value => typeof value === 'string'))
+    || DATE_META.slice(1).map(key => parseFeedDate(meta[key])).find(Boolean)
+    || parseFeedDate(html.match(/<time\b[^>]*\bdatetime\s*=\s*["']([^"']+)["']/i)?.[1])
+    || dateFromUrl(url);
+
+  let host = '';
+  try {
+    host = new URL(url).hostname;
+  } catch {
+    // leave the site name empty
+  }
+
+  const paragraphs = articleParagraphs(html);
+  const bodyLength = paragraphs.reduce((sum, { text }) => sum + text.length, 0);
+  const title = plainText(meta['og:title'] || meta['twitter:title'] || titleTag || '');
+
+  return {
+    url,
+    title,
+    date: published ? published.toISOString() : null,
+    siteName: plainText(meta['og:site_name'] || '') || host.replace(/^www\./, ''),
+    excerpt: excerpt(findLede(paragraphs), 280),
+    bodyText: paragraphs.map(({ text }) => text).filter(Boolean).join(' ').slice(0, BODY_TEXT_CHARS),
+    articleText: paragraphs.map(({ text }) => text).filter(Boolean).join(' '),
+    links: paragraphLinks(paragraphs, url),
+    bodyLength,
+    paywall: paywallSignal(html, meta),
+    consentWall: /^(consent|guce)\./i.test(host)
+      || ((CONSENT_MARKERS.test(html) || CONSENT_TITLE.test(title)) && bodyLength < CONSENT_WALL_CHARS),
+    canonicalUrl: declaredCanonical(canonicalLink(html), url) || declaredCanonical(meta['og:url'], url),
+  };
+}
+
+/**
+ * Where a link ends up: its redirects followed with HEAD requests, up to
+ * five, and the result cleaned (see cleanUrl). A site that refuses HEAD
+ * is read with GET instead, and then the canonical URL the page declares
+ * wins. Requests go through the crawler, so robots.txt and per-host
+ * pacing apply; throws if the link can't be followed.
+ */
+export async function resolveUrl(url, { client = sharedCrawler(), maxBytes = config.scrape.maxBytes } = {}) {
+  let current = cleanUrl(url);
+  for (let hops = 0; ; hops++) {
+    let res;
+    try {
+      res = await client.fetch(current, { method: 'HEAD', headers: { Accept: 'text/html,application/xhtml+xml' } });
+    } catch (err) {
+      if (err.disallowed) throw err;
+      break;
+    }
+    const location = res.headers.location;
+    if (res.status >= 300 && res.status < 400 && location) {
+      if (hops >= MAX_REDIRECTS) throw new Error(`Too many redirects resolving ${url}`);
+      current = cleanUrl(new URL(location, current).toString());
+      continue;
+    }
+    if (res.ok) return current;
+    break;
+  }
+
+  const page = await readPage(client, current, {}, maxBytes);
+  return page.canonicalUrl || cleanUrl(page.url);
+}
+
+/**
+ * Give every item its canonical URL before dedup, so an article linked
+ * through a feed proxy, an AMP page and a ?utm_source=rss variant is one
+ * item. Links already in the tracker (as `url` or `url_original`) take the
+ * tracked URL; others are resolved with resolveUrl() when `resolve`
+ * (config.scrape.resolveUrls) is on, config.scrape.concurrency at a time,
+ * and only cleaned otherwise, or when they can't be followed.
+ * Resolutions are cached for the run. A changed item keeps the link its
+ * source gave as `originalUrl`. Returns the number of items changed.
+ */
+export async function canonicalizeItems(items, {
+  tracker = [],
+  resolve = resolveUrl,
+  resolveLinks = config.scrape.resolveUrls,
+} = {}) {
+  const known = new Map();
+  for (const item of tracker) {
+    if (item.url_original) known.set(normalizeUrl(item.url_original), item.url);
+    known.set(normalizeUrl(item.url), item.url);
+  }
+  let count = 0;
+
+  const pending = items.filter(item => item.url);
+  const batchSize = Math.max(1, config.scrape.concurrency);
+  for (let i = 0; i < pending.length; i += batchSize) {
+    const batch = pending.slice(i, i + batchSize);
+    await Promise.all(batch.map(async item => {
+      let canonical = known.get(normalizeUrl(item.url)) || cleanUrl(item.url);
+      if (resolveLinks && !known.has(normalizeUrl(item.url))) {
+        if (!resolutions.has(canonical)) resolutions.set(canonical, resolve(canonical));
+        try {
+          canonical = await resolutions.get(canonical);
+        } catch (err) {
+          console.log(`  Could not resolve ${item.url}: ${err.message}`);
+        }
+      }
+      if (canonical === item.url) return;
+      item.originalUrl = item.originalUrl || item.url;
+      item.url = canonical;
+      count++;
+    }));
+  }
+  return count;
+}
+
+/**
+ * Decode a page body in its character set: a byte order mark, else the
+ * Content-Type charset, else a <meta charset> in the first 1024 bytes,
+ * else UTF-8. An unknown charset is read as UTF-8.
+ */
+export function decodeHtml(buffer, contentType = '') {
+  const charset = bomCharset(buffer)
+    || contentType.match(/charset\s*=\s*["']?([\w.:-]+)/i)?.[1]
+    || buffer.subarray(0, 1024).toString('latin1').match(/<meta\b[^>]*charset\s*=\s*["']?\s*([\w.:-]+)/i)?.[1]
+    || 'utf-8';
+  try {
+    return new TextDecoder(charset).decode(buffer);
+  } catch {
+    return new TextDecoder('utf-8').decode(buffer);
+  }
+}
+
+/**
+ * Fill in what other sources left out: scrape the page of each item that
+ * has no excerpt, or no publish date of its own (only a found-on date,
+ * flagged `undated`, or none at all), and take the page's lede and publish
+ * date. Items already in the tracker are skipped, since they were
+ * enriched when first found. Pages are fetched config.scrape.concurrency
+ * at a time; a page that can't be read leaves its item as it was, and one
+ * robots.txt keeps us from is flagged `unfetched`.
+ *
+ * An item still without a publish date afterwards keeps the time it was
+ * found (or is given it, if it had none) and is flagged `dateEstimated`.
+ * Every item whose page is read gets the start of its text as `bodyText`,
+ * for its article hash (see utils/tracker.js articleHash), and the links
+ * in it as `bodyLinks`, for its mention confidence (utils/confidence.js),
+ * and the sentence naming us as `mentionContext` (see mentionContext).
+ *
+ * With `paywalls` (config.scrape.paywalls), every new item's page is read,
+ * and items behind a paywall are flagged `paywalled`: the page says so
+ * (see extractArticle), answers 402 or 403, or has less article text than
+ * the feed's description of it. Returns the number of items changed.
+ */
+export async function enrichItems(items, {
+  tracker = [],
+  scrape = scrapeArticle,
+  paywalls = config.scrape.paywalls,
+} = {}) {
+  const tracked = new Set(tracker.map(item => normalizeUrl(item.url)));
+  const pending = items.filter(item =>
+    item.url && !tracked.has(normalizeUrl(item.url)) && (paywalls || !item.excerpt || needsDate(item))
+  );
+  let count = 0;
+
+  const batchSize = Math.max(1, config.scrape.concurrency);
+  for (let i = 0; i < pending.length; i += batchSize) {
+    const batch = pending.slice(i, i + batchSize);
+    await Promise.all(batch.map(async item => {
+      try {
+        const page = await scrape(item.url);
+        let changed = false;
+        if (page.canonicalUrl && page.canonicalUrl !== item.url) {
+          item.originalUrl = item.originalUrl || item.url;
+          item.url = page.canonicalUrl;
+          changed = true;
+        }
+        const paywall = paywalls && (page.paywall || shortBody(item.excerpt, page));
+        if (paywall) {
+          console.log(`  🔒 ${item.url}: ${paywall}`);
+          item.paywalled = true;
+          changed = true;
+        }
+        if (!item.excerpt && page.excerpt) {
+          item.excerpt = page.excerpt;
+          changed = true;
+        }
+        // Not shown anywhere; tells a moved article from a new one
+        if (page.bodyText) item.bodyText = page.bodyText;
+        if (page.links?.length) item.bodyLinks = page.links;
+        const context = mentionContext(page.articleText || page.bodyText, item);
+        if (context) {
+          item.mentionContext = context;
+          changed = true;
+        }
+        if (needsDate(item) && page.date) {
+          item.date = page.date;
+          delete item.undated;
+          delete item.dateEstimated;
+          changed = true;
+        }
+        if (changed) count++;
+      } catch (err) {
+        if (err.disallowed) {
+          console.log(`  Skipping ${item.url}: ${err.message}`);
+          item.unfetched = true;
+          return;
+        }
+        if (err.paywalled && paywalls) {
+          console.log(`  🔒 ${item.url}: ${err.message}`);
+          item.paywalled = true;
+          count++;
+          return;
+        }
+        console.warn(`  Warning: Could not read ${item.url}: ${err.message}`);
+      } finally {
+        if (needsDate(item)) estimateDate(item);
+      }
+    }));
+  }
+  return count;
+}
+
+/**
+ * The first sentence of an article's text that names one of the item's
+ * tools, or a context term ("Praetorian"): where the article mentions us,
+ * which the lede shown as its excerpt often doesn't. Cut to
+ * MENTION_CONTEXT_CHARS as an excerpt is. Null when no sentence does (the
+ * page was paywalled, and only the title names us), or when the one that
+ * does only repeats the title or is already in the excerpt.
+ *
+ * @param {string} text - the article text (extractArticle's articleText)
+ * @param {Object} item - title, excerpt and toolsMentioned are read
+ * @param {Object} [options]
+ *   detector - tool detector (default: utils/tools.js sharedToolDetector())
+ * @returns {string|null}
+ */
+export function mentionContext(text, item, { detector = sharedToolDetector() } = {}) {
+  const tools = canonicalTools(item.toolsMentioned || []);
+  const sentence = splitSentences(text).find(candidate =>
+    detector.detect(candidate, { assumeContext: true }).some(tool => tools.includes(tool)) || hasContextTerm(candidate)
+  );
+  if (!sentence) return null;
+  const comparable = value => String(value || '').toLowerCase().replace(/[^\p{L}\p{N}]+/gu, ' ').trim();
+  const found = comparable(sentence);
+  if (found === comparable(item.title) || comparable(item.excerpt).includes(found)) return null;
+  return excerpt(sentence, MENTION_CONTEXT_CHARS);
+}
+
+// Only a found-on date, or no usable date at all (missing, or the epoch)
+function needsDate(item) {
+  return Boolean(item.undated) || !(Date.parse(item.date) > 0);
+}
+
+function estimateDate(item) {
+  if (!(Date.parse(item.date) > 0)) item.date = new Date().toISOString();
+  item.dateEstimated = true;
+}
+
+async function readPage(client, url, headers, maxBytes, signal = null) {
+  const res = await client.fetch(url, { headers: { Accept: 'text/html,application/xhtml+xml', ...headers }, maxBytes, signal });
+  if (res.status === 402 || res.status === 403) {
+    throw Object.assign(new Error(`HTTP ${res.status}`), { paywalled: true });
+  }
+  if (!res.ok) {
+    throw new Error(`HTTP ${res.status}`);
+  }
+  const contentType = res.headers['content-type'] || '';
+  if (contentType && !/html/i.test(contentType)) {
+    throw new Error(`Not an HTML page (${contentType.split(';')[0]})`);
+  }
+  return extractArticle(decodeHtml(await res.buffer(), contentType), res.url || url);
+}
+
+/**
+ * The date in an article URL's path, /2026/02/13/ or /2026-02-13-slug or
+ * /20260213/, as midnight UTC; null if there's none, it isn't a real
+ * date, or it's in the future.
+ */
+export function dateFromUrl(url) {
+  let path;
+  try {
+    path = new URL(url).pathname;
+  } catch {
+    return null;
+  }
+  const match = path.match(URL_DATE);
+  if (!match) return null;
+  const [year, month, day] = match[1] ? [match[1], match[3], match[4]] : [match[5], match[6], match[7]];
+  const date = parseFeedDate(`${year}/${month}/${day}`);
+  return date && date.getTime() <= Date.now() ? date : null;
+}
+
+// Why the page looks paywalled, from what it says about itself
+function paywallSignal(html, meta) {
+  if (jsonLdValues(html, 'isAccessibleForFree').some(value => value === false || /^false$/i.test(value))) {
+    return 'isAccessibleForFree: false in JSON-LD';
+  }
+  if ((meta['article:content_tier'] || '').toLowerCase() === 'locked') {
+    return 'content tier "locked"';
+  }
+  const scripts = [...html.matchAll(/<script\b([^>]*)>([\s\S]*?)<\/script>/gi)].map(([, attrs, body]) => `${attrs} ${body}`).join('\n');
+  const provider = PAYWALL_SCRIPTS.find(([, pattern]) => pattern.test(scripts));
+  return provider ? `${provider[0]} paywall script` : null;
+}
+
+// A page with less article text than the feed's description of it
+function shortBody(description, page) {
+  if (!description || description.length < MIN_DESCRIPTION_CHARS || page.bodyLength >= description.length) return null;
+  return `${page.bodyLength} characters of article text against a ${description.length}-character description`;
+}
+
+function bomCharset(buffer) {
+  if (buffer[0] === 0xef && buffer[1] === 0xbb && buffer[2] === 0xbf) return 'utf-8';
+  if (buffer[0] === 0xfe && buffer[1] === 0xff) return 'utf-16be';
+  if (buffer[0] === 0xff && buffer[1] === 0xfe) return 'utf-16le';
+  return null;
+}
+
+// { name-or-property (lowercased): content } for the page's meta tags,
+// keeping the first of each
+function readMeta(html) {
+  const meta = {};
+  for (const [tag] of html.matchAll(/<meta\b[^>]*>/gi)) {
+    const attrs = tagAttributes(tag);
+    const key = (attrs.property || attrs.name || attrs.itemprop || '').toLowerCase();
+    if (key && attrs.content && !(key in meta)) meta[key] = decodeEntities(attrs.content).trim();
+  }
+  return meta;
+}
+
+// The href of the page's <link rel="canonical">, as written
+function canonicalLink(html) {
+  for (const [tag] of html.matchAll(/<link\b[^>]*>/gi)) {
+    const attrs = tagAttributes(tag);
+    if ((attrs.rel || '').toLowerCase().split(/\s+/).includes('canonical') && attrs.href) {
+      return decodeEntities(attrs.href);
+    }
+  }
+  return '';
+}
+
+// { name (lowercased): value } for an HTML tag's attributes
+function tagAttributes(tag) {
+  const attrs = {};
+  for (const [, name, , double, single, bare] of tag.matchAll(/([\w:.-]+)\s*=\s*("([^"]*)"|'([^']*)'|([^\s"'>]+))/g)) {
+    attrs[name.toLowerCase()] = double ?? single ?? bare;
+  }
+  return attrs;
+}
+
+// Every value of `key` in the page's JSON-LD, wherever it sits in the graph
+function jsonLdValues(html, key) {
+  const values = [];
+  for (const [, json] of html.matchAll(/<script\b[^>]*type\s*=\s*["']application\/ld\+json["'][^>]*>([\s\S]*?)<\/script>/gi)) {
+    let data;
+    try {
+      data = JSON.parse(json);
+    } catch {
+      continue;
+    }
+    const queue = [data];
+    while (queue.length > 0) {
+      const node = queue.shift();
+      if (Array.isArray(node)) {
+        queue.push(...node);
+      } else if (node && typeof node === 'object') {
+        if (key in node) values.push(node[key]);
+        queue.push(...Object.values(node).filter(value => value && typeof value === 'object'));
+      }
+    }
+  }
+  return values;
+}
+
+// The first paragraph that reads like article text
+// The paragraphs of the article body (inside <article> or <main> when
+// there is one), leaving out page furniture
+function articleParagraphs(html) {
+  const body = html
+    .replace(/<!--[\s\S]*?-->/g, '')
+    .replace(/<(script|style|noscript|template|svg|iframe|form|nav|header|footer|aside|figure)\b[\s\S]*?<\/\1>/gi, '');
+  const scope = body.match(/<article\b[\s\S]*<\/article>/i)?.[0] || body.match(/<main\b[\s\S]*<\/main>/i)?.[0] || body;
+
+  return [...scope.matchAll(/<p\b([^>]*)>([\s\S]*?)<\/p>/gi)]
+    .filter(([, attrs]) => !FURNITURE.test(attrs))
+    .map(([, , inner]) => ({ inner, text: plainText(inner) }));
+}
+
+// The absolute http(s) targets of the links in the paragraphs, each once
+function paragraphLinks(paragraphs, base) {
+  const links = new Set();
+  for (const { inner } of paragraphs) {
+    for (const [, href] of inner.matchAll(/<a\b[^>]*\bhref\s*=\s*["']([^"']+)["']/gi)) {
+      try {
+        const link = new URL(decodeEntities(href), base);
+        if (link.protocol === 'http:' || link.protocol === 'https:') links.add(link.href);
+      } catch {
+        // not a URL
+      }
+    }
+  }
+  return [...links];
+}
+
+function findLede(paragraphs) {
+  for (const { inner, text } of paragraphs) {
+    if (text.length < MIN_LEDE_CHARS) continue;
+    const linkText = [...inner.matchAll(/<a\b[^>]*>([\s\S]*?)<\/a>/gi)].map(([, link]) => plainText(link)).join('');
+    if (linkText.length / text.length > MAX_LINK_DENSITY) continue;
+    return text;
+  }
+  return '';
+}
+
+function plainText(html) {
+  return decodeEntities(String(html).replace(/<br\s*\/?>/gi, ' ').replace(/<[^>]+>/g, ''))
+    .replace(/\s+/g, ' ')
+    .trim();
+}
+
+function decodeEntities(text) {
+  return text.replace(/&(#x[\da-f]+|#\d+|[a-z]+);/gi, (entity, code) => {
+    if (code[0] === '#') {
+      const point = code[1].toLowerCase() === 'x' ? parseInt(code.slice(2), 16) : parseInt(code.slice(1), 10);
+      return point > 0 && point <= 0x10ffff ? String.fromCodePoint(point) : entity;
+    }
+    return NAMED_ENTITIES[code.toLowerCase()] ?? entity;
+  });
+}
@@ -0,0 +1,15 @@
+// The built-in sources, each of which registers itself with the registry
+// when imported. Import sources from here rather than from registry.js so
+// they're all registered before any are looked up.
+import './rss-feeds.js';
+import './manual-submissions.js';
+import './google-news.js';
+import './news-api.js';
+import './mastodon.js';
+import './podcasts.js';
+import './hacker-news.js';
+
+export {
+  registerSource, registeredSources, loadSourceModules, enabledSources, fetchSources,
+  formatRunSummary, saveRunSummary, loadRunSummary,
+} from './registry.js';
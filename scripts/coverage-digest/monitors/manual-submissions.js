@@ -1,5 +1,7 @@
 import { readFile } from 'fs/promises';
 import { config } from '../config.js';
+import { scrapeArticle } from './html-article.js';
+import { registerSource } from './registry.js';
 
 /**
  * Read manual submissions from the JSON file.
@@ -16,14 +18,26 @@ import { config } from '../config.js';
  *     "url": "https://...",
  *     "tools_mentioned": ["Chariot"],
  *     "excerpt": "Training session on...",
- *     "submitted_by": "john@praetorian.com"
+ *     "submitted_by": "john@praetorian.com",
+ *     "embargo_until": "2026-02-14T13:00:00Z"   // optional: hold until this time
  *   }
  * ]
  *
+ * For an outlet with no feed, a submission can be just { "url": "..." }
+ * (plus tools_mentioned, if known): the page is read for its title, date,
+ * site name and lede, and any of those given in the submission win. If
+ * the site's robots.txt keeps us from the page, the item is recorded with
+ * its URL as the title and flagged `unfetched`; a page behind a paywall
+ * is flagged `paywalled`, with its URL as the title if the paywall kept
+ * us from reading it.
+ *
  * @param {Date} since - Only return items submitted after this date
+ * @param {Array} [failures] - Receives { source, error } if the file can't be read
+ * @param {Object} [options]
+ *   signal - aborts the page fetch in flight (the run's, once the source times out)
  * @returns {Promise<Array>} Array of coverage items
  */
-export async function checkManualSubmissions(since) {
+export async function checkManualSubmissions(since, failures = [], { signal = null } = {}) {
   console.log('Checking manual submissions...');
 
   try {
@@ -35,26 +49,59 @@ export async function checkManualSubmissions(since) {
       return [];
     }
 
-    const items = submissions
-      .filter(sub => {
-        const subDate = new Date(sub.date);
-        return !since || subDate >= since;
-      })
-      .map(sub => ({
+    const inWindow = sub => {
+      // An embargo release counts as the item's arrival, so held items
+      // aren't lost once the lookback window moves past their date.
+      // Undated submissions are dated from their page, and the tracker
+      // drops them once stored.
+      const subDate = new Date(sub.date);
+      const releasedAt = sub.embargo_until ? new Date(sub.embargo_until) : null;
+      return !since || !sub.date || subDate >= since || (releasedAt && releasedAt >= since);
+    };
+
+    const items = [];
+    for (const submission of submissions.filter(inWindow)) {
+      let sub = submission;
+      if (sub.url && !sub.title) {
+        try {
+          sub = await fillFromPage(sub, signal);
+        } catch (err) {
+          if (err.disallowed) {
+            console.log(`  Not reading submitted page ${sub.url}: ${err.message}`);
+            sub = { source_type: 'media', ...sub, title: sub.url, source: sub.source || hostName(sub.url), unfetched: true };
+          } else if (err.paywalled && config.scrape.paywalls) {
+            console.log(`  🔒 ${sub.url}: ${err.message}`);
+            sub = { source_type: 'media', ...sub, title: sub.url, source: sub.source || hostName(sub.url), paywalled: true };
+          } else {
+            console.warn(`  Warning: Could not read submitted page ${sub.url}: ${err.message}`);
+            failures.push({ source: 'Manual submissions', error: `${sub.url}: ${err.message}` });
+            continue;
+          }
+        }
+        // Dated from the page: check the window again
+        if (!inWindow(sub)) continue;
+      }
+      items.push({
         source: sub.source || 'Manual Submission',
         sourceType: sub.source_type || 'manual',
         icon: getIconForType(sub.source_type),
         title: sub.title || 'Untitled',
         url: sub.url || '',
         date: sub.date ? new Date(sub.date).toISOString() : new Date().toISOString(),
+        undated: !sub.date,
         excerpt: sub.excerpt || '',
         toolsMentioned: sub.tools_mentioned || [],
+        untagged: !(sub.tools_mentioned || []).length,
         matchedTerms: ['manual'],
+        embargoUntil: sub.embargo_until || null,
+        ...(sub.unfetched ? { unfetched: true } : {}),
+        ...(sub.paywalled ? { paywalled: true } : {}),
         raw: {
           guid: `manual-${sub.date}-${sub.title}`,
           submittedBy: sub.submitted_by || 'unknown',
         },
-      }));
+      });
+    }
 
     console.log(`  Found ${items.length} manual submission(s)`);
     return items;
@@ -64,10 +111,39 @@ export async function checkManualSubmissions(since) {
       return [];
     }
     console.warn(`  Warning: Error reading manual submissions: ${err.message}`);
+    failures.push({ source: 'Manual submissions', error: err.message });
     return [];
   }
 }
 
+/**
+ * Complete a URL-only submission from its page. Fields the submission
+ * gives are kept; it's filed as media coverage unless it says otherwise.
+ */
+async function fillFromPage(sub, signal) {
+  const page = await scrapeArticle(sub.url, { signal });
+  if (!page.title) {
+    throw new Error('no title on the page');
+  }
+  return {
+    source_type: 'media',
+    ...sub,
+    title: page.title,
+    source: sub.source || page.siteName,
+    date: sub.date || page.date || undefined,
+    excerpt: sub.excerpt || page.excerpt,
+    ...(page.paywall && config.scrape.paywalls ? { paywalled: true } : {}),
+  };
+}
+
+function hostName(url) {
+  try {
+    return new URL(url).hostname.replace(/^www\./, '');
+  } catch {
+    return 'Manual Submission';
+  }
+}
+
 function getIconForType(type) {
   const icons = {
     event: '🎤',
@@ -78,3 +154,8 @@ function getIconForType(type) {
   };
   return icons[type] || '📌';
 }
+
+registerSource({
+  name: 'manual',
+  fetch: (since, failures, options, { signal }) => checkManualSubmissions(since, failures, { signal }),
+});
@@ -0,0 +1,181 @@
+import { config } from '../config.js';
+import { clientFor } from '../utils/http-client.js';
+import { excerpt } from '../utils/excerpt.js';
+import { activeSearchTerms, detectTools, isDeprecated } from '../utils/tools.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+import { registerSource } from './registry.js';
+
+const SOURCE = 'Mastodon';
+const PAGE_SIZE = 40;
+
+/**
+ * Find recent Mastodon statuses about Praetorian on the configured
+ * instance: a status search (API v2) for each active tool and for links
+ * to praetorian.com, plus each followed hashtag's timeline, keeping only
+ * statuses that match the RSS search terms. Both are paged back (by
+ * max_id) until they reach the window's start: `lastRun`, the last
+ * recorded run, so a re-run only reads what was posted since then, or
+ * `since` (the run's lookback) before any run is recorded.
+ *
+ * Each status becomes an item linking to the status, with its text (HTML
+ * stripped) as the excerpt and "Display Name (instance)" as the source,
+ * tagged with the tools its text mentions (utils/tools.js detectTools).
+ * Boosts are left out; the original status is found on its own.
+ *
+ * @param {Date} since - Only return statuses posted after this date, when there's no `lastRun`
+ * @param {Array} [failures] - Receives { source, error } for each failed search
+ * @param {Object} [options]
+ *   instance    - instance base URL (default: config.mastodon.instance)
+ *   accessToken - access token for the instance (default: config.mastodon.accessToken)
+ *   hashtags    - hashtag timelines to follow (default: config.mastodon.hashtags)
+ *   tools       - tools to search for (default: config.tools, less retired ones)
+ *   lastRun     - when the last run was recorded (utils/seen-store.js lastRunTime(); default: none)
+ *   client      - HTTP client (default: the "Mastodon" client)
+ *   memo        - the run's query memo (utils/query-memo.js; default: a new one)
+ *   signal      - aborts the requests in flight (the run's, once the source times out)
+ * @returns {Promise<Array>} Array of coverage items
+ */
+export async function checkMastodon(since, failures = [], {
+  instance = config.mastodon.instance,
+  accessToken = config.mastodon.accessToken,
+  hashtags = config.mastodon.hashtags,
+  tools = config.tools.filter(tool => !isDeprecated(tool)),
+  lastRun = null,
+  client = clientFor(SOURCE),
+  memo = createQueryMemo(),
+  signal = null,
+} = {}) {
+  const base = instance.replace(/\/+$/, '');
+  const start = lastRun || since;
+  console.log(`Searching Mastodon (${new URL(base).host}) since ${lastRun ? 'the last run' : 'the lookback'}, ${start.toISOString()}...`);
+
+  const get = async (path, params) => {
+    const res = await client.fetch(`${base}${path}?${new URLSearchParams(params)}`, {
+      headers: { Authorization: `Bearer ${accessToken}` },
+      signal,
+    });
+    if (!res.ok) {
+      throw new Error(`HTTP ${res.status}`);
+    }
+    return res.json();
+  };
+
+  const lookups = [
+    ...[...tools, 'praetorian.com'].map(query => ({
+      label: `search "${query}"`,
+      page: params => get('/api/v2/search', { q: query, type: 'statuses', resolve: 'false', ...params })
+        .then(body => body.statuses || []),
+    })),
+    ...hashtags.map(tag => ({
+      label: `#${tag}`,
+      filter: true,
+      page: params => get(`/api/v1/timelines/tag/${encodeURIComponent(tag)}`, params),
+    })),
+  ];
+
+  const byUrl = new Map();
+  for (const lookup of lookups) {
+    let statuses;
+    try {
+      statuses = await memo('mastodon', `${base} ${lookup.label}`, start, () => pageBack(lookup.page, start));
+    } catch (err) {
+      console.warn(`  Warning: Mastodon ${lookup.label} failed: ${err.message}`);
+      failures.push({ source: SOURCE, error: `${lookup.label}: ${err.message}` });
+      continue;
+    }
+
+    for (const status of statuses) {
+      if (status.reblog) continue;
+      const text = statusText(status);
+      if (lookup.filter && !matchesSearchTerms(text)) continue;
+
+      const url = status.url || status.uri;
+      const existing = byUrl.get(url);
+      if (existing) {
+        if (!existing.matchedTerms.includes(lookup.label)) existing.matchedTerms.push(lookup.label);
+        continue;
+      }
+
+      const toolsMentioned = detectTools(text);
+      byUrl.set(url, {
+        source: accountName(status.account),
+        sourceType: 'rss',
+        discoveredBy: 'mastodon',
+        icon: '🐘',
+        // Statuses have no title; the opening words stand in for one
+        title: excerpt(text, 100) || 'Untitled',
+        url,
+        date: new Date(status.created_at).toISOString(),
+        excerpt: excerpt(text, 280),
+        toolsMentioned,
+        untagged: toolsMentioned.length === 0,
+        matchedTerms: [lookup.label],
+        bootstrap: false,
+        raw: {
+          guid: status.uri || url,
+        },
+      });
+    }
+  }
+
+  const items = [...byUrl.values()];
+  console.log(`  Found ${items.length} status(es) across ${lookups.length} Mastodon lookups`);
+  return items;
+}
+
+// Newest first, a page at a time, until a page ends before `since`
+async function pageBack(fetchPage, since) {
+  const statuses = [];
+  let maxId = null;
+  for (let page = 0; page < config.mastodon.maxPages; page++) {
+    const batch = await fetchPage({ limit: String(PAGE_SIZE), ...(maxId ? { max_id: maxId } : {}) });
+    const recent = batch.filter(status => !since || new Date(status.created_at) > since);
+    statuses.push(...recent);
+    if (batch.length < PAGE_SIZE || recent.length < batch.length) break;
+    maxId = batch[batch.length - 1].id;
+  }
+  return statuses;
+}
+
+function matchesSearchTerms(text) {
+  const haystack = text.toLowerCase();
+  return activeSearchTerms().some(term => haystack.includes(term.toLowerCase()));
+}
+
+// Plain text of a status: paragraphs and line breaks become spaces, tags
+// go, and entities are decoded. Links keep their full URL, which Mastodon
+// splits across spans to shorten it on screen.
+function statusText(status) {
+  const html = [status.spoiler_text, status.content].filter(Boolean).join(' ');
+  return html
+    .replace(/<br\s*\/?>|<\/p>/gi, ' ')
+    .replace(/<[^>]+>/g, '')
+    .replace(/&quot;/g, '"')
+    .replace(/&#39;/g, "'")
+    .replace(/&lt;/g, '<')
+    .replace(/&gt;/g, '>')
+    .replace(/&amp;/g, '&')
+    .replace(/\s+/g, ' ')
+    .trim();
+}
+
+// "Display Name (infosec.exchange)", from the account's profile URL
+function accountName(account = {}) {
+  const name = (account.display_name || '').trim() || account.username || account.acct || 'Unknown';
+  let host = (account.acct || '').split('@')[1];
+  try {
+    host = new URL(account.url).host;
+  } catch {
+    // keep the acct domain
+  }
+  return host ? `${name} (${host})` : name;
+}
+
+registerSource({
+  name: 'mastodon',
+  get defaults() {
+    return { instance: config.mastodon.instance, hashtags: config.mastodon.hashtags };
+  },
+  enabled: () => Boolean(config.mastodon.instance && config.mastodon.accessToken),
+  fetch: (since, failures, { instance, hashtags }, { memo, signal, lastRun }) => checkMastodon(since, failures, { instance, hashtags, lastRun, memo, signal }),
+});
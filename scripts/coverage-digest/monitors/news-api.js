@@ -0,0 +1,200 @@
+import { config } from '../config.js';
+import { clientFor } from '../utils/http-client.js';
+import { detectTools, isDeprecated } from '../utils/tools.js';
+import { normalizeUrl } from '../utils/tracker.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+import { searchQuery } from './google-news.js';
+import { registerSource } from './registry.js';
+
+/**
+ * Commercial news search APIs, by NEWS_API_PROVIDER name. A provider is
+ * created with { apiKey, market } and looks like:
+ *
+ *   {
+ *     name,               // for logs and source failures
+ *     requestsPerSecond,  // the API's documented rate limit
+ *     client,             // HTTP client (utils/http-client.js)
+ *     search(query, since, fetch) // => Promise<RawArticle[]>, every page
+ *   }
+ *
+ * where `fetch` is the rate-limited client.fetch to make requests with,
+ * and a RawArticle is { title, url, publisher, publishedAt, description }
+ * with publishedAt an ISO string, or null when the API doesn't give one.
+ */
+export const NEWS_API_PROVIDERS = {
+  bing: createBingProvider,
+};
+
+/**
+ * Search the configured news API for "Praetorian" and for each active
+ * tool, and return the articles as coverage items. An article found by
+ * several queries is returned once, tagged with the tools its title and
+ * description mention (utils/tools.js detectTools) rather than the query
+ * that found it; one without a publish date is dated when it's found.
+ *
+ * @param {Date} since - Only return articles published after this date
+ * @param {Array} [failures] - Receives { source, error } for each failed query
+ * @param {Object} [options]
+ *   provider - a provider object (default: built from config.newsApi)
+ *   tools    - tools to search for (default: config.tools, less retired ones)
+ *   sleep    - delay function, for tests
+ *   memo     - the run's query memo (utils/query-memo.js; default: a new one)
+ *   signal   - aborts the requests in flight (the run's, once the source times out)
+ * @returns {Promise<Array>} Array of coverage items
+ */
+export async function checkNewsApi(since, failures = [], {
+  provider = createProvider(),
+  tools = config.tools.filter(tool => !isDeprecated(tool)),
+  sleep = delay,
+  memo = createQueryMemo(),
+  signal = null,
+} = {}) {
+  console.log(`Searching ${provider.name}...`);
+
+  // Requests are spaced to stay inside the provider's rate limit
+  const interval = 1000 / provider.requestsPerSecond;
+  let last = null;
+  const throttled = async (url, init) => {
+    if (last !== null) await sleep(Math.max(0, interval - (Date.now() - last)));
+    try {
+      return await provider.client.fetch(url, { ...init, signal });
+    } finally {
+      last = Date.now();
+    }
+  };
+
+  const queries = ['Praetorian', ...tools.map(searchQuery)];
+  const byUrl = new Map();
+  const foundAt = new Date().toISOString();
+
+  for (const query of queries) {
+    let articles;
+    try {
+      articles = await memo('news-api', `${provider.name}: ${query}`, since, () => provider.search(query, since, throttled));
+    } catch (err) {
+      console.warn(`  Warning: ${provider.name} search for ${query} failed: ${err.message}`);
+      failures.push({ source: provider.name, error: `${query}: ${err.message}` });
+      continue;
+    }
+
+    for (const article of articles) {
+      if (!article.url) continue;
+      const published = article.publishedAt && !isNaN(new Date(article.publishedAt)) ? new Date(article.publishedAt) : null;
+      if (published && since && published < since) continue;
+
+      const key = normalizeUrl(article.url);
+      const existing = byUrl.get(key);
+      if (existing) {
+        if (!existing.matchedTerms.includes(query)) existing.matchedTerms.push(query);
+        continue;
+      }
+
+      const title = (article.title || '').replace(/\s+/g, ' ').trim();
+      const description = (article.description || '').replace(/\s+/g, ' ').trim();
+      const toolsMentioned = detectTools(`${title} ${description}`);
+      byUrl.set(key, {
+        source: article.publisher || provider.name,
+        sourceType: 'rss',
+        discoveredBy: 'news-api',
+        icon: '📰',
+        title: title || 'Untitled',
+        url: article.url,
+        date: published ? published.toISOString() : foundAt,
+        undated: !published,
+        excerpt: description,
+        toolsMentioned,
+        untagged: toolsMentioned.length === 0,
+        matchedTerms: [query],
+        bootstrap: false,
+        raw: {
+          guid: article.url,
+        },
+      });
+    }
+  }
+
+  const items = [...byUrl.values()];
+  console.log(`  Found ${items.length} article(s) across ${queries.length} ${provider.name} queries`);
+  return items;
+}
+
+/**
+ * The provider named by config.newsApi.provider, with its API key.
+ */
+export function createProvider({ name = config.newsApi.provider, apiKey = config.newsApi.apiKey } = {}) {
+  const create = NEWS_API_PROVIDERS[name];
+  if (!create) {
+    throw new Error(`Unknown news API provider "${name}" (expected ${Object.keys(NEWS_API_PROVIDERS).join(', ')})`);
+  }
+  if (!apiKey) {
+    throw new Error(`NEWS_API_KEY is required for the ${name} news API`);
+  }
+  return create({ apiKey, market: config.newsApi.market });
+}
+
+/**
+ * Bing News Search (API v7). The free and S1 tiers both allow 3
+ * requests a second; results come 100 at a time, newest first.
+ */
+function createBingProvider({ apiKey, market, client = clientFor('Bing News'), maxPages = config.newsApi.maxPages }) {
+  const endpoint = 'https://api.bing.microsoft.com/v7.0/news/search';
+  const count = 100;
+
+  return {
+    name: 'Bing News',
+    requestsPerSecond: 3,
+    client,
+    async search(query, since, fetch) {
+      const articles = [];
+      for (let page = 0; page < maxPages; page++) {
+        const params = new URLSearchParams({
+          q: query,
+          mkt: market,
+          count: String(count),
+          offset: String(page * count),
+          sortBy: 'Date',
+          freshness: bingFreshness(since),
+        });
+        const res = await fetch(`${endpoint}?${params}`, {
+          headers: { 'Ocp-Apim-Subscription-Key': apiKey },
+        });
+        if (!res.ok) {
+          throw new Error(`HTTP ${res.status}`);
+        }
+        const body = await res.json();
+        const results = body.value || [];
+        for (const result of results) {
+          articles.push({
+            title: result.name,
+            url: result.url,
+            publisher: result.provider?.[0]?.name || '',
+            publishedAt: result.datePublished || null,
+            description: result.description || '',
+          });
+        }
+        if (results.length < count || (page + 1) * count >= (body.totalEstimatedMatches || 0)) break;
+      }
+      return articles;
+    },
+  };
+}
+
+// Bing only filters by Day, Week or Month; anything finer is done by
+// checkNewsApi against `since`
+function bingFreshness(since) {
+  const days = since ? (Date.now() - since.getTime()) / (24 * 60 * 60 * 1000) : Infinity;
+  return days <= 1 ? 'Day' : days <= 7 ? 'Week' : 'Month';
+}
+
+function delay(ms) {
+  return new Promise(resolve => setTimeout(resolve, ms));
+}
+
+registerSource({
+  name: 'news-api',
+  get defaults() {
+    return { provider: config.newsApi.provider };
+  },
+  enabled: () => Boolean(config.newsApi.provider),
+  fetch: (since, failures, { provider }, { memo, signal }) => checkNewsApi(since, failures, { provider: createProvider({ name: provider }), memo, signal }),
+});
@@ -0,0 +1,178 @@
+import { createHash } from 'crypto';
+import { config } from '../config.js';
+import { clientFor } from '../utils/http-client.js';
+import { excerpt } from '../utils/excerpt.js';
+import { detectTools, isDeprecated } from '../utils/tools.js';
+import { normalizeUrl } from '../utils/tracker.js';
+import { shiftDays } from '../utils/timezone.js';
+import { registerSource } from './registry.js';
+
+// Despite the name, matches episode titles and descriptions as well as
+// person tags
+const PODCAST_INDEX_URL = 'https://api.podcastindex.org/api/1.0/episodes/byperson';
+const ITUNES_URL = 'https://itunes.apple.com/search';
+
+/**
+ * Search podcast directories for episodes whose title or description
+ * mentions an active tool: PodcastIndex when an API key is configured,
+ * otherwise the iTunes Search API, which needs none. Each episode becomes
+ * an item with the podcast's name as the source, linking to the episode's
+ * page.
+ *
+ * Podcast directories pick up episodes days after they air, so the search
+ * reaches `extraDays` further back than `since`, and the items carry
+ * `extraLookbackDays` so the age cutoff gives them the same grace. Episodes
+ * found again on later runs are dropped as duplicates by the tracker.
+ *
+ * @param {Date} since - Only return episodes published after this date (less extraDays)
+ * @param {Array} [failures] - Receives { source, error } for each failed search
+ * @param {Object} [options]
+ *   extraDays - extra look-back, in days (default: config.podcasts.extraLookbackDays)
+ *   tools     - tools to search for (default: config.tools, less retired ones)
+ *   directory - "podcastindex" or "itunes" (default: podcastindex when keyed)
+ *   client    - HTTP client (default: the directory's client)
+ *   signal    - aborts the requests in flight (the run's, once the source times out)
+ * @returns {Promise<Array>} Array of coverage items
+ */
+export async function checkPodcasts(since, failures = [], {
+  extraDays = config.podcasts.extraLookbackDays,
+  tools = config.tools.filter(tool => !isDeprecated(tool)),
+  directory = config.podcasts.podcastIndexKey ? 'podcastindex' : 'itunes',
+  client = null,
+  signal = null,
+} = {}) {
+  const { name, search } = DIRECTORIES[directory];
+  const http = client || clientFor(name);
+  const after = since ? shiftDays(since, -extraDays) : null;
+  console.log(`Searching ${name} for podcast episodes...`);
+
+  const byUrl = new Map();
+  for (const tool of tools) {
+    let episodes;
+    try {
+      episodes = await search(http, tool, signal);
+    } catch (err) {
+      console.warn(`  Warning: ${name} search for "${tool}" failed: ${err.message}`);
+      failures.push({ source: name, error: `"${tool}": ${err.message}` });
+      continue;
+    }
+
+    for (const episode of episodes) {
+      if (!episode.url) continue;
+      const published = episode.publishedAt ? new Date(episode.publishedAt) : null;
+      if (published && after && published < after) continue;
+
+      // Directory search is loose; keep episodes that really name the tool
+      const description = plainText(episode.description);
+      const toolsMentioned = detectTools(`${episode.title} ${description}`);
+      if (!toolsMentioned.includes(tool)) continue;
+
+      const key = normalizeUrl(episode.url);
+      if (byUrl.has(key)) continue;
+      byUrl.set(key, {
+        source: episode.podcast || name,
+        sourceType: 'rss',
+        discoveredBy: 'podcasts',
+        icon: '🎙️',
+        title: plainText(episode.title) || 'Untitled',
+        url: episode.url,
+        date: published ? published.toISOString() : new Date().toISOString(),
+        undated: !published,
+        excerpt: excerpt(description, 280),
+        toolsMentioned,
+        untagged: false,
+        matchedTerms: [tool],
+        bootstrap: false,
+        extraLookbackDays: extraDays,
+        raw: {
+          guid: episode.guid || episode.url,
+        },
+      });
+    }
+  }
+
+  const items = [...byUrl.values()];
+  console.log(`  Found ${items.length} episode(s) across ${tools.length} ${name} searches`);
+  return items;
+}
+
+// Each directory's search, returning { title, podcast, url, publishedAt,
+// description, guid } per episode
+const DIRECTORIES = {
+  podcastindex: {
+    name: 'PodcastIndex',
+    async search(client, term, signal) {
+      const res = await client.fetch(`${PODCAST_INDEX_URL}?${new URLSearchParams({ q: term, fulltext: '', max: '100' })}`, {
+        headers: podcastIndexHeaders(),
+        signal,
+      });
+      if (!res.ok) {
+        throw new Error(`HTTP ${res.status}`);
+      }
+      const body = await res.json();
+      return (body.items || []).map(episode => ({
+        title: episode.title || '',
+        podcast: episode.feedTitle || '',
+        url: episode.link || episode.enclosureUrl,
+        publishedAt: episode.datePublished ? new Date(episode.datePublished * 1000).toISOString() : null,
+        description: episode.description || '',
+        guid: episode.guid,
+      }));
+    },
+  },
+  itunes: {
+    name: 'iTunes',
+    async search(client, term, signal) {
+      const params = new URLSearchParams({ term, media: 'podcast', entity: 'podcastEpisode', limit: '200' });
+      const res = await client.fetch(`${ITUNES_URL}?${params}`, { signal });
+      if (!res.ok) {
+        throw new Error(`HTTP ${res.status}`);
+      }
+      const body = await res.json();
+      return (body.results || []).map(episode => ({
+        title: episode.trackName || '',
+        podcast: episode.collectionName || '',
+        url: episode.trackViewUrl,
+        publishedAt: episode.releaseDate || null,
+        description: episode.description || episode.shortDescription || '',
+        guid: episode.episodeGuid,
+      }));
+    },
+  },
+};
+
+// PodcastIndex signs each request with sha1(key + secret + unix time)
+function podcastIndexHeaders() {
+  const { podcastIndexKey: key, podcastIndexSecret: secret } = config.podcasts;
+  const date = String(Math.floor(Date.now() / 1000));
+  return {
+    'X-Auth-Key': key,
+    'X-Auth-Date': date,
+    Authorization: createHash('sha1').update(key + secret + date).digest('hex'),
+  };
+}
+
+// Show notes are usually HTML: tags go, entities are decoded, and
+// whitespace collapses
+function plainText(html) {
+  return String(html ?? '')
+    .replace(/<br\s*\/?>|<\/(p|li|div|h\d)>/gi, ' ')
+    .replace(/<[^>]+>/g, '')
+    .replace(/&nbsp;/g, ' ')
+    .replace(/&quot;/g, '"')
+    .replace(/&#39;|&apos;/g, "'")
+    .replace(/&lt;/g, '<')
+    .replace(/&gt;/g, '>')
+    .replace(/&amp;/g, '&')
+    .replace(/\s+/g, ' ')
+    .trim();
+}
+
+registerSource({
+  name: 'podcasts',
+  get defaults() {
+    return { extraLookbackDays: config.podcasts.extraLookbackDays };
+  },
+  enabled: () => config.podcasts.enabled,
+  fetch: (since, failures, { extraLookbackDays }, { signal }) => checkPodcasts(since, failures, { extraDays: extraLookbackDays, signal }),
+});
@@ -0,0 +1,257 @@
+import { mkdir, readFile, writeFile } from 'fs/promises';
+import { pathToFileURL } from 'url';
+import { dirname, resolve } from 'path';
+import { config } from '../config.js';
+import { retry } from '../utils/retry.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+
+const sources = new Map();
+
+/**
+ * Register a coverage source under its name. Each monitor registers
+ * itself when imported (monitors/index.js imports the built-in ones); a
+ * source outside this repo is a module named in SOURCE_MODULES that
+ * default-exports one. A source looks like:
+ *
+ *   {
+ *     name,       // what SOURCES and SOURCE_OPTIONS call it, e.g. "google-news"
+ *     defaults,   // its options and their default values (optional)
+ *     enabled(),  // whether it runs when SOURCES isn't set (optional; default: true)
+ *     fetch(since, failures, options, context) // => Promise<coverage items>
+ *     reconcile(items, others, context)        // optional, see fetchSources()
+ *   }
+ *
+ * `options` is `defaults` with the source's SOURCE_OPTIONS entry applied
+ * (built-in sources make `defaults` a getter, so it reads config when the
+ * source runs); `context` is what the caller passed fetchSources (e.g.
+ * the tracker, or the `lastRun` time Mastodon reads back to), plus the
+ * run's `signal` and query `memo` (see fetchSources()).
+ */
+export function registerSource(source) {
+  if (!source?.name || typeof source.fetch !== 'function') {
+    throw new Error('A source needs a name and a fetch(since, failures, options, context) function');
+  }
+  if (sources.has(source.name)) {
+    throw new Error(`A source named "${source.name}" is already registered`);
+  }
+  sources.set(source.name, source);
+  return source;
+}
+
+/**
+ * Names of every registered source, in registration order.
+ */
+export function registeredSources() {
+  return [...sources.keys()];
+}
+
+/**
+ * Import the sources named in SOURCE_MODULES (paths relative to this
+ * directory) and register their default exports.
+ */
+export async function loadSourceModules(modulePaths = config.sources.modules) {
+  for (const modulePath of modulePaths) {
+    const mod = await import(pathToFileURL(resolve(config.paths.root, modulePath)).href);
+    if (!mod.default) {
+      throw new Error(`Source module ${modulePath} must default-export { name, fetch(since, failures, options, context) }`);
+    }
+    registerSource(mod.default);
+  }
+}
+
+/**
+ * The sources that run: the ones SOURCES lists, in that order, or when
+ * it isn't set, every registered source whose own switch is on. Throws
+ * if SOURCES or SOURCE_OPTIONS names a source that isn't registered.
+ */
+export function enabledSources(names = config.sources.enabled, options = config.sources.options) {
+  for (const name of [...(names || []), ...Object.keys(options)]) {
+    if (!sources.has(name)) {
+      throw new Error(`Unknown source "${name}" (registered: ${registeredSources().join(', ')})`);
+    }
+  }
+  if (names) return names.map(name => sources.get(name));
+  return [...sources.values()].filter(source => !source.enabled || source.enabled());
+}
+
+/**
+ * A source's options: its defaults, overridden by its SOURCE_OPTIONS
+ * entry. Keys the source doesn't declare, and values of a different type
+ * than the default, are errors; `timeoutMs` is accepted for every source
+ * (see fetchSources()).
+ */
+export function sourceOptions(source, overrides = config.sources.options[source.name] || {}) {
+  const defaults = source.defaults || {};
+  const options = { ...defaults };
+  for (const [key, value] of Object.entries(overrides)) {
+    if (key === 'timeoutMs') {
+      if (typeof value !== 'number' || value <= 0) {
+        throw new Error(`Option "timeoutMs" for source "${source.name}" must be a positive number`);
+      }
+      options.timeoutMs = value;
+      continue;
+    }
+    if (!(key in defaults)) {
+      const known = Object.keys(defaults);
+      throw new Error(`Unknown option "${key}" for source "${source.name}" (${known.length > 0 ? `expected ${known.join(', ')}` : 'it takes none'})`);
+    }
+    if (defaults[key] !== null && defaults[key] !== undefined && typeof value !== typeof defaults[key]
+        && !(Array.isArray(defaults[key]) && Array.isArray(value))) {
+      throw new Error(`Option "${key}" for source "${source.name}" must be a ${typeof defaults[key]}`);
+    }
+    options[key] = value;
+  }
+  return options;
+}
+
+/**
+ * Run every enabled source, config.sources.concurrency at a time (each
+ * slot takes the next source as soon as its last one is done), and return { bySource, summary }: bySource is Map(name => items) in source
+ * name order, so output doesn't depend on which source answered first.
+ *
+ * Sources are isolated from each other. One that throws, or takes longer
+ * than its timeout (config.sources.timeoutMs, or its own `timeoutMs` in
+ * SOURCE_OPTIONS), is pushed onto `failures` and contributes no items;
+ * the rest carry on. A source that throws a transient error (see
+ * utils/retry.js) is tried again first. When a source times out its
+ * `context.signal` is aborted, which tears down the requests it has in
+ * flight (the built-in sources pass it to every client.fetch()); whatever
+ * it reports after that is ignored. The failures each source reports
+ * itself are collected per source and added to `failures` in the same
+ * name order.
+ *
+ * Every source gets the run's query memo as `context.memo` (one
+ * utils/query-memo.js memo, or the caller's), so the same query isn't
+ * asked twice in a run, not even by a source that's retried.
+ *
+ * Afterwards, a source with a reconcile() hook gets its items back along
+ * with everyone else's, and returns the ones to keep (Hacker News uses
+ * this to fold stories about known articles into those articles).
+ *
+ * `summary` has one row per source: { source, status ("ok", "failed" or
+ * "timed out"), items, failures, durationMs, error, requestsSaved }, where
+ * requestsSaved counts the queries the memo answered.
+ */
+export async function fetchSources(since, failures = [], context = {}, selected = enabledSources()) {
+  context = { ...context, memo: context.memo || createQueryMemo() };
+  const runs = [...selected]
+    .sort((a, b) => a.name.localeCompare(b.name))
+    .map(source => {
+      const { timeoutMs = config.sources.timeoutMs, ...options } = sourceOptions(source);
+      return { source, options, timeoutMs, items: [], failures: [], status: 'ok', error: null, durationMs: 0 };
+    });
+
+  // A pool of workers, each taking the next source when it's free, so one
+  // slow source holds up a slot rather than a whole batch
+  const queue = [...runs];
+  const worker = async () => {
+    for (let run = queue.shift(); run; run = queue.shift()) {
+      await runSource(run, since, context);
+    }
+  };
+  await Promise.all(Array.from({ length: Math.min(Math.max(1, config.sources.concurrency), runs.length) }, worker));
+
+  const bySource = new Map(runs.map(run => [run.source.name, run.items]));
+  for (const run of runs) {
+    failures.push(...run.failures);
+    if (!run.source.reconcile || run.status !== 'ok') continue;
+    const others = [...bySource].filter(([name]) => name !== run.source.name).flatMap(([, items]) => items);
+    run.items = run.source.reconcile(run.items, others, context);
+    bySource.set(run.source.name, run.items);
+  }
+
+  const summary = runs.map(run => ({
+    source: run.source.name,
+    status: run.status,
+    items: run.items.length,
+    failures: run.failures.length,
+    durationMs: run.durationMs,
+    error: run.error,
+    requestsSaved: context.memo.stats(run.source.name).saved,
+  }));
+  return { bySource, summary };
+}
+
+/**
+ * The run summary as log lines: one per source, with its item count,
+ * failures, requests the query memo saved, and how long it took.
+ */
+export function formatRunSummary(summary) {
+  const width = Math.max(...summary.map(row => row.source.length), 6);
+  const lines = summary.map(row => {
+    const status = row.status === 'ok'
+      ? `${row.items} item(s)${row.failures > 0 ? `, ${row.failures} failure(s)` : ''}`
+      : row.status === 'failed' ? `failed: ${row.error}` : row.error;
+    const saved = row.requestsSaved > 0 ? `, ${row.requestsSaved} repeated request(s) saved` : '';
+    return `  ${row.source.padEnd(width)}  ${formatDuration(row.durationMs).padStart(6)}  ${status}${saved}`;
+  });
+  const failed = summary.filter(row => row.status !== 'ok').length;
+  const saved = summary.reduce((sum, row) => sum + (row.requestsSaved || 0), 0);
+  return [`Sources: ${summary.length} run, ${failed} failed${saved > 0 ? `, ${saved} repeated request(s) saved` : ''}`, ...lines].join('\n');
+}
+
+/**
+ * Save the run summary for later steps (publish-issue.js adds it to the
+ * digest issue).
+ */
+export async function saveRunSummary(summary, path = config.paths.sourceRun) {
+  await mkdir(dirname(path), { recursive: true });
+  await writeFile(path, JSON.stringify({ ranAt: new Date().toISOString(), sources: summary }, null, 2) + '\n');
+}
+
+/**
+ * The last saved run summary, or null if there isn't one.
+ */
+export async function loadRunSummary(path = config.paths.sourceRun) {
+  try {
+    return JSON.parse(await readFile(path, 'utf-8'));
+  } catch (err) {
+    if (err.code === 'ENOENT') return null;
+    throw err;
+  }
+}
+
+function formatDuration(ms) {
+  return ms < 1000 ? `${ms}ms` : `${(ms / 1000).toFixed(1)}s`;
+}
+
+async function runSource(run, since, context) {
+  const { source, options, timeoutMs } = run;
+  const started = Date.now();
+  const controller = new AbortController();
+  let timer;
+  const timeout = new Promise((_, reject) => {
+    timer = setTimeout(() => reject(Object.assign(new Error(`timed out after ${formatDuration(timeoutMs)}`), { timedOut: true })), timeoutMs);
+  });
+  // A transient error out of the whole source gets another try, with a
+  // clean slate of failures; the timeout covers every attempt
+  const work = retry(() => {
+    run.failures = [];
+    return source.fetch(since, run.failures, options, { ...context, signal: controller.signal });
+  }, {
+    signal: controller.signal,
+    onRetry: ({ attempt, error, delayMs }) =>
+      console.log(`  Source ${source.name} attempt ${attempt} failed (${error.message}); retrying in ${delayMs}ms`),
+  });
+  // Whatever a timed-out source does later is of no interest
+  work.catch(() => {});
+  try {
+    const items = await Promise.race([work, timeout]);
+    if (!Array.isArray(items)) {
+      throw new Error(`fetch() returned ${items === null ? 'null' : typeof items}, not an array of items`);
+    }
+    run.items = items;
+  } catch (err) {
+    run.status = err.timedOut ? 'timed out' : 'failed';
+    run.error = err.message;
+    // A timed-out source still holds the old list; what it adds while it
+    // winds down isn't wanted
+    run.failures = [...run.failures];
+    console.warn(`  Warning: Source ${source.name} ${err.timedOut ? '' : 'failed: '}${err.message}`);
+    run.failures.push({ source: source.name, error: err.message });
+  } finally {
+    clearTimeout(timer);
+    controller.abort();
+    run.durationMs = Date.now() - started;
+  }
+}
@@ -1,27 +1,49 @@
+import { readFile, writeFile } from 'fs/promises';
 import Parser from 'rss-parser';
 import { config } from '../config.js';
+import { clientFor } from '../utils/http-client.js';
+import { detectTools, activeSearchTerms, matchableText } from '../utils/tools.js';
+import { excerpt } from '../utils/excerpt.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+import { registerSource } from './registry.js';
 
-const parser = new Parser({
-  timeout: 15000,
-  headers: {
-    'User-Agent': 'PraetorianCoverageDigest/1.0',
-  },
-});
+const parser = new Parser();
+
+const MONTHS = ['jan', 'feb', 'mar', 'apr', 'may', 'jun', 'jul', 'aug', 'sep', 'oct', 'nov', 'dec'];
+// RFC 822 zones plus abbreviations feeds use anyway, as minutes east of UTC.
+// An unrecognized zone is read as UTC: a few hours off, but still dated.
+const ZONES = {
+  UT: 0, UTC: 0, GMT: 0, Z: 0,
+  EST: -300, EDT: -240, CST: -360, CDT: -300, MST: -420, MDT: -360, PST: -480, PDT: -420,
+  WET: 0, WEST: 60, BST: 60, CET: 60, CEST: 120, EET: 120, EEST: 180,
+  JST: 540, AEST: 600, AEDT: 660,
+};
+// "[Mon, ]16 Feb 2026[ 10:00[:00]] [zone]", tolerating full or misspelled
+// day and month names, two-digit years, and a missing time or odd zone
+const RFC822_DATE = /^(?:[a-z]+,?\s*)?(\d{1,2})[\s-]+([a-z]+)\.?[\s-]+(\d{4}|\d{2})(?!\d)(?:,?\s+(\d{1,2}):(\d{2})(?::(\d{2}))?)?\s*(.*)$/i;
+// "[Friday, ]February 13th, 2026[ at 3:45 PM] [zone]", as article pages
+// write dates for people
+const MONTH_FIRST_DATE = /^(?:[a-z]+,?\s+)?([a-z]+)\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})(?:,?\s+(?:at\s+)?(\d{1,2}):(\d{2})(?::(\d{2}))?\s*([ap]\.?m\.?)?)?\s*(.*)$/i;
+// "2026/02/13" and day-first "13.02.2026", each with an optional time
+const SLASHED_DATE = /^(\d{4})\/(\d{1,2})\/(\d{1,2})(?:[T\s]+(\d{1,2}):(\d{2})(?::(\d{2}))?)?\s*(.*)$/i;
+const DOTTED_DATE = /^(\d{1,2})\.(\d{1,2})\.(\d{4})(?:,?\s+(\d{1,2}):(\d{2})(?::(\d{2}))?)?\s*(.*)$/i;
+// ISO 8601 date-time without a zone, which Date would read as local time
+const ZONELESS_ISO = /^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?$/;
 
 /**
  * Check if an RSS item mentions Praetorian or any tracked tools.
  * Returns matched terms for tagging.
  */
 function findMentions(item) {
-  const searchText = [
+  const searchText = matchableText([
     item.title || '',
     item.contentSnippet || '',
     item.content || '',
     item.summary || '',
-  ].join(' ').toLowerCase();
+  ].join(' '));
 
-  const matched = config.searchTerms.filter(term =>
-    searchText.includes(term.toLowerCase())
+  const matched = activeSearchTerms().filter(term =>
+    searchText.includes(matchableText(term))
   );
 
   return matched;
@@ -31,15 +53,11 @@ function findMentions(item) {
  * Identify which Praetorian tools are mentioned in an item.
  */
 function findToolMentions(item) {
-  const searchText = [
+  return detectTools([
     item.title || '',
     item.contentSnippet || '',
     item.content || '',
-  ].join(' ').toLowerCase();
-
-  return config.tools.filter(tool =>
-    searchText.toLowerCase().includes(tool.toLowerCase())
-  );
+  ].join(' '));
 }
 
 /**
@@ -47,29 +65,156 @@ function findToolMentions(item) {
  */
 function extractExcerpt(item, maxLength = 200) {
   const text = item.contentSnippet || item.summary || item.content || '';
-  const clean = text.replace(/<[^>]+>/g, '').replace(/\s+/g, ' ').trim();
-  if (clean.length <= maxLength) return clean;
-  return clean.substring(0, maxLength).replace(/\s+\S*$/, '') + '...';
+  return excerpt(text.replace(/<[^>]+>/g, ''), maxLength);
+}
+
+/**
+ * Parse a feed item's or article page's date. Feeds often break RFC
+ * 822/1123 (full day names, zones like CEST, no zone at all) and pages
+ * write dates for people ("February 13th, 2026 at 3:45 PM"), which Date
+ * either rejects or reads in the runner's local time. Zoneless dates are
+ * taken as UTC, and a date without a time as midnight UTC.
+ * Returns a Date, or null if the value can't be read as a date.
+ */
+export function parseFeedDate(value) {
+  const text = String(value ?? '').trim();
+  if (!text) return null;
+  if (ZONELESS_ISO.test(text)) return validDate(new Date(`${text.replace(' ', 'T')}Z`));
+
+  let match = text.match(RFC822_DATE);
+  if (match) {
+    const [, day, monthName, yearText, hour, minute, second, zone] = match;
+    const year = yearText.length === 2 ? 2000 + parseInt(yearText, 10) : parseInt(yearText, 10);
+    const date = dateFromParts(year, monthIndex(monthName), day, hour, minute, second, zone);
+    if (date) return date;
+  }
+  match = text.match(MONTH_FIRST_DATE);
+  if (match) {
+    const [, monthName, day, year, hour, minute, second, meridiem, zone] = match;
+    const date = dateFromParts(year, monthIndex(monthName), day, twentyFourHour(hour, meridiem), minute, second, zone);
+    if (date) return date;
+  }
+  match = text.match(SLASHED_DATE);
+  if (match) {
+    const [, year, month, day, hour, minute, second, zone] = match;
+    return dateFromParts(year, parseInt(month, 10) - 1, day, hour, minute, second, zone);
+  }
+  match = text.match(DOTTED_DATE);
+  if (match) {
+    const [, day, month, year, hour, minute, second, zone] = match;
+    return dateFromParts(year, parseInt(month, 10) - 1, day, hour, minute, second, zone);
+  }
+  return validDate(new Date(text));
+}
+
+// A Date from written-out parts, or null if they don't make one (a month
+// name we don't know, February 30th)
+function dateFromParts(year, month, day, hour = '0', minute = '0', second = '0', zone = '') {
+  if (month < 0 || month > 11) return null;
+  const parts = [year, month, day, hour, minute, second].map(Number);
+  const utc = new Date(Date.UTC(...parts));
+  if (utc.getUTCMonth() !== parts[1] || utc.getUTCDate() !== parts[2] || parts[3] > 23) return null;
+  return validDate(new Date(utc.getTime() - zoneOffset(zone) * 60000));
+}
+
+function monthIndex(name) {
+  return name.length >= 3 ? MONTHS.indexOf(name.slice(0, 3).toLowerCase()) : -1;
+}
+
+// "3", "pm" -> 15; "12", "a.m." -> 0
+function twentyFourHour(hour, meridiem) {
+  if (hour === undefined || !meridiem) return hour;
+  const value = parseInt(hour, 10) % 12;
+  return String(/^p/i.test(meridiem) ? value + 12 : value);
+}
+
+// Minutes east of UTC for "+0100", "-05:00", "GMT+0100 (CET)", or a zone
+// name
+function zoneOffset(zone) {
+  const numeric = zone.match(/([+-])(\d{2}):?(\d{2})(?!\d)/);
+  if (numeric) {
+    const minutes = parseInt(numeric[2], 10) * 60 + parseInt(numeric[3], 10);
+    return numeric[1] === '-' ? -minutes : minutes;
+  }
+  return ZONES[zone.match(/[a-z]+/i)?.[0].toUpperCase()] ?? 0;
+}
+
+function validDate(date) {
+  return Number.isNaN(date.getTime()) ? null : date;
+}
+
+/**
+ * Fetch and parse a feed through its configured HTTP client. With
+ * `validators` (see loadFeedValidators), the request is conditional on the
+ * ETag and Last-Modified stored for the URL; an unchanged feed answers 304
+ * and this returns null. The validators from a successful fetch are
+ * stored back for next time.
+ */
+async function fetchFeed(feed, client = clientFor(feed.name), validators = null, signal = null) {
+  const stored = validators?.[feed.url];
+  const headers = {};
+  if (stored?.etag) headers['If-None-Match'] = stored.etag;
+  if (stored?.last_modified) headers['If-Modified-Since'] = stored.last_modified;
+
+  const res = await client.fetch(feed.url, { headers, signal });
+  if (res.status === 304 && stored) return null;
+  if (!res.ok) {
+    throw new Error(`HTTP ${res.status}`);
+  }
+  const parsed = await parser.parseString(await res.text());
+  if (validators) {
+    const { etag, 'last-modified': lastModified } = res.headers;
+    if (etag || lastModified) {
+      validators[feed.url] = { ...(etag && { etag }), ...(lastModified && { last_modified: lastModified }) };
+    } else {
+      delete validators[feed.url];
+    }
+  }
+  return parsed;
+}
+
+/**
+ * Load the stored ETag / Last-Modified values for conditional feed
+ * requests, keyed by feed URL. Missing file: no validators yet.
+ */
+export async function loadFeedValidators(path = config.paths.feedValidators) {
+  try {
+    return JSON.parse(await readFile(path, 'utf-8'));
+  } catch (err) {
+    if (err.code === 'ENOENT') return {};
+    throw err;
+  }
+}
+
+/**
+ * Save feed validators. Call only once the items fetched with them are
+ * safely stored: after a 304 the feed's items aren't seen again.
+ */
+export async function saveFeedValidators(validators, path = config.paths.feedValidators) {
+  const sorted = Object.fromEntries(Object.entries(validators).sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0)));
+  await writeFile(path, JSON.stringify(sorted, null, 2) + '\n');
 }
 
 /**
  * Fetch and filter a single RSS feed for Praetorian mentions.
+ * Tool attribution is done per item, so a shared fetch still tags
+ * each item with whichever tools it actually mentions.
  */
-async function checkFeed(feed, since) {
+async function checkFeed(feed, since, fetch = fetchFeed, failures = []) {
   try {
-    const parsed = await Promise.race([
-      parser.parseURL(feed.url),
-      new Promise((_, reject) =>
-        setTimeout(() => reject(new Error('Feed fetch timed out after 30s')), 30000)
-      ),
-    ]);
+    const parsed = await fetch(feed);
+    if (parsed === null) {
+      console.log(`  ${feed.name || feed.url}: not modified`);
+      return [];
+    }
     const items = [];
 
     for (const item of parsed.items || []) {
-      const pubDate = item.pubDate ? new Date(item.pubDate) : null;
+      const pubDate = parseFeedDate(item.pubDate) || parseFeedDate(item.isoDate);
 
-      // Skip items older than our lookback window
-      if (pubDate && since && pubDate < since) continue;
+      // Skip items older than our lookback window, unless the feed is being
+      // bootstrapped, in which case its history is returned for absorption
+      if (pubDate && since && pubDate < since && !feed.bootstrap) continue;
 
       const mentions = findMentions(item);
       if (mentions.length === 0) continue;
@@ -77,37 +222,57 @@ async function checkFeed(feed, since) {
       const tools = findToolMentions(item);
 
       items.push({
-        source: feed.name,
+        source: feed.name || parsed.title?.trim() || 'Unknown',
         sourceType: 'rss',
         icon: feed.icon,
-        title: item.title || 'Untitled',
+        title: item.title?.replace(/\s+/g, ' ').trim() || 'Untitled',
         url: item.link || '',
         date: pubDate ? pubDate.toISOString() : new Date().toISOString(),
+        undated: !pubDate,
         excerpt: extractExcerpt(item),
         toolsMentioned: tools,
+        untagged: tools.length === 0,
         matchedTerms: mentions,
+        bootstrap: Boolean(feed.bootstrap),
         raw: {
-          guid: item.guid || item.link || item.title,
+          // Atom entries have an id rather than a guid
+          guid: item.guid || item.id || item.link || item.title,
         },
       });
     }
 
     return items;
   } catch (err) {
-    console.warn(`  Warning: Failed to fetch ${feed.name} (${feed.url}): ${err.message}`);
+    console.warn(`  Warning: Failed to fetch ${feed.name || 'feed'} (${feed.url}): ${err.message}`);
+    failures.push({ source: feed.name || feed.url, error: err.message });
     return [];
   }
 }
 
 /**
- * Check all configured RSS feeds for Praetorian mentions.
+ * Check all configured RSS and Atom feeds for Praetorian mentions.
  * @param {Date} since - Only return items published after this date
+ * @param {Array} [failures] - Receives { source, error } for each feed that failed
+ * @param {Object} [options.validators] - Stored ETag / Last-Modified values
+ *   (loadFeedValidators); when given, unchanged feeds are skipped with a
+ *   conditional request and the object is updated in place
+ * @param {Function} [options.memo] - The run's query memo
+ *   (utils/query-memo.js), through which a feed listed more than once (e.g.
+ *   Google Alerts for different tools that resolve to the same feed) is
+ *   fetched once; feeds with different client overrides are fetched
+ *   separately
+ * @param {AbortSignal} [options.signal] - Aborts the requests in flight (the
+ *   run's, once the source times out)
  * @returns {Promise<Array>} Array of coverage items
  */
-export async function checkRssFeeds(since) {
+export async function checkRssFeeds(since, failures = [], { validators = null, memo = createQueryMemo(), signal = null } = {}) {
   console.log('Checking RSS feeds...');
 
   const allFeeds = [...config.rssFeeds, ...config.googleAlertsFeeds];
+  const fetch = feed => {
+    const client = clientFor(feed.name);
+    return memo('rss', `${client.name} ${feed.url}`, since, () => fetchFeed(feed, client, validators, signal));
+  };
   const results = [];
 
   // Process feeds concurrently with a concurrency limit
@@ -115,7 +280,7 @@ export async function checkRssFeeds(since) {
   for (let i = 0; i < allFeeds.length; i += batchSize) {
     const batch = allFeeds.slice(i, i + batchSize);
     const batchResults = await Promise.all(
-      batch.map(feed => checkFeed(feed, since))
+      batch.map(feed => checkFeed(feed, since, fetch, failures))
     );
     for (const items of batchResults) {
       results.push(...items);
@@ -125,3 +290,8 @@ export async function checkRssFeeds(since) {
   console.log(`  Found ${results.length} Praetorian mention(s) across ${allFeeds.length} feeds`);
   return results;
 }
+
+registerSource({
+  name: 'rss',
+  fetch: (since, failures, options, { validators = null, memo, signal }) => checkRssFeeds(since, failures, { validators, memo, signal }),
+});
@@ -0,0 +1,109 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - Outbound Digest Webhooks
+ *
+ * After a digest is published (the workflow stamps its items with
+ * last_sent_at), POST the digest as JSON to every endpoint in
+ * DIGEST_WEBHOOKS. Each request carries X-Digest-Timestamp and
+ * X-Digest-Signature headers, an HMAC-SHA256 over the timestamp and raw
+ * body with that endpoint's secret (see utils/webhook-signature.js for
+ * the consumer-side verifier).
+ *
+ * Failed deliveries are retried with exponential backoff up to
+ * maxAttempts, and every delivery's outcome and body are recorded in
+ * webhook-outbox.json next to the tracker, so it can be sent again as it
+ * was (see utils/digest-webhooks.js).
+ *
+ * Usage:
+ *   node notify-webhooks.js                   # Deliver the latest digest
+ *   node notify-webhooks.js --replay          # Re-send every failed outbox entry
+ *   node notify-webhooks.js --replay=<digest> # Re-send one digest, even if delivered
+ *   node notify-webhooks.js --dry-run         # Show what would be sent
+ */
+
+import { readFile, writeFile } from 'fs/promises';
+import { config } from './config.js';
+import { loadTracker } from './utils/tracker.js';
+import { buildPayload, deliverWebhook, findEntry, recordDelivery, replayDeliveries } from './utils/digest-webhooks.js';
+
+const isDryRun = process.argv.includes('--dry-run');
+const replayArg = process.argv.find(arg => arg === '--replay' || arg.startsWith('--replay='));
+const isReplay = Boolean(replayArg);
+const replayDigest = replayArg?.split('=')[1] || null;
+
+async function main() {
+  const { endpoints } = config.digestWebhooks;
+  if (endpoints.length === 0) {
+    console.log('No digest webhooks configured (DIGEST_WEBHOOKS). Nothing to do.');
+    return;
+  }
+
+  const tracker = await loadTracker(config.paths.coverageTracker);
+  const outbox = await loadOutbox();
+
+  // Work list: (digest, endpoint, body) to deliver
+  let deliveries = [];
+  if (isReplay) {
+    deliveries = replayDeliveries(outbox, endpoints, tracker, replayDigest);
+  } else {
+    const latest = tracker.map(item => item.last_sent_at).filter(Boolean).sort().pop();
+    if (!latest) {
+      console.log('No published digest yet (no item has last_sent_at).');
+      return;
+    }
+    const body = JSON.stringify(buildPayload(tracker, latest));
+    for (const endpoint of endpoints) {
+      const existing = findEntry(outbox, latest, endpoint.name);
+      if (existing?.status === 'delivered') continue;
+      deliveries.push({ digest: latest, endpoint, body });
+    }
+  }
+
+  if (deliveries.length === 0) {
+    console.log('All deliveries up to date.');
+    return;
+  }
+
+  let failed = 0;
+  for (const { digest, endpoint, body } of deliveries) {
+    console.log(`Delivering digest ${digest} to ${endpoint.name} (${body.length} bytes)...`);
+    if (isDryRun) continue;
+
+    const result = await deliverWebhook(endpoint, body);
+    recordDelivery(outbox, digest, endpoint.name, result, body);
+    if (result.ok) {
+      console.log(`  ✓ Delivered after ${result.attempts} attempt(s)`);
+    } else {
+      console.log(`  ✗ Failed after ${result.attempts} attempt(s): ${result.error}`);
+      failed++;
+    }
+  }
+
+  if (!isDryRun) {
+    await saveOutbox(outbox);
+    console.log(`\nOutbox saved: ${config.digestWebhooks.outbox}`);
+  }
+  if (failed > 0) {
+    console.log(`${failed} delivery(ies) failed; run with --replay to retry`);
+    process.exitCode = 1;
+  }
+}
+
+async function loadOutbox() {
+  try {
+    return JSON.parse(await readFile(config.digestWebhooks.outbox, 'utf-8'));
+  } catch (err) {
+    if (err.code === 'ENOENT') return [];
+    throw err;
+  }
+}
+
+async function saveOutbox(outbox) {
+  await writeFile(config.digestWebhooks.outbox, JSON.stringify(outbox, null, 2) + '\n');
+}
+
+main().catch(err => {
+  console.error('\nFATAL:', err.message);
+  process.exit(1);
+});
@@ -0,0 +1,163 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - GitHub Issue Backup Record
+ *
+ * Files the day's new tracker items as a "Coverage Digest - <date>" issue.
+ * A day with no new items files nothing, a one-paragraph note, or the
+ * empty digest, per EMPTY_DIGEST (see utils/empty-digest.js).
+ * If an open digest issue for the same date already exists (e.g. the
+ * workflow was re-run), the dedupe strategy decides what happens instead:
+ *
+ *   skip     leave the existing issue alone
+ *   update   merge the new items into its body, keeping ticked boxes and
+ *            notes people added (falls back to a comment if the body has
+ *            been restructured)
+ *   comment  append the new items as a comment
+ *
+ * With DIGEST_ISSUE_MODE=weekly there is instead one issue per ISO week,
+ * found by a hidden <!-- coverage-digest: 2026-W08 --> line in its body.
+ * Each run replaces its body with the week's items so far and comments
+ * with what changed; the dedupe strategy doesn't apply.
+ *
+ * Usage:
+ *   node publish-issue.js                    # Strategy from DIGEST_ISSUE_DEDUPE
+ *   node publish-issue.js --dedupe=comment   # Override the strategy
+ *   node publish-issue.js --dry-run          # Print the issue instead of filing it
+ *   node publish-issue.js --format=json      # Print the digest as JSON (see utils/digest-json.js)
+ *   node publish-issue.js --template=t.md    # Print the digest rendered through a template
+ *                                            # (--template alone uses digest-template.md)
+ *
+ * With S3_BUCKET set, the digest filed is also archived in the bucket as
+ * markdown and JSON (see utils/digest-archive.js), unless
+ * S3_ARCHIVE_DIGESTS=false.
+ *
+ * Items that fail the digest's validation (utils/validate.js) are dropped
+ * from every output, or fail the run with DIGEST_VALIDATION=strict.
+ *
+ * Requires GITHUB_TOKEN and GITHUB_REPOSITORY (set automatically in Actions).
+ */
+
+import { config } from './config.js';
+import { loadTracker } from './utils/tracker.js';
+import { buildDigest, writeDigestJSON } from './utils/digest-json.js';
+import { archiveDigest } from './utils/digest-archive.js';
+import { sharedS3 } from './utils/s3.js';
+import { loadDigestTemplate } from './utils/digest-template.js';
+import { planDigest } from './utils/empty-digest.js';
+import { loadRunSummary } from './monitors/registry.js';
+import { openPendingTools } from './utils/tool-discovery.js';
+import { publishableTrackerItems } from './utils/validate.js';
+import {
+  compactIssueTitle,
+  createGitHubApi,
+  digestDate,
+  digestPeriod,
+  issueAssignees,
+  issueLabels,
+  issueTitle,
+  periodItems,
+  periodMarker,
+  publishDigestIssue,
+  publishRollingIssue,
+  renderCompactIssueBody,
+  renderIssueBody,
+  rollingIssueTitle,
+} from './utils/github-issue.js';
+
+const isDryRun = process.argv.includes('--dry-run');
+const dedupeArg = process.argv.find(arg => arg.startsWith('--dedupe='));
+const formatArg = process.argv.find(arg => arg.startsWith('--format='));
+const format = formatArg ? formatArg.split('=')[1] : 'markdown';
+const templateArg = process.argv.find(arg => arg === '--template' || arg.startsWith('--template='));
+
+async function main() {
+  if (!['json', 'markdown'].includes(format)) {
+    throw new Error(`Unknown --format "${format}" (expected json or markdown)`);
+  }
+
+  // Compile first so a broken template fails before any work is done
+  const template = templateArg ? await loadDigestTemplate(templateArg.split('=')[1] || '') : null;
+
+  const tracker = await loadTracker(config.paths.coverageTracker);
+  // Every rendering below (JSON, template, issue) gets only items that
+  // pass the digest's validation (utils/validate.js)
+  const newItems = publishableTrackerItems(tracker.filter(item => item.status === 'new'));
+  // New tools the pipeline found on the blog, for Action Needed
+  const pendingTools = config.toolDiscovery.enabled
+    ? (await openPendingTools()).pendingTools().map(entry => entry.name)
+    : [];
+  // JSON goes to stdout for dashboards and is never filed as an issue
+  if (format === 'json') {
+    writeDigestJSON(process.stdout, buildDigest(newItems, new Date(), tracker, { pendingTools }));
+    return;
+  }
+  if (template) {
+    process.stdout.write(template.render(buildDigest(newItems, new Date(), tracker, { pendingTools })));
+    return;
+  }
+  // EMPTY_DIGEST decides what a day without new items files
+  const plan = planDigest(newItems, tracker);
+  if (!plan.publish) {
+    console.log('No new items; no issue to file.');
+    return;
+  }
+
+  const date = digestDate();
+  const weekly = config.digestIssue.mode === 'weekly';
+  if (isDryRun && plan.compact && !weekly) {
+    console.log(`${compactIssueTitle(date)}\n\n${renderCompactIssueBody(plan.lastItem)}`);
+    return;
+  }
+  // DIGEST_ISSUE_RUN_SUMMARY adds what the pipeline saved about its sources
+  const runSummary = config.digestIssue.runSummary ? await loadRunSummary() : null;
+  // The weekly issue lists the whole week so far, not only today's items
+  const period = digestPeriod();
+  const issueItems = weekly ? publishableTrackerItems(periodItems(tracker, period)) : newItems;
+  if (isDryRun) {
+    const { body, continuations } = renderIssueBody(issueItems, { history: tracker, runSummary, pendingTools });
+    const count = issueItems.filter(item => !item.low_relevance).length;
+    console.log(weekly
+      ? `${rollingIssueTitle(period, count)}\n\n${periodMarker(period)}\n${body}`
+      : `${issueTitle(date, count)}\n\n${body}`);
+    const labels = issueLabels(issueItems).map(label => label.name);
+    const assignees = issueAssignees(issueItems);
+    console.log(`[labels] ${[config.digestIssue.label, ...labels].join(', ')}${assignees.length > 0 ? `\n[assignees] ${assignees.join(', ')}` : ''}`);
+    continuations.forEach(comment => console.log(`\n[comment]\n\n${comment}`));
+    return;
+  }
+
+  const token = process.env.GITHUB_TOKEN;
+  const repo = process.env.GITHUB_REPOSITORY;
+  if (!token || !repo) {
+    throw new Error('GITHUB_TOKEN and GITHUB_REPOSITORY are required');
+  }
+
+  const api = createGitHubApi({ token, repo });
+  if (weekly) {
+    await publishRollingIssue(api, issueItems, { period, history: tracker, runSummary, pendingTools });
+  } else {
+    const strategy = dedupeArg ? dedupeArg.split('=')[1] : config.digestIssue.dedupeStrategy;
+    await publishDigestIssue(api, newItems, {
+      date, strategy, history: tracker, runSummary, pendingTools, compact: plan.compact, lastItem: plan.lastItem,
+    });
+  }
+
+  // The whole digest, whatever the issue ended up holding, for rollups
+  // and trends to read back (utils/digest-archive.js listDigests)
+  if (sharedS3() && config.s3.archiveDigests) {
+    const { body, continuations } = newItems.length === 0 && plan.compact
+      ? { body: renderCompactIssueBody(plan.lastItem), continuations: [] }
+      : renderIssueBody(newItems, { history: tracker, runSummary, pendingTools });
+    const keys = await archiveDigest({
+      markdown: [body, ...continuations].join('\n\n'),
+      json: buildDigest(newItems, new Date(), tracker, { pendingTools }),
+    });
+    console.log(`Archived digest to s3://${config.s3.bucket}/${config.s3.prefix}${keys.markdown} (and .json)`);
+  }
+}
+
+main().catch(err => {
+  console.error('\nFATAL:', err.message);
+  process.exit(1);
+});
@@ -0,0 +1,56 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - Microsoft Teams
+ *
+ * After a digest is published (the workflow stamps its items with
+ * last_sent_at), post it to the Teams channel behind TEAMS_WEBHOOK_URL
+ * as Adaptive Cards: the summary, then up to TEAMS_MAX_ITEMS items. A
+ * digest too big for one Teams message (28 KB) goes out as several.
+ * See utils/teams.js. Items that fail the digest's validation
+ * (utils/validate.js) are left out, or fail the run in strict mode.
+ *
+ * Usage:
+ *   node publish-teams.js              # Post the latest digest
+ *   node publish-teams.js --dry-run    # Print the messages instead of posting them
+ */
+
+import { config } from './config.js';
+import { loadTracker } from './utils/tracker.js';
+import { digestDate } from './utils/github-issue.js';
+import { publishTeamsDigest, renderTeamsMessages } from './utils/teams.js';
+import { publishableTrackerItems } from './utils/validate.js';
+
+const isDryRun = process.argv.includes('--dry-run');
+
+async function main() {
+  if (!config.teams.webhookUrl && !isDryRun) {
+    console.log('No Teams webhook configured (TEAMS_WEBHOOK_URL). Nothing to do.');
+    return;
+  }
+
+  const tracker = await loadTracker(config.paths.coverageTracker);
+  const latest = tracker.map(item => item.last_sent_at).filter(Boolean).sort().pop();
+  if (!latest) {
+    console.log('No published digest yet (no item has last_sent_at).');
+    return;
+  }
+  const items = publishableTrackerItems(tracker.filter(item => item.last_sent_at === latest));
+  const date = digestDate(new Date(latest));
+
+  if (isDryRun) {
+    for (const message of renderTeamsMessages(items, { date })) {
+      const json = JSON.stringify(message, null, 2);
+      console.log(`[message] ${Buffer.byteLength(JSON.stringify(message))} bytes\n${json}\n`);
+    }
+    return;
+  }
+
+  const count = await publishTeamsDigest(items, { date });
+  console.log(`Posted digest ${latest} to Teams (${count} message(s))`);
+}
+
+main().catch(err => {
+  console.error('\nFATAL:', err.message);
+  process.exit(1);
+});
@@ -6,34 +6,90 @@
  * This is the production script that GitHub Actions runs daily.
  * It handles the complete lifecycle:
  *
- *   1. Scan RSS feeds for new Praetorian mentions
- *   2. Check manual submissions
+ *   1. Scan RSS feeds (and news searches, Mastodon, podcasts, and Hacker News, if enabled) for new Praetorian mentions
+ *   2. Check manual submissions, and take webhook submissions from the inbox
  *   3. Merge new discoveries into coverage-tracker.json (deduped)
  *   4. Mark previously-sent items as "sent" (lifecycle management)
  *   5. Render branded HTML digest from current "new" items
- *   6. Save preview.html for email delivery
+ *   6. Save preview.html (and its plain-text alternative, preview.txt) for email delivery
  *   7. Save updated tracker for git commit
  *
  * Usage:
  *   node run-digest-pipeline.js              # Full pipeline
  *   node run-digest-pipeline.js --dry-run    # Scan + merge but skip email render
+ *   node run-digest-pipeline.js --skip-archive   # Don't fetch Wayback Machine links
+ *   node run-digest-pipeline.js --fail-on=publish-error
+ *
+ * Exit codes:
+ *   0  Success (or a condition not selected by --fail-on)
+ *   1  Fatal error
+ *   2  Published, but one or more sources failed
+ *   3  Publish partially failed (tracker saved, preview not written)
+ *   4  Nothing to publish
+ *
+ * --fail-on takes a comma-separated list of conditions that should produce
+ * a non-zero exit: source-error, publish-error, empty (or "all" / "none").
+ * Default: source-error,publish-error.
  */
 
-import { readFile, writeFile } from 'fs/promises';
+import { writeFile } from 'fs/promises';
 import { join } from 'path';
 import { config } from './config.js';
-import { checkRssFeeds } from './monitors/rss-feeds.js';
-import { checkManualSubmissions } from './monitors/manual-submissions.js';
-import { renderDigest } from './utils/template-renderer.js';
+import { fetchSources, formatRunSummary, loadSourceModules, saveRunSummary } from './monitors/index.js';
+import { loadFeedValidators, saveFeedValidators } from './monitors/rss-feeds.js';
+import { canonicalizeItems, enrichItems } from './monitors/html-article.js';
+import { applyLanguagePolicy } from './utils/language.js';
+import { normalizeSources } from './utils/publishers.js';
+import { collapseSyndicated } from './utils/syndication.js';
+import { applyConfidence } from './utils/confidence.js';
+import { applyRelevance } from './utils/relevance.js';
+import { applyToolDiscovery } from './utils/tool-discovery.js';
+import { renderDigest, renderDigestText } from './utils/template-renderer.js';
+import { publishableTrackerItems } from './utils/validate.js';
+import { sortItems } from './utils/sort.js';
+import { loadClassifier, classifyTrackerItems } from './utils/sentiment.js';
+import { alertNegativeCoverage } from './utils/alerts.js';
+import { archiveTrackerItems } from './utils/archive.js';
+import { planDigest } from './utils/empty-digest.js';
+import { openInbox } from './utils/submissions.js';
+import { openSeenStore } from './utils/seen-store.js';
+import { compareSummaries, previousDigestSummary, summarizeDigest } from './utils/summary.js';
+import { shiftDays, startOfDay } from './utils/timezone.js';
+import {
+  loadTracker, saveTracker, mergeIntoTracker, countByStatus, liftExpiredEmbargoes, splitByAge,
+  refreshTrackedItems, relocateMovedItems, isPendingUpdate,
+} from './utils/tracker.js';
+
+const EXIT_CODES = {
+  success: 0,
+  fatal: 1,
+  'source-error': 2,
+  'publish-error': 3,
+  empty: 4,
+};
+
+// How each discovering source is labelled in a digest item's matchedTerms
+const MATCHED_TERMS = {
+  'rss-monitor': 'rss',
+  'google-news': 'google-news',
+  'news-api': 'news-api',
+  mastodon: 'mastodon',
+  podcasts: 'podcasts',
+  'hacker-news': 'hacker-news',
+};
+
+const FAIL_ON_CONDITIONS = ['source-error', 'publish-error', 'empty'];
+const DEFAULT_FAIL_ON = ['source-error', 'publish-error'];
 
 // Safety: kill process if it hasn't finished in 5 minutes
 const GLOBAL_TIMEOUT_MS = 5 * 60 * 1000;
 setTimeout(() => {
   console.error('\nFATAL: Pipeline exceeded 5-minute safety timeout. Forcing exit.');
-  process.exit(2);
+  process.exit(EXIT_CODES.fatal);
 }, GLOBAL_TIMEOUT_MS).unref();
 
 const isDryRun = process.argv.includes('--dry-run');
+const skipArchive = process.argv.includes('--skip-archive');
 
 async function main() {
   console.log('============================================');
@@ -55,88 +111,288 @@ async function main() {
     console.log(`Lifecycle: marked ${markedSent} previously "new" items as "sent"\n`);
   }
 
+  // 2a. Take what the webhook receiver queued since the last run. They're
+  //     removed from the inbox only once the tracker is saved; if this run
+  //     dies first, the next takes them again (as duplicates, if saved)
+  const inbox = openInbox();
+  const submissions = await inbox.list();
+  if (submissions.length > 0) {
+    const received = mergeIntoTracker(tracker, submissions);
+    console.log(`Webhook: ${received} of ${submissions.length} submission(s) added (the rest already tracked)\n`);
+  }
+  const saveAll = async () => {
+    await saveTracker(trackerPath, tracker);
+    await saveFeedValidators(feedValidators);
+    // A dry run leaves them for the real one
+    if (!isDryRun) await inbox.remove(submissions);
+  };
+
+  // 2b. Release embargoed items whose embargo has passed
+  const lifted = liftExpiredEmbargoes(tracker);
+  if (lifted > 0) {
+    console.log(`Lifecycle: lifted embargo on ${lifted} item(s)\n`);
+  }
+
   // 3. Auto-archive old "sent" items (>30 days since sent)
   const markedArchived = autoArchiveOldItems(tracker);
   if (markedArchived > 0) {
     console.log(`Lifecycle: auto-archived ${markedArchived} items older than 30 days\n`);
   }
 
-  // 4. Scan RSS feeds for new mentions (look back 7 days to catch anything missed)
+  // 4. Scan RSS feeds for new mentions (look back 7 days to catch anything
+  //    missed). Whole calendar days in the digest's time zone, from midnight.
   const lookbackDays = 7;
-  const since = new Date();
-  since.setDate(since.getDate() - lookbackDays);
+  const since = startOfDay(shiftDays(new Date(), -lookbackDays));
   console.log(`Scanning RSS feeds (lookback: ${lookbackDays} days)...`);
 
-  const [rssItems, manualItems] = await Promise.all([
-    checkRssFeeds(since),
-    checkManualSubmissions(since),
-  ]);
+  // Unchanged feeds answer a conditional request with 304; the validators
+  // are saved with the tracker, so a feed is only skipped once its items
+  // are stored
+  const feedValidators = await loadFeedValidators();
+  const sourceFailures = [];
+  await loadSourceModules();
+  // Mastodon reads back only to the last recorded run, so a re-run doesn't
+  // emit every status in the lookback again
+  const lastRun = (await openSeenStore()).lastRunTime();
+  const { bySource, summary: sourceRun } = await fetchSources(since, sourceFailures, { validators: feedValidators, tracker, lastRun });
+  const discovered = [...bySource.values()].flat();
+  console.log(`\n${formatRunSummary(sourceRun)}`);
+  await saveRunSummary(sourceRun);
+
+  // 4a. Canonical URLs, so the same article from two sources (a feed
+  //     proxy link, an AMP page, a utm_ variant) dedups as one item, and
+  //     publisher names looked up by the article's own host
+  const canonicalized = await canonicalizeItems(discovered, { tracker });
+  if (canonicalized > 0) {
+    console.log(`Canonical URLs: rewrote ${canonicalized} item link(s)`);
+  }
+  normalizeSources(discovered);
+
+  //     Then read the pages of new items that came without an excerpt or
+  //     a publish date. Before the age cutoff, which needs the real date.
+  if (config.scrape.enrich) {
+    const enriched = await enrichItems(discovered, { tracker });
+    if (enriched > 0) {
+      console.log(`Enrichment: filled in ${enriched} item(s) from their pages`);
+    }
+  }
+
+  // 4b. New tools announced on the Praetorian Blog, kept pending in
+  //     coverage-tracker/pending-tools.json for Action Needed (not saved
+  //     on --dry-run); with MATCH_PENDING_TOOLS, items mentioning one are
+  //     tagged with it
+  let pendingTools = [];
+  if (config.toolDiscovery.enabled) {
+    const discovery = await applyToolDiscovery(discovered, { save: !isDryRun });
+    for (const entry of discovery.found) {
+      console.log(`New tool: "${entry.name}" announced in "${entry.title}"; pending until configured`);
+    }
+    if (discovery.tagged > 0) {
+      console.log(`New tool: tagged ${discovery.tagged} item(s) with a pending tool`);
+    }
+    pendingTools = discovery.pending.map(entry => entry.name);
+  }
+
+  // 4c. Language of each item; with FOREIGN_LANGUAGE_ITEMS=exclude, items
+  //     in other languages go no further
+  const languages = applyLanguagePolicy(discovered);
+  if (languages.foreign > 0) {
+    console.log(`Language: ${languages.foreign} item(s) not in ${config.language.primary}${languages.excluded > 0 ? `, ${languages.excluded} left out` : ''}`);
+  }
+  let candidates = languages.items;
+
+  // 4d. Syndicated copies: one wire story republished by several outlets
+  //     is one item, listing the others as "also published by"
+  if (config.syndication.enabled) {
+    const syndicated = collapseSyndicated(candidates);
+    if (syndicated.collapsed > 0) {
+      console.log(`Syndication: folded ${syndicated.collapsed} republished item(s) into their originals`);
+    }
+    candidates = syndicated.items;
+  }
+
+  // 4e. Age cutoff: only items published within the window (or up to
+  //     maxItemAgeDays before being first seen) go into the digest.
+  //     Older items from bootstrapped feeds are absorbed as already-seen.
+  const { fresh, historical } = splitByAge(candidates, since, config.maxItemAgeDays);
+  const toAbsorb = historical.filter(item => item.bootstrap);
+  const tooOld = historical.length - toAbsorb.length;
+  if (tooOld > 0) {
+    console.log(`Age cutoff: skipped ${tooOld} item(s) published before the digest window`);
+  }
 
-  const discovered = [...rssItems, ...manualItems];
-  console.log(`\nDiscovered: ${rssItems.length} from RSS, ${manualItems.length} from manual submissions`);
+  // 4f. Mention confidence: items that may not be about Praetorian at all
+  //     go under Needs Review, and the unlikely ones no further
+  const scored = applyConfidence(fresh);
+  if (scored.review > 0) {
+    console.log(`Confidence: ${scored.review} item(s) need review`);
+  }
+  for (const item of scored.dropped) {
+    console.log(`Confidence: left out "${item.title}" (${item.source}), scored ${item.confidence}`);
+  }
+
+  // 4g. Relevance: items only naming us in passing (a roundup of fifty
+  //     vendors) are kept out of the digest's list and just counted
+  if (config.relevance.enabled) {
+    const { excluded } = applyRelevance(scored.items);
+    for (const item of excluded) {
+      console.log(`Relevance: low for "${item.title}" (${item.source}), scored ${item.relevance.score}`);
+    }
+  }
 
-  // 5. Merge new discoveries into tracker (dedup by URL)
-  const newlyAdded = mergeIntoTracker(tracker, discovered);
+  // 5. Refresh items we already track; a rewrite of something already
+  //    sent goes back into the digest under Updated Coverage. First follow
+  //    tracked articles to new URLs, known by their text (article hash)
+  const moved = relocateMovedItems(tracker, candidates);
+  if (moved > 0) {
+    console.log(`Moved: ${moved} tracked article(s) found at a new URL`);
+  }
+  const updated = refreshTrackedItems(tracker, candidates);
+  if (updated > 0) {
+    console.log(`Updates: ${updated} previously sent item(s) have been rewritten since`);
+  }
+
+  // 5a. Merge new discoveries into tracker (dedup by URL)
+  const absorbed = mergeIntoTracker(tracker, toAbsorb, { absorb: true });
+  if (toAbsorb.length > 0) {
+    console.log(`Bootstrap: absorbed ${absorbed} historical item(s) silently (${toAbsorb.length - absorbed} already tracked)`);
+  }
+  const newlyAdded = mergeIntoTracker(tracker, scored.items);
   console.log(`Merged: ${newlyAdded} genuinely new items added to tracker\n`);
 
+  // 5b. Label sentiment on anything not yet classified (new discoveries,
+  //     plus items added by the CLI or webhook since the last run)
+  const classifier = await loadClassifier();
+  const classified = await classifyTrackerItems(tracker, classifier);
+  if (classified > 0) {
+    console.log(`Sentiment: classified ${classified} item(s) with the ${classifier.name} classifier\n`);
+  }
+
+  // 5c. Alert on new negative coverage now rather than with the digest
+  //     (ALERT_WEBHOOK_URL); a failed alert is retried next run
+  if (config.alerts.webhookUrl && !isDryRun) {
+    try {
+      const alerted = await alertNegativeCoverage(tracker.filter(item => item.status === 'new' && !item.low_relevance));
+      if (alerted > 0) console.log(`Alerts: posted ${alerted} negative item(s)\n`);
+    } catch (err) {
+      console.warn(`  Warning: Could not post negative coverage alert: ${err.message}`);
+    }
+  }
+
   // 6. Get all current "new" items for the digest
   const newItems = tracker.filter(item => item.status === 'new');
   console.log(`Digest will contain: ${newItems.length} items with status "new"`);
 
+  // 6b. Archive links. Slow (Save Page Now can take a minute per page), and
+  //     an item that can't be archived just goes out without a link.
+  if (config.archive.enabled && !skipArchive && newItems.length > 0) {
+    console.log('Archiving new items on the Wayback Machine...');
+    const archiveFailures = [];
+    const archived = await archiveTrackerItems(newItems, { failures: archiveFailures });
+    console.log(`  Archived ${archived} item(s)${archiveFailures.length > 0 ? `, ${archiveFailures.length} failed` : ''}`);
+  }
+
   if (newItems.length === 0) {
-    console.log('No new items to send. Saving tracker and exiting.');
-    await saveTracker(trackerPath, tracker);
-    // Write empty preview so the workflow doesn't fail
-    await writeFile(join(config.paths.root, 'preview.html'), '<html><body>No new items</body></html>');
-    return;
+    // EMPTY_DIGEST decides whether a quiet day still sends something
+    const plan = planDigest(newItems, tracker);
+    console.log(`No new items (empty digest policy: ${config.emptyDigest}). Saving tracker and exiting.`);
+    await saveAll();
+    if (plan.publish && !isDryRun) {
+      const options = { compact: plan.compact, lastItem: plan.lastItem };
+      await writeFile(join(config.paths.root, 'preview.html'), await renderDigest([], options));
+      await writeFile(join(config.paths.root, 'preview.txt'), renderDigestText([], options));
+    } else {
+      // Write empty preview so the workflow doesn't fail
+      await writeFile(join(config.paths.root, 'preview.html'), '<html><body>No new items</body></html>');
+      await writeFile(join(config.paths.root, 'preview.txt'), renderDigestText([]));
+    }
+    return { sourceFailures, publishError: null, empty: true };
   }
 
-  // 7. Convert tracker items to digest format and render
-  const digestItems = newItems.map(item => trackerToDigestFormat(item));
-  digestItems.sort((a, b) => new Date(b.date) - new Date(a.date));
+  // 7. Validate (drops invalid items, or throws in strict mode), then
+  // convert tracker items to digest format and render. Validating first
+  // means a malformed date is reported rather than thrown by the conversion.
+  const digestItems = sortItems(publishableTrackerItems(newItems).map(item => trackerToDigestFormat(item)));
+  pinEmbargoLifted(digestItems);
 
   for (const item of digestItems) {
     console.log(`  [${item.sourceType}] ${item.title} (${item.source})`);
   }
 
+  // A render failure shouldn't lose the lifecycle changes and new
+  // discoveries, so the tracker is still saved below.
+  let publishError = null;
   if (!isDryRun) {
     console.log('\nRendering email...');
-    const html = await renderDigest(digestItems);
-    const previewPath = join(config.paths.root, 'preview.html');
-    await writeFile(previewPath, html);
-    console.log(`Preview saved to: ${previewPath}`);
+    try {
+      // Trends compare this digest with the previous one in the tracker,
+      // leaving out items under Needs Review and low-relevance ones
+      const confirmed = digestItems.filter(item => !item.needsReview && !item.lowRelevance);
+      const summary = summarizeDigest(confirmed.map(item => ({ ...item, tools: item.toolsMentioned })), tracker);
+      const trends = compareSummaries(summary, previousDigestSummary(tracker, confirmed));
+      const html = await renderDigest(digestItems, { trends, pendingTools });
+      const previewPath = join(config.paths.root, 'preview.html');
+      await writeFile(previewPath, html);
+      await writeFile(join(config.paths.root, 'preview.txt'), renderDigestText(digestItems, { trends }));
+      console.log(`Preview saved to: ${previewPath}`);
+    } catch (err) {
+      publishError = err.message;
+      console.error(`  Error: Could not render preview: ${err.message}`);
+    }
   }
 
   // 8. Save updated tracker (with lifecycle changes + new discoveries)
-  await saveTracker(trackerPath, tracker);
+  await saveAll();
   console.log(`\nTracker saved with ${tracker.length} total items`);
   const finalCounts = countByStatus(tracker);
   console.log(`  new: ${finalCounts.new || 0} | sent: ${finalCounts.sent || 0} | archived: ${finalCounts.archived || 0}`);
 
   console.log('\nPipeline complete!');
+  return { sourceFailures, publishError, empty: false };
 }
 
 /**
- * Load coverage tracker from JSON file.
+ * Parse --fail-on=<conditions> from argv into a Set of conditions.
  */
-async function loadTracker(path) {
-  try {
-    const raw = await readFile(path, 'utf-8');
-    return JSON.parse(raw);
-  } catch (err) {
-    if (err.code === 'ENOENT') {
-      console.log('No existing tracker found - starting fresh');
-      return [];
-    }
-    throw err;
+function parseFailOn(argv) {
+  const arg = argv.find(a => a.startsWith('--fail-on='));
+  if (!arg) return new Set(DEFAULT_FAIL_ON);
+
+  const values = arg.slice('--fail-on='.length).split(',').map(v => v.trim()).filter(Boolean);
+  if (values.includes('none')) return new Set();
+  if (values.includes('all')) return new Set(FAIL_ON_CONDITIONS);
+
+  const unknown = values.filter(v => !FAIL_ON_CONDITIONS.includes(v));
+  if (unknown.length > 0) {
+    throw new Error(`Unknown --fail-on condition(s): ${unknown.join(', ')} (expected ${FAIL_ON_CONDITIONS.join(', ')}, all, none)`);
   }
+  return new Set(values);
 }
 
 /**
- * Save coverage tracker back to JSON file.
+ * Map a run outcome to an exit code and reason under the --fail-on policy.
+ * When several conditions hold, the most severe selected one wins
+ * (publish-error, then source-error, then empty).
  */
-async function saveTracker(path, tracker) {
-  await writeFile(path, JSON.stringify(tracker, null, 2) + '\n');
+function resolveExitCode(outcome, failOn) {
+  const failedSources = outcome.sourceFailures.map(f => f.source).join(', ');
+  const conditions = [
+    ['publish-error', outcome.publishError, `publish partially failed: ${outcome.publishError}`],
+    ['source-error', outcome.sourceFailures.length > 0, `${outcome.sourceFailures.length} source(s) failed: ${failedSources}`],
+    ['empty', outcome.empty, 'nothing to publish'],
+  ];
+
+  for (const [condition, present, reason] of conditions) {
+    if (present && failOn.has(condition)) {
+      return { code: EXIT_CODES[condition], reason };
+    }
+  }
+
+  const ignored = conditions.filter(([, present]) => present).map(([condition]) => condition);
+  return {
+    code: EXIT_CODES.success,
+    reason: ignored.length > 0 ? `success (ignored by --fail-on: ${ignored.join(', ')})` : 'success',
+  };
 }
 
 /**
@@ -146,11 +402,13 @@ async function saveTracker(path, tracker) {
  *
  * On the very first run, this is a no-op (we want to send them first).
  * We use a "last_sent_at" field to know if an item has been emailed.
+ * An item that was rewritten after it went out stays "new" until the
+ * update has been emailed too.
  */
 function markPreviousNewAsSent(tracker) {
   let count = 0;
   for (const item of tracker) {
-    if (item.status === 'new' && item.last_sent_at) {
+    if (item.status === 'new' && item.last_sent_at && !isPendingUpdate(item)) {
       item.status = 'sent';
       count++;
     }
@@ -162,8 +420,7 @@ function markPreviousNewAsSent(tracker) {
  * Auto-archive "sent" items older than 30 days.
  */
 function autoArchiveOldItems(tracker) {
-  const thirtyDaysAgo = new Date();
-  thirtyDaysAgo.setDate(thirtyDaysAgo.getDate() - 30);
+  const thirtyDaysAgo = shiftDays(new Date(), -30);
   let count = 0;
   for (const item of tracker) {
     if (item.status === 'sent' && new Date(item.date) < thirtyDaysAgo) {
@@ -174,74 +431,6 @@ function autoArchiveOldItems(tracker) {
   return count;
 }
 
-/**
- * Merge newly discovered items into the tracker (dedup by URL).
- * Returns count of genuinely new items added.
- */
-function mergeIntoTracker(tracker, discovered) {
-  const existingUrls = new Set(tracker.map(item => normalizeUrl(item.url)));
-  const existingTitles = new Set(tracker.map(item => item.title.toLowerCase().trim()));
-  let added = 0;
-
-  for (const item of discovered) {
-    const url = normalizeUrl(item.url);
-    const title = item.title.toLowerCase().trim();
-
-    // Skip if we already have this URL or exact title
-    if (existingUrls.has(url) || existingTitles.has(title)) {
-      continue;
-    }
-
-    // Generate a new tracker ID
-    const nextId = tracker.length + 1;
-    const paddedId = String(nextId).padStart(3, '0');
-
-    tracker.push({
-      id: `cov-${paddedId}`,
-      date: item.date ? item.date.split('T')[0] : new Date().toISOString().split('T')[0],
-      source: item.source,
-      source_type: mapSourceType(item),
-      title: item.title,
-      url: item.url,
-      tools_mentioned: item.toolsMentioned || [],
-      excerpt: item.excerpt || '',
-      status: 'new',
-      amplification: {
-        linkedin_post: null,
-        slack_message: null,
-        employee_shares: [],
-        added_to_website: false,
-      },
-      discovered_by: item.sourceType === 'rss' ? 'rss-monitor' : 'manual',
-      discovered_at: new Date().toISOString(),
-    });
-
-    existingUrls.add(url);
-    existingTitles.add(title);
-    added++;
-  }
-
-  return added;
-}
-
-/**
- * Normalize a URL for dedup comparison.
- */
-function normalizeUrl(url) {
-  if (!url) return '';
-  return url.toLowerCase().replace(/\/+$/, '').replace(/^https?:\/\//, '');
-}
-
-/**
- * Map discovered item to tracker source_type.
- */
-function mapSourceType(item) {
-  const source = (item.source || '').toLowerCase();
-  if (source.includes('praetorian blog') || source.includes('praetorian.com/blog')) return 'blog';
-  if (item.sourceType === 'manual' || item.sourceType === 'event') return item.sourceType;
-  return 'media';
-}
-
 /**
  * Convert a tracker-format item to digest-format for rendering.
  */
@@ -250,8 +439,8 @@ function trackerToDigestFormat(item) {
   const sourceType = item.source_type || 'media';
 
   let mappedType;
-  if (sourceType === 'event') {
-    mappedType = 'event';
+  if (['event', 'podcast', 'social'].includes(sourceType)) {
+    mappedType = sourceType;
   } else if (sourceType === 'blog' || source.toLowerCase().includes('praetorian blog')) {
     mappedType = 'blog';
   } else {
@@ -264,27 +453,53 @@ function trackerToDigestFormat(item) {
     title: item.title,
     url: item.url,
     date: new Date(item.date).toISOString(),
+    dateEstimated: Boolean(item.date_estimated),
     excerpt: item.excerpt || '',
+    mentionContext: item.mention_context || '',
     toolsMentioned: item.tools_mentioned || [],
-    matchedTerms: item.discovered_by === 'rss-monitor' ? ['rss'] : ['manual'],
+    untagged: Array.isArray(item.tools_mentioned) && item.tools_mentioned.length === 0,
+    matchedTerms: [MATCHED_TERMS[item.discovered_by] || 'manual'],
+    embargoLifted: Boolean(item.embargo_lifted_at),
+    archiveUrl: item.archive_url || '',
+    hackerNews: item.hacker_news || null,
+    alsoPublishedBy: item.also_published_by || [],
+    paywalled: Boolean(item.paywalled),
+    language: item.language || null,
+    confidence: item.confidence ?? null,
+    needsReview: Boolean(item.needs_review),
+    sentiment: item.sentiment || null,
+    relevance: item.relevance || null,
+    lowRelevance: Boolean(item.low_relevance),
+    update: isPendingUpdate(item)
+      ? { changed: item.update.changed, previousTitle: item.update.previous_title || '', previousUrl: item.update.previous_url || '' }
+      : null,
     raw: { guid: item.id },
   };
 }
 
 /**
- * Count items by status.
+ * Move items whose embargo just lifted to the top, keeping relative order.
  */
-function countByStatus(tracker) {
-  const counts = {};
-  for (const item of tracker) {
-    counts[item.status] = (counts[item.status] || 0) + 1;
-  }
-  return counts;
+function pinEmbargoLifted(items) {
+  const lifted = items.filter(item => item.embargoLifted);
+  const rest = items.filter(item => !item.embargoLifted);
+  items.splice(0, items.length, ...lifted, ...rest);
+}
+
+let failOn;
+try {
+  failOn = parseFailOn(process.argv);
+} catch (err) {
+  console.error(`FATAL: ${err.message}`);
+  process.exit(EXIT_CODES.fatal);
 }
 
-main().then(() => {
-  process.exit(0);
+main().then(outcome => {
+  const { code, reason } = resolveExitCode(outcome, failOn);
+  console.log(`\nExit ${code}: ${reason}`);
+  process.exit(code);
 }).catch(err => {
   console.error('\nFATAL:', err.message);
-  process.exit(1);
+  console.error(`\nExit ${EXIT_CODES.fatal}: fatal error`);
+  process.exit(EXIT_CODES.fatal);
 });
@@ -9,19 +9,34 @@
  * Usage:
  *   node send-daily-digest.js              # Normal run
  *   node send-daily-digest.js --preview    # Render HTML to stdout (no email)
+ *   node send-daily-digest.js --allow-resurface  # Include items already sent, marked previously covered
  *   DRY_RUN=true node send-daily-digest.js # Log what would be sent
  */
 
 import { writeFile } from 'fs/promises';
 import { join } from 'path';
 import { config } from './config.js';
-import { checkRssFeeds } from './monitors/rss-feeds.js';
-import { checkManualSubmissions } from './monitors/manual-submissions.js';
+import { fetchSources, formatRunSummary, loadSourceModules } from './monitors/index.js';
+import { canonicalizeItems } from './monitors/html-article.js';
 import { getSinceDate, filterNewItems, recordRun } from './utils/state-manager.js';
 import { renderDigest } from './utils/template-renderer.js';
+import { assertPublishable } from './utils/validate.js';
+import { sortItems } from './utils/sort.js';
+import { applyLanguagePolicy } from './utils/language.js';
+import { normalizeSources } from './utils/publishers.js';
+import { collapseSyndicated } from './utils/syndication.js';
+import { applyConfidence } from './utils/confidence.js';
+import { applyRelevance } from './utils/relevance.js';
+import { classifyTrackerItems, loadClassifier } from './utils/sentiment.js';
+import { alertNegativeCoverage } from './utils/alerts.js';
+import { applyToolDiscovery } from './utils/tool-discovery.js';
+import { formatDate } from './utils/i18n.js';
+import { planDigest } from './utils/empty-digest.js';
+import { loadTracker, normalizeUrl } from './utils/tracker.js';
 import { sendDigestEmail } from './utils/email-sender.js';
 
 const isPreview = process.argv.includes('--preview');
+const allowResurface = process.argv.includes('--allow-resurface');
 
 async function main() {
   console.log('========================================');
@@ -34,32 +49,91 @@ async function main() {
   console.log(`Looking for items since: ${since.toLocaleString()}\n`);
 
   // 2. Collect items from all monitors
-  const [rssItems, manualItems] = await Promise.all([
-    checkRssFeeds(since),
-    checkManualSubmissions(since),
-  ]);
-
-  const allItems = [...rssItems, ...manualItems];
-  console.log(`\nTotal items found: ${allItems.length}`);
-
-  // 3. Deduplicate against previously sent items
-  const newItems = await filterNewItems(allItems);
-  console.log(`New items (not previously sent): ${newItems.length}`);
+  await loadSourceModules();
+  const { bySource, summary: sourceRun } = await fetchSources(since);
+  console.log(`\n${formatRunSummary(sourceRun)}`);
+  const manualItems = bySource.get('manual') || [];
+
+  // Hold embargoed items until their embargo passes
+  const now = new Date();
+  const embargoed = manualItems.filter(i => i.embargoUntil && new Date(i.embargoUntil) > now);
+  for (const item of manualItems) {
+    item.embargoLifted = Boolean(item.embargoUntil) && !embargoed.includes(item);
+  }
+  if (embargoed.length > 0) {
+    console.log(`Holding ${embargoed.length} embargoed item(s)`);
+  }
 
-  // 4. Sort by date (newest first)
-  newItems.sort((a, b) => new Date(b.date) - new Date(a.date));
+  // Canonical URLs, so one article reached by several links is one item
+  // under one publisher name, and syndicated copies folded into it; items in other languages are left out with
+  // FOREIGN_LANGUAGE_ITEMS=exclude
+  const found = [...bySource.values()].flat().filter(i => !embargoed.includes(i));
+  await canonicalizeItems(found);
+  normalizeSources(found);
+  // New tools announced on the Praetorian Blog, for Action Needed; a
+  // preview doesn't keep them
+  const discovery = config.toolDiscovery.enabled
+    ? await applyToolDiscovery(found, { save: !isPreview && !config.dryRun })
+    : { found: [], pending: [] };
+  for (const entry of discovery.found) {
+    console.log(`New tool: "${entry.name}" announced in "${entry.title}"; pending until configured`);
+  }
+  const seenUrls = new Set();
+  const unique = found.filter(item => {
+    const key = normalizeUrl(item.url);
+    if (!key) return true;
+    if (seenUrls.has(key)) return false;
+    seenUrls.add(key);
+    return true;
+  });
+  const syndicated = config.syndication.enabled ? collapseSyndicated(unique).items : unique;
+  const { items: languageItems, excluded } = applyLanguagePolicy(syndicated);
+  // Items that may not be about Praetorian go under Needs Review, and the
+  // unlikely ones are left out (CONFIDENCE_LOW / CONFIDENCE_HIGH)
+  const { items: allItems, review, dropped } = applyConfidence(languageItems);
+  console.log(`\nTotal items found: ${allItems.length}${excluded > 0 ? ` (${excluded} in other languages left out)` : ''}`);
+  if (review > 0 || dropped.length > 0) {
+    console.log(`Confidence: ${review} item(s) need review, ${dropped.length} left out`);
+    for (const item of dropped) console.log(`  - "${item.title}" (${item.source}), scored ${item.confidence}`);
+  }
+  // Items only naming us in passing are counted in the email, not listed
+  // (RELEVANCE_THRESHOLD)
+  if (config.relevance.enabled) {
+    const { excluded: lowRelevance } = applyRelevance(allItems);
+    if (lowRelevance.length > 0) console.log(`Relevance: ${lowRelevance.length} low-relevance item(s) only counted`);
+  }
 
-  // 5. Check if we should skip
-  if (newItems.length === 0 && config.skipIfEmpty && !isPreview) {
-    console.log('\nNo new items and SKIP_IF_EMPTY=true. Skipping email.');
+  // 3. Deduplicate against previously sent items (SEEN_SUPPRESS_DAYS);
+  // --allow-resurface keeps them all
+  let newItems = await filterNewItems(allItems, { allowResurface });
+  const resurfaced = newItems.filter(i => i.previouslyCovered).length;
+  console.log(`New items (not previously sent): ${newItems.length - resurfaced}${resurfaced > 0 ? `, plus ${resurfaced} previously covered` : ''}`);
+  // Negative coverage is listed first, and alerted on once sent
+  await classifyTrackerItems(newItems, await loadClassifier());
+
+  // 4. Sort (DIGEST_SORT, newest first by default, negative coverage
+  // first), items just out of embargo pinned on top
+  const sorted = sortItems(newItems);
+  newItems = [...sorted.filter(i => i.embargoLifted), ...sorted.filter(i => !i.embargoLifted)];
+
+  // 5. Check if we should skip (EMPTY_DIGEST); a compact digest names the
+  // last item in the tracker
+  const plan = planDigest(newItems, newItems.length === 0 ? await loadTracker(config.paths.coverageTracker) : []);
+  if (!plan.publish && !isPreview) {
+    console.log('\nNo new items and EMPTY_DIGEST=skip. Skipping email.');
     // Still record the run so next time we look from now
     await recordRun([]);
     return;
   }
 
-  // 6. Render email
+  // 6. Validate, then render email
+  const digestItems = assertPublishable(newItems);
   console.log('\nRendering email template...');
-  const html = await renderDigest(newItems);
+  const html = await renderDigest(digestItems, {
+    compact: plan.compact,
+    lastItem: plan.lastItem,
+    pendingTools: discovery.pending.map(entry => entry.name),
+  });
 
   // 7. Preview mode: write HTML to file and stdout
   if (isPreview) {
@@ -71,25 +145,39 @@ async function main() {
   }
 
   // 8. Generate subject line
-  const today = new Date().toLocaleDateString('en-US', {
+  const today = formatDate(now, 'en-US', {
     month: 'short',
     day: 'numeric',
   });
-  const tools = [...new Set(newItems.flatMap(i => i.toolsMentioned || []))];
+  // Low-relevance items aren't listed, so aren't counted here either
+  const listed = digestItems.filter(i => !i.lowRelevance);
+  const tools = [...new Set(listed.flatMap(i => i.toolsMentioned || []))];
   let subject;
-  if (newItems.length === 0) {
+  if (digestItems.length === 0 && plan.compact) {
+    subject = `Coverage Digest - ${today} - No new coverage`;
+  } else if (listed.length === 0) {
     subject = `Coverage Digest - ${today} - No new items`;
   } else if (tools.length > 0) {
     const toolStr = tools.slice(0, 3).join(', ');
-    subject = `Coverage Digest - ${today} - ${newItems.length} new (${toolStr})`;
+    subject = `Coverage Digest - ${today} - ${listed.length} new (${toolStr})`;
   } else {
-    subject = `Coverage Digest - ${today} - ${newItems.length} new item${newItems.length > 1 ? 's' : ''}`;
+    subject = `Coverage Digest - ${today} - ${listed.length} new item${listed.length > 1 ? 's' : ''}`;
   }
 
   // 9. Send email
   console.log(`\nSending: "${subject}"`);
   await sendDigestEmail(subject, html);
 
+  // 9b. Alert on negative coverage (ALERT_WEBHOOK_URL)
+  if (config.alerts.webhookUrl && !config.dryRun) {
+    try {
+      const alerted = await alertNegativeCoverage(listed);
+      if (alerted > 0) console.log(`Alerts: posted ${alerted} negative item(s)`);
+    } catch (err) {
+      console.warn(`  Warning: Could not post negative coverage alert: ${err.message}`);
+    }
+  }
+
   // 10. Record successful run
   await recordRun(newItems);
 
@@ -12,6 +12,9 @@
 
 import { config } from './config.js';
 import { renderDigest, renderDashboard } from './utils/template-renderer.js';
+import { assertPublishable } from './utils/validate.js';
+import { sortItems } from './utils/sort.js';
+import { isPendingUpdate } from './utils/tracker.js';
 import { readFile, writeFile } from 'fs/promises';
 import { join } from 'path';
 import { execSync } from 'child_process';
@@ -29,8 +32,9 @@ async function main() {
   // Default: only items from Jan 1, 2026 onwards
   // --all: include everything regardless of date
   const cutoffDate = new Date('2026-01-01');
+  // Embargoed items never leave the tracker, even with --all
   const filtered = includeAll
-    ? allCoverage.filter(item => new Date(item.date) >= cutoffDate)
+    ? allCoverage.filter(item => new Date(item.date) >= cutoffDate && item.status !== 'embargoed')
     : allCoverage.filter(item => item.status === 'new');
 
   if (filtered.length === 0) {
@@ -39,7 +43,7 @@ async function main() {
   }
 
   // Convert tracker format to digest item format with proper categorization
-  const items = filtered.map(item => {
+  let items = filtered.map(item => {
     const source = item.source || '';
     const sourceType = item.source_type || 'media';
 
@@ -60,13 +64,19 @@ async function main() {
       date: new Date(item.date).toISOString(),
       excerpt: item.excerpt,
       toolsMentioned: item.tools_mentioned || [],
+      untagged: Array.isArray(item.tools_mentioned) && item.tools_mentioned.length === 0,
       matchedTerms: ['manual'],
+      embargoLifted: Boolean(item.embargo_lifted_at) && item.status === 'new',
+      archiveUrl: item.archive_url || '',
+      update: isPendingUpdate(item)
+        ? { changed: item.update.changed, previousTitle: item.update.previous_title || '' }
+        : null,
       raw: { guid: item.id },
     };
   });
 
-  // Sort newest first
-  items.sort((a, b) => new Date(b.date) - new Date(a.date));
+  // Sort newest first (or per DIGEST_SORT)
+  items = sortItems(items);
 
   const media = items.filter(i => i.sourceType === 'media');
   const blog = items.filter(i => i.sourceType === 'blog');
@@ -80,7 +90,7 @@ async function main() {
 
   // Render email
   console.log('\nRendering email...');
-  const html = await renderDigest(items);
+  const html = await renderDigest(assertPublishable(items));
   const previewPath = join(config.paths.root, 'preview.html');
   await writeFile(previewPath, html);
   console.log(`Preview saved to: ${previewPath}`);
@@ -0,0 +1,174 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - Webhook Receiver
+ *
+ * Long-running mode for push-based sources (Zapier hooks from media
+ * monitoring tools, the blog's publish webhook, etc.). Accepted
+ * submissions are queued in the webhook inbox (WEBHOOK_INBOX, see
+ * utils/submissions.js), never written to the tracker directly, so a
+ * pipeline run in progress can't save over them. The next run takes
+ * them into the tracker with status "new", exactly once.
+ *
+ * Endpoints:
+ *   POST /submissions   Submit a coverage item (JSON, X-Coverage-Token required)
+ *   GET  /healthz       Liveness check
+ *
+ * Usage:
+ *   WEBHOOK_TOKEN=... node serve-webhook.js
+ */
+
+import { createServer } from 'http';
+import { appendFile, mkdir } from 'fs/promises';
+import { timingSafeEqual } from 'crypto';
+import { config } from './config.js';
+import { loadTracker, findDuplicate } from './utils/tracker.js';
+import { openInbox, submissionItem, validateSubmission } from './utils/submissions.js';
+
+const inbox = openInbox();
+
+// Serialize the duplicate check and the write, so two copies of one
+// submission arriving together can't both be accepted.
+let inboxQueue = Promise.resolve();
+
+function withInbox(fn) {
+  const run = inboxQueue.then(fn);
+  inboxQueue = run.catch(() => {});
+  return run;
+}
+
+/**
+ * Constant-time comparison of the request token against the configured one.
+ */
+function isAuthorized(req) {
+  const provided = Buffer.from(String(req.headers['x-coverage-token'] || ''));
+  const expected = Buffer.from(config.webhook.token);
+  return provided.length === expected.length && timingSafeEqual(provided, expected);
+}
+
+/**
+ * Read the request body, rejecting anything over the configured limit.
+ */
+function readBody(req, limit) {
+  return new Promise((resolve, reject) => {
+    const chunks = [];
+    let size = 0;
+    req.on('data', chunk => {
+      size += chunk.length;
+      if (size > limit) {
+        reject(Object.assign(new Error(`body exceeds ${limit} bytes`), { statusCode: 413 }));
+        req.destroy();
+        return;
+      }
+      chunks.push(chunk);
+    });
+    req.on('end', () => resolve(Buffer.concat(chunks).toString('utf-8')));
+    req.on('error', reject);
+  });
+}
+
+/**
+ * Write one structured audit record per submission attempt.
+ */
+async function audit(entry) {
+  const line = JSON.stringify({ ts: new Date().toISOString(), event: 'submission', ...entry });
+  console.log(line);
+  try {
+    await mkdir(config.paths.state, { recursive: true });
+    await appendFile(config.paths.webhookAuditLog, line + '\n');
+  } catch (err) {
+    console.warn(`  Warning: Could not write audit log: ${err.message}`);
+  }
+}
+
+function send(res, statusCode, payload) {
+  res.writeHead(statusCode, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify(payload));
+}
+
+async function handleSubmission(req, res) {
+  const remote = req.socket.remoteAddress;
+
+  if (!isAuthorized(req)) {
+    await audit({ remote, status: 401, verdict: 'rejected', reason: 'invalid token' });
+    return send(res, 401, { error: 'invalid or missing X-Coverage-Token' });
+  }
+
+  const declaredLength = parseInt(req.headers['content-length'] || '0', 10);
+  if (declaredLength > config.webhook.maxBodyBytes) {
+    await audit({ remote, status: 413, verdict: 'rejected', reason: 'body too large' });
+    return send(res, 413, { error: `body exceeds ${config.webhook.maxBodyBytes} bytes` });
+  }
+
+  let body;
+  try {
+    body = JSON.parse(await readBody(req, config.webhook.maxBodyBytes));
+  } catch (err) {
+    const status = err.statusCode || 400;
+    await audit({ remote, status, verdict: 'rejected', reason: err.message });
+    return send(res, status, { error: status === 413 ? err.message : 'body must be valid JSON' });
+  }
+
+  const errors = validateSubmission(body);
+  if (errors.length > 0) {
+    await audit({ remote, status: 422, verdict: 'rejected', reason: 'validation failed', errors });
+    return send(res, 422, { error: 'validation failed', errors });
+  }
+
+  const item = submissionItem(body);
+
+  // Checked against the tracker as this checkout has it and the
+  // submissions still waiting; the pipeline checks again when it takes
+  // them, against the tracker as it is then
+  const result = await withInbox(async () => {
+    const existing = findDuplicate(await loadTracker(config.paths.coverageTracker), item);
+    if (existing) return { verdict: 'duplicate', id: existing.id, status: existing.status };
+    const waiting = findDuplicate(await inbox.list(), item);
+    if (waiting) return { verdict: 'duplicate', id: waiting.submissionId, status: 'pending' };
+    await inbox.add(item);
+    return { verdict: 'accepted', id: item.submissionId, status: 'pending' };
+  });
+
+  const statusCode = result.verdict === 'accepted' ? 201 : 200;
+  await audit({
+    remote,
+    status: statusCode,
+    verdict: result.verdict,
+    id: result.id,
+    title: item.title,
+    url: item.url,
+  });
+  return send(res, statusCode, { verdict: result.verdict, id: result.id, status: result.status });
+}
+
+function main() {
+  if (!config.webhook.token) {
+    console.error('WEBHOOK_TOKEN is not configured. Add it to .env');
+    process.exit(1);
+  }
+
+  const server = createServer((req, res) => {
+    const path = new URL(req.url, 'http://localhost').pathname;
+
+    if (req.method === 'GET' && path === '/healthz') {
+      return send(res, 200, { ok: true });
+    }
+    if (path === '/submissions') {
+      if (req.method !== 'POST') return send(res, 405, { error: 'method not allowed' });
+      handleSubmission(req, res).catch(err => {
+        console.error(`Error handling submission: ${err.message}`);
+        if (!res.headersSent) send(res, 500, { error: 'internal error' });
+      });
+      return;
+    }
+    send(res, 404, { error: 'not found' });
+  });
+
+  server.listen(config.webhook.port, () => {
+    console.log(`Coverage webhook receiver listening on :${config.webhook.port}`);
+    console.log(`  Inbox: ${config.webhook.inbox === 's3' ? `s3://${config.s3.bucket}/${config.s3.prefix}webhook-inbox/` : config.paths.webhookInbox}`);
+    console.log(`  Max body: ${config.webhook.maxBodyBytes} bytes`);
+  });
+}
+
+main();
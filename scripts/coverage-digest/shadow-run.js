@@ -0,0 +1,216 @@
+#!/usr/bin/env node
+
+/**
+ * Praetorian Coverage Digest - Shadow Run
+ *
+ * Runs discovery with a candidate configuration against the window of the
+ * most recently published digest and reports how the result differs from
+ * what was actually sent. Nothing is published and the tracker is never
+ * written, so it is safe to run on a config-change PR.
+ *
+ * Ground truth is the set of tracker items stamped with the latest
+ * last_sent_at (the last digest the workflow emailed). Items that entered
+ * the tracker outside discovery (CLI, webhook, issue form) are not
+ * compared, since a config change can't affect them.
+ *
+ * The candidate config is a JS module whose default export is an object of
+ * top-level config overrides, e.g.
+ *
+ *   export default {
+ *     searchTerms: [...config.searchTerms, 'praetorian guard'],
+ *     maxItemAgeDays: 3,
+ *   };
+ *
+ * Usage:
+ *   node shadow-run.js --config candidate.js
+ *   node shadow-run.js --config candidate.js --out shadow-report.md
+ *   node shadow-run.js --config candidate.js --pr 123   # Comment on PR #123
+ */
+
+import { writeFile } from 'fs/promises';
+import { resolve } from 'path';
+import { pathToFileURL } from 'url';
+import { config } from './config.js';
+import { fetchSources, formatRunSummary, loadSourceModules } from './monitors/index.js';
+import { canonicalizeItems } from './monitors/html-article.js';
+import { loadTracker, normalizeUrl, mapSourceType, splitByAge } from './utils/tracker.js';
+import { canonicalTools } from './utils/tools.js';
+import { applyLanguagePolicy } from './utils/language.js';
+import { normalizeSources } from './utils/publishers.js';
+import { collapseSyndicated } from './utils/syndication.js';
+import { clientFor } from './utils/http-client.js';
+import { shiftDays, startOfDay } from './utils/timezone.js';
+
+const LOOKBACK_DAYS = 7;
+const DISCOVERED_BY = ['rss-monitor', 'google-news', 'news-api', 'mastodon', 'podcasts', 'hacker-news', 'manual'];
+
+function getArg(name) {
+  const idx = process.argv.indexOf(name);
+  return idx !== -1 ? process.argv[idx + 1] : null;
+}
+
+async function main() {
+  const configPath = getArg('--config');
+  const outPath = getArg('--out');
+  const pr = getArg('--pr');
+  if (!configPath) {
+    console.error('Usage: node shadow-run.js --config <candidate.js> [--out report.md] [--pr <number>]');
+    process.exit(1);
+  }
+
+  // 1. Find the last published digest
+  const tracker = await loadTracker(config.paths.coverageTracker);
+  const lastSentAt = tracker
+    .map(item => item.last_sent_at)
+    .filter(Boolean)
+    .sort()
+    .pop();
+  if (!lastSentAt) {
+    console.error('No published digest in the tracker yet (no item has last_sent_at). Nothing to compare against.');
+    process.exit(1);
+  }
+  const published = tracker.filter(item =>
+    item.last_sent_at === lastSentAt && DISCOVERED_BY.includes(item.discovered_by)
+  );
+
+  // 2. Apply the candidate config in memory
+  const mod = await import(pathToFileURL(resolve(configPath)).href);
+  const overrides = mod.default || mod.config || {};
+  Object.assign(config, overrides);
+  console.log(`Candidate config: ${configPath} (overrides: ${Object.keys(overrides).join(', ') || 'none'})`);
+
+  // 3. Re-run discovery over that digest's window
+  const windowEnd = new Date(lastSentAt);
+  const since = startOfDay(shiftDays(windowEnd, -LOOKBACK_DAYS));
+  console.log(`Shadowing digest sent ${lastSentAt} (window ${since.toISOString().split('T')[0]} .. ${lastSentAt.split('T')[0]})\n`);
+
+  await loadSourceModules();
+  const { bySource, summary: sourceRun } = await fetchSources(since);
+  console.log(`\n${formatRunSummary(sourceRun)}`);
+  const found = [...bySource.values()].flat();
+  await canonicalizeItems(found, { tracker });
+  normalizeSources(found);
+  const { items: languageItems } = applyLanguagePolicy(found);
+  const discovered = config.syndication.enabled ? collapseSyndicated(languageItems).items : languageItems;
+  const inWindow = discovered.filter(item => new Date(item.date) <= windowEnd);
+  const { fresh } = splitByAge(inWindow, since, config.maxItemAgeDays, windowEnd);
+
+  // 4. Compare
+  const report = compare(published, fresh);
+  const markdown = renderReport(report, { configPath, lastSentAt, overrides });
+  console.log('\n' + markdown);
+
+  if (outPath) {
+    await writeFile(outPath, markdown);
+    console.log(`Report saved to: ${outPath}`);
+  }
+  if (pr) {
+    await postComment(pr, markdown);
+  }
+}
+
+/**
+ * Diff the candidate's discoveries against the items actually published.
+ */
+function compare(published, candidates) {
+  const byUrl = new Map();
+  for (const item of candidates) {
+    const key = normalizeUrl(item.url);
+    if (!byUrl.has(key)) byUrl.set(key, item);
+  }
+  const publishedUrls = new Set(published.map(item => normalizeUrl(item.url)));
+
+  const gained = [...byUrl.entries()]
+    .filter(([key]) => !publishedUrls.has(key))
+    .map(([, item]) => ({ title: item.title, url: item.url, source: item.source }));
+  const lost = published
+    .filter(item => !byUrl.has(normalizeUrl(item.url)))
+    .map(item => ({ title: item.title, url: item.url, source: item.source }));
+
+  const changed = [];
+  for (const item of published) {
+    const candidate = byUrl.get(normalizeUrl(item.url));
+    if (!candidate) continue;
+
+    const before = { sourceType: item.source_type, tools: canonicalTools(item.tools_mentioned) };
+    const after = { sourceType: mapSourceType(candidate), tools: canonicalTools(candidate.toolsMentioned) };
+    const diffs = [];
+    if (before.sourceType !== after.sourceType) {
+      diffs.push(`type ${before.sourceType} → ${after.sourceType}`);
+    }
+    if ([...before.tools].sort().join(',') !== [...after.tools].sort().join(',')) {
+      diffs.push(`tools [${before.tools.join(', ')}] → [${after.tools.join(', ')}]`);
+    }
+    if (diffs.length > 0) {
+      changed.push({ title: item.title, url: item.url, diffs });
+    }
+  }
+
+  return { published: published.length, candidate: byUrl.size, gained, lost, changed };
+}
+
+function renderReport(report, { configPath, lastSentAt, overrides }) {
+  const lines = [
+    '## Coverage digest shadow run',
+    '',
+    `Candidate \`${configPath}\` (overrides: ${Object.keys(overrides).map(k => `\`${k}\``).join(', ') || 'none'})`,
+    `against the digest sent ${lastSentAt}.`,
+    '',
+    `| | Items |`,
+    `|---|---|`,
+    `| Published (discovered) | ${report.published} |`,
+    `| Candidate | ${report.candidate} |`,
+    `| Gained | ${report.gained.length} |`,
+    `| Lost | ${report.lost.length} |`,
+    `| Reclassified | ${report.changed.length} |`,
+    '',
+  ];
+
+  const section = (title, items, describe) => {
+    if (items.length === 0) return;
+    lines.push(`### ${title}`, '');
+    for (const item of items) lines.push(`- ${describe(item)}`);
+    lines.push('');
+  };
+  section('Gained', report.gained, item => `[${item.title}](${item.url}) — ${item.source}`);
+  section('Lost', report.lost, item => `[${item.title}](${item.url}) — ${item.source}`);
+  section('Reclassified', report.changed, item => `[${item.title}](${item.url}): ${item.diffs.join('; ')}`);
+
+  if (report.gained.length + report.lost.length + report.changed.length === 0) {
+    lines.push('No differences from the published digest.', '');
+  }
+  return lines.join('\n');
+}
+
+/**
+ * Post the report as a comment on an issue or PR in GITHUB_REPOSITORY.
+ */
+async function postComment(number, body) {
+  const token = process.env.GITHUB_TOKEN;
+  const repo = process.env.GITHUB_REPOSITORY;
+  if (!token || !repo) {
+    console.error('GITHUB_TOKEN and GITHUB_REPOSITORY are required for --pr');
+    process.exit(1);
+  }
+
+  const res = await clientFor('GitHub').fetch(`https://api.github.com/repos/${repo}/issues/${number}/comments`, {
+    method: 'POST',
+    headers: {
+      'Authorization': `Bearer ${token}`,
+      'Accept': 'application/vnd.github+json',
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ body }),
+  });
+  if (!res.ok) {
+    throw new Error(`GitHub comment failed: HTTP ${res.status}`);
+  }
+  console.log(`Report posted to ${repo}#${number}`);
+}
+
+main().then(() => {
+  process.exit(0);
+}).catch(err => {
+  console.error('\nFATAL:', err.message);
+  process.exit(1);
+});
@@ -0,0 +1,83 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { digestItem, trackerItem } from './helpers.js';
+import { articleHash, relocateMovedItems } from '../utils/tracker.js';
+
+// What articleHash gave these articles when it shipped. The hashes are
+// stored in the tracker and compared on every later run, so they must
+// never change: fix the code, not this table.
+const LONG_BODY = 'Praetorian released Brutus, a credential tester. '.repeat(20);
+const GOLDEN = [
+  {
+    url: 'https://www.darkreading.com/application-security/praetorian-brutus',
+    title: 'Brutus  Released',
+    bodyText: 'Praetorian\n has RELEASED Brutus.',
+    hash: '20d2c2c7e489b59f',
+  },
+  {
+    url: 'https://www.securityweek.com/augustus-llm-prompt-injection/',
+    title: 'Augustus Tests LLMs for Prompt Injection',
+    bodyText: 'Praetorian has released Augustus, an open-source tool that tests large language models against prompt injection and jailbreaks.',
+    hash: '747c086926439398',
+  },
+  {
+    url: 'https://www.heise.de/news/Praetorian-Augustus-123.html',
+    title: 'Praetorian veröffentlicht Augustus',
+    bodyText: 'Mit Augustus können Sicherheitsteams große Sprachmodelle prüfen.',
+    hash: '7031aba2f53d302a',
+  },
+  {
+    url: 'https://www.itmedia.co.jp/news/articles/2602/16/news001.html',
+    title: 'Praetorian、「Augustus」を公開',
+    bodyText: 'AugustusはLLMの脆弱性を検査するオープンソースツールだ。',
+    hash: 'da078775ce11de0d',
+  },
+  {
+    url: 'https://www.helpnetsecurity.com/2026/02/16/brutus-praetorian/',
+    title: 'Brutus',
+    bodyText: LONG_BODY,
+    hash: '8350bfe1617f96c6',
+  },
+];
+
+for (const { url, title, bodyText, hash } of GOLDEN) {
+  test(`articleHash of ${url} is ${hash}`, () => {
+    assert.equal(articleHash({ url, title, bodyText }), hash);
+  });
+}
+
+test('articleHash ignores what a republished copy changes, and nothing else', () => {
+  const { title, bodyText, hash } = GOLDEN[0];
+  // The URL itself isn't hashed
+  assert.equal(articleHash({ url: 'https://www.darkreading.com/praetorian-brutus-released', title, bodyText }), hash);
+  // Case and whitespace
+  assert.equal(articleHash({ title: ' BRUTUS\tReleased ', bodyText: 'praetorian has released brutus.\n\n' }), hash);
+  // Composed and decomposed accents
+  assert.equal(articleHash({ title: GOLDEN[2].title.normalize('NFD'), bodyText: GOLDEN[2].bodyText.normalize('NFD') }), GOLDEN[2].hash);
+  // Past the first 500 characters of the body
+  assert.equal(articleHash({ title: 'Brutus', bodyText: `${LONG_BODY}An update appended later.` }), GOLDEN[4].hash);
+  // Within them, or in the title
+  assert.notEqual(articleHash({ title, bodyText: 'Praetorian has released Brutus!' }), hash);
+  assert.notEqual(articleHash({ title: 'Brutus Re-released', bodyText }), hash);
+  // No body text, no hash
+  assert.equal(articleHash({ title }), null);
+  assert.equal(articleHash({ title, bodyText: '' }), null);
+});
+
+test('an article found at a new URL moves the tracked item there', () => {
+  const [{ url, title, bodyText, hash }] = GOLDEN;
+  const tracker = [trackerItem({ url, article_hash: hash, last_sent_at: '2026-02-16T13:00:00Z', status: 'sent' })];
+  const movedTo = 'https://www.darkreading.com/vulnerabilities-threats/praetorian-brutus-released';
+  const discovered = [digestItem({ url: movedTo, title, bodyText }), digestItem({ url: 'https://example.com/other', title: 'Other', bodyText: 'Other text' })];
+
+  assert.equal(relocateMovedItems(tracker, discovered, new Date('2026-02-17T13:00:00Z'), { note: true }), 1);
+  assert.equal(tracker.length, 1);
+  assert.equal(tracker[0].url, movedTo);
+  assert.deepEqual(tracker[0].previous_urls, [url]);
+  assert.equal(tracker[0].status, 'new');
+  assert.deepEqual(tracker[0].update.changed, ['url']);
+  assert.equal(tracker[0].update.previous_url, url);
+
+  // Seen again at either URL, it's the same item
+  assert.equal(relocateMovedItems(tracker, discovered, new Date('2026-02-18T13:00:00Z'), { note: true }), 0);
+});
@@ -0,0 +1,162 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { parseXml, trackerItem } from './helpers.js';
+import { entryId, renderAtomFeed } from '../utils/atom.js';
+
+const ATOM_NS = 'http://www.w3.org/2005/Atom';
+const RFC3339 = /^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$/;
+const IRI = /^[a-z][a-z0-9+.-]*:\S+$/i;
+
+// RFC 4287's schema (atom.rnc), as each element's children with how many
+// may appear ("1" exactly one, "?" at most one, "*" any), its attributes
+// (true when required) and what its text must be
+const TEXT_CONSTRUCT = { attributes: { type: false }, text: 'text' };
+const PERSON = { children: { name: '1', uri: '?', email: '?' } };
+const SCHEMA = {
+  feed: {
+    children: {
+      author: '*', category: '*', contributor: '*', generator: '?', icon: '?', id: '1',
+      link: '*', logo: '?', rights: '?', subtitle: '?', title: '1', updated: '1', entry: '*',
+    },
+  },
+  entry: {
+    children: {
+      author: '*', category: '*', content: '?', contributor: '*', id: '1', link: '*',
+      published: '?', rights: '?', source: '?', summary: '?', title: '1', updated: '1',
+    },
+  },
+  author: PERSON,
+  contributor: PERSON,
+  name: { text: 'text' },
+  email: { text: /^\S+@\S+$/ },
+  uri: { text: IRI },
+  id: { text: IRI },
+  icon: { text: IRI },
+  logo: { text: IRI },
+  updated: { text: RFC3339 },
+  published: { text: RFC3339 },
+  title: TEXT_CONSTRUCT,
+  subtitle: TEXT_CONSTRUCT,
+  summary: TEXT_CONSTRUCT,
+  rights: TEXT_CONSTRUCT,
+  content: { attributes: { type: false, src: false }, text: 'text' },
+  category: { attributes: { term: true, scheme: false, label: false } },
+  link: { attributes: { href: true, rel: false, type: false, hreflang: false, title: false, length: false } },
+  generator: { attributes: { uri: false, version: false }, text: 'text' },
+};
+
+/**
+ * Check a parsed feed against SCHEMA and the rules RFC 4287 states
+ * beside it. Returns a list of violations.
+ */
+function validateAtom(feed) {
+  const errors = [];
+  const check = (node, path) => {
+    const spec = SCHEMA[node.name];
+    if (!spec) return errors.push(`${path}: <${node.name}> is not an Atom element`);
+    for (const [name, required] of Object.entries(spec.attributes || {})) {
+      if (required && !(name in node.attributes)) errors.push(`${path}: missing ${name}="..."`);
+    }
+    for (const name of Object.keys(node.attributes)) {
+      if (!(name in (spec.attributes || {})) && !(node.name === 'feed' && name === 'xmlns')) errors.push(`${path}: unknown attribute ${name}`);
+    }
+    if (spec.attributes?.type && node.attributes.type && !['text', 'html'].includes(node.attributes.type) && node.name !== 'content') {
+      errors.push(`${path}: type="${node.attributes.type}" (xhtml needs a div, not text)`);
+    }
+    const counts = {};
+    for (const child of node.children) counts[child.name] = (counts[child.name] || 0) + 1;
+    for (const [name, how] of Object.entries(spec.children || {})) {
+      const count = counts[name] || 0;
+      if (how === '1' && count !== 1) errors.push(`${path}: ${count} <${name}>, expected exactly one`);
+      if (how === '?' && count > 1) errors.push(`${path}: ${count} <${name}>, expected at most one`);
+    }
+    if (!spec.children && node.children.length > 0) errors.push(`${path}: <${node.name}> can't have child elements`);
+    if (spec.children) {
+      for (const child of node.children) {
+        if (!(child.name in spec.children)) errors.push(`${path}: <${child.name}> can't appear in <${node.name}>`);
+      }
+    }
+    if (spec.text instanceof RegExp && !spec.text.test(node.text)) errors.push(`${path}: "${node.text}" isn't valid here`);
+    if (!spec.text && node.text.trim()) errors.push(`${path}: <${node.name}> can't have text`);
+    if (node.name === 'link' && !IRI.test(node.attributes.href || '')) errors.push(`${path}: href "${node.attributes.href}" isn't an IRI`);
+    node.children.forEach((child, i) => check(child, `${path}/${child.name}[${i}]`));
+  };
+
+  if (feed.name !== 'feed') return [`root is <${feed.name}>, not <feed>`];
+  if (feed.attributes.xmlns !== ATOM_NS) errors.push(`feed isn't in the Atom namespace (${feed.attributes.xmlns})`);
+  check(feed, 'feed');
+
+  const entries = feed.children.filter(child => child.name === 'entry');
+  const feedHasAuthor = feed.children.some(child => child.name === 'author');
+  const ids = new Set();
+  entries.forEach((entry, i) => {
+    const child = name => entry.children.filter(c => c.name === name);
+    // 4.1.1: every entry needs an author, its own or the feed's
+    if (!feedHasAuthor && child('author').length === 0) errors.push(`entry[${i}]: no author`);
+    // 4.1.2: an entry without content must link to it
+    if (child('content').length === 0 && !child('link').some(link => (link.attributes.rel || 'alternate') === 'alternate')) {
+      errors.push(`entry[${i}]: no content and no alternate link`);
+    }
+    // 4.1.2: at most one alternate link per type and hreflang
+    const alternates = child('link').filter(link => (link.attributes.rel || 'alternate') === 'alternate')
+      .map(link => `${link.attributes.type}|${link.attributes.hreflang}`);
+    if (new Set(alternates).size !== alternates.length) errors.push(`entry[${i}]: repeated alternate link`);
+    // 4.1.1: entries must not share an id
+    const id = child('id')[0]?.text;
+    if (ids.has(id)) errors.push(`entry[${i}]: id ${id} is repeated`);
+    ids.add(id);
+  });
+  return errors;
+}
+
+const items = [
+  trackerItem(),
+  trackerItem({
+    id: 'cov-002',
+    title: 'Augustus & friends <on> "LLM" testing',
+    url: 'https://www.securityweek.com/augustus?a=1&b=2',
+    source: 'SecurityWeek & Co',
+    date: '2026-02-15T08:30:00Z',
+    tools_mentioned: ['Augustus', 'Brutus'],
+    excerpt: 'Control \u0001characters and <tags> & ampersands.',
+    archive_url: 'https://web.archive.org/web/2026/https://www.securityweek.com/augustus',
+  }),
+  trackerItem({ id: 'cov-003', url: 'https://example.com/undated', source: '', date: '', excerpt: '', tools_mentioned: [] }),
+  // A syndicated copy, an embargoed item and a low-relevance one, all left out
+  trackerItem({ id: 'cov-004', url: 'https://www.darkreading.com/application-security/praetorian-brutus?utm_source=x', date: '2026-02-17' }),
+  trackerItem({ id: 'cov-005', url: 'https://example.com/embargoed', status: 'embargoed' }),
+  trackerItem({ id: 'cov-006', url: 'https://example.com/low', low_relevance: true }),
+];
+
+test('the feed is well-formed and valid against the Atom schema', () => {
+  const feed = parseXml(renderAtomFeed(items, { selfUrl: 'https://example.com/feed.xml' }));
+  assert.deepEqual(validateAtom(feed), []);
+  assert.equal(feed.children.filter(child => child.name === 'entry').length, 3);
+});
+
+test('an empty feed, and one without a self link, are valid too', () => {
+  assert.deepEqual(validateAtom(parseXml(renderAtomFeed([], { selfUrl: '' }))), []);
+  assert.deepEqual(validateAtom(parseXml(renderAtomFeed(items.slice(0, 1), { selfUrl: null }))), []);
+});
+
+test('the schema check catches what an invalid feed gets wrong', () => {
+  const broken = parseXml('<feed xmlns="http://www.w3.org/2005/Atom"><title>t</title><updated>yesterday</updated>'
+    + '<entry><id>a</id><title>x</title><title>y</title><updated>2026-02-16T00:00:00Z</updated><link href="/relative"/><bogus/></entry></feed>');
+  const errors = validateAtom(broken);
+  for (const expected of [/feed: 0 <id>/, /"yesterday" isn't valid/, /2 <title>/, /entry\[0\]: no author/, /isn't an IRI/, /<bogus> is not an Atom element/, /"a" isn't valid/]) {
+    assert.ok(errors.some(error => expected.test(error)), `expected ${expected} in ${JSON.stringify(errors)}`);
+  }
+});
+
+test('entry ids are stable across regenerations and shared by URLs that normalize the same', () => {
+  assert.equal(entryId('https://www.darkreading.com/application-security/praetorian-brutus?utm_source=x'), entryId(items[0].url));
+  assert.equal(renderAtomFeed(items), renderAtomFeed([...items].reverse()));
+  assert.match(entryId(items[0].url), /^tag:praetorian\.com,2026:coverage-digest:[0-9a-f]{32}$/);
+});
+
+test('the feed keeps the most recent entries up to the limit', () => {
+  const feed = parseXml(renderAtomFeed(items, { limit: 1 }));
+  const entries = feed.children.filter(child => child.name === 'entry');
+  assert.equal(entries.length, 1);
+  assert.equal(entries[0].children.find(child => child.name === 'link').attributes.href, items[0].url);
+});
@@ -0,0 +1,69 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { digestItem } from './helpers.js';
+import { formatDate } from '../utils/i18n.js';
+import { renderDigestText } from '../utils/template-renderer.js';
+
+// The 15th of each month of 2026, which between them fall on every weekday
+const DATES = Array.from({ length: 12 }, (_, i) => `2026-${String(i + 1).padStart(2, '0')}-15`);
+const WEEKDAYS = ['Thursday', 'Sunday', 'Sunday', 'Wednesday', 'Friday', 'Monday', 'Wednesday', 'Saturday', 'Tuesday', 'Thursday', 'Sunday', 'Tuesday'];
+
+// Written out rather than taken from Intl, so a wrong name table shows
+const NAMES = {
+  en: {
+    months: ['January', 'February', 'March', 'April', 'May', 'June', 'July', 'August', 'September', 'October', 'November', 'December'],
+    weekdays: { Monday: 'Monday', Tuesday: 'Tuesday', Wednesday: 'Wednesday', Thursday: 'Thursday', Friday: 'Friday', Saturday: 'Saturday', Sunday: 'Sunday' },
+    header: (weekday, day, month) => `${weekday}, ${month} ${day}, 2026`,
+    item: (day, month) => `${['Jan', 'Feb', 'Mar', 'Apr', 'May', 'Jun', 'Jul', 'Aug', 'Sep', 'Oct', 'Nov', 'Dec'][month - 1]} ${day}, 2026`,
+  },
+  de: {
+    months: ['Januar', 'Februar', 'März', 'April', 'Mai', 'Juni', 'Juli', 'August', 'September', 'Oktober', 'November', 'Dezember'],
+    weekdays: { Monday: 'Montag', Tuesday: 'Dienstag', Wednesday: 'Mittwoch', Thursday: 'Donnerstag', Friday: 'Freitag', Saturday: 'Samstag', Sunday: 'Sonntag' },
+    header: (weekday, day, month) => `${weekday}, ${day}. ${month} 2026`,
+    item: (day, month) => `${day}.${String(month).padStart(2, '0')}.2026`,
+  },
+  fr: {
+    months: ['janvier', 'février', 'mars', 'avril', 'mai', 'juin', 'juillet', 'août', 'septembre', 'octobre', 'novembre', 'décembre'],
+    weekdays: { Monday: 'lundi', Tuesday: 'mardi', Wednesday: 'mercredi', Thursday: 'jeudi', Friday: 'vendredi', Saturday: 'samedi', Sunday: 'dimanche' },
+    header: (weekday, day, month) => `${weekday} ${day} ${month} 2026`,
+    item: (day, month) => `${day}/${String(month).padStart(2, '0')}/2026`,
+  },
+  es: {
+    months: ['enero', 'febrero', 'marzo', 'abril', 'mayo', 'junio', 'julio', 'agosto', 'septiembre', 'octubre', 'noviembre', 'diciembre'],
+    weekdays: { Monday: 'lunes', Tuesday: 'martes', Wednesday: 'miércoles', Thursday: 'jueves', Friday: 'viernes', Saturday: 'sábado', Sunday: 'domingo' },
+    header: (weekday, day, month) => `${weekday}, ${day} de ${month} de 2026`,
+    item: (day, month) => `${day}/${String(month).padStart(2, '0')}/2026`,
+  },
+};
+
+for (const [locale, names] of Object.entries(NAMES)) {
+  test(`${locale} header and item dates, one in each month`, () => {
+    DATES.forEach((date, i) => {
+      const month = i + 1;
+      assert.equal(formatDate(date, locale, { style: 'header' }), names.header(names.weekdays[WEEKDAYS[i]], 15, names.months[i]), date);
+      assert.equal(formatDate(date, locale, { style: 'item' }), names.item(15, month), date);
+    });
+  });
+}
+
+test('an instant is shown on its day in the time zone asked for; a calendar day in every zone', () => {
+  assert.equal(formatDate('2026-02-28T23:30:00Z', 'de', { style: 'item', timeZone: 'Europe/Berlin' }), '01.03.2026');
+  assert.equal(formatDate('2026-02-28T23:30:00Z', 'de', { style: 'item', timeZone: 'UTC' }), '28.02.2026');
+  assert.equal(formatDate('2026-02-28', 'de', { style: 'item', timeZone: 'Pacific/Kiritimati' }), '28.02.2026');
+});
+
+test('an unknown or malformed locale is formatted as English', () => {
+  for (const locale of ['xx', 'zz-ZZ', 'not a locale', '', undefined]) {
+    assert.equal(formatDate('2026-02-16', locale, { style: 'header' }), 'Monday, February 16, 2026', String(locale));
+  }
+  assert.throws(() => formatDate('2026-02-16', 'en', { style: 'long' }), /Unknown date style "long"/);
+});
+
+test('the locale changes only what is shown, not the items\' dates', () => {
+  const items = [digestItem({ date: '2026-02-15T08:30:00.000Z' })];
+  const before = structuredClone(items);
+  const text = renderDigestText(items, { locale: 'de', now: new Date('2026-02-16T12:00:00Z'), timeZone: 'UTC' });
+  assert.ok(text.includes('Montag, 16. Februar 2026'));
+  assert.ok(text.includes('15.02.2026'));
+  assert.deepEqual(items, before);
+});
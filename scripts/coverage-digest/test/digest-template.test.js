@@ -0,0 +1,74 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { assertGolden, trackerItem } from './helpers.js';
+import { buildDigest } from '../utils/digest-json.js';
+import { SAMPLE_DIGEST, compileDigestTemplate, loadDigestTemplate } from '../utils/digest-template.js';
+import { renderIssueBody } from '../utils/github-issue.js';
+
+// The custom template the README shows
+const LINKS = `# Coverage for {{date | date "long"}}
+{{#each items}}
+- [{{title | md}}]({{url}}) · {{source}}{{#if excerpt}}: {{excerpt | truncate 120}}{{/if}}
+{{/each}}
+`;
+
+test('the sample digest has every field buildDigest gives, each populated', () => {
+  const plain = buildDigest([trackerItem()]);
+  assert.deepEqual(Object.keys(SAMPLE_DIGEST), Object.keys(plain));
+  assert.deepEqual(Object.keys(SAMPLE_DIGEST.summary), Object.keys(plain.summary));
+  assert.deepEqual(Object.keys(SAMPLE_DIGEST.items[0]), Object.keys(plain.items[0]));
+  for (const [field, value] of Object.entries(SAMPLE_DIGEST.items[0])) {
+    assert.notEqual(value, null, `items[0].${field} is null`);
+  }
+  assert.ok(SAMPLE_DIGEST.trends);
+  assert.ok(SAMPLE_DIGEST.summary.first_seen_tools.length > 0);
+  assert.ok(SAMPLE_DIGEST.trends.new_publications.length > 0);
+});
+
+test('the built-in template renders the sample digest', async () => {
+  const template = await loadDigestTemplate();
+  await assertGolden('digest-template.builtin.md', template.render(SAMPLE_DIGEST));
+});
+
+test('a custom template renders the sample digest', async () => {
+  const template = compileDigestTemplate(LINKS, 'links.md');
+  await assertGolden('digest-template.links.md', template.render(SAMPLE_DIGEST));
+});
+
+test('a template naming a field the digest lacks fails at load, with its line', () => {
+  assert.throws(() => compileDigestTemplate(LINKS.replace('{{title | md}}', '{{titel | md}}'), 'links.md'),
+    /^Error: links\.md:3: unknown field "titel"$/);
+});
+
+test('the built-in template renders a digest into the issue body, byte for byte', async () => {
+  const earlier = [
+    trackerItem({ id: 'cov-001', url: 'https://www.wired.com/brutus', source: 'Wired', date: '2026-02-09', status: 'sent', last_sent_at: '2026-02-09T13:00:00Z' }),
+  ];
+  const items = [
+    trackerItem({
+      id: 'cov-002', title: 'Praetorian [releases] Brutus', url: 'https://www.heise.de/news/Brutus_(Werkzeug)', source: 'heise online',
+      date: '2026-02-16', tools_mentioned: ['Brutus', 'Augustus'], language: 'de', sentiment: 'negative',
+      embargo_lifted_at: '2026-02-16T12:00:00Z', paywalled: true,
+      archive_url: 'https://web.archive.org/web/2026/https://www.heise.de/news/Brutus_(Werkzeug)',
+      hacker_news: { points: 120, comments: 45, url: 'https://news.ycombinator.com/item?id=1' },
+      also_published_by: [{ source: 'c\'t', url: 'https://www.heise.de/ct/brutus' }],
+      mention_context: 'Praetorian released *Brutus* on Monday.',
+    }),
+    trackerItem({ id: 'cov-003', title: 'A roundup', url: 'https://example.com/roundup', source: 'Example', date: '2026-02-15', date_estimated: true, tools_mentioned: [], excerpt: '' }),
+    trackerItem({
+      id: 'cov-004', title: 'Augustus tests LLMs', url: 'https://www.securityweek.com/augustus', source: 'SecurityWeek', date: '2026-02-14',
+      tools_mentioned: ['Augustus'], update: { changed: ['title'], previous_title: 'Augustus probes LLMs', detected_at: '2026-02-16T12:00:00Z' },
+    }),
+    trackerItem({ id: 'cov-005', title: 'Brutus the dog', url: 'https://example.com/dog', source: 'Local News', needs_review: true, confidence: 0.42 }),
+    trackerItem({ id: 'cov-006', title: 'Security vendors list', url: 'https://example.com/list', source: 'Example', low_relevance: true }),
+  ];
+  const history = [...earlier, ...items];
+  const pendingTools = ['Vespasian'];
+
+  const { body, continuations } = renderIssueBody(items, {
+    layout: 'flat', history, excerptChars: 500, sources: false, maxItems: 25, overflow: 'list', runSummary: null, pendingTools,
+  });
+  const template = await loadDigestTemplate();
+  assert.deepEqual(continuations, []);
+  assert.equal(template.render(buildDigest(items, new Date('2026-02-16T13:00:00Z'), history, { pendingTools })), body);
+});
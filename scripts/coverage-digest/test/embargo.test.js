@@ -0,0 +1,88 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { trackerItem } from './helpers.js';
+import { liftExpiredEmbargoes, markAmplified, mergeIntoTracker } from '../utils/tracker.js';
+import { renderAtomFeed } from '../utils/atom.js';
+import { pressPageItems, renderToolPage } from '../utils/press-pages.js';
+import { buildRollup, renderRollupMarkdown } from '../utils/rollup.js';
+import { planDigest } from '../utils/empty-digest.js';
+import { previousDigestSummary } from '../utils/summary.js';
+
+const now = new Date('2026-02-16T12:00:00Z');
+const amplification = { linkedin_post: null, slack_message: null, employee_shares: [], added_to_website: false };
+
+// A published item, and one under embargo until tomorrow that was (wrongly)
+// stamped sent too, so only its status holds it back
+function tracker() {
+  return [
+    trackerItem({ status: 'sent', last_sent_at: '2026-02-16T09:00:00Z', amplification: { ...amplification } }),
+    trackerItem({
+      id: 'cov-002',
+      title: 'Praetorian to announce Augustus at RSAC',
+      url: 'https://www.securityweek.com/embargoed-augustus',
+      source: 'SecurityWeek',
+      date: '2026-02-16',
+      tools_mentioned: ['Brutus', 'Augustus'],
+      status: 'embargoed',
+      embargo_until: '2026-02-17T13:00:00.000Z',
+      last_sent_at: '2026-02-16T09:00:00Z',
+      amplification: { ...amplification },
+    }),
+  ];
+}
+
+// Every output read straight from the tracker, as text (the digest itself
+// takes only status "new")
+function outputs(items) {
+  const rollup = buildRollup(items, '2026-02-16', '2026-02-22');
+  return {
+    feed: renderAtomFeed(items, { selfUrl: 'https://example.com/feed.xml' }),
+    pressPages: ['Brutus', 'Augustus']
+      .map(tool => [tool, pressPageItems(items, tool)])
+      .filter(([, page]) => page.length > 0)
+      .map(([tool, page]) => renderToolPage(tool, page))
+      .join('\n'),
+    rollup: renderRollupMarkdown(rollup),
+    compactDigest: JSON.stringify(planDigest([], items, 'compact').lastItem),
+    trends: JSON.stringify(previousDigestSummary(items, [])),
+  };
+}
+
+function assertHeld(items) {
+  for (const [name, text] of Object.entries(outputs(items))) {
+    assert.ok(!text.includes('embargoed-augustus'), `${name} lists the embargoed item's URL`);
+    assert.ok(!text.includes('announce Augustus at RSAC'), `${name} lists the embargoed item's title`);
+  }
+}
+
+test('an embargoed item is absent from every output', () => {
+  const items = tracker();
+  assertHeld(items);
+  // The published item is there, so the checks above aren't vacuous
+  assert.ok(outputs(items).feed.includes('praetorian-brutus'));
+  assert.ok(outputs(items).rollup.includes('praetorian-brutus'));
+});
+
+test('amplifying an embargoed item is refused and leaves it embargoed', () => {
+  const items = tracker();
+  assert.throws(() => markAmplified(items[1], { linkedin: true, slack: true, website: true }, now), /cov-002 is embargoed until/);
+  assert.equal(items[1].status, 'embargoed');
+  assert.equal(items[1].amplification.linkedin_post, null);
+  assertHeld(items);
+});
+
+test('amplifying a published item queues it until every channel is done', () => {
+  const [item] = tracker();
+  assert.deepEqual(markAmplified(item, { linkedin: true }, now), ['linkedin']);
+  assert.equal(item.status, 'queued');
+  markAmplified(item, { slack: true, website: true }, now);
+  assert.equal(item.status, 'amplified');
+});
+
+test('a submission with a future embargo is held, then released once it passes', () => {
+  const items = [];
+  mergeIntoTracker(items, [{ title: 'Embargoed story', url: 'https://example.com/story', source: 'Example', date: '2026-02-16', embargoUntil: '2099-01-01T00:00:00Z' }]);
+  assert.equal(items[0].status, 'embargoed');
+  assert.equal(liftExpiredEmbargoes(items, new Date('2099-01-01T00:00:01Z')), 1);
+  assert.equal(items[0].status, 'new');
+});
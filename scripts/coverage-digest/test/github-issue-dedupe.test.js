@@ -0,0 +1,143 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { mockGitHubApi, rateLimitError, trackerItem } from './helpers.js';
+import { issueTitle, publishDigestIssue, renderIssueBody } from '../utils/github-issue.js';
+
+const date = 'Monday, February 16, 2026';
+const first = trackerItem({ id: 'cov-001' });
+const second = trackerItem({ id: 'cov-002', title: 'Augustus probes LLMs', url: 'https://www.securityweek.com/augustus', source: 'SecurityWeek', tools_mentioned: ['Augustus'] });
+
+// An open digest issue for `date` that lists `first`
+function openIssue() {
+  return { number: 7, title: issueTitle(date, 1), body: renderIssueBody([first]).body, state: 'open', labels: [{ name: 'coverage-digest' }], assignees: [] };
+}
+
+function calledWith(api, method) {
+  return api.calls.filter(([name]) => name === method);
+}
+
+test('creates an issue when the search finds none', async () => {
+  const api = mockGitHubApi();
+  const result = await publishDigestIssue(api, [first], { date, strategy: 'update' });
+  assert.equal(result.action, 'created');
+  assert.equal(api.issues.length, 1);
+  assert.match(calledWith(api, 'searchIssues')[0][1], /"Coverage Digest - Monday, February 16, 2026" in:title is:issue is:open/);
+});
+
+test('skip leaves the open issue alone', async () => {
+  const api = mockGitHubApi({ issues: [openIssue()] });
+  const before = api.issues[0].body;
+  const result = await publishDigestIssue(api, [first, second], { date, strategy: 'skip' });
+  assert.deepEqual(result, { action: 'skipped', number: 7 });
+  assert.equal(api.issues[0].body, before);
+  assert.equal(calledWith(api, 'createIssue').length, 0);
+  assert.equal(calledWith(api, 'createComment').length, 0);
+});
+
+test('update merges new items into the open issue body', async () => {
+  const api = mockGitHubApi({ issues: [openIssue()] });
+  const result = await publishDigestIssue(api, [first, second], { date, strategy: 'update' });
+  assert.deepEqual(result, { action: 'updated', number: 7 });
+  const { body, title } = api.issues[0];
+  assert.ok(body.includes('<!-- item:cov-001 -->'));
+  assert.ok(body.includes('<!-- item:cov-002 -->'));
+  assert.equal(title, issueTitle(date, 2));
+  assert.equal(calledWith(api, 'createIssue').length, 0);
+});
+
+test('update keeps a box someone ticked', async () => {
+  const issue = openIssue();
+  issue.body = issue.body.replace('- [ ] Post to #praetorian-in-the-wild', '- [x] Post to #praetorian-in-the-wild');
+  const api = mockGitHubApi({ issues: [issue] });
+  await publishDigestIssue(api, [first, second], { date, strategy: 'update' });
+  assert.ok(api.issues[0].body.includes('- [x] Post to #praetorian-in-the-wild'));
+});
+
+test('update replaces a syndicated item\'s outlet line rather than adding another', async () => {
+  const outlet = { source: 'Yahoo Finance', url: 'https://finance.yahoo.com/news/brutus' };
+  const syndicated = { ...first, also_published_by: [outlet] };
+  const issue = { ...openIssue(), body: renderIssueBody([syndicated]).body };
+  const api = mockGitHubApi({ issues: [issue] });
+  const more = { ...syndicated, also_published_by: [outlet, { source: 'MSN', url: 'https://www.msn.com/brutus' }] };
+  await publishDigestIssue(api, [more, second], { date, strategy: 'update' });
+  await publishDigestIssue(api, [more, second, trackerItem({ id: 'cov-003', url: 'https://example.com/third' })], { date, strategy: 'update' });
+
+  const lines = api.issues[0].body.split('\n').filter(line => line.startsWith('Also published by: '));
+  assert.deepEqual(lines, ['Also published by: [Yahoo Finance](https://finance.yahoo.com/news/brutus), [MSN](https://www.msn.com/brutus)']);
+});
+
+test('comment posts only the items the issue lacks', async () => {
+  const api = mockGitHubApi({ issues: [openIssue()] });
+  const result = await publishDigestIssue(api, [first, second], { date, strategy: 'comment' });
+  assert.deepEqual(result, { action: 'commented', number: 7 });
+  assert.equal(api.comments.length, 1);
+  assert.ok(api.comments[0].body.includes('<!-- item:cov-002 -->'));
+  assert.ok(!api.comments[0].body.includes('<!-- item:cov-001 -->'));
+});
+
+test('comment posts nothing when every item is listed', async () => {
+  const api = mockGitHubApi({ issues: [openIssue()] });
+  const result = await publishDigestIssue(api, [first], { date, strategy: 'comment' });
+  assert.equal(result.action, 'skipped');
+  assert.equal(api.comments.length, 0);
+});
+
+test('a rate-limited search falls back to listing open issues', async () => {
+  const api = mockGitHubApi({ issues: [openIssue()], search: rateLimitError() });
+  const result = await publishDigestIssue(api, [first, second], { date, strategy: 'update' });
+  assert.deepEqual(result, { action: 'updated', number: 7 });
+  assert.equal(calledWith(api, 'listOpenIssues').length, 1);
+  assert.equal(calledWith(api, 'createIssue').length, 0);
+});
+
+test('other search errors are not swallowed', async () => {
+  const api = mockGitHubApi({ search: Object.assign(new Error('HTTP 500'), { status: 500 }) });
+  await assert.rejects(publishDigestIssue(api, [first], { date }), /HTTP 500/);
+  assert.equal(calledWith(api, 'listOpenIssues').length, 0);
+});
+
+test('an issue for another date is not a match', async () => {
+  const other = { ...openIssue(), title: issueTitle('Sunday, February 15, 2026', 1) };
+  const api = mockGitHubApi({ issues: [other] });
+  const result = await publishDigestIssue(api, [first], { date, strategy: 'update' });
+  assert.equal(result.action, 'created');
+});
+
+test('an unknown strategy is refused', async () => {
+  await assert.rejects(publishDigestIssue(mockGitHubApi(), [first], { date, strategy: 'replace' }), /Unknown dedupe strategy/);
+});
+
+test('updating a digest split across comments edits its parts rather than posting them again', async () => {
+  const many = Array.from({ length: 200 }, (_, i) => trackerItem({
+    id: `cov-${String(i + 1).padStart(3, '0')}`,
+    title: `Coverage item ${i + 1}`,
+    url: `https://example.com/coverage/${i + 1}`,
+    excerpt: `Item ${i + 1}: ${'Praetorian released an open-source tool. '.repeat(30)}`,
+  }));
+  const options = { date, strategy: 'update', maxItems: 0, excerptChars: 2000 };
+  const api = mockGitHubApi();
+  await publishDigestIssue(api, many.slice(0, 150), options);
+  const parts = api.comments.length;
+  assert.ok(parts >= 2, `expected several continuation comments, got ${parts}`);
+
+  // The same items again: nothing to post or edit
+  await publishDigestIssue(api, many.slice(0, 150), options);
+  assert.equal(api.comments.length, parts);
+  assert.equal(calledWith(api, 'updateComment').length, 0);
+
+  // More items: the parts are edited, and only what no longer fits is posted
+  await publishDigestIssue(api, many, options);
+  const all = [api.issues[0].body, ...api.comments.map(comment => comment.body)].join('\n');
+  for (const item of many) {
+    assert.equal(all.split(`<!-- item:${item.id} -->`).length - 1, 1, `${item.id} listed other than once`);
+  }
+  assert.ok(calledWith(api, 'updateComment').length > 0);
+});
+
+test('comment doesn\'t post again an item an earlier comment lists', async () => {
+  const api = mockGitHubApi({ issues: [openIssue()] });
+  await publishDigestIssue(api, [first, second], { date, strategy: 'comment' });
+  const result = await publishDigestIssue(api, [first, second], { date, strategy: 'comment' });
+  assert.equal(result.action, 'skipped');
+  assert.equal(api.comments.length, 1);
+});
@@ -0,0 +1,138 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { mockGitHubApi, trackerItem } from './helpers.js';
+import { periodMarker, publishRollingIssue, renderIssueBody, rollingIssueTitle } from '../utils/github-issue.js';
+
+const period = '2026-W08';
+const first = trackerItem({ id: 'cov-001' });
+const second = trackerItem({ id: 'cov-002', title: 'Augustus probes LLMs', url: 'https://www.securityweek.com/augustus', source: 'SecurityWeek', tools_mentioned: ['Augustus'] });
+
+// The open rolling issue for `week`, listing `first`
+function rollingIssue(week = period) {
+  return {
+    number: 7, title: rollingIssueTitle(week, 1), body: `${periodMarker(week)}\n${renderIssueBody([first]).body}`,
+    state: 'open', labels: [{ name: 'coverage-digest' }], assignees: [],
+  };
+}
+
+function calledWith(api, method) {
+  return api.calls.filter(([name]) => name === method);
+}
+
+test('the week\'s issue is found by listing open digest issues, not by searching', async () => {
+  const api = mockGitHubApi({ issues: [rollingIssue('2026-W07'), { ...rollingIssue(), number: 8 }] });
+  const result = await publishRollingIssue(api, [first, second], { period });
+  assert.deepEqual(result, { action: 'updated', number: 8 });
+  assert.equal(calledWith(api, 'searchIssues').length, 0);
+  assert.equal(calledWith(api, 'listOpenIssues').length, 1);
+});
+
+test('a new week starts a new issue with its marker', async () => {
+  const api = mockGitHubApi({ issues: [rollingIssue('2026-W07')] });
+  const result = await publishRollingIssue(api, [first], { period });
+  assert.equal(result.action, 'created');
+  assert.ok(api.issues[1].body.startsWith(`${periodMarker(period)}\n`));
+});
+
+test('an update merges new items and keeps what people added to the body', async () => {
+  const issue = rollingIssue();
+  issue.body = issue.body
+    .replace('- [ ] Post to #praetorian-in-the-wild', '- [x] Post to #praetorian-in-the-wild')
+    .replace('<!-- /item:cov-001 -->', 'Shared with the Brutus maintainers.\n<!-- /item:cov-001 -->');
+  const api = mockGitHubApi({ issues: [issue] });
+
+  await publishRollingIssue(api, [first, second], { period });
+  const { body, title } = api.issues[0];
+  assert.ok(body.startsWith(`${periodMarker(period)}\n`));
+  assert.ok(body.includes('<!-- item:cov-002 -->'));
+  assert.ok(body.includes('- [x] Post to #praetorian-in-the-wild'));
+  assert.ok(body.includes('Shared with the Brutus maintainers.'));
+  assert.equal(title, rollingIssueTitle(period, 2));
+  assert.deepEqual(api.comments.map(comment => comment.body), ['+1 item since last update\n']);
+
+  // Nothing new: left alone
+  const again = await publishRollingIssue(api, [first, second], { period });
+  assert.equal(again.action, 'skipped');
+  assert.equal(calledWith(api, 'updateIssue').length, 1);
+});
+
+test('an edit made after the issue was found is merged, not overwritten', async () => {
+  const api = mockGitHubApi({ issues: [rollingIssue()] });
+  const getIssue = api.getIssue;
+  api.getIssue = async number => {
+    api.issues[0].body = api.issues[0].body.replace('- [ ] Queue LinkedIn posts for each item', '- [x] Queue LinkedIn posts for each item');
+    return getIssue(number);
+  };
+  await publishRollingIssue(api, [first, second], { period });
+  assert.ok(api.issues[0].body.includes('- [x] Queue LinkedIn posts for each item'));
+  assert.ok(api.issues[0].body.includes('<!-- item:cov-002 -->'));
+});
+
+test('a body restructured past merging gets the new items as a comment', async () => {
+  const issue = { ...rollingIssue(), body: `${periodMarker(period)}\nTriaged in the weekly sync; see comments.` };
+  const api = mockGitHubApi({ issues: [issue] });
+  const result = await publishRollingIssue(api, [first, second], { period });
+  assert.deepEqual(result, { action: 'commented', number: 7 });
+  assert.equal(api.issues[0].body, issue.body);
+  assert.ok(api.comments[0].body.includes('<!-- item:cov-002 -->'));
+});
+
+test('an edit landing between the read and the write is merged into on a second try', async () => {
+  const api = mockGitHubApi({ issues: [rollingIssue()] });
+  const getIssue = api.getIssue;
+  let reads = 0;
+  api.getIssue = async number => {
+    // Someone ticks a box just before the write's re-check
+    if (++reads === 2) {
+      api.issues[0].body = api.issues[0].body.replace('- [ ] Notify #amplification-crew for reshares', '- [x] Notify #amplification-crew for reshares');
+      api.issues[0].updated_at = '2026-02-16T14:00:00Z';
+    }
+    return getIssue(number);
+  };
+  const result = await publishRollingIssue(api, [first, second], { period });
+  assert.deepEqual(result, { action: 'updated', number: 7 });
+  assert.ok(api.issues[0].body.includes('- [x] Notify #amplification-crew for reshares'));
+  assert.ok(api.issues[0].body.includes('<!-- item:cov-002 -->'));
+  assert.equal(calledWith(api, 'updateIssue').length, 1);
+});
+
+test('an issue that changes again during the second try is left alone', async () => {
+  const api = mockGitHubApi({ issues: [rollingIssue()] });
+  const getIssue = api.getIssue;
+  let reads = 0;
+  api.getIssue = async number => {
+    api.issues[0].updated_at = `2026-02-16T14:00:0${++reads}Z`;
+    return getIssue(number);
+  };
+  await assert.rejects(publishRollingIssue(api, [first, second], { period }), /changed again while being updated/);
+  assert.equal(calledWith(api, 'updateIssue').length, 0);
+});
+
+test('continuation comments are edited as the week grows, not posted again', async () => {
+  const many = Array.from({ length: 200 }, (_, i) => trackerItem({
+    id: `cov-${String(i + 1).padStart(3, '0')}`,
+    title: `Coverage item ${i + 1}`,
+    url: `https://example.com/coverage/${i + 1}`,
+    excerpt: `Item ${i + 1}: ${'Praetorian released an open-source tool. '.repeat(30)}`,
+  }));
+  const options = { period, maxItems: 0, excerptChars: 2000 };
+  const api = mockGitHubApi();
+  const continuations = () => api.comments.filter(comment => comment.body.startsWith('## Coverage Items (continued, part '));
+  await publishRollingIssue(api, many.slice(0, 150), options);
+  const parts = continuations().length;
+  assert.ok(parts >= 2, `expected several continuation comments, got ${parts}`);
+
+  await publishRollingIssue(api, many.slice(0, 160), options);
+  await publishRollingIssue(api, many, options);
+  const posted = continuations().map(comment => continuationPart(comment.body));
+  assert.deepEqual(posted, [...new Set(posted)], 'a part was posted twice');
+  const all = [api.issues[0].body, ...api.comments.map(comment => comment.body)].join('\n');
+  for (const item of many) {
+    assert.equal(all.split(`<!-- item:${item.id} -->`).length - 1, 1, `${item.id} listed other than once`);
+  }
+  assert.ok(calledWith(api, 'updateComment').length > 0);
+});
+
+function continuationPart(body) {
+  return Number(body.match(/part (\d+)\)/)[1]);
+}
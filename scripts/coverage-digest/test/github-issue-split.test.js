@@ -0,0 +1,66 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { trackerItem } from './helpers.js';
+import { MAX_BODY_LENGTH, renderIssueBody } from '../utils/github-issue.js';
+
+const TOOLS = ['Brutus', 'Augustus', 'Julius', 'Nerva'];
+
+// 200 items with long excerpts, several times what one body holds
+const items = Array.from({ length: 200 }, (_, i) => trackerItem({
+  id: `cov-${String(i + 1).padStart(3, '0')}`,
+  title: `Coverage item ${i + 1}`,
+  url: `https://example.com/coverage/${i + 1}`,
+  source: `Outlet ${i % 17}`,
+  date: `2026-02-${String(1 + (i % 16)).padStart(2, '0')}`,
+  tools_mentioned: [TOOLS[i % TOOLS.length], ...(i % 5 === 0 ? [TOOLS[(i + 1) % TOOLS.length]] : [])],
+  excerpt: `Item ${i + 1}: ${'Praetorian released an open-source tool. '.repeat(30)}`,
+}));
+
+function render(layout) {
+  return renderIssueBody(items, { layout, maxItems: 0, excerptChars: 2000, history: null, sources: false });
+}
+
+for (const layout of ['flat', 'by-tool']) {
+  test(`a ${layout} digest of 200 items is split across comments without losing any`, () => {
+    const { body, continuations } = render(layout);
+    const pages = [body, ...continuations];
+    assert.ok(continuations.length >= 2, `expected the items to need several comments, got ${continuations.length}`);
+
+    for (const page of pages) {
+      assert.ok(page.length <= MAX_BODY_LENGTH, `a page is ${page.length} chars`);
+      // Every item that starts on a page ends on it
+      const opened = [...page.matchAll(/<!-- item:(\S+) -->/g)].map(match => match[1]);
+      const closed = [...page.matchAll(/<!-- \/item:(\S+) -->/g)].map(match => match[1]);
+      assert.deepEqual(opened, closed);
+    }
+
+    // Each item's full block is listed exactly once, in the body or a comment
+    const all = pages.join('\n');
+    for (const item of items) {
+      assert.equal(all.split(`<!-- item:${item.id} -->`).length - 1, 1, `${item.id} listed other than once`);
+      assert.ok(all.includes(`### [${item.title}](${item.url})`), `${item.id} lost its heading`);
+      assert.ok(all.includes(`> Item ${item.title.split(' ').pop()}: `), `${item.id} lost its excerpt`);
+    }
+
+    // The Summary counts everything and, with Action Needed, stays in the body
+    assert.match(body, /^## Summary\n/);
+    assert.match(body, /\| New Items \| 200 \|/);
+    assert.match(body, /## Action Needed\n\n<!-- action-needed -->\n[\s\S]*<!-- \/action-needed -->\n$/);
+    for (const comment of continuations) {
+      assert.ok(!comment.includes('## Summary') && !comment.includes('## Action Needed'));
+    }
+
+    // Comments continue the numbering from the body, part 1
+    continuations.forEach((comment, i) => {
+      assert.ok(comment.startsWith(`## Coverage Items (continued, part ${i + 2})\n\n`), comment.slice(0, 60));
+    });
+    const inComments = continuations.join('').split('<!-- item:').length - 1;
+    assert.match(body, new RegExp(`_${inComments} more item\\(s\\) continued in the comments below\\._`));
+  });
+}
+
+test('a digest that fits is not split', () => {
+  const { body, continuations } = renderIssueBody(items.slice(0, 5), { maxItems: 0 });
+  assert.deepEqual(continuations, []);
+  assert.ok(!body.includes('continued in the comments below'));
+});
@@ -0,0 +1,62 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { trackerItem } from './helpers.js';
+import { mergeIssueBody, renderIssueBody } from '../utils/github-issue.js';
+import { escapeLinkUrl } from '../utils/markdown.js';
+
+const brutus = trackerItem();
+// A syndicated copy of the same article, and markup in the fields that
+// the rendered lines put it in
+const copy = trackerItem({ id: 'cov-002', url: `${brutus.url}?utm_source=feed`, source: 'Dark Reading' });
+const hostile = trackerItem({
+  id: 'cov-003',
+  title: 'Why `Augustus` beats **Brutus** · really',
+  url: 'https://en.wikipedia.org/wiki/Augustus_(tool)',
+  source: 'Wiki ** · `Julius`',
+  tools_mentioned: ['Augustus'],
+});
+const spaced = trackerItem({ id: 'cov-004', title: 'Nerva notes', url: 'https://example.com/nerva notes', source: 'Example', tools_mentioned: ['Nerva'] });
+
+function summaryRow(body, metric) {
+  return body.match(new RegExp(`^\\| ${metric} \\| (.*) \\|$`, 'm'))?.[1];
+}
+
+test('the Summary is counted from the items, not read back from their markdown', () => {
+  const { body } = renderIssueBody([brutus, copy, hostile, spaced], { maxItems: 0, history: null, sources: false });
+  assert.equal(summaryRow(body, 'New Items'), '4');
+  // The copy is the same article, and "Wiki ** · `Julius`" names no tool
+  assert.equal(summaryRow(body, 'Publications'), '3');
+  assert.equal(summaryRow(body, 'Tools Mentioned'), 'Brutus (1), Augustus (1), Nerva (1)');
+});
+
+test('the by-tool layout groups by the items\' tools, whatever their text holds', () => {
+  const { body } = renderIssueBody([hostile, spaced], { layout: 'by-tool', maxItems: 0 });
+  assert.match(body, /### Augustus\n\n<!-- item:cov-003 -->/);
+  assert.match(body, /### Nerva\n\n<!-- item:cov-004 -->/);
+  assert.ok(!body.includes('### Julius'));
+});
+
+test('link targets escape parentheses and spaces', () => {
+  assert.equal(escapeLinkUrl('https://en.wikipedia.org/wiki/Augustus_(tool)'), 'https://en.wikipedia.org/wiki/Augustus_%28tool%29');
+  assert.equal(escapeLinkUrl('https://example.com/nerva notes'), 'https://example.com/nerva%20notes');
+  assert.equal(escapeLinkUrl('https://example.com/<x>'), 'https://example.com/%3Cx%3E');
+
+  const archived = { ...hostile, archive_url: 'https://web.archive.org/web/2026/https://en.wikipedia.org/wiki/Augustus_(tool)' };
+  const { body } = renderIssueBody([archived, spaced], { maxItems: 0 });
+  assert.ok(body.includes('](https://en.wikipedia.org/wiki/Augustus_%28tool%29)'));
+  assert.ok(body.includes('[(archive)](https://web.archive.org/web/2026/https://en.wikipedia.org/wiki/Augustus_%28tool%29)'));
+  assert.ok(body.includes('](https://example.com/nerva%20notes)'));
+  assert.ok(!body.includes('nerva notes)'));
+
+  // Past maxItems, the one-line listing is built from the item too
+  const listed = renderIssueBody([brutus, { ...spaced, date: '2026-02-15' }], { maxItems: 1 }).body;
+  assert.match(listed, /- \[Nerva notes\]\(https:\/\/example\.com\/nerva%20notes\) — \*\*Example\*\* · 2026-02-15 `Nerva`/);
+});
+
+test('a merged body counts an item it keeps from history', () => {
+  const existing = renderIssueBody([hostile], { maxItems: 0 }).body;
+  const merged = mergeIssueBody(existing, [spaced], { maxItems: 0, history: [hostile, spaced], sources: false });
+  assert.equal(merged.count, 2);
+  assert.equal(summaryRow(merged.body, 'Tools Mentioned'), 'Augustus (1), Nerva (1)');
+  assert.equal(summaryRow(merged.body, 'Publications'), '2');
+});
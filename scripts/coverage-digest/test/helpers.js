@@ -0,0 +1,239 @@
+import { mkdir, mkdtemp, readFile, rm, writeFile } from 'fs/promises';
+import { tmpdir } from 'os';
+import { dirname, join } from 'path';
+import { fileURLToPath } from 'url';
+import assert from 'node:assert/strict';
+
+const GOLDEN_DIR = join(dirname(fileURLToPath(import.meta.url)), 'golden');
+
+/**
+ * A tracker item (snake_case, as stored in coverage-tracker.json) with
+ * the fields the renderers read, for a test to override.
+ */
+export function trackerItem(overrides = {}) {
+  return {
+    id: 'cov-001',
+    title: 'Praetorian releases Brutus',
+    url: 'https://www.darkreading.com/application-security/praetorian-brutus',
+    source: 'Dark Reading',
+    source_type: 'media',
+    date: '2026-02-16',
+    tools_mentioned: ['Brutus'],
+    excerpt: 'Brutus is an open-source credential tester from Praetorian.',
+    status: 'new',
+    ...overrides,
+  };
+}
+
+/**
+ * A digest item (camelCase, as the email renderer takes them) with the
+ * same defaults.
+ */
+export function digestItem(overrides = {}) {
+  return {
+    title: 'Praetorian releases Brutus',
+    url: 'https://www.darkreading.com/application-security/praetorian-brutus',
+    source: 'Dark Reading',
+    sourceType: 'media',
+    date: '2026-02-16T00:00:00.000Z',
+    excerpt: 'Brutus is an open-source credential tester from Praetorian.',
+    toolsMentioned: ['Brutus'],
+    ...overrides,
+  };
+}
+
+/**
+ * An HTTP client shaped like utils/http-client.js's, answering from
+ * `responses` in order, each [status, body, headers]. What it was asked
+ * is kept in `requests` as { url, init }.
+ */
+export function mockClient(responses = []) {
+  const requests = [];
+  return {
+    requests,
+    async fetch(url, init = {}) {
+      requests.push({ url, init });
+      if (responses.length === 0) throw new Error(`Unexpected request to ${url}`);
+      const [status, body = '', headers = {}] = responses.shift();
+      const text = typeof body === 'string' ? body : JSON.stringify(body);
+      return {
+        ok: status >= 200 && status < 300,
+        status,
+        headers,
+        url,
+        text: async () => text,
+        json: async () => JSON.parse(text),
+        buffer: async () => Buffer.from(text),
+      };
+    },
+  };
+}
+
+/**
+ * Compare `actual` with test/golden/<name>. With UPDATE_GOLDEN=1 the file
+ * is (re)written instead, for a change that's meant to alter the output;
+ * review the diff before committing it.
+ */
+export async function assertGolden(name, actual) {
+  const file = join(GOLDEN_DIR, name);
+  if (process.env.UPDATE_GOLDEN === '1') {
+    await mkdir(dirname(file), { recursive: true });
+    await writeFile(file, actual);
+    return;
+  }
+  assert.equal(actual, await readFile(file, 'utf-8'), `${name} differs from its golden file (UPDATE_GOLDEN=1 to rewrite it)`);
+}
+
+/**
+ * A temporary directory, removed when the test ends.
+ */
+export async function tempDir(t) {
+  const dir = await mkdtemp(join(tmpdir(), 'coverage-digest-'));
+  t.after(() => rm(dir, { recursive: true, force: true }));
+  return dir;
+}
+
+/**
+ * A GitHub API shaped like utils/github-issue.js createGitHubApi's,
+ * holding issues in memory. `search` answers searchIssues (a function of
+ * the query, or an Error to throw); every call is kept in `calls` as
+ * [method, ...args].
+ */
+export function mockGitHubApi({ issues = [], labels = [], search = null } = {}) {
+  const calls = [];
+  let stamp = 0;
+  const touch = issue => { issue.updated_at = `2026-02-16T13:00:${String(++stamp).padStart(2, '0')}Z`; };
+  const find = number => issues.find(issue => issue.number === number);
+  const api = {
+    calls,
+    issues,
+    comments: [],
+    async searchIssues(query) {
+      calls.push(['searchIssues', query]);
+      if (search instanceof Error) throw search;
+      return (search ? search(query) : issues.filter(issue => issue.state !== 'closed')).map(issue => ({ ...issue }));
+    },
+    async listOpenIssues() {
+      calls.push(['listOpenIssues']);
+      return issues.filter(issue => issue.state !== 'closed').map(issue => ({ ...issue }));
+    },
+    async getIssue(number) {
+      calls.push(['getIssue', number]);
+      return { ...find(number) };
+    },
+    async createIssue({ title, body, labels: names = [] }) {
+      calls.push(['createIssue', title]);
+      const issue = {
+        number: issues.length + 1, title, body, state: 'open',
+        labels: ['coverage-digest', ...names].map(name => ({ name })), assignees: [],
+      };
+      touch(issue);
+      issues.push(issue);
+      return { ...issue };
+    },
+    async updateIssue(number, fields) {
+      calls.push(['updateIssue', number]);
+      Object.assign(find(number), fields);
+      touch(find(number));
+      return { ...find(number) };
+    },
+    async createComment(number, body) {
+      calls.push(['createComment', number]);
+      const comment = { id: api.comments.length + 1, number, body };
+      api.comments.push(comment);
+      touch(find(number));
+      return { ...comment };
+    },
+    async listComments(number) {
+      calls.push(['listComments', number]);
+      return api.comments.filter(comment => comment.number === number).map(comment => ({ ...comment }));
+    },
+    async updateComment(id, body) {
+      calls.push(['updateComment', id]);
+      const comment = api.comments.find(c => c.id === id);
+      comment.body = body;
+      touch(find(comment.number));
+      return { ...comment };
+    },
+    async listLabels() {
+      calls.push(['listLabels']);
+      return labels.map(name => ({ name }));
+    },
+    async createLabel({ name }) {
+      calls.push(['createLabel', name]);
+      labels.push(name);
+    },
+    async addLabels(number, names) {
+      calls.push(['addLabels', number, names]);
+      find(number).labels.push(...names.map(name => ({ name })));
+    },
+    async addAssignees(number, logins) {
+      calls.push(['addAssignees', number, logins]);
+      find(number).assignees.push(...logins.map(login => ({ login })));
+    },
+    async listTeamMembers(org, team) {
+      calls.push(['listTeamMembers', org, team]);
+      return [];
+    },
+  };
+  return api;
+}
+
+/**
+ * An error like the GitHub client throws when a rate limit is hit.
+ */
+export function rateLimitError(message = 'GitHub rate limit hit on GET /search/issues') {
+  return Object.assign(new Error(message), { rateLimited: true });
+}
+
+/**
+ * Parse a small, namespace-unaware XML document into { name, attributes,
+ * children, text } nodes, throwing where it isn't well-formed: unbalanced
+ * or mismatched tags, unquoted or repeated attributes, a bare "<" or "&",
+ * an unknown entity, characters XML 1.0 forbids, or other than one root.
+ * Enough to check what the renderers write, without an XML dependency.
+ */
+export function parseXml(text) {
+  if (/[\u0000-\u0008\u000B\u000C\u000E-\u001F￾￿]/.test(text)) throw new Error('character not allowed in XML');
+  const decode = value => value.replace(/&([^;]*);?/g, (match, name) => {
+    if (!match.endsWith(';')) throw new Error(`bare "&" in ${JSON.stringify(value)}`);
+    const named = { amp: '&', lt: '<', gt: '>', quot: '"', apos: "'" }[name];
+    if (named) return named;
+    const code = /^#x[0-9a-f]+$/i.test(name) ? parseInt(name.slice(2), 16) : /^#\d+$/.test(name) ? parseInt(name.slice(1), 10) : NaN;
+    if (Number.isNaN(code)) throw new Error(`unknown entity &${name};`);
+    return String.fromCodePoint(code);
+  });
+
+  const source = text.replace(/^<\?xml[^?]*\?>/, '');
+  const token = /<!--[\s\S]*?-->|<\/([\w:.-]+)\s*>|<([\w:.-]+)((?:\s+[\w:.-]+\s*=\s*(?:"[^"<]*"|'[^'<]*'))*)\s*(\/?)>|([^<]+)|(<)/g;
+  const stack = [{ children: [] }];
+  let match;
+  while ((match = token.exec(source))) {
+    const [, close, open, attrs, selfClosing, chars, stray] = match;
+    const parent = stack[stack.length - 1];
+    if (stray) throw new Error(`malformed markup at ${JSON.stringify(source.slice(match.index, match.index + 40))}`);
+    if (chars !== undefined) {
+      if (stack.length === 1) {
+        if (chars.trim()) throw new Error('text outside the root element');
+      } else {
+        parent.text += decode(chars);
+      }
+    } else if (open) {
+      const attributes = {};
+      for (const [, name, quoted] of attrs.matchAll(/([\w:.-]+)\s*=\s*("[^"]*"|'[^']*')/g)) {
+        if (Object.hasOwn(attributes, name)) throw new Error(`<${open}> repeats attribute ${name}`);
+        attributes[name] = decode(quoted.slice(1, -1));
+      }
+      if (stack.length === 1 && parent.children.length > 0) throw new Error('more than one root element');
+      const node = { name: open, attributes, children: [], text: '' };
+      parent.children.push(node);
+      if (!selfClosing) stack.push(node);
+    } else if (close) {
+      if (stack.length === 1 || parent.name !== close) throw new Error(`</${close}> doesn't close <${parent.name}>`);
+      stack.pop();
+    }
+  }
+  if (stack.length !== 1) throw new Error(`<${stack[stack.length - 1].name}> is never closed`);
+  if (stack[0].children.length !== 1) throw new Error('no root element');
+  return stack[0].children[0];
+}
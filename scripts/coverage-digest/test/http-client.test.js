@@ -0,0 +1,134 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import http from 'http';
+import { once } from 'events';
+import { config } from '../config.js';
+import { clientFor, createHttpClient } from '../utils/http-client.js';
+
+// A local server answering with `handler`, closed when the test ends
+async function serve(t, handler) {
+  const server = http.createServer(handler);
+  const sockets = new Set();
+  server.on('connection', socket => {
+    sockets.add(socket);
+    socket.on('close', () => sockets.delete(socket));
+  });
+  server.listen(0, '127.0.0.1');
+  await once(server, 'listening');
+  t.after(() => {
+    for (const socket of sockets) socket.destroy();
+    server.close();
+  });
+  return { server, url: `http://127.0.0.1:${server.address().port}` };
+}
+
+test('a redirect to another origin drops credential headers', async t => {
+  const seen = [];
+  const other = await serve(t, (req, res) => {
+    seen.push(req.headers);
+    res.end('ok');
+  });
+  // Another port is another origin
+  const same = await serve(t, (req, res) => {
+    if (req.url === '/start') {
+      seen.push(req.headers);
+      res.writeHead(302, { Location: '/next' }).end();
+    } else if (req.url === '/next') {
+      seen.push(req.headers);
+      res.writeHead(302, { Location: `${other.url}/landing` }).end();
+    }
+  });
+
+  const client = createHttpClient({ headers: { 'X-Scrape-Key': 'scrape-secret', 'User-Agent': 'test' }, retry: { attempts: 1 } });
+  const res = await client.fetch(`${same.url}/start`, {
+    headers: { Authorization: 'Bearer token', 'Ocp-Apim-Subscription-Key': 'bing-key', Cookie: 'session=1', Accept: 'application/json' },
+  });
+  assert.equal(await res.text(), 'ok');
+  assert.equal(res.url, `${other.url}/landing`);
+
+  const [start, next, landing] = seen;
+  for (const headers of [start, next]) {
+    assert.equal(headers.authorization, 'Bearer token');
+    assert.equal(headers['ocp-apim-subscription-key'], 'bing-key');
+    assert.equal(headers['x-scrape-key'], 'scrape-secret');
+  }
+  for (const name of ['authorization', 'ocp-apim-subscription-key', 'x-scrape-key', 'cookie']) {
+    assert.equal(landing[name], undefined, `${name} followed the redirect to another origin`);
+  }
+  assert.equal(landing.accept, 'application/json');
+  assert.equal(landing['user-agent'], 'test');
+});
+
+test('a timed-out request is torn down, not left running', async t => {
+  let closed;
+  const gone = new Promise(resolve => { closed = resolve; });
+  const { url } = await serve(t, req => {
+    // Never answers
+    req.socket.on('close', closed);
+  });
+  const client = createHttpClient({ timeoutMs: 50 });
+  await assert.rejects(client.fetch(url, { retry: false }), err => err.code === 'ETIMEDOUT' && /timed out after 50ms/.test(err.message));
+  await gone;
+});
+
+test('aborting the caller\'s signal tears the request down', async t => {
+  let closed;
+  const gone = new Promise(resolve => { closed = resolve; });
+  const { url } = await serve(t, req => {
+    req.socket.on('close', closed);
+  });
+  const controller = new AbortController();
+  const client = createHttpClient({ timeoutMs: 60000 });
+  const pending = client.fetch(url, { signal: controller.signal });
+  setTimeout(() => controller.abort(new Error('source timed out')), 20);
+  await assert.rejects(pending, /source timed out/);
+  await gone;
+});
+
+test('a source with a proxy override goes through its proxy; the rest go direct', async t => {
+  const proxied = [];
+  const proxy = await serve(t, (req, res) => {
+    // Absolute-form request for an http target
+    proxied.push({ url: req.url, auth: req.headers['proxy-authorization'], key: req.headers['x-scrape-key'] });
+    res.end('via proxy');
+  });
+  const direct = [];
+  const target = await serve(t, (req, res) => {
+    direct.push(req.url);
+    res.end('direct');
+  });
+
+  const proxyUrl = new URL(proxy.url);
+  proxyUrl.username = 'scraper';
+  proxyUrl.password = 'p@ss';
+  config.http.sources['Dark Reading'] = { proxy: proxyUrl.toString(), headers: { 'X-Scrape-Key': 'k' }, timeoutMs: 5000 };
+  t.after(() => delete config.http.sources['Dark Reading']);
+
+  const viaProxy = clientFor('Dark Reading');
+  assert.equal(viaProxy.name, 'Dark Reading');
+  assert.equal(await (await viaProxy.fetch(`${target.url}/article`)).text(), 'via proxy');
+  assert.deepEqual(proxied, [{
+    url: `${target.url}/article`,
+    auth: `Basic ${Buffer.from('scraper:p@ss').toString('base64')}`,
+    key: 'k',
+  }]);
+
+  const plain = clientFor('SecurityWeek');
+  assert.equal(plain.name, 'default');
+  assert.equal(await (await plain.fetch(`${target.url}/other`)).text(), 'direct');
+  assert.deepEqual(direct, ['/other']);
+  assert.equal(proxied.length, 1);
+});
+
+test('an https target goes through a CONNECT tunnel, and a refusal says so', async t => {
+  const proxy = await serve(t, () => {});
+  proxy.server.on('connect', (req, socket) => {
+    socket.end('HTTP/1.1 403 Forbidden\r\n\r\n');
+  });
+  const proxyUrl = new URL(proxy.url);
+  proxyUrl.username = 'scraper';
+  proxyUrl.password = 'hunter2';
+  const client = createHttpClient({ proxy: proxyUrl.toString(), retry: { attempts: 1 } });
+  await assert.rejects(client.fetch('https://www.darkreading.com/'), err =>
+    /refused tunnel to www\.darkreading\.com: HTTP 403/.test(err.message) && !err.message.includes('hunter2'));
+});
@@ -0,0 +1,36 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { assertGolden, digestItem } from './helpers.js';
+import { availableLocales, createTranslator, resolveLocale } from '../utils/i18n.js';
+import { renderDigestText } from '../utils/template-renderer.js';
+
+const items = [
+  digestItem(),
+  digestItem({
+    title: 'Augustus probes LLMs for prompt injection',
+    url: 'https://www.securityweek.com/augustus',
+    source: 'SecurityWeek',
+    date: '2026-02-15',
+    excerpt: 'Praetorian released Augustus, an LLM vulnerability scanner.',
+    toolsMentioned: ['Augustus'],
+  }),
+];
+
+test('a locale is only ever a catalog name, never a path', () => {
+  for (const locale of ['../package', '../../config', 'de/../../package', '/etc/passwd', '..', 'de.json', '']) {
+    assert.equal(resolveLocale(locale), 'en', locale);
+  }
+  assert.equal(createTranslator('../locales/de').locale, 'en');
+});
+
+test('a regional locale falls back to its language', () => {
+  assert.equal(resolveLocale('de-AT'), 'de');
+  assert.equal(resolveLocale('ja_JP'), 'ja');
+});
+
+for (const locale of availableLocales()) {
+  test(`the ${locale} digest matches its golden file`, async () => {
+    const text = renderDigestText(items, { locale, now: new Date('2026-02-16T12:00:00Z'), timeZone: 'UTC', excerptChars: 200 });
+    await assertGolden(`digest.${locale}.txt`, text);
+  });
+}
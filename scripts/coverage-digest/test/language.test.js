@@ -0,0 +1,73 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { digestItem } from './helpers.js';
+import { applyLanguagePolicy, detectLanguage, itemLanguage, languageName } from '../utils/language.js';
+import { detectTools } from '../utils/tools.js';
+
+// Coverage of Augustus as the outlets that picked it up wrote it
+const SNIPPETS = {
+  de: {
+    title: 'Praetorian veröffentlicht Augustus: Open-Source-Werkzeug prüft Sprachmodelle auf Prompt-Injection',
+    excerpt: 'Mit Augustus können Sicherheitsteams große Sprachmodelle automatisiert auf Schwachstellen testen. '
+      + 'Das Werkzeug unterstützt mehr als zwanzig Anbieter und wird unter einer freien Lizenz entwickelt.',
+  },
+  ja: {
+    title: 'Praetorian、LLMの脆弱性を検査するオープンソースツール「Augustus」を公開',
+    excerpt: 'Augustusはプロンプトインジェクションやジェイルブレイクに対する大規模言語モデルの耐性を自動で検証する。'
+      + '20以上のプロバイダーに対応している。',
+  },
+  fr: {
+    title: 'Praetorian publie Augustus, un outil libre pour tester la sécurité des modèles de langage',
+    excerpt: 'Augustus permet aux équipes de sécurité de vérifier la résistance des grands modèles de langage '
+      + 'aux attaques par injection de prompt. L\'outil prend en charge plus de vingt fournisseurs.',
+  },
+  en: {
+    title: 'Praetorian releases Augustus, an open-source tool for testing LLM security',
+    excerpt: 'Augustus lets security teams check how well large language models hold up against prompt injection '
+      + 'and jailbreaks. The tool supports more than twenty providers.',
+  },
+};
+
+for (const [language, { title, excerpt }] of Object.entries(SNIPPETS)) {
+  test(`a ${languageName(language)} item about Augustus is detected as "${language}" and tagged`, () => {
+    assert.equal(detectLanguage(`${title} ${excerpt}`), language);
+    assert.equal(itemLanguage({ title, excerpt }), language);
+    assert.deepEqual(detectTools(`${title} ${excerpt}`), ['Augustus']);
+  });
+}
+
+test('tool names are found however the copy sets them', () => {
+  // Right against the Japanese words around it
+  assert.deepEqual(detectTools('PraetorianのAugustusは公開された'), ['Augustus']);
+  // Full-width Latin letters, as some Japanese outlets set product names
+  assert.deepEqual(detectTools('Praetorianが「Ａｕｇｕｓｔｕｓ」を公開'), ['Augustus']);
+  // A soft hyphen from German hyphenation, and a zero-width space
+  assert.deepEqual(detectTools('Praetorian: das Werkzeug Augus\u00ADtus ist frei verfügbar'), ['Augustus']);
+  assert.deepEqual(detectTools('Praetorianがオープンソースの Augustus\u200Bを公開'), ['Augustus']);
+  // Still whole words in German compounds
+  assert.deepEqual(detectTools('Praetorian: Augustusstraße, Augustusbrücke'), []);
+});
+
+test('the tool name alone doesn\'t make a foreign text English', () => {
+  assert.equal(detectLanguage('Augustus Augustus Augustus: Praetorian veröffentlicht neues Werkzeug für Sicherheitsteams'), 'de');
+  assert.equal(detectLanguage('Augustus'), null);
+});
+
+test('foreign items are kept, sectioned, or left out by policy, and each mode labels them', () => {
+  const items = () => Object.entries(SNIPPETS).map(([language, snippet]) => digestItem({ ...snippet, url: `https://example.com/${language}` }));
+
+  const included = applyLanguagePolicy(items(), 'include', 'en');
+  assert.deepEqual(included.items.map(item => item.language), ['de', 'ja', 'fr', 'en']);
+  assert.deepEqual([included.foreign, included.excluded], [3, 0]);
+
+  const sectioned = applyLanguagePolicy(items(), 'section', 'en');
+  assert.equal(sectioned.items.length, 4);
+
+  const excluded = applyLanguagePolicy(items(), 'exclude', 'en');
+  assert.deepEqual(excluded.items.map(item => item.url), ['https://example.com/en']);
+  assert.deepEqual([excluded.foreign, excluded.excluded], [3, 3]);
+
+  // A digest written in German keeps the German item and drops the rest
+  assert.deepEqual(applyLanguagePolicy(items(), 'exclude', 'de').items.map(item => item.language), ['de']);
+  assert.throws(() => applyLanguagePolicy(items(), 'translate'), /Unknown FOREIGN_LANGUAGE_ITEMS "translate"/);
+});
@@ -0,0 +1,70 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { trackerItem } from './helpers.js';
+import { escapeMarkdown } from '../utils/markdown.js';
+import { renderIssueBody } from '../utils/github-issue.js';
+import { buildRollup, renderRollupMarkdown } from '../utils/rollup.js';
+import { loadDigestTemplate } from '../utils/digest-template.js';
+import { buildDigest } from '../utils/digest-json.js';
+
+const url = 'https://www.darkreading.com/application-security/praetorian-brutus';
+
+const HOSTILE_TITLES = [
+  'Brutus [Review] | Top 10 * Best Tools',
+  'Click here](http://evil.example)',
+  '[Brutus](http://evil.example) is great',
+  'Brutus ](http://evil.example) [x',
+  '# Praetorian releases _Brutus_ with `code`',
+  '- [x] Done <img src=x onerror=alert(1)>',
+  '1. Brutus\n## Fake heading\n[link](http://evil.example)',
+  'Back\\slash ](http://evil.example)',
+];
+
+// A markdown link at the start of `line`: its text's end is the first
+// unescaped "]", so the target that follows is the one a renderer uses
+const LINK_TARGET = /\[(?:\\.|[^\\\]])*\]\(([^)]*)\)/;
+
+test('escapeMarkdown escapes the characters markdown would interpret', () => {
+  assert.equal(escapeMarkdown('Brutus [Review] | Top 10 * Best'), 'Brutus \\[Review\\] \\| Top 10 \\* Best');
+  assert.equal(escapeMarkdown('](http://evil.example)'), '\\](http://evil.example)');
+  assert.equal(escapeMarkdown('_a_ `b` #1 <i>'), '\\_a\\_ \\`b\\` \\#1 \\<i\\>');
+  assert.equal(escapeMarkdown('a\\b'), 'a\\\\b');
+  assert.equal(escapeMarkdown('- item'), '\\- item');
+  assert.equal(escapeMarkdown('1. item'), '1\\. item');
+  assert.equal(escapeMarkdown('line one\n\n## two'), 'line one \\#\\# two');
+  assert.equal(escapeMarkdown(null), '');
+});
+
+for (const title of HOSTILE_TITLES) {
+  test(`a hostile title can't retarget or break the issue: ${JSON.stringify(title)}`, () => {
+    const item = trackerItem({ title, source: 'Evil | Source ](http://evil.example)' });
+    const { body } = renderIssueBody([item], { maxItems: 0 });
+    const lines = body.split('\n');
+
+    const heading = lines.find(line => line.startsWith('### '));
+    assert.equal(heading.match(LINK_TARGET)[1], url, heading);
+    assert.ok(!lines.some(line => /^#{1,2} (Fake|Praetorian)/.test(line)), 'the title started a heading');
+    // The title and source stay on their own lines
+    for (const line of lines.filter(line => line.includes('evil.example'))) {
+      assert.ok(line.startsWith('### ') || line.startsWith('**'), line);
+    }
+
+    // The Summary table keeps its two columns
+    for (const row of lines.filter(line => line.startsWith('| '))) {
+      assert.equal(row.split(/(?<!\\)\|/).length, 4, row);
+    }
+  });
+}
+
+test('the rollup and the built-in template escape hostile titles too', async () => {
+  const items = HOSTILE_TITLES.map((title, i) => trackerItem({
+    id: `cov-${i}`, title, url: `${url}-${i}`, status: 'sent', last_sent_at: '2026-02-16T13:00:00Z',
+  }));
+  const rollup = renderRollupMarkdown(buildRollup(items, '2026-02-16', '2026-02-22'));
+  const template = (await loadDigestTemplate()).render(buildDigest(items));
+  for (const markdown of [rollup, template]) {
+    const headings = markdown.split('\n').filter(line => line.startsWith('### ['));
+    assert.equal(headings.length, HOSTILE_TITLES.length);
+    headings.forEach(line => assert.match(line.match(LINK_TARGET)[1], /^https:\/\/www\.darkreading\.com\//, line));
+  }
+});
@@ -0,0 +1,79 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { mockClient } from './helpers.js';
+import { checkGoogleNews } from '../monitors/google-news.js';
+import { checkHackerNews } from '../monitors/hacker-news.js';
+import { checkMastodon } from '../monitors/mastodon.js';
+import { checkNewsApi } from '../monitors/news-api.js';
+
+const since = new Date('2026-02-01T00:00:00Z');
+const date = '2026-02-16T12:00:00.000Z';
+
+// What a search for "Brutus" finds besides our tool
+const ARTICLES = [
+  {
+    url: 'https://www.theguardian.com/stage/2026/feb/16/julius-caesar-globe-review',
+    title: 'Et tu, Brutus? Julius Caesar returns to the Globe',
+    text: 'Shakespeare\'s tragedy of Brutus, Cassius and the conspirators opens the season at the Globe.',
+  },
+  {
+    url: 'https://www.localnews.example/2026/02/16/brutus-the-dog-rescued',
+    title: 'Brutus the dog rescued after three days in a storm drain',
+    text: 'Firefighters freed Brutus, a six-year-old boxer, on Monday morning.',
+  },
+  {
+    url: 'https://www.darkreading.com/application-security/praetorian-brutus',
+    title: 'Praetorian\'s Brutus speeds up credential testing',
+    text: 'Brutus is a new open-source tool from Praetorian for testing credentials at scale.',
+  },
+];
+const EXPECTED = [[], [], ['Brutus']];
+
+function assertTagged(items) {
+  const byUrl = new Map(items.map(item => [item.url, item]));
+  ARTICLES.forEach((article, i) => {
+    const item = byUrl.get(article.url);
+    assert.ok(item, `${article.url} wasn't returned`);
+    assert.deepEqual(item.toolsMentioned, EXPECTED[i], article.title);
+    assert.equal(item.untagged, EXPECTED[i].length === 0, article.title);
+  });
+}
+
+test('Hacker News tags stories by their titles, not by the query that found them', async () => {
+  const hits = ARTICLES.map((article, i) => ({ objectID: String(i), title: article.title, url: article.url, points: 50, created_at: date }));
+  const client = mockClient([[200, { hits, nbPages: 1 }], [200, { hits, nbPages: 1 }]]);
+  assertTagged(await checkHackerNews(since, [], { tools: ['Brutus'], client }));
+});
+
+test('a news API tags articles by their titles and descriptions', async () => {
+  const articles = ARTICLES.map(article => ({ title: article.title, url: article.url, publisher: 'Example', publishedAt: date, description: article.text }));
+  const provider = {
+    name: 'Test News',
+    requestsPerSecond: 100,
+    client: null,
+    search: async () => articles,
+  };
+  assertTagged(await checkNewsApi(since, [], { tools: ['Brutus'], provider, sleep: async () => {} }));
+});
+
+test('Mastodon tags statuses by their text', async () => {
+  const statuses = ARTICLES.map((article, i) => ({
+    id: String(100 - i),
+    url: article.url,
+    uri: article.url,
+    created_at: date,
+    content: `<p>${article.title}</p><p>${article.text}</p>`,
+    account: { display_name: 'Someone', url: 'https://infosec.exchange/@someone' },
+  }));
+  const client = mockClient([[200, { statuses }], [200, { statuses: [] }]]);
+  assertTagged(await checkMastodon(since, [], { instance: 'https://infosec.exchange', accessToken: 't', hashtags: [], tools: ['Brutus'], client }));
+});
+
+test('Google News tags results by their headlines', async () => {
+  const rss = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>"Brutus" - Google News</title>
+${ARTICLES.map(article => `<item><title>${article.title.replace(/'/g, '&apos;')} - Example</title><link>${article.url}</link><pubDate>Mon, 16 Feb 2026 12:00:00 GMT</pubDate></item>`).join('\n')}
+</channel></rss>`;
+  const client = mockClient([[200, rss]]);
+  assertTagged(await checkGoogleNews(since, [], { tools: ['Brutus'], client, delayMs: 0, sleep: async () => {} }));
+});
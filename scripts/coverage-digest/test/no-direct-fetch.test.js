@@ -0,0 +1,57 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { readdir, readFile } from 'fs/promises';
+import { dirname, join, relative } from 'path';
+import { fileURLToPath } from 'url';
+
+const ROOT = join(dirname(fileURLToPath(import.meta.url)), '..');
+const DIRS = [ROOT, join(ROOT, '..', 'coverage-tracker')];
+const SKIP = new Set(['node_modules', 'test', 'state']);
+// The one module allowed to open connections
+const CLIENT = join(ROOT, 'utils', 'http-client.js');
+
+async function sourceFiles(dir) {
+  const files = [];
+  for (const entry of await readdir(dir, { withFileTypes: true })) {
+    if (SKIP.has(entry.name)) continue;
+    const path = join(dir, entry.name);
+    if (entry.isDirectory() && dir !== DIRS[1]) files.push(...await sourceFiles(path));
+    else if (entry.isFile() && entry.name.endsWith('.js')) files.push(path);
+  }
+  return files;
+}
+
+// A call to fetch() that isn't a method (client.fetch) and isn't a fetch
+// the file declares itself (a parameter, variable, or function)
+function directFetches(source) {
+  const code = source
+    .replace(/\/\*[\s\S]*?\*\//g, '')
+    .replace(/`(?:\\.|[^`\\])*`|'(?:\\.|[^'\\\n])*'|"(?:\\.|[^"\\\n])*"/g, "''")
+    .replace(/\/\/.*$/gm, '');
+  const declared = /\b(?:function\s+fetch|(?:const|let|var)\s+fetch)\b|[(,{]\s*fetch\s*(?:[,)=]|$)/m.test(code);
+  const calls = code.match(/(?<![.\w$])(?:globalThis\.|window\.)?fetch\s*\(/g) || [];
+  return declared ? calls.filter(call => call.includes('.')) : calls;
+}
+
+test('every outbound request goes through utils/http-client.js', async () => {
+  const offenders = [];
+  for (const dir of DIRS) {
+    for (const file of await sourceFiles(dir)) {
+      if (file === CLIENT) continue;
+      const source = await readFile(file, 'utf-8');
+      const name = relative(ROOT, file);
+      if (directFetches(source).length > 0) offenders.push(`${name}: calls the global fetch()`);
+      if (/\bhttps?\.(?:request|get)\s*\(/.test(source)) offenders.push(`${name}: calls http(s).request/get`);
+      if (/from\s+['"](?:node-fetch|undici|axios|got|needle|superagent)['"]/.test(source)) offenders.push(`${name}: imports an HTTP library`);
+      if (/\.parseURL\s*\(/.test(source)) offenders.push(`${name}: lets rss-parser fetch (parseURL) instead of parseString`);
+    }
+  }
+  assert.deepEqual(offenders, [], 'use clientFor(name).fetch() so proxies, timeouts, retries, and redirect rules apply');
+});
+
+test('the check catches a direct fetch', () => {
+  assert.equal(directFetches('const res = await fetch(url);').length, 1);
+  assert.equal(directFetches('await globalThis.fetch(url);').length, 1);
+  assert.equal(directFetches('const res = await client.fetch(url);').length, 0);
+  assert.equal(directFetches('async function resolve(fetch, link) { return fetch(link); }').length, 0);
+});
@@ -0,0 +1,87 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { digestItem } from './helpers.js';
+import { BUILT_IN_PUBLISHERS, createNormalizer, normalizeSources, titleCaseDomain } from '../utils/publishers.js';
+
+const normalizer = createNormalizer(BUILT_IN_PUBLISHERS);
+
+test('a subdomain or section of a known site gets the most specific name', () => {
+  const cases = [
+    ['https://blog.praetorian.com/introducing-augustus/', 'Praetorian Blog'],
+    ['https://www.praetorian.com/blog/introducing-augustus/', 'Praetorian Blog'],
+    ['https://praetorian.com/blog', 'Praetorian Blog'],
+    ['https://www.praetorian.com/news/augustus-release', 'Praetorian'],
+    // A path that only starts like the section's
+    ['https://www.praetorian.com/blogroll', 'Praetorian'],
+    ['https://careers.praetorian.com/', 'Praetorian'],
+    ['https://feeds.helpnetsecurity.com/2026/02/16/brutus/', 'Help Net Security'],
+    ['https://WWW.DarkReading.com/application-security/praetorian-brutus', 'Dark Reading'],
+  ];
+  for (const [url, expected] of cases) {
+    assert.equal(normalizer.nameForUrl(url), expected, url);
+    assert.equal(normalizer.normalize('Some Feed Title', url), expected, url);
+  }
+  // Only whole labels: a look-alike domain isn't the site
+  assert.equal(normalizer.nameForUrl('https://notpraetorian.com/blog/x'), null);
+  assert.equal(normalizer.nameForUrl('https://praetorian.com.evil.example/blog/x'), null);
+});
+
+test('ccTLD sites are named by the label before the public suffix', () => {
+  const cases = [
+    // Known sites
+    ['https://www.theregister.co.uk/2026/02/16/brutus/', '', 'The Register'],
+    ['https://www.wired.co.uk/article/praetorian', '', 'Wired'],
+    ['https://www.heise.de/news/Augustus-123.html', '', 'heise online'],
+    // Unknown ones, from the URL or from a source that's a domain
+    ['https://www.abc.net.au/news/2026-02-16/augustus', '', 'Abc'],
+    ['https://news.bbc.co.uk/2/hi/technology/1.stm', '', 'Bbc'],
+    ['https://www.itmedia.co.jp/news/articles/2602/16/news001.html', '', 'Itmedia'],
+    ['https://www.lemondeinformatique.fr/actualites/augustus', '', 'Lemondeinformatique'],
+    ['https://example.com/x', 'golem.de', 'Golem'],
+    ['https://example.com/x', 'https://www.my-cyber-news.com.br/feed', 'My Cyber News'],
+    // A two-label domain under a ccTLD is its own registrable domain
+    ['https://cyber.co/augustus', '', 'Cyber'],
+  ];
+  for (const [url, source, expected] of cases) {
+    assert.equal(normalizer.normalize(source, url), expected, `${source} ${url}`);
+  }
+  assert.equal(titleCaseDomain('www.security-insider.de'), 'Security Insider');
+  assert.equal(titleCaseDomain('security.gov.uk'), 'Security');
+});
+
+test('names, aliases and domains given as the source resolve to the publisher', () => {
+  for (const source of ['Help Net Security', 'HNS RSS', 'helpnetsecurity.com', 'HelpNet  Security', 'help-net-security']) {
+    assert.equal(normalizer.normalize(source, ''), 'Help Net Security', source);
+  }
+  assert.equal(normalizer.nameForSource('The Record by Recorded Future'), 'The Record');
+  assert.equal(normalizer.nameForSource('Unknown Weekly'), null);
+  // Neither known nor a domain: kept as the source gave it
+  assert.equal(normalizer.normalize('  Unknown Weekly ', ''), 'Unknown Weekly');
+});
+
+test('configured publishers come before the built-in ones', () => {
+  const custom = createNormalizer([
+    { name: 'heise Security', domains: ['heise.de/security'] },
+    { name: 'The Reg', domains: ['theregister.com'], aliases: ['El Reg'] },
+    ...BUILT_IN_PUBLISHERS,
+  ]);
+  assert.equal(custom.normalize('', 'https://www.heise.de/security/artikel/1'), 'heise Security');
+  assert.equal(custom.normalize('', 'https://www.heise.de/news/1'), 'heise online');
+  // Same domain in both: the configured entry wins the tie
+  assert.equal(custom.normalize('', 'https://www.theregister.com/2026/02/16/brutus/'), 'The Reg');
+  assert.equal(custom.normalize('El Reg', ''), 'The Reg');
+});
+
+test('normalizeSources renames items and keeps what their source called them', () => {
+  const items = [
+    digestItem({ source: 'HNS RSS', url: 'https://www.helpnetsecurity.com/2026/02/16/brutus/' }),
+    digestItem({ source: 'Dark Reading', url: 'https://www.darkreading.com/x' }),
+    digestItem({ source: '', url: 'https://blog.praetorian.com/augustus' }),
+  ];
+  normalizeSources(items, normalizer);
+  assert.deepEqual(items.map(item => [item.source, item.sourceOriginal]), [
+    ['Help Net Security', 'HNS RSS'],
+    ['Dark Reading', undefined],
+    ['Praetorian Blog', ''],
+  ]);
+});
@@ -0,0 +1,79 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { mockClient } from './helpers.js';
+import { createQueryMemo } from '../utils/query-memo.js';
+import { fetchSources, formatRunSummary } from '../monitors/registry.js';
+import { checkHackerNews } from '../monitors/hacker-news.js';
+
+const since = new Date('2026-02-15T00:00:00Z');
+
+test('requests for the same query share one call, in flight or after', async () => {
+  const memo = createQueryMemo();
+  let calls = 0;
+  const run = async () => {
+    calls++;
+    await new Promise(resolve => setTimeout(resolve, 5));
+    return ['result'];
+  };
+  const [a, b] = await Promise.all([memo('rss', 'feed', since, run), memo('rss', 'feed', since, run)]);
+  const c = await memo('rss', 'feed', new Date(since), run);
+  assert.equal(calls, 1);
+  assert.equal(a, b);
+  assert.equal(a, c);
+  assert.deepEqual(memo.stats('rss'), { requested: 3, saved: 2 });
+});
+
+test('another source, query, or window is another call', async () => {
+  const memo = createQueryMemo();
+  let calls = 0;
+  const run = async () => ++calls;
+  await memo('rss', 'feed', since, run);
+  await memo('google-news', 'feed', since, run);
+  await memo('rss', 'other feed', since, run);
+  await memo('rss', 'feed', new Date('2026-02-16T00:00:00Z'), run);
+  await memo('rss', 'feed', null, run);
+  assert.equal(calls, 5);
+  assert.deepEqual(memo.stats(), { requested: 5, saved: 0 });
+});
+
+test('a failed query is asked again', async () => {
+  const memo = createQueryMemo();
+  let calls = 0;
+  const run = async () => {
+    if (++calls === 1) throw new Error('HTTP 503');
+    return 'ok';
+  };
+  await assert.rejects(memo('mastodon', 'brutus', since, run), /HTTP 503/);
+  assert.equal(await memo('mastodon', 'brutus', since, run), 'ok');
+  assert.equal(calls, 2);
+});
+
+test('a retried source reuses the answers it already had, and the summary counts them', async () => {
+  let searches = 0;
+  let attempts = 0;
+  const source = {
+    name: 'flaky',
+    async fetch(since, failures, options, { memo }) {
+      await memo('flaky', 'praetorian', since, async () => ++searches);
+      if (++attempts === 1) throw Object.assign(new Error('socket hang up'), { code: 'ECONNRESET' });
+      return [];
+    },
+  };
+  const { summary } = await fetchSources(since, [], {}, [source]);
+  assert.equal(attempts, 2);
+  assert.equal(searches, 1);
+  assert.equal(summary[0].requestsSaved, 1);
+  assert.match(formatRunSummary(summary), /1 repeated request\(s\) saved/);
+});
+
+test('a Hacker News search already made in the run isn\'t made again', async () => {
+  const memo = createQueryMemo();
+  const hits = { hits: [{ objectID: '1', title: 'Brutus: a credential tester', url: 'https://example.com/brutus', points: 50, created_at: '2026-02-16T00:00:00Z' }], nbPages: 1 };
+  const client = mockClient([[200, hits], [200, { hits: [], nbPages: 1 }]]);
+  const options = { tools: ['Brutus'], minPoints: 10, client, memo };
+  const first = await checkHackerNews(since, [], options);
+  const second = await checkHackerNews(since, [], options);
+  assert.equal(client.requests.length, 2);
+  assert.deepEqual(second, first);
+  assert.deepEqual(memo.stats('hacker-news'), { requested: 4, saved: 2 });
+});
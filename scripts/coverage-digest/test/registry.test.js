@@ -0,0 +1,120 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import http from 'http';
+import { once } from 'events';
+import { mockClient } from './helpers.js';
+import { config } from '../config.js';
+import { fetchSources } from '../monitors/registry.js';
+import { createHttpClient } from '../utils/http-client.js';
+import { checkHackerNews } from '../monitors/hacker-news.js';
+import { checkMastodon } from '../monitors/mastodon.js';
+import { checkNewsApi } from '../monitors/news-api.js';
+import { checkPodcasts } from '../monitors/podcasts.js';
+
+const since = new Date('2026-02-15T00:00:00Z');
+const sleep = ms => new Promise(resolve => setTimeout(resolve, ms));
+
+function setSourceConfig(t, values) {
+  const saved = { ...config.sources };
+  Object.assign(config.sources, values);
+  t.after(() => Object.assign(config.sources, saved));
+}
+
+test('a free slot takes the next source without waiting for the slow one', async t => {
+  setSourceConfig(t, { concurrency: 2 });
+  const events = [];
+  const source = (name, ms) => ({
+    name,
+    async fetch() {
+      events.push(`${name} start`);
+      await sleep(ms);
+      events.push(`${name} end`);
+      return [];
+    },
+  });
+  await fetchSources(since, [], {}, [source('a-slow', 150), source('b-fast', 10), source('c-fast', 10), source('d-fast', 10)]);
+  assert.deepEqual(events.slice(0, 2), ['a-slow start', 'b-fast start']);
+  assert.ok(events.indexOf('d-fast end') < events.indexOf('a-slow end'), events.join(', '));
+  // Never more than two at once
+  let running = 0;
+  for (const event of events) {
+    running += event.endsWith('start') ? 1 : -1;
+    assert.ok(running <= 2, events.join(', '));
+  }
+});
+
+test('a source that times out has its requests torn down, and what it reports late is dropped', async t => {
+  setSourceConfig(t, { concurrency: 4, timeoutMs: 50 });
+  let closed;
+  const gone = new Promise(resolve => { closed = resolve; });
+  const server = http.createServer(req => req.socket.on('close', closed));
+  server.listen(0, '127.0.0.1');
+  await once(server, 'listening');
+  t.after(() => server.close());
+  t.mock.method(console, 'warn', () => {});
+
+  const client = createHttpClient({ timeoutMs: 60000 });
+  let late;
+  const source = {
+    name: 'hangs',
+    async fetch(since, failures, options, { signal }) {
+      try {
+        await client.fetch(`http://127.0.0.1:${server.address().port}/`, { signal, retry: false });
+      } catch (err) {
+        failures.push({ source: 'hangs', error: err.message });
+        late = err;
+      }
+      return [];
+    },
+  };
+  const failures = [];
+  const { summary } = await fetchSources(since, failures, {}, [source]);
+  await gone;
+  assert.equal(summary[0].status, 'timed out');
+  assert.deepEqual(failures, [{ source: 'hangs', error: 'timed out after 50ms' }]);
+  assert.ok(late, 'the request was aborted');
+});
+
+test('the built-in sources pass the run\'s signal to every request', async () => {
+  const { signal } = new AbortController();
+  const responses = body => Array.from({ length: 10 }, () => [200, body]);
+  const checks = [
+    client => checkHackerNews(since, [], { tools: ['Brutus'], client, signal }),
+    client => checkMastodon(since, [], { instance: 'https://infosec.exchange', accessToken: 't', hashtags: ['infosec'], tools: ['Brutus'], client, signal }),
+    client => checkPodcasts(since, [], { tools: ['Brutus'], directory: 'itunes', client, signal }),
+    client => checkNewsApi(since, [], {
+      tools: ['Brutus'],
+      sleep: async () => {},
+      signal,
+      provider: {
+        name: 'Test News',
+        requestsPerSecond: 100,
+        client,
+        search: async (query, since, fetch) => (await fetch('https://news.example/search')).json(),
+      },
+    }),
+  ];
+  const bodies = [{ hits: [], nbPages: 1 }, { statuses: [] }, { results: [] }, []];
+  for (const [i, check] of checks.entries()) {
+    const client = mockClient(responses(bodies[i]));
+    await check(client);
+    assert.ok(client.requests.length > 0);
+    for (const request of client.requests) assert.equal(request.init.signal, signal, request.url);
+  }
+});
+
+test('Mastodon reads back to the last recorded run, or the lookback before one', async () => {
+  const lastRun = new Date('2026-02-16T06:00:00Z');
+  const status = (id, createdAt) => ({
+    id, url: `https://infosec.exchange/@alice/${id}`, created_at: createdAt, content: '<p>Trying Brutus today</p>',
+    account: { display_name: 'Alice', acct: 'alice', url: 'https://infosec.exchange/@alice' },
+  });
+  const statuses = [status('2', '2026-02-16T09:00:00Z'), status('1', '2026-02-15T12:00:00Z')];
+  const check = async options => {
+    const client = mockClient([[200, { statuses }], [200, { statuses: [] }]]);
+    const items = await checkMastodon(since, [], { instance: 'https://infosec.exchange', accessToken: 't', hashtags: [], tools: ['Brutus'], client, ...options });
+    return items.map(item => item.url);
+  };
+  assert.deepEqual(await check({ lastRun }), ['https://infosec.exchange/@alice/2']);
+  assert.deepEqual(await check({}), ['https://infosec.exchange/@alice/2', 'https://infosec.exchange/@alice/1']);
+});
@@ -0,0 +1,152 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import http from 'http';
+import { once } from 'events';
+import { readFile } from 'fs/promises';
+import { dirname, join } from 'path';
+import { fileURLToPath } from 'url';
+import { tempDir } from './helpers.js';
+import { config } from '../config.js';
+import { checkRssFeeds, loadFeedValidators, parseFeedDate, saveFeedValidators } from '../monitors/rss-feeds.js';
+
+const FIXTURES = join(dirname(fileURLToPath(import.meta.url)), 'fixtures', 'feeds');
+const since = new Date('2026-02-01T00:00:00Z');
+
+// Serves the fixture feeds, each with an ETag and Last-Modified, answering
+// 304 to a request that already has them. Requests are kept in `requests`.
+async function serveFeeds(t) {
+  const requests = [];
+  const server = http.createServer(async (req, res) => {
+    requests.push({ url: req.url, headers: req.headers });
+    const etag = `"${req.url.slice(1)}-v1"`;
+    const lastModified = 'Mon, 16 Feb 2026 15:00:00 GMT';
+    if (req.headers['if-none-match'] === etag) {
+      res.writeHead(304).end();
+      return;
+    }
+    try {
+      const body = await readFile(join(FIXTURES, req.url.slice(1)));
+      res.writeHead(200, { 'Content-Type': 'application/xml', ETag: etag, 'Last-Modified': lastModified }).end(body);
+    } catch {
+      res.writeHead(404).end();
+    }
+  });
+  server.listen(0, '127.0.0.1');
+  await once(server, 'listening');
+  t.after(() => server.close());
+  const base = `http://127.0.0.1:${server.address().port}`;
+
+  const saved = { rssFeeds: config.rssFeeds, googleAlertsFeeds: config.googleAlertsFeeds };
+  config.rssFeeds = [
+    { name: 'Dark Reading', url: `${base}/darkreading.xml` },
+    { name: 'SecurityWeek', url: `${base}/securityweek.xml` },
+    // No name: the source is the feed's own title
+    { url: `${base}/helpnetsecurity.atom` },
+    { name: 'The Register', url: `${base}/truncated.xml` },
+  ];
+  config.googleAlertsFeeds = [];
+  t.after(() => Object.assign(config, saved));
+  return { requests };
+}
+
+const byUrl = items => Object.fromEntries(items.map(item => [item.url, item]));
+
+test('fixture feeds are parsed, and one malformed feed doesn\'t fail the rest', async t => {
+  await serveFeeds(t);
+  const failures = [];
+  t.mock.method(console, 'warn', () => {});
+  const items = byUrl(await checkRssFeeds(since, failures));
+
+  assert.deepEqual(failures.map(failure => failure.source), ['The Register']);
+  assert.deepEqual(Object.keys(items).sort(), [
+    'https://www.darkreading.com/application-security/praetorian-brutus-credentials',
+    'https://www.helpnetsecurity.com/2026/02/15/nerva-praetorian/',
+    'https://www.helpnetsecurity.com/2026/02/16/brutus-praetorian/',
+    'https://www.securityweek.com/augustus-llm-prompt-injection/',
+    'https://www.securityweek.com/praetorian-julius/',
+    'https://www.securityweek.com/praetorian-talk/',
+  ]);
+
+  // RSS 2.0 with CDATA: the title is unwrapped and its whitespace folded,
+  // the excerpt has no markup
+  const brutus = items['https://www.darkreading.com/application-security/praetorian-brutus-credentials'];
+  assert.equal(brutus.title, 'Praetorian\'s Brutus Tests Credentials <at> Scale & Speed');
+  assert.equal(brutus.source, 'Dark Reading');
+  assert.equal(brutus.date, '2026-02-16T14:00:00.000Z');
+  assert.equal(brutus.raw.guid, 'dr-2026-0216-brutus');
+  assert.deepEqual(brutus.toolsMentioned, ['Brutus']);
+  assert.equal(brutus.excerpt, 'Praetorian released Brutus, an open-source credential tester.');
+
+  // No GUIDs, and pubDates that break RFC 1123
+  const augustus = items['https://www.securityweek.com/augustus-llm-prompt-injection/'];
+  assert.equal(augustus.raw.guid, augustus.url);
+  assert.equal(augustus.date, '2026-02-16T12:30:00.000Z');
+  assert.ok(!augustus.excerpt.includes('<'), augustus.excerpt);
+  assert.equal(items['https://www.securityweek.com/praetorian-julius/'].date, '2026-02-16T09:00:00.000Z');
+  const undated = items['https://www.securityweek.com/praetorian-talk/'];
+  assert.equal(undated.undated, true);
+
+  // Atom: the source is the feed's title, the id is the GUID, and an
+  // entry without <published> is dated by <updated>
+  const atom = items['https://www.helpnetsecurity.com/2026/02/16/brutus-praetorian/'];
+  assert.equal(atom.source, 'Help Net Security');
+  assert.equal(atom.date, '2026-02-16T08:15:00.000Z');
+  assert.equal(atom.raw.guid, 'https://www.helpnetsecurity.com/?p=301234');
+  assert.equal(items['https://www.helpnetsecurity.com/2026/02/15/nerva-praetorian/'].date, '2026-02-15T12:00:00.000Z');
+});
+
+test('stored validators make the next fetch conditional, and unchanged feeds cost nothing', async t => {
+  const { requests } = await serveFeeds(t);
+  t.mock.method(console, 'warn', () => {});
+  t.mock.method(console, 'log', () => {});
+  const validators = {};
+
+  const first = await checkRssFeeds(since, [], { validators });
+  assert.ok(first.length > 0);
+  assert.equal(Object.keys(validators).length, 3, 'the malformed feed stores nothing');
+  for (const [url, stored] of Object.entries(validators)) {
+    assert.deepEqual(stored, { etag: `"${new URL(url).pathname.slice(1)}-v1"`, last_modified: 'Mon, 16 Feb 2026 15:00:00 GMT' });
+  }
+  assert.ok(requests.every(request => !request.headers['if-none-match']));
+
+  // Saved and loaded again, as between runs
+  const path = join(await tempDir(t), 'feed-validators.json');
+  await saveFeedValidators(validators, path);
+  const loaded = await loadFeedValidators(path);
+  assert.deepEqual(loaded, validators);
+
+  requests.length = 0;
+  const failures = [];
+  const second = await checkRssFeeds(since, failures, { validators: loaded });
+  assert.deepEqual(second, []);
+  assert.deepEqual(failures.map(failure => failure.source), ['The Register']);
+  const conditional = requests.filter(request => request.headers['if-none-match']);
+  assert.equal(conditional.length, 3);
+  for (const request of conditional) {
+    assert.equal(request.headers['if-modified-since'], 'Mon, 16 Feb 2026 15:00:00 GMT');
+  }
+});
+
+test('feed validators start empty when none are stored', async t => {
+  assert.deepEqual(await loadFeedValidators(join(await tempDir(t), 'missing.json')), {});
+});
+
+test('parseFeedDate reads the dates real feeds send', () => {
+  const cases = [
+    ['Mon, 16 Feb 2026 14:00:00 GMT', '2026-02-16T14:00:00.000Z'],
+    ['Monday, 16 February 2026 14:30:00 CEST', '2026-02-16T12:30:00.000Z'],
+    ['16 Feb 26 10:00 +0100', '2026-02-16T09:00:00.000Z'],
+    ['Mon, 16 Feb 2026 09:00:00 -0500', '2026-02-16T14:00:00.000Z'],
+    ['Mon, 16 Feb 2026', '2026-02-16T00:00:00.000Z'],
+    ['2026-02-16T09:15:00+01:00', '2026-02-16T08:15:00.000Z'],
+    ['2026-02-16 09:15:00', '2026-02-16T09:15:00.000Z'],
+    ['February 13th, 2026 at 3:45 PM', '2026-02-13T15:45:00.000Z'],
+    ['13.02.2026', '2026-02-13T00:00:00.000Z'],
+  ];
+  for (const [value, expected] of cases) {
+    assert.equal(parseFeedDate(value)?.toISOString(), expected, value);
+  }
+  for (const value of ['sometime last week', '', null, undefined]) {
+    assert.equal(parseFeedDate(value), null, String(value));
+  }
+});
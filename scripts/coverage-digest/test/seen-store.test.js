@@ -0,0 +1,77 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { join } from 'path';
+import { digestItem, tempDir } from './helpers.js';
+import { config } from '../config.js';
+import { isSuppressed, openSeenStore } from '../utils/seen-store.js';
+import { filterNewItems, recordRun } from '../utils/state-manager.js';
+
+const DAY = 86400000;
+const reportedAt = new Date('2026-02-16T13:00:00.000Z');
+
+// A file-backed store in a temporary directory, with the given windows
+async function useStore(t, seenStore) {
+  const dir = await tempDir(t);
+  const saved = { paths: { ...config.paths }, seenStore: { ...config.seenStore } };
+  Object.assign(config.paths, { seenStore: join(dir, 'seen-items.json'), digestState: join(dir, 'digest-state.json') });
+  Object.assign(config.seenStore, { backend: 'file', ...seenStore });
+  t.after(() => {
+    Object.assign(config.paths, saved.paths);
+    Object.assign(config.seenStore, saved.seenStore);
+  });
+}
+
+test('an item last reported exactly suppressDays ago may come back; a moment less and it can\'t', () => {
+  const entry = { last_reported_at: reportedAt.toISOString() };
+  const edge = new Date(reportedAt.getTime() + 90 * DAY);
+  assert.equal(isSuppressed(entry, edge, 90), false);
+  assert.equal(isSuppressed(entry, new Date(edge.getTime() - 1), 90), true);
+  assert.equal(isSuppressed(entry, new Date(edge.getTime() + 1), 90), false);
+  // 0: suppressed for good
+  assert.equal(isSuppressed(entry, new Date(reportedAt.getTime() + 3650 * DAY), 0), true);
+});
+
+test('filterNewItems lets a sent item resurface at the window edge, marked previously covered', async t => {
+  await useStore(t, { suppressDays: 90, retentionDays: 0 });
+  await recordRun([digestItem()], reportedAt);
+  const edge = new Date(reportedAt.getTime() + 90 * DAY);
+
+  assert.deepEqual(await filterNewItems([digestItem()], { now: new Date(edge.getTime() - 1) }), []);
+
+  const [item] = await filterNewItems([digestItem()], { now: edge });
+  assert.equal(item.previouslyCovered, reportedAt.toISOString());
+
+  // A new item alongside isn't marked
+  const [fresh] = await filterNewItems([digestItem({ url: 'https://example.com/new' })], { now: edge });
+  assert.equal(fresh.previouslyCovered, undefined);
+});
+
+test('reporting an item again restarts its window', async t => {
+  await useStore(t, { suppressDays: 90, retentionDays: 0 });
+  await recordRun([digestItem()], reportedAt);
+  const again = new Date(reportedAt.getTime() + 90 * DAY);
+  await recordRun([digestItem()], again);
+
+  assert.deepEqual(await filterNewItems([digestItem()], { now: new Date(again.getTime() + 90 * DAY - 1) }), []);
+  const store = await openSeenStore();
+  assert.equal(store.get(digestItem()).last_reported_at, again.toISOString());
+  assert.equal(store.get(digestItem()).first_seen, reportedAt.toISOString());
+});
+
+test('allowResurface brings back a suppressed item, still marked', async t => {
+  await useStore(t, { suppressDays: 0, retentionDays: 0 });
+  await recordRun([digestItem()], reportedAt);
+  const now = new Date(reportedAt.getTime() + DAY);
+  assert.deepEqual(await filterNewItems([digestItem()], { now }), []);
+  const [item] = await filterNewItems([digestItem()], { now, allowResurface: true });
+  assert.equal(item.previouslyCovered, reportedAt.toISOString());
+});
+
+test('pruning drops entries last reported more than retentionDays ago, not those exactly that old', async () => {
+  const store = await openSeenStore({ path: '/nonexistent/seen-items.json', legacyPath: '/nonexistent/digest-state.json', backend: 'file' });
+  store.put([digestItem({ url: 'https://example.com/old' })], new Date(reportedAt.getTime() - 1));
+  store.put([digestItem({ url: 'https://example.com/edge' })], reportedAt);
+  assert.equal(store.prune(new Date(reportedAt.getTime() + 30 * DAY), 30), 1);
+  assert.equal(store.has(digestItem({ url: 'https://example.com/old' })), false);
+  assert.equal(store.has(digestItem({ url: 'https://example.com/edge' })), true);
+});
@@ -0,0 +1,91 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { digestItem, trackerItem } from './helpers.js';
+import { sortItems } from '../utils/sort.js';
+
+// Every "no date" a fetcher hands back
+const ZERO_DATES = [['empty string', ''], ['null', null], ['undefined', undefined], ['zero', 0], ['unparseable', 'not a date'], ['Invalid Date', new Date(NaN)]];
+
+const item = (id, overrides) => trackerItem({ id, url: `https://example.com/${id}`, ...overrides });
+
+const cases = [
+  {
+    name: 'date: newest first',
+    order: 'date',
+    items: [item('a', { date: '2026-02-14' }), item('b', { date: '2026-02-16' }), item('c', { date: '2026-02-15T23:00:00Z' })],
+    expected: ['b', 'c', 'a'],
+  },
+  ...ZERO_DATES.map(([name, date]) => ({
+    name: `date: ${name} sorts after every dated item`,
+    order: 'date',
+    items: [item('undated', { date }), item('old', { date: '2001-01-01' }), item('new', { date: '2026-02-16' })],
+    expected: ['new', 'old', 'undated'],
+  })),
+  {
+    name: 'date: undated items tie-break on URL',
+    order: 'date',
+    items: [item('z', { date: '' }), item('m', { date: null }), item('a', { date: 'garbage' })],
+    expected: ['a', 'm', 'z'],
+  },
+  {
+    name: 'date: same date tie-breaks on the normalized URL',
+    order: 'date',
+    items: [
+      item('b', { url: 'https://Example.com/b?utm_source=x' }),
+      item('a', { url: 'https://example.com/a/' }),
+      item('c', { url: 'http://www.example.com/c' }),
+    ],
+    expected: ['a', 'b', 'c'],
+  },
+  {
+    name: 'source: A-Z, then newest first, undated last within the source',
+    order: 'source',
+    items: [
+      item('wired-undated', { source: 'Wired', date: '' }),
+      item('dr-old', { source: 'dark reading', date: '2026-02-10' }),
+      item('wired-new', { source: 'Wired', date: '2026-02-16' }),
+      item('dr-new', { source: 'Dark Reading', date: '2026-02-16' }),
+      item('no-source', { source: undefined, date: '2026-02-01' }),
+    ],
+    expected: ['no-source', 'dr-new', 'dr-old', 'wired-new', 'wired-undated'],
+  },
+  {
+    name: 'tool: first tool A-Z, untagged last, undated last within the tool',
+    order: 'tool',
+    items: [
+      item('untagged', { tools_mentioned: [], date: '2026-02-16' }),
+      item('brutus-undated', { tools_mentioned: ['Brutus'], date: null }),
+      item('augustus', { tools_mentioned: ['Augustus', 'Brutus'], date: '2026-02-01' }),
+      item('brutus', { tools_mentioned: ['Brutus'], date: '2026-02-02' }),
+      item('untagged-undated', { tools_mentioned: [], date: 0 }),
+    ],
+    expected: ['augustus', 'brutus', 'brutus-undated', 'untagged', 'untagged-undated'],
+  },
+  {
+    name: 'negative coverage comes first, undated or not',
+    order: 'date',
+    items: [item('new', { date: '2026-02-16' }), item('negative-undated', { date: '', sentiment: 'negative' })],
+    expected: ['negative-undated', 'new'],
+  },
+];
+
+for (const { name, order, items, expected } of cases) {
+  test(`sortItems ${name}`, () => {
+    assert.deepEqual(sortItems(items, order).map(item => item.id), expected);
+    // Whatever order the fetchers returned them in
+    assert.deepEqual(sortItems([...items].reverse(), order).map(item => item.id), expected);
+  });
+}
+
+test('sortItems takes digest items (toolsMentioned) as well as tracker items', () => {
+  const items = [
+    digestItem({ url: 'https://example.com/1', date: '', toolsMentioned: ['Brutus'] }),
+    digestItem({ url: 'https://example.com/2', date: '2026-02-16T00:00:00.000Z', toolsMentioned: ['Augustus'] }),
+  ];
+  assert.deepEqual(sortItems(items, 'tool').map(item => item.url), ['https://example.com/2', 'https://example.com/1']);
+  assert.deepEqual(sortItems(items, 'date').map(item => item.url), ['https://example.com/2', 'https://example.com/1']);
+});
+
+test('sortItems rejects an unknown order', () => {
+  assert.throws(() => sortItems([], 'relevance'), /Unknown sort order "relevance"/);
+});
@@ -0,0 +1,98 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { tempDir } from './helpers.js';
+import { openInbox, submissionItem, validateSubmission } from '../utils/submissions.js';
+import { mergeIntoTracker } from '../utils/tracker.js';
+
+const body = { title: 'Praetorian releases Brutus', url: 'https://www.darkreading.com/brutus', source: 'Dark Reading' };
+
+test('a date must be a real day', () => {
+  for (const date of ['2026-13-01', '2026-02-30', '2026-00-10', '2026-2-16', 'February 16']) {
+    assert.deepEqual(validateSubmission({ ...body, date }), ['date must be a real day in YYYY-MM-DD format'], date);
+  }
+  assert.deepEqual(validateSubmission({ ...body, date: '2028-02-29' }), []);
+  assert.deepEqual(validateSubmission(body), []);
+});
+
+test('the file inbox gives back what was added, oldest first, until removed', async t => {
+  const inbox = openInbox({ backend: 'file', path: await tempDir(t) });
+  assert.deepEqual(await inbox.list(), []);
+
+  const first = submissionItem(body, new Date('2026-02-16T09:00:00Z'));
+  const second = submissionItem({ ...body, title: 'Augustus probes LLMs', url: 'https://www.securityweek.com/augustus' }, new Date('2026-02-16T10:00:00Z'));
+  await inbox.add(second);
+  await inbox.add(first);
+  assert.deepEqual(await inbox.list(), [first, second]);
+
+  await inbox.remove([first]);
+  assert.deepEqual(await inbox.list(), [second]);
+  // Removing what's already gone is fine
+  await inbox.remove([first, second]);
+  assert.deepEqual(await inbox.list(), []);
+});
+
+test('a submission added while a run is merging waits for the next run', async t => {
+  const inbox = openInbox({ backend: 'file', path: await tempDir(t) });
+  const tracker = [];
+  await inbox.add(submissionItem(body));
+
+  const taken = await inbox.list();
+  const late = submissionItem({ ...body, title: 'Augustus probes LLMs', url: 'https://www.securityweek.com/augustus' });
+  await inbox.add(late);
+  assert.equal(mergeIntoTracker(tracker, taken), 1);
+  await inbox.remove(taken);
+
+  assert.deepEqual(await inbox.list(), [late]);
+});
+
+test('an entry a crashed run left behind is merged only once', async t => {
+  const inbox = openInbox({ backend: 'file', path: await tempDir(t) });
+  const tracker = [];
+  await inbox.add(submissionItem(body));
+
+  // The run saved the tracker but died before removing the entry
+  mergeIntoTracker(tracker, await inbox.list());
+  assert.equal(mergeIntoTracker(tracker, await inbox.list()), 0);
+  assert.equal(tracker.length, 1);
+  assert.equal(tracker[0].discovered_by, 'webhook');
+});
+
+test('the s3 inbox keeps one object per submission under webhook-inbox/', async () => {
+  const objects = new Map();
+  const s3 = {
+    async putObject(key, data, { ifNoneMatch } = {}) {
+      if (ifNoneMatch === '*' && objects.has(key)) throw Object.assign(new Error('exists'), { code: 'PreconditionFailed' });
+      objects.set(key, Buffer.from(data));
+    },
+    async getObject(key) {
+      return objects.has(key) ? { body: objects.get(key), etag: '"1"' } : null;
+    },
+    async listKeys(prefix) {
+      return [...objects.keys()].filter(key => key.startsWith(prefix)).sort();
+    },
+    async deleteObject(key) {
+      objects.delete(key);
+    },
+  };
+  const inbox = openInbox({ backend: 's3', s3 });
+  const item = submissionItem(body);
+  await inbox.add(item);
+  assert.ok([...objects.keys()][0].startsWith('webhook-inbox/'));
+  assert.deepEqual(await inbox.list(), [item]);
+  await inbox.remove([item]);
+  assert.equal(objects.size, 0);
+});
+
+test('an unknown backend is refused', () => {
+  assert.throws(() => openInbox({ backend: 'redis' }), /Unknown WEBHOOK_INBOX "redis"/);
+});
+
+test('every source_type the webhook accepts is kept in the tracker', () => {
+  for (const type of ['media', 'blog', 'event', 'podcast', 'social']) {
+    assert.deepEqual(validateSubmission({ ...body, source_type: type }), [], type);
+    const tracker = [];
+    mergeIntoTracker(tracker, [submissionItem({ ...body, source_type: type })]);
+    assert.equal(tracker[0].source_type, type, type);
+  }
+  assert.deepEqual(validateSubmission({ ...body, source_type: 'newsletter' }), ['source_type must be one of: media, blog, event, podcast, social']);
+});
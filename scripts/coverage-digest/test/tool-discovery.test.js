@@ -0,0 +1,58 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { readFile } from 'fs/promises';
+import { join } from 'path';
+import { digestItem, tempDir } from './helpers.js';
+import { config } from '../config.js';
+import { applyToolDiscovery, openPendingTools } from '../utils/tool-discovery.js';
+
+const now = new Date('2026-02-16T13:00:00.000Z');
+const blogPost = (title, url = 'https://www.praetorian.com/blog/vespasian/') => digestItem({ title, url, source: 'Praetorian Blog', sourceType: 'blog', toolsMentioned: [] });
+
+// The pending list in a temporary directory
+async function usePendingTools(t) {
+  const path = join(await tempDir(t), 'coverage-tracker', 'pending-tools.json');
+  const saved = config.paths.pendingTools;
+  config.paths.pendingTools = path;
+  t.after(() => { config.paths.pendingTools = saved; });
+  return path;
+}
+
+test('a new tool found on the blog is saved to the pending list beside the tracker', async t => {
+  const path = await usePendingTools(t);
+  const { found } = await applyToolDiscovery([blogPost('Introducing Vespasian: Mapping Cloud Attack Paths')], { now, matchPending: false });
+  assert.deepEqual(found.map(entry => entry.name), ['Vespasian']);
+
+  const saved = JSON.parse(await readFile(path, 'utf-8'));
+  assert.deepEqual(saved, [{ name: 'Vespasian', title: 'Introducing Vespasian: Mapping Cloud Attack Paths', url: 'https://www.praetorian.com/blog/vespasian/', first_seen: now.toISOString() }]);
+  assert.deepEqual((await openPendingTools()).pendingTools(), saved);
+
+  // Found again, in any case, it's already pending
+  const again = await applyToolDiscovery([blogPost('Introducing VESPASIAN')], { now, matchPending: false });
+  assert.deepEqual(again.found, []);
+});
+
+test('a dry run finds pending tools but doesn\'t save them', async t => {
+  const path = await usePendingTools(t);
+  const { found } = await applyToolDiscovery([blogPost('Introducing Vespasian')], { save: false, now, matchPending: false });
+  assert.equal(found.length, 1);
+  await assert.rejects(readFile(path, 'utf-8'), { code: 'ENOENT' });
+
+  config.dryRun = true;
+  t.after(() => { config.dryRun = false; });
+  await applyToolDiscovery([blogPost('Introducing Vespasian')], { now, matchPending: false });
+  await assert.rejects(readFile(path, 'utf-8'), { code: 'ENOENT' });
+});
+
+test('a pending tool that is configured since is taken off the list', async t => {
+  await usePendingTools(t);
+  const store = await openPendingTools();
+  store.addPendingTool('Julius', blogPost('Introducing Julius'), now);
+  store.addPendingTool('Vespasian', blogPost('Introducing Vespasian'), now);
+  await store.save();
+
+  const { removed, pending } = await applyToolDiscovery([], { now, matchPending: false });
+  assert.deepEqual(removed.map(entry => entry.name), ['Julius']);
+  assert.deepEqual(pending.map(entry => entry.name), ['Vespasian']);
+  assert.deepEqual((await openPendingTools()).pendingTools().map(entry => entry.name), ['Vespasian']);
+});
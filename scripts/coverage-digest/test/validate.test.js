@@ -0,0 +1,127 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { digestItem, trackerItem } from './helpers.js';
+import { assertPublishable, publishableTrackerItems, validateItem } from '../utils/validate.js';
+import { normalizeUrl } from '../utils/tracker.js';
+import { renderAtomFeed } from '../utils/atom.js';
+import { buildPayload } from '../utils/digest-webhooks.js';
+import { renderDigestText } from '../utils/template-renderer.js';
+
+const now = new Date('2026-02-16T12:00:00Z');
+const RUNS = 500;
+
+// A small seeded PRNG (mulberry32), so a failure reproduces
+function random(seed) {
+  return () => {
+    seed = (seed + 0x6d2b79f5) | 0;
+    let t = Math.imul(seed ^ (seed >>> 15), 1 | seed);
+    t = (t + Math.imul(t ^ (t >>> 7), 61 | t)) ^ t;
+    return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+  };
+}
+
+// Values for each field, good and bad; `i` keeps good URLs distinct
+const TITLES = ['Praetorian releases Brutus', '', '   ', undefined, null, 42, '](http://evil.example)'];
+const URLS = [
+  i => `https://www.darkreading.com/story-${i}`,
+  i => `http://example.com/${i}?utm_source=x`,
+  () => 'ftp://example.com/file',
+  () => '/relative/path',
+  () => 'not a url',
+  () => '',
+  () => undefined,
+  () => 'javascript:alert(1)',
+  () => 'https://',
+];
+const DATES = ['2026-02-16', '2026-02-16T09:00:00Z', 'not a date', '', undefined, null, '1999-12-31', '2099-01-01', 12345, '2026-02-30'];
+const TOOLS = [['Brutus'], ['Brutus', 'Augustus'], [], undefined, 'Brutus'];
+const EXCERPTS = ['', 'Brutus is an open-source credential tester.', 'x'.repeat(501), undefined];
+
+function fuzzItems(seed, make) {
+  const next = random(seed);
+  const pick = values => values[Math.floor(next() * values.length)];
+  const items = [];
+  for (let i = 0; i < RUNS; i++) {
+    // Now and then repeat an earlier URL, for the duplicate-key check
+    const url = items.length > 0 && next() < 0.05 ? items[Math.floor(next() * items.length)].url : pick(URLS)(i);
+    items.push(make({ title: pick(TITLES), url, date: pick(DATES), tools: pick(TOOLS), excerpt: pick(EXCERPTS), untagged: next() < 0.5 }));
+  }
+  return items;
+}
+
+const fuzzDigest = seed => fuzzItems(seed, ({ title, url, date, tools, excerpt, untagged }) =>
+  digestItem({ title, url, date, toolsMentioned: tools, excerpt, untagged }));
+const fuzzTracker = seed => fuzzItems(seed, ({ title, url, date, tools, excerpt }) =>
+  trackerItem({ title, url, date, tools_mentioned: tools, excerpt, status: 'sent', last_sent_at: '2026-02-16T13:00:00Z' }));
+
+// The digest's view of a tracker item, as run-digest-pipeline.js maps it
+function asDigest(item) {
+  return {
+    title: item.title,
+    url: item.url,
+    date: item.date,
+    toolsMentioned: item.tools_mentioned || [],
+    untagged: Array.isArray(item.tools_mentioned) && item.tools_mentioned.length === 0,
+    excerpt: item.excerpt || '',
+  };
+}
+
+function assertAllValid(items, { view = item => item, at = now } = {}) {
+  const keys = new Set();
+  for (const item of items) {
+    assert.deepEqual(validateItem(view(item), at), [], `invalid item got through: ${JSON.stringify(item)}`);
+    const key = normalizeUrl(item.url);
+    assert.ok(!keys.has(key), `duplicate key got through: ${key}`);
+    keys.add(key);
+  }
+}
+
+test('only valid, distinct items reach the email renderer, whatever the fields hold', t => {
+  t.mock.method(console, 'warn', () => {});
+  for (const seed of [1, 2, 3, 4, 5]) {
+    const items = fuzzDigest(seed);
+    const kept = assertPublishable(items, 'best-effort', now);
+    assertAllValid(kept);
+    // Nothing valid was dropped but for repeats
+    const valid = items.filter(item => validateItem(item, now).length === 0);
+    assert.equal(kept.length, new Set(valid.map(item => normalizeUrl(item.url))).size, `seed ${seed}`);
+    assert.ok(kept.length > 0 && kept.length < items.length, `seed ${seed} made a one-sided sample`);
+    renderDigestText(kept);
+  }
+});
+
+test('strict mode fails on any invalid item instead of rendering it', () => {
+  for (const seed of [1, 2, 3]) {
+    assert.throws(() => assertPublishable(fuzzDigest(seed), 'strict', now), /^Error: Digest failed validation/);
+  }
+  assert.deepEqual(assertPublishable([digestItem()], 'strict', now), [digestItem()]);
+});
+
+test('tracker items are validated before anything converts or renders them', t => {
+  t.mock.method(console, 'warn', () => {});
+  for (const seed of [6, 7, 8, 9, 10]) {
+    const tracker = fuzzTracker(seed);
+    // Never throws, e.g. a RangeError for "not a date". The outputs check
+    // dates against the clock, so this does too.
+    const at = new Date();
+    const kept = publishableTrackerItems(tracker, 'best-effort', at);
+    assertAllValid(kept, { view: asDigest, at });
+    for (const item of kept) new Date(item.date).toISOString();
+
+    const payload = buildPayload(tracker, '2026-02-16T13:00:00Z');
+    assert.deepEqual(payload.items.map(item => item.url), kept.map(item => item.url));
+
+    const hrefs = [...renderAtomFeed(publishableTrackerItems(tracker, 'best-effort'), { limit: RUNS })
+      .matchAll(/<link rel="alternate" type="text\/html" href="([^"]*)"/g)].map(match => match[1]);
+    assert.ok(hrefs.length > 0);
+    for (const href of hrefs) assert.match(href, /^https?:\/\/[^/]+\//);
+  }
+});
+
+test('a malformed tracker date is reported, not thrown by the conversion', t => {
+  const warn = t.mock.method(console, 'warn', () => {});
+  const bad = trackerItem({ id: 'cov-002', url: 'https://example.com/bad-date', date: 'not a date' });
+  assert.deepEqual(publishableTrackerItems([trackerItem(), bad], 'best-effort'), [trackerItem()]);
+  assert.match(warn.mock.calls[0].arguments[0], /date is missing or unparseable \("not a date"\)/);
+  assert.throws(() => publishableTrackerItems([bad], 'strict'), /Digest failed validation/);
+});
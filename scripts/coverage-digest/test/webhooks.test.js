@@ -0,0 +1,102 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { createHmac } from 'crypto';
+import { mockClient, trackerItem } from './helpers.js';
+import { signBody, signatureHeaders, verifySignature } from '../utils/webhook-signature.js';
+import { buildPayload, deliverWebhook, recordDelivery, replayDeliveries } from '../utils/digest-webhooks.js';
+
+const secret = 'whsec_test';
+const body = '{"event":"digest.published","item_count":1}';
+const now = Date.parse('2026-02-16T13:00:05Z');
+const endpoint = { name: 'crm', url: 'https://hooks.example.com/digest', secret };
+const noSleep = async () => {};
+
+// The headers as a Node server sees them
+const received = headers => Object.fromEntries(Object.entries(headers).map(([name, value]) => [name.toLowerCase(), value]));
+
+test('a signed body verifies', () => {
+  const headers = received(signatureHeaders(secret, body, now));
+  assert.equal(headers['x-digest-timestamp'], '1771246805');
+  verifySignature(secret, body, headers, { now });
+});
+
+test('a changed body, timestamp, or secret doesn\'t verify', () => {
+  const headers = received(signatureHeaders(secret, body, now));
+  assert.throws(() => verifySignature(secret, body.replace('1', '2'), headers, { now }), /does not match body/);
+  assert.throws(() => verifySignature('other', body, headers, { now }), /does not match body/);
+  const moved = { ...headers, 'x-digest-timestamp': '1771246806' };
+  assert.throws(() => verifySignature(secret, body, moved, { now }), /does not match body/);
+});
+
+test('a timestamp outside the tolerance is refused, however well signed', () => {
+  const stale = received(signatureHeaders(secret, body, now - 301_000));
+  assert.throws(() => verifySignature(secret, body, stale, { now }), /more than 300s from now/);
+  const early = received(signatureHeaders(secret, body, now + 301_000));
+  assert.throws(() => verifySignature(secret, body, early, { now }), /more than 300s from now/);
+  verifySignature(secret, body, received(signatureHeaders(secret, body, now - 299_000)), { now });
+  verifySignature(secret, body, stale, { now, toleranceSeconds: 600 });
+});
+
+test('missing or malformed headers are refused', () => {
+  const { 'x-digest-signature': signature } = received(signatureHeaders(secret, body, now));
+  assert.throws(() => verifySignature(secret, body, { 'x-digest-signature': signature }, { now }), /X-Digest-Timestamp/);
+  assert.throws(() => verifySignature(secret, body, { 'x-digest-timestamp': '1771246805' }, { now }), /X-Digest-Signature header/);
+  assert.throws(() => verifySignature(secret, body, { 'x-digest-timestamp': '1771246805', 'x-digest-signature': 'sha1=abc' }, { now }), /malformed X-Digest-Signature/);
+  assert.throws(() => verifySignature('', body, {}, { now }), /not configured/);
+  // A signature over the body alone, as before timestamps, doesn't pass
+  const bodyOnly = `sha256=${createHmac('sha256', secret).update(body).digest('hex')}`;
+  assert.throws(() => verifySignature(secret, body, { 'x-digest-timestamp': '1771246805', 'x-digest-signature': bodyOnly }, { now }), /does not match body/);
+  assert.equal(signBody(secret, body, 1771246805), received(signatureHeaders(secret, body, now))['x-digest-signature']);
+});
+
+test('each delivery attempt is signed when it is sent', async () => {
+  const client = mockClient([[503], [200, 'ok']]);
+  let clock = now;
+  const result = await deliverWebhook(endpoint, body, { client, attempts: 3, now: () => (clock += 60_000), sleep: noSleep });
+  assert.deepEqual(result, { ok: true, attempts: 2, statusCode: 200 });
+  const [first, second] = client.requests.map(({ init }) => received(init.headers));
+  assert.notEqual(first['x-digest-timestamp'], second['x-digest-timestamp']);
+  verifySignature(secret, body, second, { now: clock });
+});
+
+test('a 4xx isn\'t retried; running out of attempts reports the last error', async () => {
+  const rejected = mockClient([[400]]);
+  assert.deepEqual(await deliverWebhook(endpoint, body, { client: rejected, attempts: 3, sleep: noSleep }), { ok: false, attempts: 1, error: 'HTTP 400' });
+  const down = mockClient([[503], [502], [500]]);
+  assert.deepEqual(await deliverWebhook(endpoint, body, { client: down, attempts: 3, sleep: noSleep }), { ok: false, attempts: 3, error: 'HTTP 500' });
+});
+
+test('a replay re-sends the stored body, not the tracker as it is now', async () => {
+  const digest = '2026-02-16T13:00:05Z';
+  const tracker = [trackerItem({ last_sent_at: digest })];
+  const sent = JSON.stringify(buildPayload(tracker, digest));
+  const outbox = [];
+  recordDelivery(outbox, digest, 'crm', { ok: false, attempts: 4, error: 'HTTP 503' }, sent);
+  recordDelivery(outbox, digest, 'archive', { ok: true, attempts: 1, statusCode: 200 }, sent);
+
+  // The item is edited after the digest went out
+  tracker[0].title = 'Praetorian releases Brutus 2.0';
+  const endpoints = [endpoint, { ...endpoint, name: 'archive' }];
+
+  const failed = replayDeliveries(outbox, endpoints, tracker);
+  assert.deepEqual(failed.map(d => d.endpoint.name), ['crm']);
+  assert.equal(failed[0].body, sent);
+
+  // Named, a delivered digest is sent again too
+  const again = replayDeliveries(outbox, endpoints, tracker, digest);
+  assert.deepEqual(again.map(d => d.endpoint.name), ['crm', 'archive']);
+  assert.ok(again.every(d => d.body === sent));
+
+  const client = mockClient([[200, 'ok']]);
+  await deliverWebhook(endpoint, failed[0].body, { client, now: () => now });
+  assert.equal(client.requests[0].init.body, sent);
+  verifySignature(secret, sent, received(client.requests[0].init.headers), { now });
+});
+
+test('an entry without a stored body is rebuilt from the tracker', () => {
+  const digest = '2026-02-16T13:00:05Z';
+  const tracker = [trackerItem({ last_sent_at: digest })];
+  const outbox = [{ digest, endpoint: 'crm', attempts: 4, status: 'failed', last_error: 'HTTP 503' }];
+  const [delivery] = replayDeliveries(outbox, [endpoint], tracker);
+  assert.equal(delivery.body, JSON.stringify(buildPayload(tracker, digest)));
+});
@@ -0,0 +1,56 @@
+import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+import { chatItem } from './chat-digest.js';
+
+/**
+ * Post negative coverage (sentiment "negative", see utils/sentiment.js)
+ * to the alert webhook, ALERT_WEBHOOK_URL, as soon as it's found rather
+ * than with the next digest. The body works as a Slack incoming webhook
+ * message, and carries the items for any other receiver:
+ *
+ *   { "text": "⚠️ Negative coverage: 1 item\n• <url|title> — Source (Brutus)",
+ *     "event": "coverage.negative",
+ *     "items": [{ "title", "url", "source", "tools", "sentiment_score" }] }
+ *
+ * Items are tracker items (tools_mentioned) or digest items
+ * (toolsMentioned). Each item alerted on is stamped `alerted_at`, and
+ * items already stamped are skipped, so one item is alerted on once.
+ * Nothing is sent without a webhook or a new negative item.
+ *
+ * @returns {Promise<number>} the number of items alerted on
+ * @throws when the webhook doesn't accept the alert
+ */
+export async function alertNegativeCoverage(items, {
+  url = config.alerts.webhookUrl,
+  client = clientFor('Alerts'),
+  now = new Date(),
+} = {}) {
+  const negative = items.filter(item => item.sentiment === 'negative' && !item.alerted_at);
+  if (!url || negative.length === 0) return 0;
+
+  const lines = negative.map(item => {
+    const { title, url, source, tools } = chatItem(item);
+    return `• <${url}|${slackText(title)}> — ${slackText(source)}${tools.length > 0 ? ` (${tools.join(', ')})` : ''}`;
+  });
+  const body = JSON.stringify({
+    text: `⚠️ Negative coverage: ${negative.length} item${negative.length > 1 ? 's' : ''}\n${lines.join('\n')}`,
+    event: 'coverage.negative',
+    items: negative.map(item => ({
+      title: item.title,
+      url: item.url,
+      source: item.source,
+      tools: chatItem(item).tools,
+      sentiment_score: item.sentiment_score ?? null,
+    })),
+  });
+
+  const res = await client.fetch(url, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body });
+  if (!res.ok) throw new Error(`Alert webhook returned HTTP ${res.status}`);
+  for (const item of negative) item.alerted_at = now.toISOString();
+  return negative.length;
+}
+
+// Slack reads &, < and > in message text as markup
+function slackText(text) {
+  return String(text || '').replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+}
@@ -0,0 +1,94 @@
+import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+
+const AVAILABILITY_API = 'https://archive.org/wayback/available';
+const SPN2_API = 'https://web.archive.org/save';
+
+/**
+ * Give tracker items a Wayback Machine link, so digests outlive the
+ * articles they point to. Items that already have `archive_url` are left
+ * alone. For the rest, the availability API is asked for an existing
+ * snapshot; if there is none (and config.archive.capture is on) Save Page
+ * Now is asked to take one and polled until it finishes.
+ *
+ * Items are archived config.archive.concurrency at a time. A failure only
+ * costs that item its link: it is logged, pushed onto `failures` as
+ * { source, error }, and retried on the next run. Returns the count
+ * archived.
+ */
+export async function archiveTrackerItems(items, { client = clientFor('Wayback Machine'), failures = [], sleep = delay } = {}) {
+  const pending = items.filter(item => !item.archive_url && item.url);
+  let count = 0;
+
+  // Archive concurrently with a concurrency limit
+  const batchSize = Math.max(1, config.archive.concurrency);
+  for (let i = 0; i < pending.length; i += batchSize) {
+    const batch = pending.slice(i, i + batchSize);
+    await Promise.all(batch.map(async item => {
+      try {
+        const archiveUrl = await findSnapshot(client, item.url) || await captureSnapshot(client, item.url, sleep);
+        if (!archiveUrl) return;
+        item.archive_url = archiveUrl;
+        count++;
+      } catch (err) {
+        console.warn(`  Warning: Could not archive ${item.url}: ${err.message}`);
+        failures.push({ source: 'Wayback Machine', error: `${item.id || item.url}: ${err.message}` });
+      }
+    }));
+  }
+  return count;
+}
+
+/**
+ * URL of the closest existing snapshot, or null.
+ */
+async function findSnapshot(client, url) {
+  const res = await client.fetch(`${AVAILABILITY_API}?url=${encodeURIComponent(url)}`);
+  if (!res.ok) throw new Error(`availability API returned ${res.status}`);
+  const closest = (await res.json())?.archived_snapshots?.closest;
+  return closest?.available && String(closest.status).startsWith('2') ? httpsUrl(closest.url) : null;
+}
+
+/**
+ * Ask SPN2 for a new snapshot and wait for it. Returns its URL, or null
+ * when capture is off or the job didn't finish within the poll budget.
+ */
+async function captureSnapshot(client, url, sleep) {
+  if (!config.archive.capture) return null;
+
+  const headers = { Accept: 'application/json' };
+  if (config.archive.accessKey && config.archive.secretKey) {
+    headers.Authorization = `LOW ${config.archive.accessKey}:${config.archive.secretKey}`;
+  }
+  const res = await client.fetch(SPN2_API, {
+    method: 'POST',
+    headers: { ...headers, 'Content-Type': 'application/x-www-form-urlencoded' },
+    body: new URLSearchParams({ url, skip_first_archive: '1' }).toString(),
+  });
+  if (!res.ok) throw new Error(`Save Page Now returned ${res.status}`);
+  const job = await res.json();
+  if (!job?.job_id) throw new Error(job?.message || 'Save Page Now did not start a capture');
+
+  for (let attempt = 0; attempt < config.archive.pollAttempts; attempt++) {
+    await sleep(config.archive.pollIntervalMs);
+    const statusRes = await client.fetch(`${SPN2_API}/status/${encodeURIComponent(job.job_id)}`, { headers });
+    if (!statusRes.ok) throw new Error(`Save Page Now status returned ${statusRes.status}`);
+    const status = await statusRes.json();
+    if (status.status === 'success') {
+      return `https://web.archive.org/web/${status.timestamp}/${status.original_url || url}`;
+    }
+    if (status.status === 'error') {
+      throw new Error(status.message || status.status_ext || 'capture failed');
+    }
+  }
+  return null;
+}
+
+// The availability API still hands out http:// snapshot links
+function httpsUrl(url) {
+  return String(url).replace(/^http:\/\//, 'https://');
+}
+
+function delay(ms) {
+  return new Promise(resolve => setTimeout(resolve, ms));
+}
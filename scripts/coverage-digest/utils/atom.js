@@ -0,0 +1,107 @@
+import { createHash } from 'crypto';
+import { config } from '../config.js';
+import { normalizeUrl } from './tracker.js';
+import { canonicalTools } from './tools.js';
+
+// Tag URIs (RFC 4151) need an authority and a date that never changes
+const ID_PREFIX = 'tag:praetorian.com,2026:coverage-digest';
+
+/**
+ * Render tracker items as an Atom feed (RFC 4287): the most recent
+ * `limit` items by publish date, one entry each, linking to the article,
+ * with the source as author, tool tags as categories, and the excerpt as
+ * summary. Embargoed and low-relevance items are left out.
+ *
+ * Entry ids are derived from the normalized URL, so an article keeps its
+ * id across regenerations and syndicated copies that normalize the same
+ * collapse into one entry (the earliest published). The feed's <updated> is the newest entry's,
+ * not the clock, so an unchanged tracker renders byte-identical output.
+ *
+ * Options:
+ *   limit   - maximum entries (default: config.feed.maxEntries)
+ *   selfUrl - where the feed is published, for rel="self" (optional)
+ */
+export function renderAtomFeed(items, { limit = config.feed.maxEntries, selfUrl = config.feed.url } = {}) {
+  const byId = new Map();
+  for (const item of items) {
+    if (!item.url || item.status === 'embargoed' || item.low_relevance) continue;
+    const id = entryId(item.url);
+    const kept = byId.get(id);
+    // Of duplicates, the original (earliest) publication wins
+    if (!kept || time(item.date) < time(kept.item.date)
+        || (time(item.date) === time(kept.item.date) && compareText(item.url, kept.item.url) < 0)) {
+      byId.set(id, { id, item });
+    }
+  }
+  const entries = [...byId.values()]
+    .sort((a, b) => time(b.item.date) - time(a.item.date) || compareText(a.id, b.id))
+    .slice(0, Math.max(0, limit));
+
+  const updated = entries.map(({ item }) => entryUpdated(item)).sort().pop() || toRfc3339(0);
+
+  const lines = [
+    '<?xml version="1.0" encoding="utf-8"?>',
+    '<feed xmlns="http://www.w3.org/2005/Atom">',
+    `  <id>${ID_PREFIX}</id>`,
+    `  <title>${escapeXml(config.feed.title)}</title>`,
+    `  <updated>${updated}</updated>`,
+    '  <author><name>Praetorian</name></author>',
+  ];
+  if (selfUrl) lines.push(`  <link rel="self" type="application/atom+xml" href="${escapeXml(selfUrl)}"/>`);
+  lines.push('  <generator>praetorian-coverage-digest</generator>');
+
+  for (const { id, item } of entries) {
+    lines.push('  <entry>');
+    lines.push(`    <id>${id}</id>`);
+    lines.push(`    <title type="text">${escapeXml(item.title || item.url)}</title>`);
+    lines.push(`    <link rel="alternate" type="text/html" href="${escapeXml(item.url)}"/>`);
+    if (item.archive_url) lines.push(`    <link rel="related" type="text/html" title="archive" href="${escapeXml(item.archive_url)}"/>`);
+    if (time(item.date)) lines.push(`    <published>${toRfc3339(item.date)}</published>`);
+    lines.push(`    <updated>${entryUpdated(item)}</updated>`);
+    lines.push(`    <author><name>${escapeXml(item.source || 'Unknown')}</name></author>`);
+    for (const tool of canonicalTools(item.tools_mentioned, item.date)) {
+      lines.push(`    <category term="${escapeXml(tool)}"/>`);
+    }
+    if (item.excerpt) lines.push(`    <summary type="text">${escapeXml(item.excerpt)}</summary>`);
+    lines.push('  </entry>');
+  }
+  lines.push('</feed>');
+  return lines.join('\n') + '\n';
+}
+
+/**
+ * Stable entry id for an article URL.
+ */
+export function entryId(url) {
+  const digest = createHash('sha256').update(normalizeUrl(url)).digest('hex').slice(0, 32);
+  return `${ID_PREFIX}:${digest}`;
+}
+
+// Rewrites count as updates; otherwise the publish date (or first sighting)
+function entryUpdated(item) {
+  return toRfc3339(item.update?.detected_at || item.date || item.discovered_at || 0);
+}
+
+function toRfc3339(value) {
+  const date = new Date(value);
+  return (isNaN(date) ? new Date(0) : date).toISOString().replace(/\.\d{3}Z$/, 'Z');
+}
+
+function time(value) {
+  const ms = new Date(value).getTime();
+  return Number.isNaN(ms) ? 0 : ms;
+}
+
+function compareText(a, b) {
+  return a < b ? -1 : a > b ? 1 : 0;
+}
+
+function escapeXml(text) {
+  return String(text)
+    // Control characters aren't allowed anywhere in XML 1.0
+    .replace(/[\u0000-\u0008\u000B\u000C\u000E-\u001F￾￿]/g, '')
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
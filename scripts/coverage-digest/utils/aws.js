@@ -0,0 +1,91 @@
+import { createHash, createHmac } from 'crypto';
+
+/**
+ * AWS credentials from the standard AWS_ACCESS_KEY_ID /
+ * AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN. Throws, naming `purpose`,
+ * when they aren't set.
+ */
+export function awsCredentials(purpose) {
+  const accessKeyId = process.env.AWS_ACCESS_KEY_ID;
+  const secretAccessKey = process.env.AWS_SECRET_ACCESS_KEY;
+  if (!accessKeyId || !secretAccessKey) {
+    throw new Error(`AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required ${purpose}`);
+  }
+  return { accessKeyId, secretAccessKey, sessionToken: process.env.AWS_SESSION_TOKEN };
+}
+
+/**
+ * AWS Signature Version 4 headers for a request. `path` is sent as given,
+ * so it must already be URI-encoded; `query` is an object of parameters.
+ * Every header passed in is signed, along with host and x-amz-date, and
+ * for S3 the payload hash (x-amz-content-sha256).
+ */
+export function signRequest({
+  method, host, path, query = {}, headers = {}, body = '', region, service,
+  credentials: { accessKeyId, secretAccessKey, sessionToken },
+}) {
+  const amzDate = new Date().toISOString().replace(/[:-]|\.\d{3}/g, '');
+  const dateStamp = amzDate.slice(0, 8);
+  const scope = `${dateStamp}/${region}/${service}/aws4_request`;
+  const payloadHash = sha256(body);
+
+  const signed = {
+    ...Object.fromEntries(Object.entries(headers).map(([name, value]) => [name.toLowerCase(), String(value).trim()])),
+    'host': host,
+    'x-amz-date': amzDate,
+    ...(service === 's3' ? { 'x-amz-content-sha256': payloadHash } : {}),
+    ...(sessionToken ? { 'x-amz-security-token': sessionToken } : {}),
+  };
+  const names = Object.keys(signed).sort();
+  const signedHeaders = names.join(';');
+  const canonicalRequest = [
+    method,
+    path,
+    canonicalQuery(query),
+    names.map(name => `${name}:${signed[name]}\n`).join(''),
+    signedHeaders,
+    payloadHash,
+  ].join('\n');
+
+  const stringToSign = ['AWS4-HMAC-SHA256', amzDate, scope, sha256(canonicalRequest)].join('\n');
+  let key = `AWS4${secretAccessKey}`;
+  for (const part of [dateStamp, region, service, 'aws4_request']) {
+    key = hmac(key, part);
+  }
+  const signature = createHmac('sha256', key).update(stringToSign).digest('hex');
+
+  const { host: _host, ...sent } = signed;
+  return {
+    ...sent,
+    'Authorization': `AWS4-HMAC-SHA256 Credential=${accessKeyId}/${scope}, SignedHeaders=${signedHeaders}, Signature=${signature}`,
+  };
+}
+
+/**
+ * URI-encode a string the way SigV4 expects: everything but unreserved
+ * characters, and "/" too unless `keepSlashes`.
+ */
+export function uriEncode(value, keepSlashes = false) {
+  const encoded = encodeURIComponent(value).replace(/[!'()*]/g, c => `%${c.charCodeAt(0).toString(16).toUpperCase()}`);
+  return keepSlashes ? encoded.replace(/%2F/g, '/') : encoded;
+}
+
+/**
+ * A query object as a sorted, encoded query string (no leading "?").
+ */
+export function canonicalQuery(query) {
+  return Object.entries(query)
+    .filter(([, value]) => value !== undefined && value !== null)
+    .map(([name, value]) => [uriEncode(name), uriEncode(String(value))])
+    .sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0))
+    .map(([name, value]) => `${name}=${value}`)
+    .join('&');
+}
+
+function sha256(data) {
+  return createHash('sha256').update(data).digest('hex');
+}
+
+function hmac(key, data) {
+  return createHmac('sha256', key).update(data).digest();
+}
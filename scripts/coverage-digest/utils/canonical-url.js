@@ -0,0 +1,89 @@
+// Query parameters that only record where a click came from. Content
+// hashes (utils/tracker.js) keep their own, older list so the hashes
+// already stored stay valid.
+const TRACKING_PARAM = /^(utm_\w+|fbclid|gclid|dclid|msclkid|yclid|mc_cid|mc_eid|mkt_tok|igshid|_hsenc|_hsmi|ref|ref_src)$/i;
+// Hosts that serve other sites' AMP pages under their own paths:
+// google.com/amp/s/example.com/... and example-com.cdn.ampproject.org/c/s/example.com/...
+const AMP_VIEWERS = [
+  [/^(www\.)?google\.[a-z.]+$/i, /^\/amp\/(s\/)?(.+)$/],
+  [/\.cdn\.ampproject\.org$/i, /^\/[cvi]\/(s\/)?(.+)$/],
+];
+
+/**
+ * The form of a URL that different links to the same article share,
+ * without any network lookups:
+ *
+ *   - scheme and host lowercased, default ports dropped
+ *   - tracking parameters (utm_*, fbclid, gclid, ref, ...) removed
+ *   - AMP unwrapped: google.com/amp/s/... and the AMP cache give way to
+ *     the publisher's URL, a leading or trailing /amp path segment or an
+ *     .amp suffix is dropped, and so are amp and outputType=amp parameters
+ *   - the fragment removed (a "#!" route is kept) and trailing slashes
+ *     trimmed from the path
+ *
+ * Anything that doesn't parse as an http(s) URL is returned trimmed.
+ */
+export function cleanUrl(url) {
+  const text = String(url ?? '').trim();
+  let target;
+  try {
+    target = new URL(text);
+  } catch {
+    return text;
+  }
+  if (target.protocol !== 'http:' && target.protocol !== 'https:') return text;
+
+  target = unwrapAmpViewer(target);
+  const segments = target.pathname.split('/');
+  if (segments.length > 3 && segments[1].toLowerCase() === 'amp') segments.splice(1, 1);
+  while (segments.length > 2 && segments[segments.length - 1] === '') segments.pop();
+  if (segments.length > 2 && segments[segments.length - 1].toLowerCase() === 'amp') segments.pop();
+  segments[segments.length - 1] = segments[segments.length - 1].replace(/\.amp(?=\.html?$|$)/i, '');
+  target.pathname = segments.join('/').replace(/\/+$/, '') || '/';
+
+  for (const key of [...target.searchParams.keys()]) {
+    const value = target.searchParams.get(key);
+    if (TRACKING_PARAM.test(key) || /^amp$/i.test(key) || (/^outputtype$/i.test(key) && /^amp$/i.test(value))) {
+      target.searchParams.delete(key);
+    }
+  }
+  if (!target.hash.startsWith('#!')) target.hash = '';
+  return target.toString();
+}
+
+/**
+ * The canonical URL a page declares (`<link rel="canonical">`, else
+ * `og:url`), cleaned and resolved against the page's own URL, or null if
+ * it declares none we'd trust. A canonical that points at a site's home
+ * page from one of its articles is a misconfiguration, and is ignored.
+ *
+ * @param {string} declared - href or content of the tag, as written
+ * @param {string} pageUrl  - where the page was read from
+ */
+export function declaredCanonical(declared, pageUrl) {
+  if (!declared) return null;
+  let target;
+  try {
+    target = new URL(declared.trim(), pageUrl);
+  } catch {
+    return null;
+  }
+  if (target.protocol !== 'http:' && target.protocol !== 'https:') return null;
+  const page = new URL(pageUrl);
+  if (target.pathname.replace(/\/+$/, '') === '' && page.pathname.replace(/\/+$/, '') !== '') return null;
+  return cleanUrl(target.toString());
+}
+
+function unwrapAmpViewer(target) {
+  for (const [host, path] of AMP_VIEWERS) {
+    if (!host.test(target.hostname)) continue;
+    const match = target.pathname.match(path);
+    if (!match) continue;
+    try {
+      return new URL(`${match[1] ? 'https' : 'http'}://${match[2]}${target.search}`);
+    } catch {
+      return target;
+    }
+  }
+  return target;
+}
@@ -0,0 +1,62 @@
+import { sortItems } from './sort.js';
+import { canonicalTools } from './tools.js';
+import { excerpt } from './excerpt.js';
+
+// Chat clients show long titles in full, however long
+const TITLE_CHARS = 200;
+
+/**
+ * Pick which of a digest's items a chat message lists (utils/teams.js),
+ * where there's room for a few at most. Low-relevance items are left
+ * out, and items under Needs Review are only counted, as in the issue;
+ * the rest are sorted (DIGEST_SORT, negative first) and the first
+ * `maxItems` listed (0 for no cap). Items are tracker items or digest
+ * items.
+ *
+ * @returns {{ confirmed: Array<Object>, shown: Array<Object>, more: number,
+ *   review: number, lowRelevance: number }} the items counted as coverage,
+ *   sorted, the ones to list, and how many were left off, under Needs
+ *   Review, and low-relevance
+ */
+export function selectChatItems(items, { maxItems = 0 } = {}) {
+  const relevant = items.filter(item => !(item.low_relevance || item.lowRelevance));
+  const confirmed = sortItems(relevant.filter(item => !(item.needs_review || item.needsReview)));
+  const cap = maxItems > 0 ? maxItems : confirmed.length;
+  return {
+    confirmed,
+    shown: confirmed.slice(0, cap),
+    more: Math.max(0, confirmed.length - cap),
+    review: relevant.length - confirmed.length,
+    lowRelevance: items.length - relevant.length,
+  };
+}
+
+/**
+ * An item cut down for a chat message (utils/teams.js, and the Slack
+ * alert in utils/alerts.js): { title, url, source, date, tools, excerpt,
+ * negative }, the title cut at TITLE_CHARS and the excerpt at a sentence
+ * end within `excerptChars` (see utils/excerpt.js; 0 for none).
+ */
+export function chatItem(item, { excerptChars = 0 } = {}) {
+  return {
+    title: truncate(item.title || item.url, TITLE_CHARS),
+    url: item.url,
+    source: item.source || '',
+    date: item.date || null,
+    tools: canonicalTools(item.tools_mentioned || item.toolsMentioned || [], item.date),
+    excerpt: excerptChars > 0 && item.excerpt ? excerpt(item.excerpt, excerptChars) : '',
+    negative: item.sentiment === 'negative',
+  };
+}
+
+/**
+ * Cut text to at most `maxChars`, at a word break where there is one,
+ * ending in "…".
+ */
+export function truncate(text, maxChars) {
+  const flat = String(text ?? '').replace(/\s+/g, ' ').trim();
+  if (flat.length <= maxChars) return flat;
+  const cut = flat.slice(0, maxChars - 1);
+  const space = cut.lastIndexOf(' ');
+  return `${(space > maxChars / 2 ? cut.slice(0, space) : cut).trimEnd()}…`;
+}
@@ -0,0 +1,136 @@
+import { config } from '../config.js';
+import { sharedNormalizer } from './publishers.js';
+import { isPendingTool, sharedToolDetector } from './tools.js';
+import { mapSourceType } from './tracker.js';
+
+// What each signal adds to an item's confidence; together they make 1
+const WEIGHTS = {
+  // A tool it mentions is named in its title
+  title: 0.35,
+  // The article links to praetorian.com
+  link: 0.25,
+  // A context term ("Praetorian", "brute force") near the tool's name
+  proximity: 0.25,
+  // It ran in a security publication
+  outlet: 0.15,
+};
+
+/**
+ * How sure we are that a monitor's item is about Praetorian, from 0 to 1:
+ * the weights of the signals it shows (WEIGHTS). An item tagged with no
+ * tool mentions Praetorian itself, so "Praetorian" stands in for the
+ * tool's name: in the title, it counts as a tool named there, and it is
+ * its own context term. A context term farther than `proximityWords`
+ * words from the name counts half. An item whose tools are all pending
+ * (MATCH_PENDING_TOOLS, see utils/tool-discovery.js) has its score
+ * multiplied by `pendingWeight`, as the name may yet turn out not to be a
+ * tool. Rounded to two places.
+ *
+ * @param {Object} item - discovered item; title, excerpt, bodyText,
+ *   bodyLinks (see monitors/html-article.js enrichItems), source and
+ *   toolsMentioned are read
+ * @param {Object} [options]
+ *   detector       - tool detector (default: utils/tools.js sharedToolDetector())
+ *   outlets        - security publication names (default: securityOutlets())
+ *   proximityWords - default: config.confidence.proximityWords
+ *   pendingWeight  - default: config.toolDiscovery.pendingWeight
+ */
+export function mentionConfidence(item, {
+  detector = sharedToolDetector(),
+  outlets = securityOutlets(),
+  proximityWords = config.confidence.proximityWords,
+  pendingWeight = config.toolDiscovery.pendingWeight,
+} = {}) {
+  const tools = item.toolsMentioned || [];
+  const text = [item.title, item.excerpt, item.bodyText].filter(Boolean).join(' ');
+  let score = 0;
+
+  const titleTools = detector.detect(item.title || '', { assumeContext: true });
+  const namedInTitle = tools.length > 0
+    ? tools.some(tool => titleTools.includes(tool))
+    : config.toolContext.some(term => (item.title || '').toLowerCase().includes(term.toLowerCase()));
+  if (namedInTitle) score += WEIGHTS.title;
+
+  if ((item.bodyLinks || []).some(isPraetorianLink)) score += WEIGHTS.link;
+
+  const distances = tools.map(tool => detector.proximity(text, tool)).filter(words => words !== null);
+  if (tools.length === 0 || distances.some(words => words <= proximityWords)) {
+    score += WEIGHTS.proximity;
+  } else if (distances.length > 0) {
+    score += WEIGHTS.proximity / 2;
+  }
+
+  if (outlets.has(outletKey(item.source))) score += WEIGHTS.outlet;
+  if (tools.length > 0 && tools.every(isPendingTool)) score *= pendingWeight;
+
+  return Math.round(score * 100) / 100;
+}
+
+/**
+ * Score items and sort them by confidence (see mentionConfidence). Items
+ * a monitor found get their score as `confidence`; manual submissions
+ * and Praetorian's own blog, which are known to be ours, get 1. Items
+ * scoring from `low` up to `high` are flagged `needsReview`.
+ *
+ * @param {Array<Object>} items - discovered items (changed in place)
+ * @param {Object} [options] - high, low (default: config.confidence), and
+ *   mentionConfidence's options
+ * @returns {{ items: Array<Object>, review: number, dropped: Array<Object> }}
+ *   the items kept (confident and for review) in their original order,
+ *   how many of them need review, and the items scoring under `low`
+ */
+export function applyConfidence(items, {
+  high = config.confidence.high,
+  low = config.confidence.low,
+  ...options
+} = {}) {
+  if (!(low >= 0 && low <= high && high <= 1)) {
+    throw new Error(`Confidence thresholds must be 0 <= CONFIDENCE_LOW (${low}) <= CONFIDENCE_HIGH (${high}) <= 1`);
+  }
+  const outlets = options.outlets || securityOutlets();
+  const kept = [];
+  const dropped = [];
+  let review = 0;
+  for (const item of items) {
+    item.confidence = isScored(item) ? mentionConfidence(item, { ...options, outlets }) : 1;
+    if (item.confidence < low) {
+      dropped.push(item);
+      continue;
+    }
+    if (item.confidence < high) {
+      item.needsReview = true;
+      review++;
+    }
+    kept.push(item);
+  }
+  return { items: kept, review, dropped };
+}
+
+/**
+ * The publications that count as security outlets: the RSS feeds' and
+ * config.confidence.securityOutlets, by publisher name (see
+ * utils/publishers.js), lowercased.
+ */
+export function securityOutlets() {
+  const names = [...config.rssFeeds.map(feed => feed.name), ...config.confidence.securityOutlets];
+  return new Set(names.map(outletKey));
+}
+
+// Found by a monitor, rather than submitted or published by Praetorian
+function isScored(item) {
+  return item.sourceType === 'rss' && mapSourceType(item) !== 'blog';
+}
+
+function isPraetorianLink(url) {
+  try {
+    const host = new URL(url).hostname.toLowerCase();
+    return host === 'praetorian.com' || host.endsWith('.praetorian.com');
+  } catch {
+    return false;
+  }
+}
+
+function outletKey(name) {
+  const known = sharedNormalizer().nameForSource(name || '');
+  return (known || name || '').trim().toLowerCase();
+}
@@ -0,0 +1,186 @@
+import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+
+let shared = null;
+
+/**
+ * The crawler article pages are read through, shared by everything that
+ * scrapes in this run so they share its robots.txt cache and per-host
+ * pacing.
+ */
+export function sharedCrawler() {
+  if (!shared) shared = createCrawler();
+  return shared;
+}
+
+/**
+ * Create a polite HTTP client for publisher pages, with the same
+ * fetch(url, init) interface as utils/http-client.js:
+ *
+ *   - each host's robots.txt is read once and cached; a URL it disallows
+ *     for us is not fetched, and fetch() throws an error with
+ *     `disallowed: true` instead
+ *   - requests to one host go one at a time, however many are in flight
+ *     overall, at least minIntervalMs apart, or the host's Crawl-delay if
+ *     that's longer. A Crawl-delay longer than maxCrawlDelayMs is treated
+ *     as a request not to be crawled by us at all, and is refused the
+ *     same way.
+ *
+ * Our group in robots.txt is the one for the product token of
+ * config.http.userAgent ("PraetorianCoverageDigest"), else "*". Per RFC
+ * 9309, a robots.txt that's missing (4xx) allows everything, and one that
+ * can't be read (5xx, network error) allows nothing.
+ *
+ * @param {Object} [options]
+ *   clientForHost   - host => HTTP client (default: clientFor(host))
+ *   userAgent       - for matching robots.txt groups (default: config.http.userAgent)
+ *   robots          - whether to follow robots.txt (default: config.crawl.robots)
+ *   minIntervalMs   - gap between requests to one host (default: config.crawl.minIntervalMs)
+ *   maxCrawlDelayMs - longest Crawl-delay honored (default: config.crawl.maxCrawlDelayMs)
+ *   sleep           - delay function, for tests
+ */
+export function createCrawler({
+  clientForHost = host => clientFor(host),
+  userAgent = config.http.userAgent,
+  robots = config.crawl.robots,
+  minIntervalMs = config.crawl.minIntervalMs,
+  maxCrawlDelayMs = config.crawl.maxCrawlDelayMs,
+  sleep = delay,
+} = {}) {
+  const agent = userAgent.split('/')[0].trim();
+  const rulesByHost = new Map();
+  const slots = new Map();
+
+  // One request at a time per host, spaced by its interval
+  function paced(host, intervalMs, send) {
+    if (!slots.has(host)) slots.set(host, { tail: Promise.resolve(), last: 0 });
+    const slot = slots.get(host);
+    const turn = slot.tail.then(async () => {
+      const wait = slot.last + intervalMs - Date.now();
+      if (slot.last && wait > 0) await sleep(wait);
+      try {
+        return await send();
+      } finally {
+        slot.last = Date.now();
+      }
+    });
+    slot.tail = turn.catch(() => {});
+    return turn;
+  }
+
+  function rulesFor(target) {
+    if (!rulesByHost.has(target.host)) {
+      rulesByHost.set(target.host, paced(target.host, minIntervalMs, async () => {
+        try {
+          const res = await clientForHost(target.host).fetch(`${target.origin}/robots.txt`, { headers: { Accept: 'text/plain' } });
+          if (res.ok) return parseRobots(await res.text(), agent);
+          if (res.status >= 400 && res.status < 500) return ALLOW_ALL;
+          return disallowAll(`robots.txt unreadable (HTTP ${res.status})`);
+        } catch (err) {
+          return disallowAll(`robots.txt unreadable (${err.message})`);
+        }
+      }));
+    }
+    return rulesByHost.get(target.host);
+  }
+
+  async function fetch(url, init = {}) {
+    const target = new URL(url);
+    const rules = robots ? await rulesFor(target) : ALLOW_ALL;
+    const path = target.pathname + target.search;
+    if (!rules.allows(path)) {
+      throw refusal(`Disallowed by ${target.origin}/robots.txt${rules.reason ? `: ${rules.reason}` : ''}`);
+    }
+    if (rules.crawlDelayMs > maxCrawlDelayMs) {
+      throw refusal(`${target.host} asks for a Crawl-delay of ${rules.crawlDelayMs / 1000}s`);
+    }
+    const interval = Math.max(minIntervalMs, rules.crawlDelayMs || 0);
+    return paced(target.host, interval, () => clientForHost(target.host).fetch(url, init));
+  }
+
+  return { name: 'crawler', fetch };
+}
+
+/**
+ * Parse robots.txt for one user agent (its product token, e.g.
+ * "PraetorianCoverageDigest"): the rules of every group naming the
+ * agent, or else of the "*" groups. Returns { allows(path), crawlDelayMs }.
+ *
+ * The longest matching rule wins, and Allow wins a tie; patterns may use
+ * "*" for any run of characters and a trailing "$" to anchor the end.
+ * /robots.txt itself is always allowed.
+ */
+export function parseRobots(text, agent) {
+  const groups = [];
+  let group = null;
+  let lastWasAgent = false;
+
+  for (const rawLine of String(text).split(/\r?\n/)) {
+    const line = rawLine.replace(/#.*/, '').trim();
+    const colon = line.indexOf(':');
+    if (colon === -1) continue;
+    const key = line.slice(0, colon).trim().toLowerCase();
+    const value = line.slice(colon + 1).trim();
+
+    if (key === 'user-agent') {
+      if (!lastWasAgent) {
+        group = { agents: [], rules: [], crawlDelayMs: null };
+        groups.push(group);
+      }
+      group.agents.push(value.toLowerCase());
+      lastWasAgent = true;
+      continue;
+    }
+    lastWasAgent = false;
+    if (!group) continue;
+    if ((key === 'allow' || key === 'disallow') && value) {
+      group.rules.push({ allow: key === 'allow', pattern: value, regex: patternRegex(value) });
+    } else if (key === 'crawl-delay') {
+      const seconds = parseFloat(value);
+      if (Number.isFinite(seconds) && seconds >= 0) group.crawlDelayMs = seconds * 1000;
+    }
+  }
+
+  const ours = groups.filter(g => g.agents.includes(agent.toLowerCase()));
+  const chosen = ours.length > 0 ? ours : groups.filter(g => g.agents.includes('*'));
+  const rules = chosen.flatMap(g => g.rules);
+  const delays = chosen.map(g => g.crawlDelayMs).filter(ms => ms !== null);
+
+  return {
+    crawlDelayMs: delays.length > 0 ? Math.max(...delays) : 0,
+    allows(path) {
+      if (path === '/robots.txt') return true;
+      let best = null;
+      for (const rule of rules) {
+        if (!rule.regex.test(path)) continue;
+        if (!best || rule.pattern.length > best.pattern.length || (rule.pattern.length === best.pattern.length && rule.allow)) {
+          best = rule;
+        }
+      }
+      return !best || best.allow;
+    },
+  };
+}
+
+const ALLOW_ALL = { crawlDelayMs: 0, allows: () => true };
+
+function disallowAll(reason) {
+  return { crawlDelayMs: 0, reason, allows: path => path === '/robots.txt' };
+}
+
+function refusal(message) {
+  return Object.assign(new Error(message), { disallowed: true });
+}
+
+function patternRegex(pattern) {
+  const anchored = pattern.endsWith('$');
+  const body = (anchored ? pattern.slice(0, -1) : pattern)
+    .split('*')
+    .map(part => part.replace(/[.+?^${}()|[\]\\]/g, '\\$&'))
+    .join('.*');
+  return new RegExp(`^${body}${anchored ? '$' : ''}`);
+}
+
+function delay(ms) {
+  return new Promise(resolve => setTimeout(resolve, ms));
+}
@@ -0,0 +1,89 @@
+import { config } from '../config.js';
+import { sharedS3 } from './s3.js';
+import { zonedParts } from './timezone.js';
+
+// Where digests are archived in the bucket, one prefix per day:
+// digests/2026/02/16/digest.md and digests/2026/02/16/digest.json
+const ARCHIVE_PREFIX = 'digests/';
+
+/**
+ * Archive a rendered digest in S3 under the day it went out (in the
+ * digest's time zone): the markdown and the JSON digest (see
+ * utils/digest-json.js). A second digest the same day (a rerun) replaces
+ * the first. Returns the keys written.
+ */
+export async function archiveDigest({ markdown, json }, now = new Date(), {
+  s3 = sharedS3(),
+  timeZone = config.timeZone,
+} = {}) {
+  if (!s3) throw new Error('S3_BUCKET must be set to archive digests');
+  const dayPrefix = `${ARCHIVE_PREFIX}${dayPath(now, timeZone)}/`;
+  const keys = { markdown: `${dayPrefix}digest.md`, json: `${dayPrefix}digest.json` };
+  await s3.putObject(keys.markdown, markdown, { contentType: 'text/markdown; charset=utf-8' });
+  await s3.putObject(keys.json, JSON.stringify(json, null, 2) + '\n', { contentType: 'application/json' });
+  return keys;
+}
+
+/**
+ * The archived digests from `from` to `to` (inclusive YYYY-MM-DD dates),
+ * oldest first, as [{ date, digest, markdownKey }] where digest is the
+ * parsed JSON digest. Days without an archived digest are left out.
+ */
+export async function listDigests(from, to, { s3 = sharedS3() } = {}) {
+  if (!s3) throw new Error('S3_BUCKET must be set to read archived digests');
+  const first = from.replaceAll('-', '/');
+  const last = to.replaceAll('-', '/');
+  // Keys sort by date, so listing starts at `from`'s prefix
+  const keys = await s3.listKeys(ARCHIVE_PREFIX, { startAfter: `${ARCHIVE_PREFIX}${first}` });
+
+  const digests = [];
+  for (const key of keys) {
+    const match = key.slice(ARCHIVE_PREFIX.length).match(/^(\d{4}\/\d{2}\/\d{2})\/digest\.json$/);
+    if (!match) continue;
+    if (match[1] > last) break;
+    const object = await s3.getObject(key);
+    if (!object) continue;
+    digests.push({
+      date: match[1].replaceAll('/', '-'),
+      digest: JSON.parse(object.body.toString('utf-8')),
+      markdownKey: key.replace(/json$/, 'md'),
+    });
+  }
+  return digests;
+}
+
+/**
+ * The items of archived digests as tracker items sent on their digest's
+ * day, for the rollup (utils/rollup.js) to read history from the archive
+ * rather than the tracker.
+ */
+export function archivedItems(digests) {
+  return digests.flatMap(({ date, digest }) => digest.items.map(item => ({
+    id: item.id,
+    title: item.title,
+    url: item.url,
+    source: item.source,
+    date: item.published_at,
+    ...(item.date_estimated ? { date_estimated: true } : {}),
+    tools_mentioned: item.tools,
+    excerpt: item.excerpt,
+    ...(item.mention_context ? { mention_context: item.mention_context } : {}),
+    ...(item.archive_url ? { archive_url: item.archive_url } : {}),
+    ...(item.hacker_news ? { hacker_news: item.hacker_news } : {}),
+    ...(item.also_published_by?.length ? { also_published_by: item.also_published_by } : {}),
+    ...(item.paywalled ? { paywalled: true } : {}),
+    ...(item.language ? { language: item.language } : {}),
+    ...(item.confidence != null ? { confidence: item.confidence } : {}),
+    ...(item.needs_review ? { needs_review: true } : {}),
+    ...(item.relevance ? { relevance: item.relevance } : {}),
+    ...(item.low_relevance ? { low_relevance: true } : {}),
+    status: 'sent',
+    last_sent_at: digest.date || `${date}T00:00:00Z`,
+  })));
+}
+
+// "2026/02/16" for an instant, in the zone
+function dayPath(date, timeZone) {
+  const { year, month, day } = zonedParts(date, timeZone);
+  return `${year}/${String(month).padStart(2, '0')}/${String(day).padStart(2, '0')}`;
+}
@@ -0,0 +1,196 @@
+import { canonicalTools } from './tools.js';
+import { isForeign, languageName } from './language.js';
+import { compareSummaries, previousDigestSummary, summarizeDigest } from './summary.js';
+import { sortItems } from './sort.js';
+import { isPendingUpdate } from './tracker.js';
+
+// Bump when a field is renamed or removed; adding fields is compatible.
+export const DIGEST_SCHEMA_VERSION = 1;
+
+/**
+ * Build the machine-readable digest for a set of tracker items, the same
+ * items the markdown issue lists, in the issue's order: items whose
+ * embargo just lifted first, then DIGEST_SORT, ties broken on URL. Field
+ * order, item order and tool order are fixed so two runs over the same
+ * data serialize identically. The built-in template (digest-template.md)
+ * renders it into the issue body renderIssueBody gives.
+ *
+ *   {
+ *     "schema_version": 1,
+ *     "date": "2026-02-16T13:00:05Z",
+ *     "item_count": 2,
+ *     "tools_mentioned": ["Augustus", "Brutus"],
+ *     "pending_tools": [],
+ *     "summary": { "unique_sources": 2, "tool_counts": { "Augustus": 1, "Brutus": 1 },
+ *                  "first_seen_tools": [],
+ *                  "source_counts": [{ "source": "Help Net Security", "count": 1, "all_time": 4 }],
+ *                  "new_items": 2, "updated_items": 0, "needs_review": 0, "low_relevance": 0 },
+ *     "items": [{ "id", "title", "url", "source", "published_at", "date_estimated",
+ *                 "tools", "excerpt", "mention_context", "archive_url", "hacker_news", "also_published_by",
+ *                 "paywalled", "language", "foreign_language", "sentiment", "embargo_lifted",
+ *                 "confidence", "needs_review", "relevance", "low_relevance", "update" }]
+ *
+ * `pending_tools` names the tools found announced on the blog but not
+ * configured yet (utils/tool-discovery.js), from options.pendingTools.
+ *
+ * summary.tool_counts and first_seen_tools are most-mentioned first, as
+ * the issue's Summary lists them. new_items and updated_items count the
+ * items the issue lists under Coverage Items and Updated Coverage.
+ *
+ * `date_estimated` is true when no publish date could be found for the
+ * item, and published_at is when it was found instead.
+ *
+ * `mention_context` is the article's first sentence naming one of the
+ * item's tools or Praetorian, when the excerpt doesn't, or null.
+ *
+ * `hacker_news` is null, or { points, comments, url } for an item
+ * discussed on Hacker News, where url is the discussion thread.
+ *
+ * `also_published_by` lists other outlets that ran the same article, as
+ * [{ source, url }]; empty for most items.
+ *
+ * `paywalled` is true for an article behind a paywall.
+ *
+ * `language` is the ISO 639-1 code of the item's title and excerpt
+ * ("en", "de", "ja"), or null where it couldn't be told.
+ * `foreign_language` is its name ("German") when that isn't the digest's
+ * language (LANGUAGE), or null.
+ *
+ * `sentiment` is "positive", "neutral" or "negative" (see
+ * utils/sentiment.js), or null for an item not scored. `embargo_lifted`
+ * is true for an item held under embargo until this digest.
+ *
+ * `confidence` is how sure the monitors are that the item is about
+ * Praetorian, 0-1 (see utils/confidence.js), or null for an item found
+ * before it was scored. `needs_review` is true for one listed under Needs
+ * Review rather than as coverage; summary.needs_review counts them, and
+ * the rest of the summary and trends leave them out.
+ *
+ * `relevance` is how much of the item is about Praetorian, as
+ * { score, signals: { title, share, link, source } } with each 0-1 (see
+ * utils/relevance.js), or null for an item not scored. `low_relevance`
+ * is true for one scored under RELEVANCE_THRESHOLD: the digest leaves it
+ * out and only counts it (summary.low_relevance), so it's here for a
+ * look at why. The rest of the summary and trends leave them out too.
+ *
+ * `update` is null, or { changed: ["title", "excerpt"], previous_title,
+ * previous_url, note } for an article that was rewritten after it went
+ * out; changed includes "url", and previous_url is set, for one that
+ * moved (with NOTE_MOVED_URLS). `note` says what changed, as the issue
+ * does ("Retitled, was \"...\"").
+ *   }
+ *
+ * `trends` compares the digest with the previous one sent:
+ *
+ *   { "items": { "count": 3, "previous": 1, "delta": 2 },
+ *     "tools": [{ "tool": "Brutus", "count": 2, "previous": 0, "delta": null }],
+ *     "new_publications": ["Wired"] }
+ *
+ * where a null delta means the tool wasn't in the previous digest.
+ *
+ * `history` (the full tracker) is needed for first_seen_tools and trends;
+ * without it that list is empty and trends is null. trends is also null
+ * before a second digest has gone out.
+ */
+export function buildDigest(items, now = new Date(), history = null, { pendingTools = [] } = {}) {
+  const sorted = sortItems(items);
+  const digestItems = [...sorted.filter(item => item.embargo_lifted_at), ...sorted.filter(item => !item.embargo_lifted_at)]
+    .map(item => ({
+      id: item.id,
+      title: item.title,
+      url: item.url,
+      source: item.source,
+      published_at: toRfc3339(item.date),
+      date_estimated: Boolean(item.date_estimated),
+      tools: sortedTools(item.tools_mentioned, item.date),
+      excerpt: item.excerpt || '',
+      mention_context: item.mention_context || null,
+      archive_url: item.archive_url || null,
+      hacker_news: item.hacker_news
+        ? { points: item.hacker_news.points, comments: item.hacker_news.comments, url: item.hacker_news.url }
+        : null,
+      also_published_by: (item.also_published_by || []).map(({ source, url }) => ({ source, url })),
+      paywalled: Boolean(item.paywalled),
+      language: item.language || null,
+      foreign_language: isForeign(item) ? languageName(item.language) : null,
+      sentiment: item.sentiment || null,
+      embargo_lifted: Boolean(item.embargo_lifted_at),
+      confidence: item.confidence ?? null,
+      needs_review: Boolean(item.needs_review),
+      relevance: item.relevance
+        ? { score: item.relevance.score, signals: { ...item.relevance.signals } }
+        : null,
+      low_relevance: Boolean(item.low_relevance),
+      update: isPendingUpdate(item)
+        ? {
+            changed: item.update.changed,
+            previous_title: item.update.previous_title ?? null,
+            previous_url: item.update.previous_url ?? null,
+            note: updateNote(item.update),
+          }
+        : null,
+    }));
+
+  // Items under Needs Review, and low-relevance ones, aren't counted as
+  // coverage; low-relevance ones aren't listed at all
+  const confirmed = digestItems.filter(item => !item.needs_review && !item.low_relevance);
+  const summary = summarizeDigest(confirmed, history);
+  const trends = history && compareSummaries(summary, previousDigestSummary(history, confirmed));
+  const updated = confirmed.filter(item => item.update).length;
+
+  return {
+    schema_version: DIGEST_SCHEMA_VERSION,
+    date: toRfc3339(now),
+    item_count: digestItems.length,
+    tools_mentioned: sortedTools(digestItems.flatMap(item => item.tools)),
+    pending_tools: [...pendingTools],
+    summary: {
+      unique_sources: summary.uniqueSources,
+      tool_counts: Object.fromEntries(summary.toolCounts.map(({ tool, count }) => [tool, count])),
+      first_seen_tools: [...summary.firstSeenTools],
+      source_counts: summary.sourceCounts.map(({ source, count, allTime }) => ({ source, count, all_time: allTime })),
+      new_items: confirmed.length - updated,
+      updated_items: updated,
+      needs_review: digestItems.filter(item => item.needs_review && !item.low_relevance).length,
+      low_relevance: digestItems.filter(item => item.low_relevance).length,
+    },
+    trends: trends
+      ? { items: trends.items, tools: trends.tools, new_publications: trends.newSources }
+      : null,
+    items: digestItems,
+  };
+}
+
+/**
+ * What changed in a rewritten item, as the issue notes it.
+ */
+export function updateNote({ changed = [], previous_title: previousTitle, previous_url: previousUrl }) {
+  if (changed.includes('title') && previousTitle) {
+    return `${changed.includes('excerpt') ? 'Retitled and revised' : 'Retitled'}, was "${previousTitle}"`;
+  }
+  if (changed.includes('url') && previousUrl) {
+    return `Moved, was ${previousUrl}`;
+  }
+  return 'Article text revised';
+}
+
+/**
+ * Write a digest as pretty-printed JSON to a writable stream.
+ */
+export function writeDigestJSON(stream, digest) {
+  stream.write(JSON.stringify(digest, null, 2) + '\n');
+}
+
+/**
+ * RFC 3339 in UTC without fractional seconds. Date-only tracker values
+ * ("2026-02-16") are taken as midnight UTC.
+ */
+function toRfc3339(value) {
+  const date = new Date(value);
+  if (isNaN(date)) return null;
+  return date.toISOString().replace(/\.\d{3}Z$/, 'Z');
+}
+
+function sortedTools(tools, date = null) {
+  return [...new Set(canonicalTools(tools || [], date))].sort();
+}
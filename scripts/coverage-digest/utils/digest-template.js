@@ -0,0 +1,321 @@
+import { readFile } from 'fs/promises';
+import { join } from 'path';
+import { config } from '../config.js';
+import { buildDigest } from './digest-json.js';
+import { excerpt } from './excerpt.js';
+import { escapeLinkUrl, escapeMarkdown } from './markdown.js';
+import { formatDelta } from './summary.js';
+
+/**
+ * Digest templates: render the JSON digest (utils/digest-json.js) through
+ * a user-supplied text template, so teams can shape their own digest
+ * without new hardcoded layouts.
+ *
+ *   {{field}}                      a digest field; dotted paths work
+ *                                  ({{summary.unique_sources}})
+ *   {{field | fn arg ...}}         piped through template functions
+ *   {{#each list}}...{{/each}}     loop; inside, fields resolve against the
+ *                                  element first ({{.}} is the element,
+ *                                  {{@index}}, {{@first}}, {{@last}}; for
+ *                                  objects also {{key}} and {{value}})
+ *   {{#if field}}...{{else}}...{{/if}}
+ *
+ * Functions:
+ *   date "long"|"short"|"iso"      format an RFC 3339 date (UTC)
+ *   truncate N                     shorten to N characters, ending at a
+ *                                  sentence end where possible
+ *   md                             escape markdown control characters
+ *   link                           escape a URL for a markdown link target
+ *   percent                        a 0-1 score as a whole percentage ("85")
+ *   is "value"                     whether the field is "value", for
+ *                                  {{#if sentiment | is "negative"}}
+ *   join ", "                      join a list
+ *   default "text"                 fallback for empty values
+ *   delta                          a trend delta as "+2", "−1", "0", or "new"
+ *
+ * Block tags alone on a line don't leave a blank line behind.
+ */
+
+const BUILTIN_TEMPLATE = 'digest-template.md';
+
+const FUNCTIONS = {
+  date(value, format = 'long') {
+    if (!value) return '';
+    const date = new Date(value);
+    if (isNaN(date)) return String(value);
+    if (format === 'iso') return date.toISOString().split('T')[0];
+    const options = format === 'short'
+      ? { month: 'short', day: 'numeric', year: 'numeric', timeZone: 'UTC' }
+      : { weekday: 'long', year: 'numeric', month: 'long', day: 'numeric', timeZone: 'UTC' };
+    return date.toLocaleDateString('en-US', options);
+  },
+  truncate(value, max) {
+    return excerpt(value, max);
+  },
+  md(value) {
+    return escapeMarkdown(stringify(value));
+  },
+  link(value) {
+    return escapeLinkUrl(stringify(value));
+  },
+  percent(value) {
+    return String(Math.round((Number(value) || 0) * 100));
+  },
+  is(value, expected) {
+    return value === expected;
+  },
+  join(value, separator = ', ') {
+    return Array.isArray(value) ? value.join(separator) : stringify(value);
+  },
+  default(value, fallback = '') {
+    return isTruthy(value) ? value : fallback;
+  },
+  delta(value) {
+    return value === undefined ? '' : formatDelta(value);
+  },
+};
+
+// The fixture the sample digest is built from: one item with every field
+// set, and an earlier digest for it to be compared with
+const SAMPLE_ITEM = {
+  id: 'cov-002',
+  title: 'Sample',
+  url: 'https://example.com/sample',
+  source: 'Example',
+  source_type: 'media',
+  date: '2026-02-16',
+  date_estimated: false,
+  tools_mentioned: ['Brutus', 'Augustus'],
+  excerpt: 'Sample excerpt.',
+  mention_context: 'Praetorian released Brutus on Monday.',
+  archive_url: 'https://web.archive.org/web/20260216000000/https://example.com/sample',
+  hacker_news: { points: 120, comments: 45, url: 'https://news.ycombinator.com/item?id=1' },
+  also_published_by: [{ source: 'Other Example', url: 'https://other.example.com/sample' }],
+  paywalled: false,
+  language: 'de',
+  sentiment: 'negative',
+  embargo_lifted_at: '2026-02-16T12:00:00Z',
+  confidence: 0.85,
+  needs_review: false,
+  relevance: { score: 0.8, signals: { title: 1, share: 0.5, link: 0, source: 1 } },
+  low_relevance: false,
+  update: {
+    changed: ['title', 'url'],
+    previous_title: 'Old sample',
+    previous_url: 'https://example.com/old-sample',
+    detected_at: '2026-02-16T12:00:00Z',
+  },
+  status: 'new',
+};
+const SAMPLE_HISTORY = [
+  {
+    id: 'cov-001',
+    title: 'Earlier sample',
+    url: 'https://example.org/earlier',
+    source: 'Earlier Example',
+    date: '2026-02-09',
+    tools_mentioned: ['Brutus'],
+    status: 'sent',
+    last_sent_at: '2026-02-09T13:00:00Z',
+  },
+  SAMPLE_ITEM,
+];
+
+/**
+ * A digest built by buildDigest from the fixture above, so it has every
+ * field buildDigest gives a template, each populated (trends,
+ * first_seen_tools, update...). Templates are rendered against it at
+ * load time.
+ */
+export const SAMPLE_DIGEST = buildDigest([SAMPLE_ITEM], new Date('2026-02-16T13:00:00Z'), SAMPLE_HISTORY, { pendingTools: ['Vespasian'] });
+
+/**
+ * Load and compile a digest template, or the built-in one when `path` is
+ * empty. The template is rendered once against a sample digest so typos
+ * in field or function names fail here, with the offending line, rather
+ * than mid-run. Returns { name, render(digest) }.
+ */
+export async function loadDigestTemplate(path = '') {
+  const name = path || BUILTIN_TEMPLATE;
+  const source = await readFile(path || join(config.paths.templates, BUILTIN_TEMPLATE), 'utf-8');
+  return compileDigestTemplate(source, name);
+}
+
+export function compileDigestTemplate(source, name = 'template') {
+  const fail = (line, message) => {
+    throw new Error(`${name}:${line}: ${message}`);
+  };
+  const nodes = parse(source, fail);
+  const render = digest => renderNodes(nodes, [digest], fail);
+  render(SAMPLE_DIGEST);
+  return { name, render };
+}
+
+function parse(source, fail) {
+  const root = { type: 'root', body: [], line: 1 };
+  root.target = root.body;
+  const stack = [root];
+  const current = () => stack[stack.length - 1];
+  const lineAt = index => source.slice(0, index).split('\n').length;
+
+  const tagPattern = /\{\{([\s\S]*?)\}\}/g;
+  let cursor = 0;
+  let match;
+  while ((match = tagPattern.exec(source))) {
+    const inner = match[1].trim();
+    const line = lineAt(match.index);
+    const isBlockTag = /^(#|\/|else$)/.test(inner);
+
+    let textEnd = match.index;
+    let next = tagPattern.lastIndex;
+    if (isBlockTag) {
+      // Standalone block tag: drop its indentation and trailing newline
+      const lineStart = source.lastIndexOf('\n', match.index - 1) + 1;
+      const newline = source.indexOf('\n', next);
+      const lineEnd = newline === -1 ? source.length : newline;
+      if (!source.slice(lineStart, match.index).trim() && !source.slice(next, lineEnd).trim() && lineStart >= cursor) {
+        textEnd = lineStart;
+        next = newline === -1 ? lineEnd : newline + 1;
+      }
+    }
+    if (textEnd > cursor) current().target.push({ type: 'text', value: source.slice(cursor, textEnd) });
+    cursor = next;
+    tagPattern.lastIndex = next;
+
+    if (!inner) fail(line, 'empty tag {{}}');
+
+    if (inner.startsWith('#')) {
+      const [, keyword, expr] = inner.match(/^#(\w+)\s*(.*)$/) || [];
+      if (keyword !== 'each' && keyword !== 'if') fail(line, `unknown block {{${inner}}} (expected #each or #if)`);
+      if (!expr) fail(line, `{{#${keyword}}} needs a field`);
+      const node = { type: keyword, expr: parseExpression(expr, line, fail), body: [], elseBody: null, line };
+      node.target = node.body;
+      current().target.push(node);
+      stack.push(node);
+    } else if (inner === 'else') {
+      const node = current();
+      if (node.type !== 'if' || node.elseBody) fail(line, '{{else}} outside {{#if}}');
+      node.elseBody = [];
+      node.target = node.elseBody;
+    } else if (inner.startsWith('/')) {
+      const keyword = inner.slice(1).trim();
+      const node = current();
+      if (node.type === 'root') fail(line, `{{/${keyword}}} without a matching {{#${keyword}}}`);
+      if (node.type !== keyword) fail(line, `{{/${keyword}}} closes {{#${node.type}}} opened on line ${node.line}`);
+      stack.pop();
+    } else {
+      current().target.push({ type: 'var', expr: parseExpression(inner, line, fail), line });
+    }
+  }
+  if (cursor < source.length) current().target.push({ type: 'text', value: source.slice(cursor) });
+
+  if (stack.length > 1) {
+    const open = current();
+    fail(open.line, `{{#${open.type}}} is never closed`);
+  }
+  return root.body;
+}
+
+/**
+ * "path | fn arg | fn" -> { path, pipes: [{ name, args }] }
+ */
+function parseExpression(text, line, fail) {
+  const segments = [];
+  let segment = '';
+  let quoted = false;
+  for (const ch of text) {
+    if (ch === '"') quoted = !quoted;
+    if (ch === '|' && !quoted) {
+      segments.push(segment);
+      segment = '';
+    } else {
+      segment += ch;
+    }
+  }
+  segments.push(segment);
+
+  const [path, ...pipeTexts] = segments.map(s => s.trim());
+  if (!path || /\s/.test(path)) fail(line, `expected a field name, got "${path}"`);
+  const pipes = pipeTexts.map(pipeText => {
+    const tokens = [...pipeText.matchAll(/"((?:[^"\\]|\\.)*)"|(\S+)/g)];
+    if (tokens.length === 0) fail(line, 'empty pipe');
+    const name = tokens[0][2];
+    if (!Object.hasOwn(FUNCTIONS, name)) {
+      fail(line, `unknown function "${name}" (available: ${Object.keys(FUNCTIONS).join(', ')})`);
+    }
+    const args = tokens.slice(1).map(([, str, bare]) => {
+      if (str !== undefined) return str.replace(/\\(.)/g, '$1');
+      if (/^-?\d+(\.\d+)?$/.test(bare)) return Number(bare);
+      fail(line, `function arguments must be numbers or "quoted strings", got ${bare}`);
+    });
+    return { name, args };
+  });
+  return { path, pipes };
+}
+
+function renderNodes(nodes, scopes, fail) {
+  let out = '';
+  for (const node of nodes) {
+    if (node.type === 'text') {
+      out += node.value;
+    } else if (node.type === 'var') {
+      out += stringify(evaluate(node.expr, scopes, node.line, fail));
+    } else if (node.type === 'if') {
+      const value = evaluate(node.expr, scopes, node.line, fail);
+      out += renderNodes(isTruthy(value) ? node.body : node.elseBody || [], scopes, fail);
+    } else if (node.type === 'each') {
+      const value = evaluate(node.expr, scopes, node.line, fail);
+      const entries = Array.isArray(value)
+        ? value
+        : value && typeof value === 'object'
+          ? Object.entries(value).map(([key, val]) => ({ key, value: val }))
+          : fail(node.line, `{{#each ${node.expr.path}}} needs a list or object`);
+      entries.forEach((element, index) => {
+        const loop = { '@index': index, '@first': index === 0, '@last': index === entries.length - 1 };
+        out += renderNodes(node.body, [...scopes, loop, element], fail);
+      });
+    }
+  }
+  return out;
+}
+
+function evaluate({ path, pipes }, scopes, line, fail) {
+  let value = lookup(path, scopes, line, fail);
+  for (const { name, args } of pipes) {
+    value = FUNCTIONS[name](value, ...args);
+  }
+  return value;
+}
+
+function lookup(path, scopes, line, fail) {
+  if (path === '.' || path === 'this') return scopes[scopes.length - 1];
+  const [head, ...rest] = path.split('.');
+  let value;
+  let found = false;
+  for (let i = scopes.length - 1; i >= 0; i--) {
+    const scope = scopes[i];
+    if (scope && typeof scope === 'object' && Object.hasOwn(scope, head)) {
+      value = scope[head];
+      found = true;
+      break;
+    }
+  }
+  if (!found) fail(line, `unknown field "${path}"`);
+  for (const key of rest) {
+    if (!value || typeof value !== 'object' || !Object.hasOwn(value, key)) fail(line, `unknown field "${path}"`);
+    value = value[key];
+  }
+  return value;
+}
+
+function isTruthy(value) {
+  if (Array.isArray(value)) return value.length > 0;
+  if (value && typeof value === 'object') return Object.keys(value).length > 0;
+  return Boolean(value);
+}
+
+function stringify(value) {
+  if (value == null) return '';
+  if (Array.isArray(value)) return value.join(', ');
+  return String(value);
+}
@@ -0,0 +1,123 @@
+import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+import { parseRetryAfter, retry } from './retry.js';
+import { publishableTrackerItems } from './validate.js';
+import { signatureHeaders } from './webhook-signature.js';
+
+/**
+ * The JSON payload sent for a digest: its items as stored in the tracker,
+ * less any that fail the digest's validation (utils/validate.js; in
+ * strict mode those throw instead).
+ */
+export function buildPayload(tracker, digest) {
+  const items = publishableTrackerItems(tracker.filter(item => item.last_sent_at === digest))
+    .map(item => ({
+      id: item.id,
+      title: item.title,
+      url: item.url,
+      source: item.source,
+      source_type: item.source_type,
+      date: item.date,
+      tools_mentioned: item.tools_mentioned || [],
+      excerpt: item.excerpt || '',
+      ...(item.sentiment ? { sentiment: item.sentiment } : {}),
+    }));
+  return { event: 'digest.published', digest_id: digest, sent_at: digest, item_count: items.length, items };
+}
+
+/**
+ * POST a body to an endpoint, signed with its secret (see
+ * utils/webhook-signature.js). Each attempt is signed afresh, so its
+ * X-Digest-Timestamp is when it was sent, however long the backoff.
+ * Network errors, 429 and 5xx responses are retried (utils/retry.js),
+ * waiting `baseDelayMs`, then twice that, and so on, or for Retry-After;
+ * other responses won't change on a second try.
+ *
+ * @returns {Promise<Object>} { ok: true, attempts, statusCode }, or
+ *   { ok: false, attempts, error }
+ */
+export async function deliverWebhook(endpoint, body, {
+  client = clientFor(endpoint.name),
+  attempts = config.digestWebhooks.maxAttempts,
+  baseDelayMs = config.digestWebhooks.backoffMs,
+  now = Date.now,
+  sleep,
+} = {}) {
+  let made = 0;
+  try {
+    const res = await retry(async attempt => {
+      made = attempt;
+      const res = await client.fetch(endpoint.url, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...signatureHeaders(endpoint.secret, body, now()) },
+        body,
+      });
+      if (!res.ok) {
+        throw Object.assign(new Error(`HTTP ${res.status}`), {
+          status: res.status,
+          retryAfterMs: parseRetryAfter(res.headers['retry-after']),
+        });
+      }
+      return res;
+    }, {
+      attempts,
+      baseDelayMs,
+      onRetry: ({ attempt, error, delayMs }) => console.log(`  Attempt ${attempt} failed (${error.message}); retrying in ${delayMs}ms`),
+      ...(sleep ? { sleep } : {}),
+    });
+    return { ok: true, attempts: made, statusCode: res.status };
+  } catch (err) {
+    return { ok: false, attempts: made, error: (err.cause || err).message };
+  }
+}
+
+/**
+ * Record a delivery's outcome in the outbox (webhook-outbox.json), with
+ * the body sent, so a replay sends those exact bytes again rather than
+ * whatever the tracker holds by then.
+ */
+export function recordDelivery(outbox, digest, endpoint, result, body, now = new Date()) {
+  let entry = findEntry(outbox, digest, endpoint);
+  if (!entry) {
+    entry = { digest, endpoint, attempts: 0 };
+    outbox.push(entry);
+  }
+  entry.attempts += result.attempts;
+  entry.last_attempt_at = now.toISOString();
+  entry.payload = JSON.parse(body);
+  if (result.ok) {
+    entry.status = 'delivered';
+    entry.delivered_at = now.toISOString();
+    delete entry.last_error;
+  } else {
+    entry.status = 'failed';
+    entry.last_error = result.error;
+  }
+  return entry;
+}
+
+/**
+ * The outbox entries to send again, as { digest, endpoint, body }: every
+ * failed one, or with `digest`, every one for that digest, delivered or
+ * not (for a consumer that lost it). Each body is the payload stored
+ * when it was first sent; an entry from before payloads were stored is
+ * rebuilt from `tracker`. Entries for endpoints no longer configured are
+ * skipped with a warning.
+ */
+export function replayDeliveries(outbox, endpoints, tracker, digest = null) {
+  const deliveries = [];
+  for (const entry of outbox.filter(entry => (digest ? entry.digest === digest : entry.status === 'failed'))) {
+    const endpoint = endpoints.find(e => e.name === entry.endpoint);
+    if (!endpoint) {
+      console.warn(`  Warning: ${entry.endpoint} is no longer configured; skipping ${entry.digest}`);
+      continue;
+    }
+    const payload = entry.payload || buildPayload(tracker, entry.digest);
+    deliveries.push({ digest: entry.digest, endpoint, body: JSON.stringify(payload) });
+  }
+  return deliveries;
+}
+
+export function findEntry(outbox, digest, endpoint) {
+  return outbox.find(e => e.digest === digest && e.endpoint === endpoint);
+}
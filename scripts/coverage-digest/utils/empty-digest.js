@@ -0,0 +1,38 @@
+import { config } from '../config.js';
+import { sortItems } from './sort.js';
+
+export const EMPTY_DIGEST_POLICIES = ['skip', 'compact', 'full'];
+
+/**
+ * Decide what to publish for a run's digest items. Every publisher (email,
+ * GitHub issue, the workflow's send step) asks this rather than checking
+ * for an empty list itself, so they all treat a quiet day the same way.
+ *
+ * With items, the digest is always published in full. Without, the
+ * policy (EMPTY_DIGEST) decides:
+ *
+ *   skip     publish nothing
+ *   compact  publish a one-paragraph note naming the last item covered
+ *   full     publish the regular digest with its empty sections
+ *
+ * Returns { publish, compact, lastItem }. `lastItem` is only looked up
+ * for a compact digest: the most recent item in `history` (the tracker),
+ * or null if there is none.
+ */
+export function planDigest(items, history = [], policy = config.emptyDigest) {
+  if (!EMPTY_DIGEST_POLICIES.includes(policy)) {
+    throw new Error(`Unknown empty digest policy "${policy}" (expected ${EMPTY_DIGEST_POLICIES.join(', ')})`);
+  }
+  if (items.length > 0 || policy === 'full') return { publish: true, compact: false, lastItem: null };
+  if (policy === 'skip') return { publish: false, compact: false, lastItem: null };
+  return { publish: true, compact: true, lastItem: lastCoverage(history) };
+}
+
+/**
+ * The most recently published item in the tracker, leaving out embargoed
+ * items (which can't be mentioned yet) and ones without a title.
+ */
+export function lastCoverage(history = []) {
+  const published = history.filter(item => item.title && item.date && item.status !== 'embargoed');
+  return sortItems(published, 'date', { negativeFirst: false })[0] || null;
+}
@@ -0,0 +1,96 @@
+// Words that end in a period without ending the sentence. Compared
+// lowercased, without the trailing period.
+const ABBREVIATIONS = new Set([
+  'mr', 'mrs', 'ms', 'dr', 'prof', 'sr', 'jr', 'st', 'mt', 'no', 'vs', 'etc', 'al',
+  'inc', 'corp', 'co', 'ltd', 'llc', 'dept', 'gov', 'approx', 'est', 'fig',
+  'jan', 'feb', 'mar', 'apr', 'jun', 'jul', 'aug', 'sep', 'sept', 'oct', 'nov', 'dec',
+]);
+
+const graphemes = new Intl.Segmenter('en', { granularity: 'grapheme' });
+
+/**
+ * Shorten text to an excerpt of at most `max` characters (grapheme
+ * clusters, so emoji and accented letters are never split).
+ *
+ * Blockquote markers, newlines, and runs of whitespace are flattened
+ * first. Text that still doesn't fit is cut at the last sentence end
+ * before the limit; when that would keep less than half of it, at the
+ * last word boundary instead, and failing that mid-word. Cuts other than
+ * at a sentence end are marked with an ellipsis, which counts toward
+ * `max`.
+ *
+ * A period after a common abbreviation ("Inc.", "Dr.") or dotted
+ * initials ("U.S.", "e.g.") is not a sentence end, nor is one followed
+ * by a lowercase word.
+ */
+export function excerpt(text, max) {
+  const clean = flatten(text);
+  const chars = [...graphemes.segment(clean)].map(({ segment }) => segment);
+  if (!(max > 0) || chars.length <= max) return clean;
+
+  const sentenceEnd = lastSentenceEnd(chars, max);
+  if (sentenceEnd >= max / 2) return chars.slice(0, sentenceEnd).join('');
+
+  const limit = max - 1; // room for the ellipsis
+  let cut = chars.lastIndexOf(' ', limit);
+  if (cut < limit / 2) cut = limit;
+  return chars.slice(0, cut).join('').replace(/[\s,;:.\-–—]+$/, '') + '…';
+}
+
+/**
+ * Split text into sentences, flattened as excerpt() flattens it, where
+ * excerpt() would end one: not after an abbreviation or initials, nor
+ * before a lowercase word, and with closing quotes and brackets kept on
+ * the sentence they close.
+ *
+ *   splitSentences('Dr. Smith said "Brutus works." It does.')
+ *   // ['Dr. Smith said "Brutus works."', 'It does.']
+ */
+export function splitSentences(text) {
+  const chars = [...graphemes.segment(flatten(text))].map(({ segment }) => segment);
+  const sentences = [];
+  let start = 0;
+  for (let end = 1; end <= chars.length; end++) {
+    if (end < chars.length && (chars[end] !== ' ' || !isSentenceEnd(chars, end))) continue;
+    sentences.push(chars.slice(start, end).join('').trim());
+    start = end + 1;
+  }
+  return sentences.filter(Boolean);
+}
+
+// Blockquote markers, newlines, and runs of whitespace flattened
+function flatten(text) {
+  return String(text ?? '')
+    .replace(/^[ \t]*(?:>[ \t]?)+/gm, '')
+    .replace(/\s+/g, ' ')
+    .trim();
+}
+
+// Length of the longest run of whole sentences that fits in `max`, or 0
+function lastSentenceEnd(chars, max) {
+  for (let end = Math.min(max, chars.length); end > 0; end--) {
+    if (isSentenceEnd(chars, end)) return end;
+  }
+  return 0;
+}
+
+// Whether a sentence can end just before chars[end]
+function isSentenceEnd(chars, end) {
+  if (end < chars.length && chars[end] !== ' ') return false;
+
+  // Closing quotes and brackets belong to the sentence they close
+  let i = end - 1;
+  while (i >= 0 && /^["'”’)\]»]$/.test(chars[i])) i--;
+  if (i < 0 || !/^[.!?…]$/.test(chars[i])) return false;
+
+  const next = chars.slice(end + 1, end + 3).join('');
+  if (/^\p{Ll}/u.test(next)) return false;
+  if (chars[i] !== '.') return true;
+
+  const start = chars.lastIndexOf(' ', i) + 1;
+  const word = chars.slice(start, i).join('').replace(/^["'“‘(\[«]+/, '');
+  if (ABBREVIATIONS.has(word.toLowerCase())) return false;
+  // Dotted initials: U.S, e.g, i.e, U.K
+  if (/^(\p{L}\.)+\p{L}$/u.test(word) || /^\p{Lu}$/u.test(word)) return false;
+  return true;
+}
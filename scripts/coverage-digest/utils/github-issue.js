@@ -0,0 +1,1074 @@
+import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+import {
+  compareSummaries,
+  previousDigestSummary,
+  renderSourcesMarkdown,
+  renderTrendsMarkdown,
+  summarizeDigest,
+} from './summary.js';
+import { sortItems } from './sort.js';
+import { canonicalTool, canonicalTools } from './tools.js';
+import { excerpt } from './excerpt.js';
+import { updateNote } from './digest-json.js';
+import { isForeign, languageName } from './language.js';
+import { escapeLinkUrl, escapeMarkdown } from './markdown.js';
+import { isPendingUpdate } from './tracker.js';
+import { assertTimeZone, zonedParts } from './timezone.js';
+
+export const DEDUPE_STRATEGIES = ['skip', 'update', 'comment'];
+// "daily" files an issue per digest; "weekly" keeps one rolling issue per
+// ISO week, its body replaced on each run
+export const ISSUE_MODES = ['daily', 'weekly'];
+export const LAYOUTS = ['flat', 'by-tool'];
+export const OVERFLOW_STYLES = ['list', 'details'];
+
+// GitHub rejects issue and comment bodies longer than this
+export const MAX_BODY_LENGTH = 65536;
+// GitHub assigns an issue to this many people at most
+const MAX_ASSIGNEES = 10;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+const TITLE_PREFIX = 'Coverage Digest - ';
+// Subsection for items that mention Praetorian but no specific tool
+const OTHER_GROUP = 'Other / Company';
+
+const ACTIONS = [
+  'Queue LinkedIn posts for each item',
+  'Post to #praetorian-in-the-wild',
+  'Update website "In the News" page',
+  'Notify #amplification-crew for reshares',
+];
+
+// Hidden markers delimit the machine-rendered parts of the body so an
+// update can find them again after people have edited the issue.
+const ITEM_BLOCK = /<!-- item:(\S+) -->\n([\s\S]*?)<!-- \/item:\1 -->/g;
+const ACTION_START = '<!-- action-needed -->';
+const ACTION_END = '<!-- /action-needed -->';
+// The Action Needed line for items under Needs Review, first when there are any
+const REVIEW_ACTION = /^Confirm the \d+ item\(s\) under Needs Review$/;
+// The Action Needed lines for tools found announced on the blog, one per
+// tool, after the review line
+const NEW_TOOL_ACTION = /^New tool detected: .+ — confirm tracking$/;
+
+// Lines inside an item block that the renderer owns; anything else in
+// the block was added by a person and is kept on update.
+const MACHINE_LINE = /^(### \[|\*\*.*\*\* · |> |🔄 |Also published by: |Context: _|- \[.*\]\(\S+\) — \*\*)/;
+// Marks an item listed past maxItems as a rewrite
+const COMPACT_UPDATE = ' · 🔄 updated';
+// "- [ ] [Title](url) — " at the start of an item under Needs Review,
+// checked ("- [x]") once someone has confirmed it
+const REVIEW_PREFIX = /^- \[[ xX]\] \[(?:\\.|[^\\\]])*\]\([^)\s]+\) — /;
+
+/**
+ * The date string used in digest issue titles, e.g. "Monday, February 16, 2026",
+ * as of `now` in the digest's time zone.
+ */
+export function digestDate(now = new Date(), timeZone = config.timeZone) {
+  return now.toLocaleDateString('en-US', {
+    timeZone: assertTimeZone(timeZone),
+    weekday: 'long', year: 'numeric', month: 'long', day: 'numeric',
+  });
+}
+
+export function issueTitle(date, count) {
+  return `${TITLE_PREFIX}${date} - ${count} new items`;
+}
+
+export function compactIssueTitle(date) {
+  return `${TITLE_PREFIX}${date} - No new coverage`;
+}
+
+/**
+ * The ISO week `now` falls in, in the digest's time zone, e.g. "2026-W08":
+ * the period a rolling issue covers.
+ */
+export function digestPeriod(now = new Date(), timeZone = config.timeZone) {
+  const p = zonedParts(now, assertTimeZone(timeZone));
+  // The week belongs to the year its Thursday falls in
+  const thursday = new Date(Date.UTC(p.year, p.month - 1, p.day));
+  thursday.setUTCDate(thursday.getUTCDate() + 4 - (thursday.getUTCDay() || 7));
+  const year = thursday.getUTCFullYear();
+  const week = Math.floor((thursday - Date.UTC(year, 0, 1)) / DAY_MS / 7) + 1;
+  return `${year}-W${String(week).padStart(2, '0')}`;
+}
+
+/**
+ * The hidden line at the top of a rolling issue's body that finds it again.
+ */
+export function periodMarker(period) {
+  return `<!-- coverage-digest: ${period} -->`;
+}
+
+export function rollingIssueTitle(period, count) {
+  return `${TITLE_PREFIX}${period} - ${count} items`;
+}
+
+/**
+ * The tracker items a rolling issue for `period` lists: those sent in a
+ * digest during the week (last_sent_at), and those not sent yet.
+ */
+export function periodItems(tracker, period, timeZone = config.timeZone) {
+  return tracker.filter(item => item.status === 'new'
+    || (item.last_sent_at && digestPeriod(new Date(item.last_sent_at), timeZone) === period));
+}
+
+/**
+ * Body of a compact digest issue, filed on a day with no new items under
+ * EMPTY_DIGEST=compact: one paragraph naming the last item covered.
+ */
+export function renderCompactIssueBody(lastItem) {
+  if (!lastItem) return 'No new coverage this period, and none has been recorded yet.\n';
+  const archiveLink = lastItem.archive_url ? ` <sub>[(archive)](${lastItem.archive_url})</sub>` : '';
+  return `No new coverage this period. The last item was [${escapeMarkdown(lastItem.title)}](${lastItem.url})${archiveLink} `
+    + `(**${escapeMarkdown(lastItem.source)}**) on ${lastItem.date}.\n`;
+}
+
+/**
+ * Render the issue for a set of tracker items. Returns { body, continuations }:
+ * items that don't fit in the body are moved, whole, into continuation
+ * comments so the Summary and Action Needed sections are never cut off.
+ *
+ * Options:
+ *   layout       - "flat" (one chronological list, default) or "by-tool" (one
+ *                  subsection per tool, then "Other / Company")
+ *   limit        - maximum body length (default: GitHub's limit)
+ *   history      - the full tracker, used to flag tools covered for the first time
+ *   excerptChars - excerpt length, cut at a sentence end
+ *                  (default: config.digestIssue.excerptChars)
+ *   sources      - add the Sources section, per-publication counts
+ *                  (default: config.digestIssue.sources)
+ *   maxItems     - items rendered in full; the rest are listed by title under
+ *                  "Additional Coverage", 0 for no cap (default: config.digestIssue.maxItems)
+ *   overflow     - "list" or "details" (the list folded into a <details> block)
+ *                  for the items past maxItems (default: config.digestIssue.overflow)
+ *   runSummary   - the pipeline's saved source run summary (loadRunSummary()),
+ *                  added as a collapsed Source Run block (default: none)
+ *   pendingTools - names of tools found announced on the blog but not yet
+ *                  configured (utils/tool-discovery.js), each listed under
+ *                  Action Needed to confirm (default: none)
+ *
+ * The Summary always counts every item, listed in full or not. Items
+ * flagged needs_review (see utils/confidence.js) are left out of it, and
+ * listed under "Needs Review" as a box to tick, with their confidence;
+ * Action Needed then starts with confirming them. Items flagged
+ * low_relevance (see utils/relevance.js) aren't listed at all, only
+ * counted in a note under the Summary.
+ */
+export function renderIssueBody(items, {
+  layout = config.digestIssue.layout,
+  limit = MAX_BODY_LENGTH,
+  history = null,
+  excerptChars = config.digestIssue.excerptChars,
+  sources = config.digestIssue.sources,
+  maxItems = config.digestIssue.maxItems,
+  overflow = config.digestIssue.overflow,
+  runSummary = null,
+  pendingTools = [],
+} = {}) {
+  const { relevant, lowRelevance } = splitRelevance(items);
+  const entries = orderItems(relevant).map(item => ({ item, block: renderBlock(item, excerptChars) }));
+  const reviewCount = relevant.filter(item => item.needs_review).length;
+  return assembleBody(entries, renderActionNeeded(reviewCount, pendingTools), '', { layout, limit, history, sources, maxItems, overflow, runSummary, lowRelevance });
+}
+
+/**
+ * Render newly found items as comments on an existing digest issue,
+ * split across as many comments as needed.
+ */
+export function renderCommentBodies(items, { limit = MAX_BODY_LENGTH, excerptChars = config.digestIssue.excerptChars } = {}) {
+  const segments = orderItems(items).map(item => `${renderBlock(item, excerptChars)}\n---\n\n`);
+  return paginate(segments, limit, part =>
+    part === 1 ? `## ${items.length} more item(s)\n\n` : `## ${items.length} more item(s) (continued, part ${part})\n\n`
+  );
+}
+
+/**
+ * Merge new items into an existing issue body without clobbering human
+ * edits. Items already in the body keep any lines people added to their
+ * block and get their machine-rendered lines refreshed; new items are
+ * inserted at the top; the Action Needed section keeps its check state
+ * and notes, and items under Needs Review stay ticked once confirmed. A
+ * new tool's line goes once the tool is no longer pending, unless ticked.
+ * Takes the same options as renderIssueBody, and `continued`: the bodies
+ * of the continuation comments already posted, whose items count as
+ * listed (and keep their human edits) like the body's. Returns
+ * { body, continuations, count } or null if the body no longer has the
+ * markers needed to merge safely.
+ */
+export function mergeIssueBody(existing, items, {
+  layout = config.digestIssue.layout,
+  limit = MAX_BODY_LENGTH,
+  history = null,
+  excerptChars = config.digestIssue.excerptChars,
+  sources = config.digestIssue.sources,
+  maxItems = config.digestIssue.maxItems,
+  overflow = config.digestIssue.overflow,
+  runSummary = null,
+  pendingTools = [],
+  continued = [],
+} = {}) {
+  const { relevant, lowRelevance } = splitRelevance(items);
+  items = relevant;
+  const existingBlocks = new Map();
+  for (const match of existing.matchAll(ITEM_BLOCK)) {
+    existingBlocks.set(match[1], match[2]);
+  }
+  const actionStart = existing.indexOf(ACTION_START);
+  const actionEnd = existing.indexOf(ACTION_END);
+  if (existingBlocks.size === 0 || actionStart === -1 || actionEnd < actionStart) {
+    return null;
+  }
+  for (const match of continued.join('\n').matchAll(ITEM_BLOCK)) {
+    if (!existingBlocks.has(match[1])) existingBlocks.set(match[1], match[2]);
+  }
+
+  const byId = new Map(items.map(item => [item.id, item]));
+  const added = orderItems(items.filter(item => !existingBlocks.has(item.id)))
+    .map(item => ({ item, block: renderBlock(item, excerptChars) }));
+  // An item the body lists but `items` no longer has keeps its block as
+  // it was; its fields, for the Summary and layout, come from `history`
+  const kept = [...existingBlocks.entries()].map(([id, inner]) => (byId.has(id)
+    ? { item: byId.get(id), block: mergeItemBlock(inner, renderBlock(byId.get(id), excerptChars)) }
+    : { item: history?.find(item => item.id === id) || null, block: wrapBlock(id, inner) }));
+
+  let actionLines = existing
+    .slice(actionStart + ACTION_START.length, actionEnd)
+    .split('\n')
+    .filter(line => line.trim());
+  const listed = new Set(actionLines.map(checkboxText).filter(Boolean));
+  for (const action of ACTIONS) {
+    if (!listed.has(action)) actionLines.push(`- [ ] ${action}`);
+  }
+  // The review line counts the items under Needs Review now; a new count
+  // has to be confirmed again
+  const entries = [...added, ...kept];
+  const reviewCount = entries.filter(isReview).length;
+  const reviewAt = actionLines.findIndex(line => REVIEW_ACTION.test(checkboxText(line) || ''));
+  const reviewLine = reviewCount > 0 ? `- [ ] ${reviewAction(reviewCount)}` : null;
+  if (reviewAt === -1) {
+    if (reviewLine) actionLines.unshift(reviewLine);
+  } else if (checkboxText(actionLines[reviewAt]) !== reviewAction(reviewCount)) {
+    actionLines.splice(reviewAt, 1, ...(reviewLine ? [reviewLine] : []));
+  }
+  // A line per pending tool, after the review line
+  const toolActions = pendingTools.map(newToolAction);
+  actionLines = actionLines.filter(line => {
+    const text = checkboxText(line) || '';
+    return !NEW_TOOL_ACTION.test(text) || toolActions.includes(text) || /^\s*- \[[xX]\] /.test(line);
+  });
+  const stillListed = new Set(actionLines.map(checkboxText).filter(Boolean));
+  const toolAt = actionLines.findLastIndex(line =>
+    REVIEW_ACTION.test(checkboxText(line) || '') || NEW_TOOL_ACTION.test(checkboxText(line) || '')
+  );
+  actionLines.splice(toolAt + 1, 0, ...toolActions.filter(action => !stillListed.has(action)).map(action => `- [ ] ${action}`));
+  const actionSection = `## Action Needed\n\n${ACTION_START}\n${actionLines.join('\n')}\n${ACTION_END}\n`;
+  const trailer = existing.slice(actionEnd + ACTION_END.length).replace(/^\n+/, '');
+
+  return {
+    ...assembleBody(entries, actionSection, trailer, { layout, limit, history, sources, maxItems, overflow, runSummary, lowRelevance }),
+    count: entries.length,
+  };
+}
+
+/**
+ * File the digest issue, first checking for an open digest issue with
+ * the same date. The strategy decides what to do with a match; without
+ * one a new issue is created. Returns { action, number }.
+ *
+ * With `compact` and no items, the compact note naming `lastItem` is filed
+ * instead, unless a digest issue for the date is already open. The title
+ * counts the items listed, not low-relevance ones.
+ *
+ * A new issue is labeled and assigned from its items (see issueLabels and
+ * issueAssignees); an updated or commented one gets whichever labels and
+ * assignees it's missing, so publishing again changes nothing.
+ */
+export async function publishDigestIssue(api, items, {
+  date = digestDate(),
+  strategy = config.digestIssue.dedupeStrategy,
+  layout = config.digestIssue.layout,
+  history = null,
+  excerptChars = config.digestIssue.excerptChars,
+  sources = config.digestIssue.sources,
+  maxItems = config.digestIssue.maxItems,
+  overflow = config.digestIssue.overflow,
+  runSummary = null,
+  pendingTools = [],
+  compact = false,
+  lastItem = null,
+} = {}) {
+  if (!DEDUPE_STRATEGIES.includes(strategy)) {
+    throw new Error(`Unknown dedupe strategy "${strategy}" (expected ${DEDUPE_STRATEGIES.join(', ')})`);
+  }
+  const renderOptions = { layout, history, excerptChars, sources, maxItems, overflow, runSummary, pendingTools };
+
+  const existing = await findOpenDigestIssue(api, date);
+  if (compact && items.length === 0) {
+    // Nothing to merge or comment: the open issue already covers the day
+    if (existing) {
+      console.log(`Open digest issue #${existing.number} already exists for ${date}; skipping`);
+      return { action: 'skipped', number: existing.number };
+    }
+    const issue = await api.createIssue({ title: compactIssueTitle(date), body: renderCompactIssueBody(lastItem) });
+    console.log(`Created issue #${issue.number}: ${issue.title}`);
+    return { action: 'created', number: issue.number };
+  }
+  if (!existing) {
+    const { body, continuations } = renderIssueBody(items, renderOptions);
+    const labels = issueLabels(items);
+    await ensureLabels(api, labels);
+    const issue = await api.createIssue({
+      title: issueTitle(date, splitRelevance(items).relevant.length),
+      body,
+      labels: labels.map(label => label.name),
+    });
+    console.log(`Created issue #${issue.number}: ${issue.title}`);
+    await routeIssue(api, issue, items);
+    await postComments(api, issue.number, continuations);
+    return { action: 'created', number: issue.number };
+  }
+
+  if (strategy === 'skip') {
+    console.log(`Open digest issue #${existing.number} already exists for ${date}; skipping`);
+    return { action: 'skipped', number: existing.number };
+  }
+
+  // Items can be listed in the body or, past what it holds, in comments
+  const comments = await api.listComments(existing.number);
+  if (strategy === 'update') {
+    const continued = comments.map(comment => comment.body || '').filter(continuationPart);
+    const merged = mergeIssueBody(existing.body || '', items, { ...renderOptions, continued });
+    if (merged) {
+      await api.updateIssue(existing.number, { title: issueTitle(date, merged.count), body: merged.body });
+      console.log(`Updated issue #${existing.number} (${merged.count} items)`);
+      await routeIssue(api, existing, items);
+      await syncContinuations(api, existing.number, merged.continuations, comments);
+      return { action: 'updated', number: existing.number };
+    }
+    console.log(`Issue #${existing.number} body has been restructured and can't be merged; appending a comment instead`);
+  }
+
+  const listed = [existing.body || '', ...comments.map(comment => comment.body || '')].join('\n');
+  const unlisted = splitRelevance(items).relevant.filter(item => !listed.includes(`<!-- item:${item.id} -->`));
+  await routeIssue(api, existing, items);
+  if (unlisted.length === 0) {
+    console.log(`Issue #${existing.number} already lists every item; nothing to add`);
+    return { action: 'skipped', number: existing.number };
+  }
+  await postComments(api, existing.number, renderCommentBodies(unlisted, { excerptChars }));
+  console.log(`Commented on issue #${existing.number} with ${unlisted.length} item(s)`);
+  return { action: 'commented', number: existing.number };
+}
+
+/**
+ * Keep one open issue per period (DIGEST_ISSUE_MODE=weekly) in step with
+ * `items`, the whole period's items (see periodItems). The issue is found
+ * by its periodMarker and `items` merged into its body as
+ * publishDigestIssue's "update" strategy does (see mergeIssueBody), so
+ * ticked boxes and notes people added survive. When items were added, a
+ * comment says so ("+2 items since last update"). Without an open issue
+ * for the period, one is created. A body that can no longer be merged
+ * gets the items it doesn't list as a comment instead. Labels and
+ * assignees are added as for publishDigestIssue. Returns
+ * { action, number }, action "created", "updated", "commented" or
+ * "skipped" (the body is already current).
+ *
+ * Pages past the body's limit go in continuation comments, which are
+ * edited in place on later updates rather than posted again (see
+ * syncContinuations).
+ *
+ * GitHub has no conditional update for issues, and the workflow's
+ * concurrency group doesn't cover a manual run or a person editing the
+ * issue: the issue is read again just before the write, and if its
+ * updated_at has moved since it was read, the items are merged into
+ * what's there now and the write tried once more. A second conflict
+ * throws.
+ *
+ * Takes renderIssueBody's options, except `limit`.
+ */
+export async function publishRollingIssue(api, items, { period = digestPeriod(), ...renderOptions } = {}) {
+  const marker = periodMarker(period);
+  const limit = MAX_BODY_LENGTH - marker.length - 1;
+
+  const found = await findRollingIssue(api, period);
+  if (!found) {
+    const { body, continuations } = renderIssueBody(items, { ...renderOptions, limit });
+    const labels = issueLabels(items);
+    await ensureLabels(api, labels);
+    const created = await api.createIssue({
+      title: rollingIssueTitle(period, splitRelevance(items).relevant.length),
+      body: `${marker}\n${body}`,
+      labels: labels.map(label => label.name),
+    });
+    console.log(`Created issue #${created.number}: ${created.title}`);
+    await routeIssue(api, created, items);
+    await postComments(api, created.number, continuations);
+    return { action: 'created', number: created.number };
+  }
+
+  let issue = await api.getIssue(found.number);
+  for (let attempt = 1; ; attempt++) {
+    const existing = issue.body || '';
+    const comments = await api.listComments(issue.number);
+    const continued = comments.map(comment => comment.body || '');
+    const merged = mergeIssueBody(existing.startsWith(marker) ? existing.slice(marker.length + 1) : existing, items, { ...renderOptions, limit, continued });
+    if (!merged) {
+      console.log(`Issue #${issue.number} body has been restructured and can't be merged; appending a comment instead`);
+      await routeIssue(api, issue, items);
+      const listed = [existing, ...continued].join('\n');
+      const unlisted = splitRelevance(items).relevant.filter(item => !listed.includes(`<!-- item:${item.id} -->`));
+      if (unlisted.length === 0) {
+        console.log(`Issue #${issue.number} already lists every item; nothing to add`);
+        return { action: 'skipped', number: issue.number };
+      }
+      await postComments(api, issue.number, renderCommentBodies(unlisted, { excerptChars: renderOptions.excerptChars }));
+      console.log(`Commented on issue #${issue.number} with ${unlisted.length} item(s)`);
+      return { action: 'commented', number: issue.number };
+    }
+
+    const body = `${marker}\n${merged.body}`;
+    const title = rollingIssueTitle(period, merged.count);
+    if (existing === body && issue.title === title) {
+      console.log(`Issue #${issue.number} for ${period} is already current`);
+      await routeIssue(api, issue, items);
+      return { action: 'skipped', number: issue.number };
+    }
+
+    const current = await api.getIssue(issue.number);
+    if (current.updated_at !== issue.updated_at) {
+      if (attempt === 2) {
+        throw new Error(`Issue #${issue.number} changed again while being updated; not overwriting it`);
+      }
+      console.log(`Issue #${issue.number} changed since it was read; merging into it again`);
+      issue = current;
+      continue;
+    }
+
+    const before = listedIds(existing);
+    const added = [...listedIds(body)].filter(id => !before.has(id)).length;
+    await api.updateIssue(issue.number, { title, body });
+    console.log(`Updated issue #${issue.number} for ${period} (+${added})`);
+    await routeIssue(api, current, items);
+    if (added > 0) {
+      await api.createComment(issue.number, changeNote(added));
+    }
+    await syncContinuations(api, issue.number, merged.continuations, comments);
+    return { action: 'updated', number: issue.number };
+  }
+}
+
+/**
+ * Find the open rolling issue for a period: the open digest issue whose
+ * body starts with its periodMarker. Open digest issues are listed rather
+ * than searched for, since search matches words, not the exact marker,
+ * and lags behind new issues.
+ */
+export async function findRollingIssue(api, period) {
+  const marker = periodMarker(period);
+  const candidates = await api.listOpenIssues();
+  return candidates.find(issue => !issue.pull_request && (issue.body || '').startsWith(marker)) || null;
+}
+
+/**
+ * The labels routing a digest issue to its owners, as { name, color,
+ * description }: one per tool its items mention, A-Z, named
+ * config.digestIssue.toolLabelPrefix and the tool in lowercase with
+ * hyphens for spaces ("coverage:nosey-parker"), then
+ * config.digestIssue.negativeLabel when any item is negative (see
+ * utils/sentiment.js). Items under Needs Review and low-relevance ones
+ * don't count.
+ */
+export function issueLabels(items, {
+  prefix = config.digestIssue.toolLabelPrefix,
+  negativeLabel = config.digestIssue.negativeLabel,
+  colors = config.digestIssue.labelColors,
+} = {}) {
+  const labels = routedTools(items).map(tool => ({
+    name: `${prefix}${tool.toLowerCase().replace(/\s+/g, '-')}`,
+    color: colors.tool,
+    description: `Coverage mentioning ${tool}`,
+  }));
+  if (negativeLabel && routedItems(items).some(item => item.sentiment === 'negative')) {
+    labels.push({ name: negativeLabel, color: colors.negative, description: 'Digest includes negative coverage' });
+  }
+  return labels;
+}
+
+/**
+ * Who a digest issue is assigned to: the config.digestIssue.assignees
+ * entries (GitHub logins, or "org/team") for the tools its items mention,
+ * counted as issueLabels counts them. Entries are unique and in tool
+ * order, A-Z; teams are expanded to their members when the issue is
+ * filed.
+ */
+export function issueAssignees(items, { assignees = config.digestIssue.assignees } = {}) {
+  const byTool = new Map();
+  for (const [tool, entries] of Object.entries(assignees)) {
+    const key = canonicalTool(tool);
+    byTool.set(key, [...(byTool.get(key) || []), ...[entries].flat()]);
+  }
+  return [...new Set(routedTools(items).flatMap(tool => byTool.get(tool) || []).map(entry => entry.replace(/^@/, '')))];
+}
+
+/**
+ * Find the open digest issue for a date. Uses the search API, which has
+ * its own small rate limit; if that is exhausted, fall back to listing
+ * the repo's open digest issues through the core API.
+ */
+export async function findOpenDigestIssue(api, date) {
+  const prefix = `${TITLE_PREFIX}${date} - `;
+  let candidates;
+  try {
+    candidates = await api.searchIssues(`"${TITLE_PREFIX}${date}" in:title is:issue is:open label:${config.digestIssue.label}`);
+  } catch (err) {
+    if (!err.rateLimited) throw err;
+    console.warn(`  Warning: ${err.message}; falling back to listing open issues`);
+    candidates = await api.listOpenIssues();
+  }
+  return candidates.find(issue => !issue.pull_request && issue.title.startsWith(prefix)) || null;
+}
+
+/**
+ * Minimal GitHub REST client for the digest issue. `repo` is "owner/name".
+ * Rate-limit responses throw an error with `rateLimited: true`.
+ */
+export function createGitHubApi({ token, repo, client = clientFor('GitHub') }) {
+  const label = config.digestIssue.label;
+
+  async function request(method, path, body) {
+    const res = await client.fetch(`https://api.github.com${path}`, {
+      method,
+      headers: {
+        'Authorization': `Bearer ${token}`,
+        'Accept': 'application/vnd.github+json',
+        ...(body ? { 'Content-Type': 'application/json' } : {}),
+      },
+      body: body ? JSON.stringify(body) : undefined,
+    });
+    if (res.ok) return res.json();
+
+    const text = await res.text();
+    if (res.status === 429 || (res.status === 403 && (res.headers['x-ratelimit-remaining'] === '0' || /rate limit/i.test(text)))) {
+      const err = new Error(`GitHub rate limit hit on ${method} ${path.split('?')[0]}`);
+      err.rateLimited = true;
+      throw err;
+    }
+    throw Object.assign(new Error(`GitHub ${method} ${path.split('?')[0]} failed: HTTP ${res.status}`), { status: res.status });
+  }
+
+  return {
+    async searchIssues(query) {
+      const q = encodeURIComponent(`repo:${repo} ${query}`);
+      return (await request('GET', `/search/issues?q=${q}&per_page=20`)).items;
+    },
+    async listOpenIssues() {
+      const issues = [];
+      for (let page = 1; ; page++) {
+        const batch = await request('GET', `/repos/${repo}/issues?state=open&labels=${encodeURIComponent(label)}&per_page=100&page=${page}`);
+        issues.push(...batch);
+        if (batch.length < 100) return issues;
+      }
+    },
+    createIssue({ title, body, labels = [] }) {
+      return request('POST', `/repos/${repo}/issues`, { title, body, labels: [label, ...labels] });
+    },
+    getIssue(number) {
+      return request('GET', `/repos/${repo}/issues/${number}`);
+    },
+    updateIssue(number, fields) {
+      return request('PATCH', `/repos/${repo}/issues/${number}`, fields);
+    },
+    createComment(number, body) {
+      return request('POST', `/repos/${repo}/issues/${number}/comments`, { body });
+    },
+    async listComments(number) {
+      const comments = [];
+      for (let page = 1; ; page++) {
+        const batch = await request('GET', `/repos/${repo}/issues/${number}/comments?per_page=100&page=${page}`);
+        comments.push(...batch);
+        if (batch.length < 100) return comments;
+      }
+    },
+    updateComment(id, body) {
+      return request('PATCH', `/repos/${repo}/issues/comments/${id}`, { body });
+    },
+    async listLabels() {
+      const labels = [];
+      for (let page = 1; ; page++) {
+        const batch = await request('GET', `/repos/${repo}/labels?per_page=100&page=${page}`);
+        labels.push(...batch);
+        if (batch.length < 100) return labels;
+      }
+    },
+    createLabel({ name, color, description = '' }) {
+      return request('POST', `/repos/${repo}/labels`, { name, color, description });
+    },
+    addLabels(number, labels) {
+      return request('POST', `/repos/${repo}/issues/${number}/labels`, { labels });
+    },
+    addAssignees(number, assignees) {
+      return request('POST', `/repos/${repo}/issues/${number}/assignees`, { assignees });
+    },
+    listTeamMembers(org, team) {
+      return request('GET', `/orgs/${encodeURIComponent(org)}/teams/${encodeURIComponent(team)}/members?per_page=100`);
+    },
+  };
+}
+
+// Items whose embargo just lifted are pinned to the top
+function orderItems(items) {
+  const sorted = sortItems(items);
+  return [...sorted.filter(item => item.embargo_lifted_at), ...sorted.filter(item => !item.embargo_lifted_at)];
+}
+
+function renderBlock(item, excerptChars) {
+  return item.needs_review ? renderReviewBlock(item) : renderItemBlock(item, excerptChars);
+}
+
+function renderItemBlock(item, excerptChars) {
+  const archiveLink = item.archive_url ? ` <sub>[(archive)](${escapeLinkUrl(item.archive_url)})</sub>` : '';
+  const hnLink = item.hacker_news
+    ? ` <sub>[(HN: ${item.hacker_news.points} points, ${item.hacker_news.comments} comments)](${escapeLinkUrl(item.hacker_news.url)})</sub>`
+    : '';
+  const paywallTag = item.paywalled ? ' 🔒' : '';
+  let inner = `### ${itemLink(item)}${paywallTag}${archiveLink}${hnLink}\n`;
+  inner += `${itemMeta(item)}\n\n`;
+  if (isPendingUpdate(item)) {
+    inner += `🔄 _${escapeMarkdown(updateNote(item.update))}_\n\n`;
+  }
+  if (item.also_published_by?.length) {
+    const outlets = item.also_published_by.map(outlet => `[${escapeMarkdown(outlet.source)}](${escapeLinkUrl(outlet.url)})`);
+    inner += `Also published by: ${outlets.join(', ')}\n\n`;
+  }
+  if (item.excerpt) {
+    inner += `> ${escapeMarkdown(excerpt(item.excerpt, excerptChars))}\n\n`;
+  }
+  if (item.mention_context) {
+    inner += `Context: _${escapeMarkdown(item.mention_context)}_\n\n`;
+  }
+  return wrapBlock(item.id, inner);
+}
+
+// An item's "[Title](url)" link
+function itemLink(item) {
+  return `[${escapeMarkdown(item.title)}](${escapeLinkUrl(item.url)})`;
+}
+
+// An item's "**Source** · date `Tool`" line, as a full item shows it under
+// its heading and an item past maxItems after its link
+function itemMeta(item) {
+  const toolTags = toolTagsOf(item);
+  const embargoTag = item.embargo_lifted_at ? ' · 📰 embargo lifted' : '';
+  const negativeTag = item.sentiment === 'negative' ? ' · ⚠️ negative' : '';
+  const languageTag = isForeign(item) ? ` · 🌐 ${languageName(item.language)}` : '';
+  return `**${escapeMarkdown(item.source)}** · ${item.date_estimated ? '~' : ''}${item.date}${negativeTag}${embargoTag}${languageTag} ${toolTags}`;
+}
+
+function itemTools(item) {
+  return canonicalTools(item.tools_mentioned, item.date);
+}
+
+// An item's tools as `code` tags, A-Z as the JSON digest has them
+function toolTagsOf(item) {
+  return [...itemTools(item)].sort().map(tool => `\`${tool}\``).join(' ');
+}
+
+/**
+ * An item under Needs Review, as one line with a box to tick: its title
+ * link, then the meta line with its confidence.
+ */
+function renderReviewBlock(item) {
+  const toolTags = toolTagsOf(item);
+  const confidence = `🔍 ${Math.round((item.confidence ?? 0) * 100)}% confidence`;
+  const negativeTag = item.sentiment === 'negative' ? ' · ⚠️ negative' : '';
+  return wrapBlock(item.id, `- [ ] ${itemLink(item)} — **${escapeMarkdown(item.source)}** · `
+    + `${item.date_estimated ? '~' : ''}${item.date}${negativeTag} · ${confidence} ${toolTags}\n`);
+}
+
+function wrapBlock(id, inner) {
+  return `<!-- item:${id} -->\n${inner}<!-- /item:${id} -->\n`;
+}
+
+/**
+ * Refresh an item's machine-rendered lines, keeping lines people added.
+ */
+function mergeItemBlock(existingInner, renderedBlock) {
+  const lines = existingInner.split('\n');
+  // Someone confirmed it: keep the tick
+  if (lines.some(line => /^- \[[xX]\] /.test(line) && REVIEW_PREFIX.test(line))) {
+    renderedBlock = renderedBlock.replace(/^(<!-- item:\S+ -->\n)- \[ \] /, '$1- [x] ');
+  }
+  const human = lines.filter(line => line.trim() && !MACHINE_LINE.test(line));
+  if (human.length === 0) return renderedBlock;
+  return renderedBlock.replace(/(<!-- \/item:\S+ -->\n)$/, `${human.join('\n')}\n\n$1`);
+}
+
+async function postComments(api, number, bodies) {
+  for (const body of bodies) {
+    await api.createComment(number, body);
+  }
+  if (bodies.length > 0) {
+    console.log(`  Posted ${bodies.length} comment(s) on #${number}`);
+  }
+}
+
+/**
+ * Bring an updated issue's continuation comments in line with `bodies`
+ * (see assembleBody), matched by part number among its `comments`: a part
+ * already posted is edited if it changed and left alone if not, a new part
+ * is posted, and a part no longer needed is emptied to a note, so items
+ * aren't listed twice.
+ */
+async function syncContinuations(api, number, bodies, comments) {
+  const posted = new Map();
+  for (const comment of comments) {
+    const part = continuationPart(comment.body || '');
+    if (part && !posted.has(part)) posted.set(part, comment);
+  }
+  const wanted = new Map(bodies.map(body => [continuationPart(body), body]));
+  for (const part of posted.keys()) {
+    if (!wanted.has(part)) wanted.set(part, `${continuationHeader(part)}_Nothing continued here any more._\n`);
+  }
+
+  let created = 0;
+  let edited = 0;
+  for (const [part, body] of wanted) {
+    const comment = posted.get(part);
+    if (!comment) {
+      await api.createComment(number, body);
+      created++;
+    } else if (comment.body !== body) {
+      await api.updateComment(comment.id, body);
+      edited++;
+    }
+  }
+  if (created > 0 || edited > 0) {
+    console.log(`  Continuation comments on #${number}: ${created} posted, ${edited} edited`);
+  }
+}
+
+// "## Coverage Items (continued, part 2)", the header of a continuation
+// comment; the issue body is part 1
+function continuationHeader(part) {
+  return `## Coverage Items (continued, part ${part})\n\n`;
+}
+
+function continuationPart(body) {
+  const match = body.match(/^## Coverage Items \(continued, part (\d+)\)\n/);
+  return match ? Number(match[1]) : null;
+}
+
+// Items a digest issue is labeled and assigned by: not under Needs
+// Review, nor low-relevance
+function routedItems(items) {
+  return splitRelevance(items).relevant.filter(item => !item.needs_review);
+}
+
+// The tools routedItems mention, A-Z
+function routedTools(items) {
+  return [...new Set(routedItems(items).flatMap(item => canonicalTools(item.tools_mentioned || [], item.date)))].sort();
+}
+
+/**
+ * Create whichever of `labels` ({ name, color, description }) the repo
+ * doesn't have yet. One created meanwhile (HTTP 422) counts as there.
+ */
+async function ensureLabels(api, labels) {
+  if (labels.length === 0) return;
+  const existing = new Set((await api.listLabels()).map(label => label.name.toLowerCase()));
+  for (const label of labels.filter(label => !existing.has(label.name.toLowerCase()))) {
+    try {
+      await api.createLabel(label);
+      console.log(`  Created label ${label.name}`);
+    } catch (err) {
+      if (err.status !== 422) throw err;
+    }
+  }
+}
+
+/**
+ * Give an issue whichever of its items' labels and assignees it doesn't
+ * have. Teams ("org/team") are expanded to their members; a team that
+ * can't be read, or an assignment GitHub refuses, is logged and skipped,
+ * as the issue itself is filed. Rate limits still stop the publish.
+ */
+async function routeIssue(api, issue, items) {
+  const have = new Set((issue.labels || []).map(label => (label.name ?? label).toLowerCase()));
+  const labels = issueLabels(items).filter(label => !have.has(label.name.toLowerCase()));
+  if (labels.length > 0) {
+    await ensureLabels(api, labels);
+    await api.addLabels(issue.number, labels.map(label => label.name));
+    console.log(`  Labeled #${issue.number}: ${labels.map(label => label.name).join(', ')}`);
+  }
+
+  const logins = [];
+  for (const entry of issueAssignees(items)) {
+    if (!entry.includes('/')) {
+      logins.push(entry);
+      continue;
+    }
+    const [org, team] = entry.split('/');
+    try {
+      logins.push(...(await api.listTeamMembers(org, team)).map(member => member.login));
+    } catch (err) {
+      if (err.rateLimited) throw err;
+      console.warn(`  Warning: Could not read team ${entry}: ${err.message}`);
+    }
+  }
+  const assigned = (issue.assignees || []).map(user => user.login.toLowerCase());
+  const unassigned = [...new Set(logins)].filter(login => !assigned.includes(login.toLowerCase()));
+  const room = MAX_ASSIGNEES - assigned.length;
+  if (unassigned.length > room) {
+    console.warn(`  Warning: GitHub assigns ${MAX_ASSIGNEES} people at most; not assigning ${unassigned.slice(Math.max(0, room)).join(', ')}`);
+  }
+  const assignees = unassigned.slice(0, Math.max(0, room));
+  if (assignees.length === 0) return;
+  try {
+    await api.addAssignees(issue.number, assignees);
+    console.log(`  Assigned #${issue.number} to ${assignees.join(', ')}`);
+  } catch (err) {
+    if (err.rateLimited) throw err;
+    console.warn(`  Warning: Could not assign #${issue.number}: ${err.message}`);
+  }
+}
+
+// Ids of the items an issue body lists
+function listedIds(body) {
+  return new Set([...body.matchAll(ITEM_BLOCK)].map(match => match[1]));
+}
+
+// The comment on a rolling issue saying what an update changed
+function changeNote(added) {
+  return `+${added} item${added > 1 ? 's' : ''} since last update\n`;
+}
+
+// Items to list, and how many were left out as low-relevance
+function splitRelevance(items) {
+  const relevant = items.filter(item => !item.low_relevance);
+  return { relevant, lowRelevance: items.length - relevant.length };
+}
+
+function renderActionNeeded(reviewCount = 0, pendingTools = []) {
+  const lines = [
+    ...(reviewCount > 0 ? [reviewAction(reviewCount)] : []),
+    ...pendingTools.map(newToolAction),
+    ...ACTIONS,
+  ].map(action => `- [ ] ${action}`);
+  return `## Action Needed\n\n${ACTION_START}\n${lines.join('\n')}\n${ACTION_END}\n`;
+}
+
+/**
+ * Lay out the body from `entries`, each { item, block }: the tracker item
+ * and its rendered block. The Summary, the sections and the by-tool
+ * groups are worked out from the items; the blocks are only placed. An
+ * entry's item is null for a block merged from an existing body whose
+ * item can't be found; it counts as an item, listed as it was, under
+ * the section its block's shape says (Needs Review, Updated Coverage).
+ */
+function assembleBody(entries, actionSection, trailer, { layout, limit, history, sources, maxItems, overflow, runSummary, lowRelevance = 0 }) {
+  if (!OVERFLOW_STYLES.includes(overflow)) {
+    throw new Error(`Unknown overflow style "${overflow}" (expected ${OVERFLOW_STYLES.join(', ')})`);
+  }
+  const review = entries.filter(isReview).map(entry => entry.block);
+  const confirmed = entries.filter(entry => !isReview(entry));
+  const summaryItems = confirmed.filter(entry => entry.item).map(({ item }) => ({ ...item, tools: item.tools_mentioned }));
+  const summary = summarizeDigest(summaryItems, history);
+  const updated = confirmed.filter(isUpdate);
+  const fresh = confirmed.filter(entry => !isUpdate(entry));
+  let head = `## Summary\n\n`;
+  head += `| Metric | Count |\n|--------|-------|\n`;
+  head += `| New Items | ${fresh.length} |\n`;
+  head += `| Updated Items | ${updated.length} |\n`;
+  if (review.length > 0) head += `| Needs Review | ${review.length} |\n`;
+  head += `| Publications | ${summary.uniqueSources} |\n`;
+  head += `| Tools Mentioned | ${summary.toolCounts.map(({ tool, count }) => `${escapeMarkdown(tool)} (${count})`).join(', ') || 'None'} |\n`;
+  if (summary.firstSeenTools.length > 0) {
+    head += `| First Coverage | 🆕 ${summary.firstSeenTools.map(escapeMarkdown).join(', ')} |\n`;
+  }
+  head += `\n`;
+  if (lowRelevance > 0) head += `_${lowRelevance} low-relevance mentions excluded (see JSON output)_\n\n`;
+  // Left out on the first digest, when there's nothing to compare with
+  const trends = history && compareSummaries(summary, previousDigestSummary(history, summaryItems));
+  if (trends) head += renderTrendsMarkdown(trends, 'previous digest');
+  if (sources && summary.sourceCounts.length > 0) head += renderSourcesMarkdown(summary.sourceCounts);
+  if (runSummary) head += renderRunSummary(runSummary);
+  head += `---\n\n`;
+
+  // New items come first, then rewrites; past maxItems the rest are only listed
+  const cap = maxItems > 0 ? maxItems : Infinity;
+  const shownFresh = fresh.slice(0, cap);
+  const shownUpdated = updated.slice(0, Math.max(0, cap - fresh.length));
+  const more = [...fresh.slice(cap), ...updated.slice(shownUpdated.length)];
+
+  if (shownFresh.length > 0 || shownUpdated.length === 0) head += `## Coverage Items\n\n`;
+  const tail = trailer ? `${actionSection}\n${trailer}` : actionSection;
+  const segments = layout === 'by-tool'
+    ? groupedSegments(shownFresh)
+    : shownFresh.map(({ block }) => `${block}\n---\n\n`);
+  // Rewrites of earlier coverage follow, the heading travelling with the first
+  segments.push(...shownUpdated.map(({ block }, i) => `${i === 0 ? '## Updated Coverage\n\n' : ''}${block}\n---\n\n`));
+  if (more.length > 0) segments.push(...overflowSegments(more.map(compactBlock), overflow));
+  // Items the monitors weren't sure about, to be ticked once confirmed
+  segments.push(...review.map((block, i) => {
+    const heading = i === 0 ? `## Needs Review\n\n_${review.length} item(s) that may not be about Praetorian; tick each one you confirm._\n\n` : '';
+    return `${heading}${block}${i === review.length - 1 ? '\n---\n\n' : ''}`;
+  }));
+
+  // Fill the body with whole items, leaving room for the Action Needed
+  // section and a pointer to the continuation comments.
+  const overflowNote = count => `_${count} more item(s) continued in the comments below._\n\n---\n\n`;
+  let fit = segments.length;
+  if (head.length + segments.join('').length + tail.length > limit) {
+    let room = limit - head.length - tail.length - overflowNote(entries.length).length;
+    fit = 0;
+    while (fit < segments.length && segments[fit].length <= room) {
+      room -= segments[fit].length;
+      fit++;
+    }
+  }
+
+  const continued = segments.slice(fit);
+  const continuedCount = continued.join('').split('<!-- item:').length - 1;
+  const body = head + segments.slice(0, fit).join('') + (continued.length > 0 ? overflowNote(continuedCount) : '') + tail;
+  // The body is part 1; comments continue from part 2
+  const continuations = paginate(continued, limit, part => continuationHeader(part + 1));
+  return { body, continuations };
+}
+
+/**
+ * The pipeline's source run summary, folded into a <details> block: each
+ * source's status, item count, and how long it took, and the repeated
+ * requests the run's query memo saved.
+ */
+function renderRunSummary({ sources }) {
+  const failed = sources.filter(row => row.status !== 'ok').length;
+  const saved = sources.reduce((sum, row) => sum + (row.requestsSaved || 0), 0);
+  let md = `<details>\n<summary>Source run: ${sources.length} source(s)${failed > 0 ? `, ${failed} failed` : ''}${saved > 0 ? `, ${saved} repeated request(s) saved` : ''}</summary>\n\n`;
+  md += `| Source | Status | Items | Time |\n|--------|--------|-------|------|\n`;
+  for (const row of sources) {
+    const status = row.status === 'ok'
+      ? (row.failures > 0 ? `⚠️ ${row.failures} failure(s)` : '✅ ok')
+      : `❌ ${row.status === 'failed' ? 'failed: ' : ''}${escapeMarkdown(row.error || '')}`;
+    const time = row.durationMs < 1000 ? `${row.durationMs}ms` : `${(row.durationMs / 1000).toFixed(1)}s`;
+    md += `| ${escapeMarkdown(row.source)} | ${status} | ${row.items} | ${time} |\n`;
+  }
+  return md + `\n</details>\n\n`;
+}
+
+/**
+ * Segments for the by-tool layout: a subsection per tool in config.tools
+ * order, then one for items with no tool. Each item's full block goes
+ * under its first tool with an "also mentions" note; its other tools'
+ * subsections get a one-line pointer instead of a second copy.
+ */
+function groupedSegments(entries) {
+  const groups = new Map();
+  const group = name => {
+    if (!groups.has(name)) groups.set(name, { cards: [], pointers: [] });
+    return groups.get(name);
+  };
+
+  for (const { item, block } of entries) {
+    const [primary = OTHER_GROUP, ...others] = item ? itemTools(item) : [];
+    const note = others.length > 0 ? `_Also mentions: ${others.map(escapeMarkdown).join(', ')}_\n\n` : '';
+    group(primary).cards.push(`${block}\n${note}---\n\n`);
+
+    const link = item && itemLink(item);
+    for (const tool of others) {
+      group(tool).pointers.push(`- ${link} (under ${escapeMarkdown(primary)})\n`);
+    }
+  }
+
+  const rank = name => {
+    if (name === OTHER_GROUP) return Infinity;
+    const index = config.tools.indexOf(name);
+    return index === -1 ? config.tools.length : index;
+  };
+  const names = [...groups.keys()].sort((a, b) => rank(a) - rank(b) || a.localeCompare(b));
+
+  const segments = [];
+  for (const name of names) {
+    const { cards, pointers } = groups.get(name);
+    const pointerList = pointers.length > 0 ? `${pointers.join('')}\n---\n\n` : '';
+    // The subsection heading travels with its first entry so a page
+    // break never leaves it dangling
+    const [first = pointerList, ...rest] = cards;
+    segments.push(`### ${escapeMarkdown(name)}\n\n${first}`, ...rest);
+    if (cards.length > 0 && pointerList) segments.push(pointerList);
+  }
+  return segments;
+}
+
+/**
+ * Segments for the items past maxItems: one linked line each under
+ * "Additional Coverage", or all of them folded into one <details> block.
+ */
+function overflowSegments(compactBlocks, style) {
+  const heading = `## Additional Coverage\n\n`;
+  const count = `${compactBlocks.length} more item(s)`;
+  if (style === 'details') {
+    return [`${heading}<details>\n<summary>${count}</summary>\n\n${compactBlocks.join('')}\n</details>\n\n---\n\n`];
+  }
+  const segments = [...compactBlocks];
+  segments[0] = `${heading}_${count}, listed by title._\n\n${segments[0]}`;
+  segments[segments.length - 1] += `\n---\n\n`;
+  return segments;
+}
+
+/**
+ * An item cut down to one line: its title link, then the meta line
+ * (source, date, tools), marked when the item is a rewrite. Keeps the
+ * item markers so an update still recognizes it. A block with no item
+ * is listed as it was.
+ */
+function compactBlock(entry) {
+  const { item, block } = entry;
+  if (!item) return block;
+  const note = isUpdate(entry) ? COMPACT_UPDATE : '';
+  return wrapBlock(item.id, `- ${itemLink(item)} — ${itemMeta(item).trimEnd()}${note}\n`);
+}
+
+/**
+ * Group item segments into pages no longer than `limit`, each starting
+ * with header(partNumber). Items are never split across pages.
+ */
+function paginate(segments, limit, header) {
+  const pages = [];
+  let page = null;
+  for (const segment of segments) {
+    if (page === null || page.length + segment.length > limit) {
+      if (page !== null) pages.push(page);
+      page = header(pages.length + 1);
+    }
+    page += segment;
+  }
+  if (page !== null) pages.push(page);
+  return pages;
+}
+
+// Under Needs Review: an item flagged needs_review, or, with no item,
+// a block listed as a box to tick
+function isReview({ item, block }) {
+  return item ? Boolean(item.needs_review) : block.split('\n').some(line => REVIEW_PREFIX.test(line));
+}
+
+// A rewrite of earlier coverage, under Updated Coverage; with no item, a
+// block with the update note, or listed past maxItems marked as one
+function isUpdate({ item, block }) {
+  if (item) return !item.needs_review && isPendingUpdate(item);
+  return block.split('\n').some(line => line.startsWith('🔄 ') || (line.startsWith('- [') && line.endsWith(COMPACT_UPDATE)));
+}
+
+function reviewAction(count) {
+  return `Confirm the ${count} item(s) under Needs Review`;
+}
+
+function newToolAction(tool) {
+  return `New tool detected: ${escapeMarkdown(tool)} — confirm tracking`;
+}
+
+function checkboxText(line) {
+  const match = line.match(/^\s*- \[[ xX]\] (.*)$/);
+  return match ? match[1].trim() : null;
+}
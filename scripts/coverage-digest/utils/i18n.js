@@ -0,0 +1,140 @@
+import { readFileSync, readdirSync } from 'fs';
+import { join } from 'path';
+import { config } from '../config.js';
+import { isCalendarDate } from './timezone.js';
+
+const DEFAULT_LOCALE = 'en';
+const LOCALES_DIR = join(config.paths.root, 'locales');
+
+const catalogs = {};
+
+// Named date styles (Intl.DateTimeFormat options): "header" for the
+// digest's date line, "item" for an item's date
+const DATE_STYLES = {
+  header: { weekday: 'long', year: 'numeric', month: 'long', day: 'numeric' },
+  item: { year: 'numeric', month: 'short', day: 'numeric' },
+};
+// German, French, and Spanish readers expect numeric, day-first item
+// dates (16.02.2026, 16/02/2026) rather than a month name
+const NUMERIC_DATE = { year: 'numeric', month: '2-digit', day: '2-digit' };
+const LOCALE_DATE_STYLES = {
+  de: { item: NUMERIC_DATE },
+  fr: { item: NUMERIC_DATE },
+  es: { item: NUMERIC_DATE },
+};
+
+/**
+ * Load a message catalog from locales/<locale>.json (cached).
+ * Returns null if no catalog exists for the locale. Only the catalogs
+ * availableLocales() lists are read, so a requested locale never becomes
+ * a path ("../../package" has no catalog rather than naming a file).
+ */
+function loadCatalog(locale) {
+  if (!availableLocales().includes(locale)) return null;
+  if (!(locale in catalogs)) {
+    try {
+      catalogs[locale] = JSON.parse(readFileSync(join(LOCALES_DIR, `${locale}.json`), 'utf-8'));
+    } catch (err) {
+      if (err.code !== 'ENOENT') throw err;
+      catalogs[locale] = null;
+    }
+  }
+  return catalogs[locale];
+}
+
+/**
+ * List the locales that have a catalog in locales/.
+ */
+export function availableLocales() {
+  return readdirSync(LOCALES_DIR)
+    .filter(f => f.endsWith('.json'))
+    .map(f => f.replace(/\.json$/, ''))
+    .sort();
+}
+
+/**
+ * Resolve a requested locale to one we have a catalog for.
+ * "ja-JP" falls back to "ja", and anything unknown falls back to English.
+ */
+export function resolveLocale(locale) {
+  const requested = (locale || DEFAULT_LOCALE).trim();
+  if (loadCatalog(requested)) return requested;
+  const base = requested.split(/[-_]/)[0].toLowerCase();
+  if (loadCatalog(base)) return base;
+  console.warn(`  Warning: No message catalog for locale "${requested}", using ${DEFAULT_LOCALE}`);
+  return DEFAULT_LOCALE;
+}
+
+/**
+ * Create a translator for a locale. Missing keys fall back to the
+ * English catalog, then to the key itself so gaps are visible.
+ *
+ *   t(key, vars)      plain text with {var} placeholders filled in
+ *   t.html(key, vars) HTML-escaped message; vars are inserted as-is
+ *                     so callers can pass pre-escaped markup
+ */
+export function createTranslator(locale) {
+  const resolved = resolveLocale(locale);
+  const messages = loadCatalog(resolved);
+  const fallback = loadCatalog(DEFAULT_LOCALE);
+
+  const lookup = key => messages[key] ?? fallback[key] ?? key;
+  const fill = (message, vars) =>
+    message.replace(/\{(\w+)\}/g, (match, name) => (name in vars ? String(vars[name]) : match));
+
+  const t = (key, vars = {}) => fill(lookup(key), vars);
+  t.html = (key, vars = {}) => fill(escapeHtml(lookup(key)), vars);
+  t.locale = resolved;
+  return t;
+}
+
+/**
+ * Replace {{t:key}} placeholders in a template with escaped messages.
+ */
+export function translateTemplate(template, t) {
+  return template.replace(/\{\{t:([\w.]+)\}\}/g, (_, key) => t.html(key));
+}
+
+/**
+ * Format a date for display in the given locale, in options.timeZone
+ * (default: config.timeZone). A bare YYYY-MM-DD is a calendar day rather
+ * than an instant, so it is shown as-is in every zone. options.style
+ * picks a named style ("header" or "item") with the locale's conventions,
+ * e.g. "Montag, 16. Februar 2026" and "16.02.2026" in German; other
+ * options are passed to Intl and override it. A locale Intl doesn't know
+ * is formatted as English.
+ *
+ * Display only: stored and compared dates stay ISO 8601.
+ */
+export function formatDate(date, locale, { style, ...options } = {}) {
+  const tag = intlLocale(locale);
+  const timeZone = isCalendarDate(date) ? 'UTC' : options.timeZone || config.timeZone;
+  return new Date(date).toLocaleDateString(tag, { ...dateStyle(tag, style), ...options, timeZone });
+}
+
+function dateStyle(locale, style) {
+  if (!style) return {};
+  if (!(style in DATE_STYLES)) {
+    throw new Error(`Unknown date style "${style}" (expected ${Object.keys(DATE_STYLES).join(', ')})`);
+  }
+  const base = locale.split(/[-_]/)[0].toLowerCase();
+  return LOCALE_DATE_STYLES[base]?.[style] || DATE_STYLES[style];
+}
+
+// Intl quietly uses the runtime's default locale for tags it lacks
+function intlLocale(locale) {
+  try {
+    if (locale && Intl.DateTimeFormat.supportedLocalesOf(locale).length > 0) return locale;
+  } catch {
+    // Malformed tag
+  }
+  return DEFAULT_LOCALE;
+}
+
+function escapeHtml(str) {
+  return str
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
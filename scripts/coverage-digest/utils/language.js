@@ -0,0 +1,187 @@
+import { config } from '../config.js';
+import { formerNames } from './tools.js';
+
+export const FOREIGN_LANGUAGE_MODES = ['include', 'exclude', 'section'];
+
+// Trigram profiles of the Latin-script languages told apart by their
+// letters, most frequent first, from news copy in each language. See
+// Cavnar & Trenkle, "N-Gram-Based Text Categorization" (1994).
+const PROFILES = Object.fromEntries(Object.entries({
+  de: [
+    'en |er | di|die|che|ie |ter| un| da| de|ich|sch| in|das|den|in |ten|und| ve|der|ein|eit|es |nte|ver| be| we|ach|as |em |her|nd |unt| ei| ge|ang|ass|cht|for|lle',
+    'nde|nen|rde|ste|ung|wer| es| me| si|ber|ch |de |erh|erk|ern|ert|est|ffe|ft |gen|he |hen|hme|ine|ion|its|le |men|ng |on |rne|rsc|rte|sic|ss |te | an| au| fo| sc',
+    ' te| zu|ati|aus|des|ehm|ell|ene|ent|erd|ere|eru|ese|ete|etz|fen|ges|hei|hte|ier|lic|neh|net|nge|or |ors|rhe|run|tio|ts |vor|wei| al| er| fi| fü| ha| ma| mi| ne',
+    ' pr| se| vo| wa| wi| wo| üb|abe|ag |am |ben|chn|chä|dem|eam|ehr|eic|end|erg|ers|erö|eug|fac|für|gan|gel|hin|hne|hr |hre|ht |hun|ies|ige|ind|inf|ist|it |ite|kze',
+  ].join('|'),
+  en: [
+    ' th|the|he | to|ed | in|to |nd | an| of| re|at | co|and|ers|hat|of |on |re |tha| be| ha|as |en |ion|ng |ent|ing|it |res|rs |tio| it| mo| pr| te|ati|com|ear|has',
+    'in |is |ver|wor| al| as| at| ex| ne| on| se|als|any|arc|are|bee|ch |ds |eas|ecu|een|es |ese|est|for|ind|ity|ls |mpa|nte|nti|ny |omp|ool|ore|pan|rch|rea|sea|st ',
+    'tin|too|ts |ty |ve | a | ar| cr| de| do| fi| is| pu| sa| sh| us| wa| we| wh| wi| wo|ack|aid|ak |ani|ar |asi|att|ave|ble|che|cri|cur|den|der|eak|eam|end|epo|er ',
+    'erv|eve|ew |exp|ey |hav|her|hey|his|id |iew|int|ks |lar|ld |ll |mor|nde|ned|net|ont|or |ord|ork|ort|ost|oul|ove|pen|por|pre|pro|rep|rev|rit|rt |sai|sec|sto|sur',
+  ].join('|'),
+  es: [
+    ' de|es |de | la|as |que| qu|os |ue | lo| co|ent|la | en| in| se|el |los| el|do |est|res|aci|ado|an |com|en |na |on |tes| a | es| un| y |ció|con|ica|ida|ien|ión',
+    'las|nte|ntr|seg|tra|ón | ha| pr|ad |cto|dad|des|egu|ier|inv|les|or |ore|par|ra |ta |tor|una| di| ma| me| pa| re|ar |ara|bie|ble|ect|emp|ers|evi|for|gad|gur|ide',
+    'iga|inc|inf|ita|lic|men|mpa|nci|ndo|nfo|nta|nve|omp|orm|per|pre|qui|rid|ron|rti|ría|se |sta|sti|tar|tie|tig|uri|var|ves|ía |ódi| an| at| au| cu| có| em| eq| ex',
+    ' fa| he| pe| po| pu| ta| va| ve|ada|ale|ami|and|ant|ari|aro|art|ata|ayo|añí|bli|cad|cas|ces|cue|cód|das|del|den|dig|dor|ece|ede|ema|enc|end|equ|err|ert|esa|esc',
+  ].join('|'),
+  fr: [
+    'es | le| de|ent|le |les|nt | qu| la|de |des|la |eur|que| à |et |ns |rs |ts |ue |ur | da| l |che|ion|tre|té | a | co| en| et| so|ans|dan|er |ntr|on |out|til|urs',
+    'uti| dé| in| se| un|ant|en |est|ide|ls |men|nts|ont|ouv|qui|teu|un |uve|ver| au| ce| d | ex| fa| me| mo| ou| pl| pr| pu|art|ati|ce |com|cte|ect|eme|erc|ers|her',
+    'ien|ils|ine|is |ise|ité|lie|me |ne |nti|omm|our|plu|rch|sen|sio|sou|sse|sur|te |tif|tio|us |ux |été| an| at| av| ch| di| du| gr| il| no| on| or| pa| po| ra| ré',
+    ' si| su| sé| te| to| ut| vo| éq|age|ale|and|ann|aqu|ass|att|aut|aux|ava|ave|ble|bli|cet|cié|cri|cur|dem|den|du |déc|ell|ema|epr|era|ert|ett|exp|gra|heu|ibl|ier',
+  ].join('|'),
+  it: [
+    'to | ch|ato|re | de| di|che|he |le | co| il|di |il |no |ti | in| pe|ent|la |li | ha| i |are|ion|per|tto| se|azi|cat|ell|er |est|ett|gli|nti|ta |te |tor| a | e ',
+    ' la| pr| so| st| un|ann|con|del|ha |ica|ice|lle|lo |min|ne |ori|ri |ric|sta|str|tra|ver| gl| gr| l | mi| ne| pi| qu| ri| si| te|acc|agg|art|ce |cer|chi|com|de ',
+    'end|erc|ers|ess|ggi|ien|ina|iù |lla|men|nal|nel|nno|one|oni|più|rca|rto|si |tà |un |ure|zio|zza| ag| al| an| az| cr| es| fa| le| lo| ma| me| pa| pu| ra| re| sa',
+    ' sp| su| vo| è |ali|all|and|ano|ati|att|bbl|bli|cia|cie|cri|cur|da |den|dic|div|do |ede|ega|ert|erv|eti|età|ezz|gat|gio|gru|han|hi |icu|ide|iet|ile|ima|in |ior',
+  ].join('|'),
+  nl: [
+    'en | de|de | he|et |at |het| in| be|dat| da|ver| di|den|ie |nde| en|een|er |ers|est|len|te | ee| ge| te| vo|dri|eke|in |ing|lle|oor| is| me| va| ve|aan|ati|bed',
+    'cht|der|die|edr|ens|gen|ige|is |ken|nd |nte|ond|ord|rij|rs |sch|ste|ter|voo| aa| al| dr| ma| om| on| ov| wa| zi|aar|ach|al |all|an |anv|ect|ege|ele|end|erz|eve',
+    'ge |gin|igi|ijf|jf |ker|maa|mee|oek|om |or |ove|pen|rde|rzo|tie|van|ven|zoe| bl| la| op| pr| to| ui| wo| za|aak|aat|and|ar |bek|bev|bli|chi|dez|dig|eam|ebr|eer',
+    'ees|eil|ek |eme|ent|erb|erd|ere|erh|eri|eze|geb|gel|ger|gt |ht |ijv|ili|ind|it |jve|ke |lan|ler|lig|log|ls |mat|men|nel|nen|ng |nge|ngs|nlo|nne|ns |nva|ool|pro',
+  ].join('|'),
+  pt: [
+    'es |as |que| qu|os |ue | co| de|de |res| a |com| o | os|tes|ent|est| se|am |do | e | em| in| pe| pr| te|ara|ia |isa|ma |nte|qui|ra |ram|ão | an| as| es| re| um',
+    'ame|egu|em |ica|na |nci|no |ore|par|pes|pre|ran|ria|sta|uma| di| fe| ma| no| pa| ve|ada|ado|ais|anç|ar |da |das|des|ede|emp|esq|eto|ida|inv|ion|is |lic|mai|men',
+    'mpa|nal|ndo|omp|ou |pro|rio|sa |seg|squ|ste|ta |tas|to |tor|uis|ver|ári|ça |ção| at| cr| có| da| do| en| eq| ex| fa| mu| na| po| pu| vá|aca|adi|al |ali|ana|and',
+    'anh|ant|are|ará|ata|aça|açã|açõ|bli|cas|caç|cia|cio|con|cre|cód|den|dig|dir|dis|dor|dos|eci|el |ema|enc|equ|er |eri|err|ert|esa|esp|evi|eze|fer|go |gur|har|hia',
+  ].join('|'),
+}).map(([code, trigrams]) => [code, new Map(trigrams.split('|').map((trigram, rank) => [trigram, rank]))]));
+
+// Scripts that name the language on their own, and the share of the
+// text's letters they need: product names and English loanwords are
+// usually written in Latin letters even in Japanese or Russian copy
+const SCRIPTS = [
+  ['ja', /[\p{Script=Hiragana}\p{Script=Katakana}]/gu, 0.05],
+  ['ko', /\p{Script=Hangul}/gu, 0.2],
+  ['zh', /\p{Script=Han}/gu, 0.2],
+  ['ru', /\p{Script=Cyrillic}/gu, 0.2],
+  ['el', /\p{Script=Greek}/gu, 0.2],
+  ['ar', /\p{Script=Arabic}/gu, 0.2],
+  ['he', /\p{Script=Hebrew}/gu, 0.2],
+];
+// Japanese is kana and kanji together
+const CJK = /[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}]/gu;
+// Fewer letters than this can't be told apart by trigrams
+const MIN_LETTERS = 20;
+// Trigrams of the text compared against each profile
+const MAX_TRIGRAMS = 300;
+
+/**
+ * The language a text is written in, as an ISO 639-1 code, or null when
+ * there's too little of it to tell. Scripts particular to a language
+ * decide it outright (kana for Japanese, Hangul for Korean, and so on);
+ * Latin-script text is matched against trigram profiles of English,
+ * German, French, Spanish, Italian, Dutch and Portuguese.
+ *
+ * Tool names, "Praetorian" and URLs are ignored: they read the same in
+ * every language and would pull short texts towards English.
+ */
+export function detectLanguage(text) {
+  const clean = String(text ?? '')
+    .normalize('NFKC')
+    .replace(/https?:\/\/\S+/g, ' ')
+    .replace(properNouns(), ' ');
+  const letters = clean.match(/\p{L}/gu)?.length || 0;
+  if (letters === 0) return null;
+
+  for (const [code, pattern, share] of SCRIPTS) {
+    const count = clean.match(pattern)?.length || 0;
+    if (count / letters < share) continue;
+    if (code === 'ja' && (clean.match(CJK)?.length || 0) / letters < 0.2) continue;
+    return code;
+  }
+  if (letters < MIN_LETTERS) return null;
+
+  const ranked = rankTrigrams(clean).slice(0, MAX_TRIGRAMS);
+  let best = null;
+  for (const [code, profile] of Object.entries(PROFILES)) {
+    // Out-of-place distance: how far each trigram's rank is from its rank
+    // in the profile, the profile's length when it isn't there at all
+    let distance = 0;
+    ranked.forEach((trigram, rank) => {
+      distance += profile.has(trigram) ? Math.abs(profile.get(trigram) - rank) : profile.size;
+    });
+    if (!best || distance < best.distance) best = { code, distance };
+  }
+  return best.code;
+}
+
+/**
+ * The language of a coverage item, from its title and excerpt.
+ */
+export function itemLanguage(item) {
+  return detectLanguage(`${item.title || ''} ${item.excerpt || ''}`);
+}
+
+/**
+ * Whether an item is in a language other than the digest's primary one
+ * (config.language.primary). Items whose language couldn't be told are
+ * taken to be in the primary language.
+ */
+export function isForeign(item, primary = config.language.primary) {
+  return Boolean(item.language) && item.language !== primary;
+}
+
+/**
+ * Label each item's `language` (keeping one a source already gave), and
+ * apply the policy for items in other languages (FOREIGN_LANGUAGE_ITEMS):
+ * "include" and "section" keep them, "exclude" leaves them out. Returns
+ * { items, foreign, excluded }: the items kept, and how many were in
+ * another language and how many of those were left out.
+ */
+export function applyLanguagePolicy(items, mode = config.language.foreign, primary = config.language.primary) {
+  assertForeignMode(mode);
+  for (const item of items) {
+    if (!item.language) item.language = itemLanguage(item);
+  }
+  const foreign = items.filter(item => isForeign(item, primary));
+  const kept = mode === 'exclude' ? items.filter(item => !foreign.includes(item)) : items;
+  return { items: kept, foreign: foreign.length, excluded: items.length - kept.length };
+}
+
+export function assertForeignMode(mode) {
+  if (!FOREIGN_LANGUAGE_MODES.includes(mode)) {
+    throw new Error(`Unknown FOREIGN_LANGUAGE_ITEMS "${mode}" (expected ${FOREIGN_LANGUAGE_MODES.join(', ')})`);
+  }
+  return mode;
+}
+
+/**
+ * A language's name in the given locale ("de" in "en" is "German"), or
+ * the code in capitals if the runtime doesn't know it.
+ */
+export function languageName(code, locale = 'en') {
+  try {
+    return new Intl.DisplayNames([locale], { type: 'language' }).of(code) || code.toUpperCase();
+  } catch {
+    return String(code).toUpperCase();
+  }
+}
+
+function rankTrigrams(text) {
+  const counts = new Map();
+  for (const word of text.toLowerCase().split(/[^\p{L}]+/u).filter(Boolean)) {
+    const padded = ` ${word} `;
+    for (let i = 0; i + 3 <= padded.length; i++) {
+      const trigram = padded.slice(i, i + 3);
+      counts.set(trigram, (counts.get(trigram) || 0) + 1);
+    }
+  }
+  return [...counts]
+    .sort((a, b) => b[1] - a[1] || (a[0] < b[0] ? -1 : 1))
+    .map(([trigram]) => trigram);
+}
+
+// Tool names (current, aliases and former) and our own, as whole words
+function properNouns() {
+  const names = ['Praetorian', ...config.tools];
+  for (const tool of new Set([...config.tools, ...Object.keys(config.toolRules), ...Object.keys(config.toolRegistry)])) {
+    names.push(...(config.toolRules[tool]?.aliases || []), ...formerNames(tool).map(entry => entry.name));
+  }
+  const escaped = names.map(name => name.replace(/[.*+?^${}()|[\]\\]/g, '\\$&'));
+  return new RegExp(`(?<!\\p{L})(?:${escaped.join('|')})(?!\\p{L})`, 'giu');
+}
@@ -0,0 +1,32 @@
+/**
+ * Escape text for inline use in GitHub markdown: inside link text, bold,
+ * blockquotes, and table cells. Emphasis, code, link brackets, pipes,
+ * HTML, and headings/issue references (#) are backslash-escaped, a
+ * leading list marker is neutralized, and newlines are folded into
+ * spaces so the text can't start a new block. URLs are never passed
+ * through here; they go into link targets through escapeLinkUrl.
+ *
+ *   escapeMarkdown('Brutus [Review] | Top 10 * Best')
+ *   // => 'Brutus \[Review\] \| Top 10 \* Best'
+ */
+export function escapeMarkdown(text) {
+  return String(text ?? '')
+    .replace(/\s*[\r\n]+\s*/g, ' ')
+    .replace(/[\\`*_[\]|<>#]/g, '\\$&')
+    .replace(/^([-+])(?=\s)/, '\\$1')
+    .replace(/^(\d+)([.)])(?=\s)/, '$1\\$2');
+}
+
+/**
+ * Make a URL safe as a markdown link target: whitespace, parentheses and
+ * angle brackets are percent-encoded, so "https://example.com/a (b)"
+ * can't end the link early or leak the rest into the text. The URL
+ * still resolves to the same page.
+ *
+ *   escapeLinkUrl('https://en.wikipedia.org/wiki/Brutus_(tool)')
+ *   // => 'https://en.wikipedia.org/wiki/Brutus_%28tool%29'
+ */
+export function escapeLinkUrl(url) {
+  return String(url ?? '').replace(/[\s()<>]/g, char =>
+    (char === '(' || char === ')' ? `%${char.charCodeAt(0).toString(16).toUpperCase()}` : encodeURIComponent(char)));
+}
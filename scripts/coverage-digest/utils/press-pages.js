@@ -0,0 +1,109 @@
+import { config } from '../config.js';
+import { canonicalTools } from './tools.js';
+
+/**
+ * The tracker items that belong on `tool`'s press page, as decided by
+ * includeItem under the page's options.
+ */
+export function pressPageItems(tracker, tool) {
+  const options = pageOptions(tool);
+  return tracker.filter(item => includeItem(item, tool, options));
+}
+
+/**
+ * Per-page options: global defaults overridden by config.pressPages.pages[tool].
+ */
+function pageOptions(tool) {
+  return {
+    excludeDead: config.pressPages.excludeDead,
+    excludeNegative: config.pressPages.excludeNegative,
+    ...(config.pressPages.pages[tool] || {}),
+  };
+}
+
+/**
+ * Decide whether a tracker item belongs on a tool's press page.
+ */
+function includeItem(item, tool, options) {
+  const tools = canonicalTools(item.tools_mentioned, item.date).map(t => t.toLowerCase());
+  if (!tools.includes(tool.toLowerCase())) return false;
+  if (!item.url) return false;
+  if (item.status === 'embargoed') return false;
+  if (item.low_relevance) return false;
+  if (options.excludeDead && item.link_status === 'dead') return false;
+  if (options.excludeNegative && item.sentiment === 'negative') return false;
+  return true;
+}
+
+/**
+ * Render a single tool's press page. Output depends only on the items,
+ * so identical input always yields byte-identical output.
+ */
+export function renderToolPage(tool, items) {
+  const sorted = [...items].sort((a, b) =>
+    b.date.localeCompare(a.date) || a.title.localeCompare(b.title) || a.url.localeCompare(b.url)
+  );
+
+  const lines = [...renderFrontMatter(tool, sorted), ''];
+  lines.push(`Press coverage of ${tool}, newest first.`, '');
+
+  let currentMonth = null;
+  for (const item of sorted) {
+    const month = item.date.substring(0, 7);
+    if (month !== currentMonth) {
+      if (currentMonth !== null) lines.push('');
+      lines.push(`## ${formatMonth(month)}`, '');
+      currentMonth = month;
+    }
+    lines.push(`- [${escapeLinkText(item.title)}](${item.url}) — *${escapeLinkText(item.source)}*, ${formatDay(item.date)}`);
+  }
+
+  return lines.join('\n') + '\n';
+}
+
+/**
+ * Build the front matter block for the configured static site generator.
+ */
+function renderFrontMatter(tool, sortedItems) {
+  const { frontMatter, layout } = config.pressPages;
+  const slug = slugify(tool);
+  const lines = ['---', `title: ${yamlString(`${tool} in the Press`)}`];
+
+  if (frontMatter === 'jekyll') {
+    lines.push(`layout: ${yamlString(layout)}`);
+    lines.push(`permalink: ${yamlString(`/press/${slug}/`)}`);
+  } else {
+    lines.push(`slug: ${yamlString(slug)}`);
+    lines.push('draft: false');
+  }
+  lines.push(`tool: ${yamlString(tool)}`);
+  lines.push(`coverage_count: ${sortedItems.length}`);
+  lines.push(`date: ${sortedItems[0].date}`);
+  lines.push('---');
+  return lines;
+}
+
+export function slugify(name) {
+  return name.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-|-$/g, '');
+}
+
+function yamlString(value) {
+  return JSON.stringify(String(value));
+}
+
+function escapeLinkText(text) {
+  return (text || '').replace(/([\[\]])/g, '\\$1');
+}
+
+function formatMonth(yyyyMm) {
+  const [year, month] = yyyyMm.split('-').map(Number);
+  return new Date(Date.UTC(year, month - 1, 1)).toLocaleDateString('en-US', {
+    month: 'long', year: 'numeric', timeZone: 'UTC',
+  });
+}
+
+function formatDay(dateStr) {
+  return new Date(`${dateStr.substring(0, 10)}T00:00:00Z`).toLocaleDateString('en-US', {
+    month: 'short', day: 'numeric', year: 'numeric', timeZone: 'UTC',
+  });
+}
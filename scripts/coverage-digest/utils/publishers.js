@@ -0,0 +1,188 @@
+import { config } from '../config.js';
+
+// Second-level labels under which the registrable domain is one label deeper
+// (e.g. theregister.co.uk -> "theregister", abc.net.au -> "abc").
+const SECOND_LEVEL_LABELS = new Set(['co', 'com', 'net', 'org', 'ac', 'edu', 'gov', 'gob', 'go', 'ne', 'or', 'ltd', 'plc']);
+
+// Publishers coverage most often comes from. A domain may carry a path
+// ("praetorian.com/blog") to name one section of a site; the most specific
+// domain matching an item's URL wins.
+export const BUILT_IN_PUBLISHERS = [
+  { name: 'Help Net Security', domains: ['helpnetsecurity.com'], aliases: ['HelpNet Security', 'HNS', 'HNS RSS'] },
+  { name: 'Dark Reading', domains: ['darkreading.com'] },
+  { name: 'Bleeping Computer', domains: ['bleepingcomputer.com'], aliases: ['BleepingComputer'] },
+  { name: 'The Hacker News', domains: ['thehackernews.com'], aliases: ['Hacker News (THN)'] },
+  { name: 'SecurityWeek', domains: ['securityweek.com'], aliases: ['Security Week'] },
+  { name: 'SC Media', domains: ['scworld.com', 'scmagazine.com'], aliases: ['SC Magazine'] },
+  { name: 'The Register', domains: ['theregister.com', 'theregister.co.uk'] },
+  { name: 'The Record', domains: ['therecord.media'], aliases: ['The Record by Recorded Future'] },
+  { name: 'CyberScoop', domains: ['cyberscoop.com'] },
+  { name: 'CSO Online', domains: ['csoonline.com'], aliases: ['CSO'] },
+  { name: 'Infosecurity Magazine', domains: ['infosecurity-magazine.com'] },
+  { name: 'Security Boulevard', domains: ['securityboulevard.com'] },
+  { name: 'SiliconANGLE', domains: ['siliconangle.com'] },
+  { name: 'Ars Technica', domains: ['arstechnica.com'] },
+  { name: 'Wired', domains: ['wired.com', 'wired.co.uk'] },
+  { name: 'TechCrunch', domains: ['techcrunch.com'] },
+  { name: 'ZDNET', domains: ['zdnet.com'] },
+  { name: 'VentureBeat', domains: ['venturebeat.com'] },
+  { name: 'heise online', domains: ['heise.de'], aliases: ['heise'] },
+  { name: 'Praetorian Blog', domains: ['praetorian.com/blog', 'blog.praetorian.com'] },
+  { name: 'Praetorian', domains: ['praetorian.com'] },
+];
+
+let shared = null;
+
+/**
+ * The normalizer for this run: config.publishers, then the built-in
+ * publishers.
+ */
+export function sharedNormalizer() {
+  if (!shared) shared = createNormalizer();
+  return shared;
+}
+
+/**
+ * Create a publisher name normalizer over `publishers`, a list of
+ * { name, domains, aliases } tried in order, so entries earlier in the
+ * list win ties. Returns { normalize, nameForUrl, nameForSource }:
+ *
+ *   normalize(source, url)  the display name for an item, resolved in order:
+ *     1. The item URL is on a known publisher domain (the most specific
+ *        one: blog.praetorian.com before praetorian.com)
+ *     2. The source string matches a known publisher name, alias, or domain
+ *        (case, spacing, and punctuation insensitive)
+ *     3. The source string is itself a domain -> title-cased registrable domain
+ *     4. The source string is empty -> the URL's title-cased registrable domain
+ *     5. Otherwise the source string is kept as-is
+ *   nameForUrl(url)  the known publisher a URL belongs to, or null
+ *   nameForSource(name)  the known publisher a name, alias or domain
+ *     stands for, or null
+ *
+ * @param {Array<Object>} [publishers] - default: config.publishers, then BUILT_IN_PUBLISHERS
+ */
+export function createNormalizer(publishers = [...config.publishers, ...BUILT_IN_PUBLISHERS]) {
+  const domains = publishers
+    .flatMap((publisher, order) => (publisher.domains || []).map(domain => ({ ...splitDomain(domain), name: publisher.name, order })))
+    .sort((a, b) => b.host.length + b.path.length - (a.host.length + a.path.length) || a.order - b.order);
+  const names = new Map();
+  for (const publisher of publishers) {
+    for (const key of [publisher.name, ...(publisher.aliases || []), ...(publisher.domains || [])].map(squash)) {
+      if (key && !names.has(key)) names.set(key, publisher.name);
+    }
+  }
+
+  function nameForUrl(url) {
+    const target = parseUrl(url);
+    if (!target) return null;
+    const host = target.hostname.toLowerCase().replace(/^www\./, '');
+    const path = target.pathname.toLowerCase();
+    const match = domains.find(domain =>
+      (host === domain.host || host.endsWith(`.${domain.host}`))
+      && (!domain.path || path === domain.path || path.startsWith(`${domain.path}/`))
+    );
+    return match ? match.name : null;
+  }
+
+  function nameForSource(name) {
+    return names.get(squash(name)) || null;
+  }
+
+  function normalize(source, url) {
+    const name = (source || '').trim();
+
+    const byUrl = nameForUrl(url);
+    if (byUrl) return byUrl;
+
+    const byName = nameForSource(name);
+    if (byName) return byName;
+
+    if (looksLikeDomain(name)) {
+      const sourceUrl = /^https?:\/\//i.test(name) ? name : `https://${name}`;
+      const sourceHost = hostnameOf(sourceUrl);
+      if (sourceHost) return nameForUrl(sourceUrl) || titleCaseDomain(sourceHost);
+    }
+
+    const host = hostnameOf(url);
+    if (!name && host) return titleCaseDomain(host);
+    return name;
+  }
+
+  return { normalize, nameForUrl, nameForSource };
+}
+
+/**
+ * Return the canonical display name for a publisher, with the run's
+ * normalizer (see createNormalizer).
+ *
+ * @param {string} source - Publisher name as reported by the discovering source
+ * @param {string} [url] - Item URL
+ * @returns {string}
+ */
+export function normalizePublisher(source, url) {
+  return sharedNormalizer().normalize(source, url);
+}
+
+/**
+ * Give discovered items their canonical publisher names, keeping the name
+ * their source reported as `sourceOriginal`. Run after canonicalizeItems()
+ * (monitors/html-article.js), so the host a name is looked up by is the
+ * article's own rather than a redirector's.
+ */
+export function normalizeSources(items, normalizer = sharedNormalizer()) {
+  for (const item of items) {
+    const source = normalizer.normalize(item.source, item.url);
+    if (source === item.source) continue;
+    item.sourceOriginal = item.sourceOriginal || item.source;
+    item.source = source;
+  }
+  return items;
+}
+
+/**
+ * Title-case the registrable part of a hostname ("www.helpnetsecurity.com" -> "Helpnetsecurity").
+ */
+export function titleCaseDomain(host) {
+  const label = registrableLabel(host);
+  return label
+    .split('-')
+    .map(part => part.charAt(0).toUpperCase() + part.slice(1))
+    .join(' ');
+}
+
+// "praetorian.com/blog/" -> { host: "praetorian.com", path: "/blog" }
+function splitDomain(domain) {
+  const [host, ...path] = domain.toLowerCase().replace(/^https?:\/\//, '').replace(/^www\./, '').split('/');
+  const joined = path.filter(Boolean).join('/');
+  return { host, path: joined ? `/${joined}` : '' };
+}
+
+function registrableLabel(host) {
+  const labels = host.replace(/^www\./, '').split('.');
+  if (labels.length >= 3 && SECOND_LEVEL_LABELS.has(labels[labels.length - 2]) && labels[labels.length - 1].length === 2) {
+    return labels[labels.length - 3];
+  }
+  return labels.length >= 2 ? labels[labels.length - 2] : labels[0];
+}
+
+function parseUrl(url) {
+  if (!url) return null;
+  try {
+    return new URL(url);
+  } catch {
+    return null;
+  }
+}
+
+function hostnameOf(url) {
+  const target = parseUrl(url);
+  return target ? target.hostname.toLowerCase().replace(/^www\./, '') : '';
+}
+
+function looksLikeDomain(str) {
+  return /^(https?:\/\/)?[a-z0-9-]+(\.[a-z0-9-]+)+(\/.*)?$/i.test(str) && !/\s/.test(str);
+}
+
+function squash(str) {
+  return (str || '').toLowerCase().replace(/[^a-z0-9]/g, '');
+}
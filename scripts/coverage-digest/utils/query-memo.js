@@ -0,0 +1,52 @@
+/**
+ * A single-flight memo for source queries within one run: the first
+ * request for a (source, query, window) runs, and every later one for the
+ * same key, whether it comes while the first is still in flight or after
+ * it answered, gets the same promise. The query sources (rss, google-news,
+ * news-api, mastodon, hacker-news) make each request through it, so a
+ * feed listed twice, two tools that search the same terms, or a source
+ * retried after a transient error (see monitors/registry.js) don't ask
+ * again for what the run already has.
+ *
+ * A query that fails is forgotten once it settles, so the next request
+ * for it (say, the source's retry) tries again.
+ *
+ *   const memo = createQueryMemo();
+ *   const stories = await memo('hacker-news', 'brutus', since, () => search(...));
+ *   memo.stats('hacker-news') // => { requested: 1, saved: 0 }
+ *
+ * `window` is what bounds the query's results in time, usually `since`;
+ * a Date, a string, or null.
+ */
+export function createQueryMemo() {
+  const entries = new Map();
+  const counts = new Map();
+
+  function memo(source, query, window, run) {
+    const key = JSON.stringify([source, query, window instanceof Date ? window.toISOString() : window ?? null]);
+    const stats = counts.get(source) || { requested: 0, saved: 0 };
+    counts.set(source, stats);
+    stats.requested++;
+    if (entries.has(key)) {
+      stats.saved++;
+      return entries.get(key);
+    }
+    const promise = Promise.resolve().then(run);
+    entries.set(key, promise);
+    promise.catch(() => entries.delete(key));
+    return promise;
+  }
+
+  // { requested, saved } for a source, or summed over every source
+  memo.stats = source => {
+    if (source !== undefined) return { ...(counts.get(source) || { requested: 0, saved: 0 }) };
+    let requested = 0;
+    let saved = 0;
+    for (const stats of counts.values()) {
+      requested += stats.requested;
+      saved += stats.saved;
+    }
+    return { requested, saved };
+  };
+  return memo;
+}
@@ -0,0 +1,106 @@
+import { config } from '../config.js';
+import { hasContextTerm, sharedToolDetector } from './tools.js';
+import { mapSourceType } from './tracker.js';
+
+// What each signal adds to an item's relevance; together they make 1
+const WEIGHTS = {
+  // The title names one of our tools, or us
+  title: 0.35,
+  // The share of its sentences that are about us
+  share: 0.3,
+  // It links to one of our sites (config.relevance.ownLinks)
+  link: 0.2,
+  // It ran somewhere other than an aggregator
+  source: 0.15,
+};
+
+/**
+ * How much of an item is about us, from 0 to 1: each signal (WEIGHTS)
+ * scored 0-1 and weighted. A roundup naming us once among fifty vendors
+ * scores low on all but `source`. `share` is read from the article's text
+ * where its page was read (see monitors/html-article.js enrichItems),
+ * else from the excerpt. Scores are rounded to two places.
+ *
+ * @param {Object} item - discovered item; title, excerpt, bodyText,
+ *   bodyLinks and url are read
+ * @param {Object} [options]
+ *   detector        - tool detector (default: utils/tools.js sharedToolDetector())
+ *   lowTrustDomains - default: config.relevance.lowTrustDomains
+ *   ownLinks        - default: config.relevance.ownLinks
+ * @returns {{ score: number, signals: { title, share, link, source } }}
+ */
+export function relevanceScore(item, {
+  detector = sharedToolDetector(),
+  lowTrustDomains = config.relevance.lowTrustDomains,
+  ownLinks = config.relevance.ownLinks,
+} = {}) {
+  const aboutUs = text => detector.detect(text, { assumeContext: true }).length > 0 || hasContextTerm(text);
+
+  const sentences = String(item.bodyText || item.excerpt || '')
+    .split(/(?<=[.!?])\s+/)
+    .filter(sentence => /[\p{L}\p{N}]/u.test(sentence));
+  const signals = {
+    title: aboutUs(item.title || '') ? 1 : 0,
+    share: sentences.length > 0 ? round(sentences.filter(aboutUs).length / sentences.length) : 0,
+    link: (item.bodyLinks || []).some(url => ownLinks.some(link => linkMatches(url, link))) ? 1 : 0,
+    source: lowTrustDomains.some(domain => linkMatches(item.url, domain)) ? 0 : 1,
+  };
+  const score = Object.entries(WEIGHTS).reduce((sum, [signal, weight]) => sum + signals[signal] * weight, 0);
+  return { score: round(score), signals };
+}
+
+/**
+ * Score the relevance of items found by the monitors (see
+ * relevanceScore); manual submissions and Praetorian's own blog aren't
+ * scored. Each scored item gets `relevance` ({ score, signals }), and
+ * those under `threshold` are flagged `lowRelevance`, to be left out of
+ * the digest and only counted.
+ *
+ * @param {Array<Object>} items - discovered items (changed in place)
+ * @param {Object} [options] - threshold (default: config.relevance.threshold),
+ *   and relevanceScore's options
+ * @returns {{ items: Array<Object>, excluded: Array<Object> }} every item,
+ *   and the ones flagged
+ */
+export function applyRelevance(items, { threshold = config.relevance.threshold, ...options } = {}) {
+  if (!(threshold >= 0 && threshold <= 1)) {
+    throw new Error(`RELEVANCE_THRESHOLD must be from 0 to 1 (got ${threshold})`);
+  }
+  const excluded = [];
+  for (const item of items) {
+    if (!isScored(item)) continue;
+    item.relevance = relevanceScore(item, options);
+    if (item.relevance.score < threshold) {
+      item.lowRelevance = true;
+      excluded.push(item);
+    }
+  }
+  return { items, excluded };
+}
+
+// Found by a monitor, rather than submitted or published by Praetorian
+function isScored(item) {
+  return item.sourceType === 'rss' && mapSourceType(item) !== 'blog';
+}
+
+// Whether a URL is on a site: "example.com" (or a subdomain), optionally
+// with a path ("github.com/praetorian-inc")
+function linkMatches(url, site) {
+  let parsed;
+  try {
+    parsed = new URL(url);
+  } catch {
+    return false;
+  }
+  const [domain, ...path] = site.toLowerCase().split('/');
+  const host = parsed.hostname.toLowerCase();
+  if (host !== domain && !host.endsWith(`.${domain}`)) return false;
+  const prefix = path.filter(Boolean).join('/');
+  if (!prefix) return true;
+  const pathname = parsed.pathname.toLowerCase();
+  return pathname === `/${prefix}` || pathname.startsWith(`/${prefix}/`);
+}
+
+function round(value) {
+  return Math.round(value * 100) / 100;
+}
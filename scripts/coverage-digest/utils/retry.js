@@ -0,0 +1,114 @@
+import { config } from '../config.js';
+
+// Network errors worth another try: the connection dropped or was
+// refused, DNS had a moment, or the request timed out
+const TRANSIENT_CODES = new Set([
+  'ECONNRESET', 'ECONNREFUSED', 'ECONNABORTED', 'EPIPE', 'ETIMEDOUT', 'EAI_AGAIN', 'EHOSTUNREACH', 'ENETUNREACH',
+]);
+
+/**
+ * Call `fn(attempt)` until it succeeds, up to `attempts` times, waiting
+ * with exponential backoff and jitter between tries: about baseDelayMs,
+ * then twice that, and so on, capped at maxDelayMs. Only errors
+ * `retryable(err)` accepts are retried (by default, isTransientError); an
+ * error with a `retryAfterMs` waits that long instead, or is given up on
+ * if that's longer than maxDelayMs.
+ *
+ * When every attempt fails, the last error is thrown wrapped, with the
+ * attempt count in its message and `attempts` and `cause` set. An abort
+ * of `signal` stops the wait between attempts and throws the abort
+ * reason.
+ *
+ * @param {Function} fn - async (attempt) => result; attempt counts from 1
+ * @param {Object} [options]
+ *   attempts    - most tries, the first included (default: config.http.retry.attempts)
+ *   baseDelayMs - first backoff (default: config.http.retry.baseDelayMs)
+ *   maxDelayMs  - longest single wait (default: config.http.retry.maxDelayMs)
+ *   retryable   - err => whether to try again (default: isTransientError)
+ *   signal      - AbortSignal that cancels the retries
+ *   onRetry     - ({ attempt, error, delayMs }) => void, before each wait
+ *   sleep       - (ms, signal) => Promise, for tests
+ */
+export async function retry(fn, {
+  attempts = config.http.retry.attempts,
+  baseDelayMs = config.http.retry.baseDelayMs,
+  maxDelayMs = config.http.retry.maxDelayMs,
+  retryable = isTransientError,
+  signal = null,
+  onRetry = null,
+  sleep = delay,
+} = {}) {
+  for (let attempt = 1; ; attempt++) {
+    signal?.throwIfAborted();
+    try {
+      return await fn(attempt);
+    } catch (err) {
+      if (signal?.aborted) throw signal.reason;
+      const wait = err.retryAfterMs ?? backoffDelay(attempt, baseDelayMs, maxDelayMs);
+      if (attempt >= attempts || !retryable(err) || wait > maxDelayMs) {
+        throw attempt > 1 ? wrapError(err, attempt) : err;
+      }
+      onRetry?.({ attempt, error: err, delayMs: wait });
+      await sleep(wait, signal);
+    }
+  }
+}
+
+/**
+ * Whether an error is worth retrying: a transient network error (see
+ * TRANSIENT_CODES), or an HTTP error (`status`) of 429 or 5xx. Other 4xx
+ * responses won't change on a second try.
+ */
+export function isTransientError(err) {
+  if (err?.status) return isRetryableStatus(err.status);
+  return TRANSIENT_CODES.has(err?.code);
+}
+
+export function isRetryableStatus(status) {
+  return status === 429 || (status >= 500 && status < 600);
+}
+
+/**
+ * A Retry-After header in milliseconds (it may be seconds or an HTTP
+ * date), or null if there isn't a usable one.
+ */
+export function parseRetryAfter(value, now = Date.now()) {
+  if (value === undefined || value === null || value === '') return null;
+  const seconds = Number(value);
+  if (Number.isFinite(seconds)) return Math.max(0, seconds * 1000);
+  const date = Date.parse(value);
+  return isNaN(date) ? null : Math.max(0, date - now);
+}
+
+// Exponential, with the top half of each step randomized so clients
+// that failed together don't retry together
+function backoffDelay(attempt, baseDelayMs, maxDelayMs) {
+  const ceiling = Math.min(maxDelayMs, baseDelayMs * 2 ** (attempt - 1));
+  return Math.round(ceiling / 2 + Math.random() * ceiling / 2);
+}
+
+function wrapError(err, attempts) {
+  const wrapped = new Error(`${err.message} (after ${attempts} attempts)`, { cause: err });
+  wrapped.attempts = attempts;
+  if (err.code) wrapped.code = err.code;
+  if (err.status) wrapped.status = err.status;
+  return wrapped;
+}
+
+function delay(ms, signal) {
+  return new Promise((resolve, reject) => {
+    if (signal?.aborted) {
+      reject(signal.reason);
+      return;
+    }
+    const onAbort = () => {
+      clearTimeout(timer);
+      reject(signal.reason);
+    };
+    const timer = setTimeout(() => {
+      signal?.removeEventListener('abort', onAbort);
+      resolve();
+    }, ms);
+    signal?.addEventListener('abort', onAbort, { once: true });
+  });
+}
@@ -0,0 +1,129 @@
+import { config } from '../config.js';
+import { normalizeUrl } from './tracker.js';
+import { canonicalTools } from './tools.js';
+import { compareSummaries, formatDelta, renderSourcesMarkdown, renderTrendsMarkdown, summarizeDigest } from './summary.js';
+import { excerpt } from './excerpt.js';
+import { isForeign, languageName } from './language.js';
+import { escapeLinkUrl, escapeMarkdown } from './markdown.js';
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/**
+ * Aggregate the daily digests sent between `from` and `to` (inclusive
+ * YYYY-MM-DD dates, UTC) into one rollup. An item belongs to the day its
+ * digest went out (last_sent_at); an article sent on several days, or
+ * under URLs that normalize the same, is counted once, on its first day.
+ *
+ * When digests were sent in the equally long window just before `from`,
+ * `previous` holds that window's item count, and `trends` compares the
+ * two windows (see compareSummaries); otherwise both are null.
+ *
+ * Returns { from, to, items, perDay: [{ day, count }], summary, previous, trends }.
+ */
+export function buildRollup(tracker, from, to) {
+  const start = new Date(`${from}T00:00:00Z`);
+  const end = new Date(`${to}T00:00:00Z`);
+  if (isNaN(start) || isNaN(end) || end < start) {
+    throw new Error(`Invalid rollup range ${from} .. ${to}`);
+  }
+  const days = Math.round((end - start) / DAY_MS) + 1;
+
+  const items = firstSends(tracker, start, days);
+  const perDay = [];
+  for (let i = 0; i < days; i++) {
+    const day = new Date(start.getTime() + i * DAY_MS).toISOString().split('T')[0];
+    perDay.push({ day, count: items.filter(item => item.last_sent_at.startsWith(day)).length });
+  }
+  items.sort((a, b) => new Date(b.date) - new Date(a.date));
+
+  const priorStart = new Date(start.getTime() - days * DAY_MS);
+  const prior = firstSends(tracker, priorStart, days);
+  const hasPrior = tracker.some(item => item.last_sent_at && new Date(item.last_sent_at) < start);
+
+  const summary = summarizeDigest(items.map(item => ({ ...item, tools: item.tools_mentioned })), tracker);
+  const priorSummary = hasPrior ? summarizeDigest(prior.map(item => ({ ...item, tools: item.tools_mentioned }))) : null;
+
+  return {
+    from,
+    to,
+    items,
+    perDay,
+    summary,
+    previous: hasPrior ? { from: priorStart.toISOString().split('T')[0], count: prior.length } : null,
+    trends: compareSummaries(summary, priorSummary),
+  };
+}
+
+/**
+ * Render a rollup as markdown. Excerpts are cut at a sentence end within
+ * `excerptChars` (default: the digest issue's length); `sources` adds the
+ * per-publication Sources section (default: as for the digest issue).
+ */
+export function renderRollupMarkdown(rollup, {
+  excerptChars = config.digestIssue.excerptChars,
+  sources = config.digestIssue.sources,
+} = {}) {
+  const { items, perDay, summary, previous, trends } = rollup;
+  const weekOf = new Date(`${rollup.from}T00:00:00Z`).toLocaleDateString('en-US', {
+    weekday: 'long', year: 'numeric', month: 'long', day: 'numeric', timeZone: 'UTC',
+  });
+  const delta = previous ? ` (${formatDelta(items.length - previous.count)} vs previous week)` : '';
+
+  let md = `# Week of ${weekOf}\n\n`;
+  md += `## Summary\n\n`;
+  md += `| Metric | Count |\n|--------|-------|\n`;
+  md += `| Items | ${items.length}${delta} |\n`;
+  md += `| Publications | ${summary.uniqueSources} |\n`;
+  md += `| Tools Mentioned | ${summary.toolCounts.map(({ tool, count }) => `${escapeMarkdown(tool)} (${count})`).join(', ') || 'None'} |\n`;
+  if (summary.firstSeenTools.length > 0) {
+    md += `| First Coverage | 🆕 ${summary.firstSeenTools.map(escapeMarkdown).join(', ')} |\n`;
+  }
+
+  md += `\n| Day | Items |\n|-----|-------|\n`;
+  for (const { day, count } of perDay) {
+    const label = new Date(`${day}T00:00:00Z`).toLocaleDateString('en-US', { weekday: 'short', month: 'short', day: 'numeric', timeZone: 'UTC' });
+    md += `| ${label} | ${count} |\n`;
+  }
+  if (trends) md += `\n${renderTrendsMarkdown(trends, 'previous week').trimEnd()}\n`;
+  if (sources && summary.sourceCounts.length > 0) md += `\n${renderSourcesMarkdown(summary.sourceCounts).trimEnd()}\n`;
+
+  md += `\n---\n\n## Coverage Items\n\n`;
+  if (items.length === 0) {
+    md += `No digests were sent in this period.\n`;
+  }
+  for (const item of items) {
+    const toolTags = canonicalTools(item.tools_mentioned, item.date).map(t => `\`${t}\``).join(' ');
+    const archiveLink = item.archive_url ? ` <sub>[(archive)](${escapeLinkUrl(item.archive_url)})</sub>` : '';
+    const hnLink = item.hacker_news
+      ? ` <sub>[(HN: ${item.hacker_news.points} points, ${item.hacker_news.comments} comments)](${escapeLinkUrl(item.hacker_news.url)})</sub>`
+      : '';
+    const paywallTag = item.paywalled ? ' 🔒' : '';
+    md += `### [${escapeMarkdown(item.title)}](${escapeLinkUrl(item.url)})${paywallTag}${archiveLink}${hnLink}\n`;
+    const languageTag = isForeign(item) ? ` · 🌐 ${languageName(item.language)}` : '';
+    md += `**${escapeMarkdown(item.source)}** · ${item.date_estimated ? '~' : ''}${item.date}${languageTag} ${toolTags}\n\n`;
+    if (item.also_published_by?.length) {
+      const outlets = item.also_published_by.map(outlet => `[${escapeMarkdown(outlet.source)}](${escapeLinkUrl(outlet.url)})`);
+      md += `Also published by: ${outlets.join(', ')}\n\n`;
+    }
+    if (item.excerpt) md += `> ${escapeMarkdown(excerpt(item.excerpt, excerptChars))}\n\n`;
+  }
+  return md;
+}
+
+// Items whose first send falls in [start, start + days), one per article,
+// leaving out those the digest left out as low-relevance
+function firstSends(tracker, start, days) {
+  const end = new Date(start.getTime() + days * DAY_MS);
+  const byUrl = new Map();
+  const sent = tracker
+    .filter(item => item.last_sent_at && item.status !== 'embargoed' && !item.low_relevance)
+    .sort((a, b) => a.last_sent_at.localeCompare(b.last_sent_at));
+  for (const item of sent) {
+    const key = normalizeUrl(item.url) || item.id;
+    if (!byUrl.has(key)) byUrl.set(key, item);
+  }
+  return [...byUrl.values()].filter(item => {
+    const sentAt = new Date(item.last_sent_at);
+    return sentAt >= start && sentAt < end;
+  });
+}
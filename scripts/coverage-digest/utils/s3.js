@@ -0,0 +1,125 @@
+import { config } from '../config.js';
+import { awsCredentials, canonicalQuery, signRequest, uriEncode } from './aws.js';
+import { clientFor } from './http-client.js';
+
+let shared = null;
+
+/**
+ * The S3 client for config.s3, or null when S3_BUCKET isn't set.
+ */
+export function sharedS3() {
+  if (!config.s3.bucket) return null;
+  if (!shared) shared = createS3Client();
+  return shared;
+}
+
+/**
+ * Create a client for one S3 bucket. Keys are relative to `prefix`.
+ * Returns { getObject, putObject, deleteObject, listKeys }:
+ *
+ *   getObject(key)  { body (Buffer), etag }, or null when there's no such
+ *     object (an empty bucket's first run)
+ *   putObject(key, body, { contentType, ifMatch, ifNoneMatch })  { etag };
+ *     ifMatch writes only over the object with that ETag, ifNoneMatch '*'
+ *     only where there's none. A failed condition throws an error with
+ *     code 'PreconditionFailed': another writer got there first.
+ *   deleteObject(key)  removes the object; one already gone is no error
+ *   listKeys(prefix, { startAfter })  every key under `prefix` (after
+ *     `startAfter`, if given) in key order, relative to the client's prefix
+ *
+ * Objects are written with SSE-KMS under `kmsKeyId` when one is set, else
+ * with the bucket's default encryption. `endpoint` points the client at an
+ * S3-compatible service (path-style URLs) instead of AWS.
+ *
+ * @param {Object} [options] - default: config.s3
+ *   bucket, region, prefix, kmsKeyId, endpoint
+ */
+export function createS3Client({
+  bucket = config.s3.bucket,
+  region = config.s3.region,
+  prefix = config.s3.prefix,
+  kmsKeyId = config.s3.kmsKeyId,
+  endpoint = config.s3.endpoint,
+} = {}) {
+  if (!bucket) throw new Error('S3_BUCKET must be set to use S3');
+  const base = endpoint
+    ? new URL(`${endpoint.replace(/\/+$/, '')}/${uriEncode(bucket)}`)
+    : new URL(`https://${bucket}.s3.${region}.amazonaws.com`);
+  const client = clientFor('S3');
+
+  async function send(method, key, { query = {}, headers = {}, body } = {}) {
+    const path = key ? `${base.pathname.replace(/\/$/, '')}/${uriEncode(key, true)}` : base.pathname;
+    const signed = signRequest({
+      method, host: base.host, path, query, headers, body: body ?? '', region, service: 's3',
+      credentials: awsCredentials('to use S3'),
+    });
+    const search = canonicalQuery(query);
+    return client.fetch(`${base.origin}${path}${search ? `?${search}` : ''}`, { method, headers: signed, body });
+  }
+
+  async function getObject(key) {
+    const res = await send('GET', prefix + key);
+    if (res.status === 404) return null;
+    if (!res.ok) throw await s3Error(res, `GET ${key}`);
+    return { body: await res.buffer(), etag: res.headers.etag };
+  }
+
+  async function putObject(key, body, { contentType = 'application/octet-stream', ifMatch, ifNoneMatch } = {}) {
+    const headers = {
+      'content-type': contentType,
+      ...(ifMatch ? { 'if-match': ifMatch } : {}),
+      ...(ifNoneMatch ? { 'if-none-match': ifNoneMatch } : {}),
+      ...(kmsKeyId
+        ? { 'x-amz-server-side-encryption': 'aws:kms', 'x-amz-server-side-encryption-aws-kms-key-id': kmsKeyId }
+        : {}),
+    };
+    const res = await send('PUT', prefix + key, { headers, body });
+    // 409 is S3's answer to two conditional writes racing each other
+    if (res.status === 412 || res.status === 409) {
+      throw Object.assign(await s3Error(res, `PUT ${key}`), { code: 'PreconditionFailed' });
+    }
+    if (!res.ok) throw await s3Error(res, `PUT ${key}`);
+    return { etag: res.headers.etag };
+  }
+
+  async function deleteObject(key) {
+    const res = await send('DELETE', prefix + key);
+    if (!res.ok && res.status !== 404) throw await s3Error(res, `DELETE ${key}`);
+  }
+
+  async function listKeys(keyPrefix = '', { startAfter } = {}) {
+    const keys = [];
+    let token = null;
+    do {
+      const query = {
+        'list-type': 2,
+        'prefix': prefix + keyPrefix,
+        ...(token ? { 'continuation-token': token } : startAfter ? { 'start-after': prefix + startAfter } : {}),
+      };
+      const res = await send('GET', '', { query });
+      if (!res.ok) throw await s3Error(res, `LIST ${keyPrefix}`);
+      const xml = await res.text();
+      for (const [, key] of xml.matchAll(/<Key>([^<]*)<\/Key>/g)) keys.push(unescapeXml(key).slice(prefix.length));
+      token = /<IsTruncated>true<\/IsTruncated>/.test(xml)
+        ? unescapeXml(xml.match(/<NextContinuationToken>([^<]*)<\/NextContinuationToken>/)?.[1] || '') || null
+        : null;
+    } while (token);
+    return keys;
+  }
+
+  return { getObject, putObject, deleteObject, listKeys };
+}
+
+async function s3Error(res, what) {
+  const text = await res.text().catch(() => '');
+  const code = text.match(/<Code>([^<]*)<\/Code>/)?.[1];
+  const message = text.match(/<Message>([^<]*)<\/Message>/)?.[1];
+  return new Error(`S3 ${what} failed (HTTP ${res.status}): ${[code, message].filter(Boolean).join(': ') || 'unknown error'}`);
+}
+
+function unescapeXml(text) {
+  return text
+    .replace(/&lt;/g, '<').replace(/&gt;/g, '>').replace(/&quot;/g, '"').replace(/&apos;/g, "'")
+    .replace(/&#(\d+);/g, (_, code) => String.fromCodePoint(Number(code)))
+    .replace(/&amp;/g, '&');
+}
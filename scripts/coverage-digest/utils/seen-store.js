@@ -0,0 +1,308 @@
+import { readFile, writeFile, mkdir } from 'fs/promises';
+import { dirname } from 'path';
+import { gunzipSync, gzipSync } from 'zlib';
+import { config } from '../config.js';
+import { sharedS3 } from './s3.js';
+import { contentHash, normalizeUrl } from './tracker.js';
+
+// Layout of the store file. Version 1 is the old state/digest-state.json
+// (lastRun, seenIds, runHistory), and version 2 had no last_reported_at;
+// migrate() brings any older file up to this one.
+export const SEEN_STORE_VERSION = 3;
+// Runs kept in run_history
+const MAX_RUN_HISTORY = 90;
+// The store's key in the bucket with SEEN_STORE_BACKEND=s3
+const S3_KEY = 'seen-items.json.gz';
+
+/**
+ * Open the store of items already sent by send-daily-digest.js, keyed by
+ * canonical URL (utils/tracker.js normalizeUrl), so a skipped run or a
+ * lost digest doesn't bring old items back. Each entry keeps the item's
+ * title, source, published date, tools, content hash, when it was first
+ * seen and when it was last reported in a digest.
+ *
+ * The store is a JSON file, like the rest of the digest's state. With no
+ * store file yet, the old digest-state.json is migrated into it: its last
+ * run time carries over, and its item IDs are still honored until they
+ * age out of the retention window.
+ *
+ * With the s3 backend the same JSON is kept gzipped as one object in
+ * config.s3's bucket, read when the store is opened and written only if
+ * no one else has written it since (If-Match on its ETag). When someone
+ * has, their store and this one are merged and written once more; a
+ * second conflict fails the save. An empty bucket starts from the local
+ * store file, if there is one, so switching backends keeps what was sent.
+ *
+ * Returns { has, get, put, range, lastRunTime, recordRun, prune, save }; only
+ * save() writes the file, so a run that fails before it leaves the store
+ * as it was.
+ *
+ * @param {Object} [options]
+ *   path       - store file (default: config.paths.seenStore)
+ *   legacyPath - digest-state.json to migrate from (default: config.paths.digestState)
+ *   backend    - file or s3 (default: config.seenStore.backend)
+ *   s3         - S3 client for the s3 backend (default: utils/s3.js sharedS3())
+ */
+export async function openSeenStore({
+  path = config.paths.seenStore,
+  legacyPath = config.paths.digestState,
+  backend = config.seenStore.backend,
+  s3 = backend === 's3' ? sharedS3() : null,
+} = {}) {
+  const file = storeFile({ path, legacyPath, backend, s3 });
+  const data = migrate(await file.read() ?? emptyStore());
+  const legacyIds = new Set(data.legacy_ids);
+
+  return {
+    /** Whether an item (by canonical URL, else source and title) was already sent */
+    has(item) {
+      return this.get(item) !== null;
+    },
+
+    /**
+     * An item's entry, or null if it hasn't been sent. An item known only
+     * from digest-state.json gets { last_reported_at } of the migration,
+     * the latest it can have been sent.
+     */
+    get(item) {
+      const key = itemKey(item);
+      if (Object.hasOwn(data.items, key)) return data.items[key];
+      return legacyIds.has(legacyId(item)) ? { last_reported_at: data.migrated_at } : null;
+    },
+
+    /** Record items as reported at `now`, adding those not yet in the store */
+    put(items, now = new Date()) {
+      for (const item of items) {
+        const key = itemKey(item);
+        if (!key) continue;
+        if (Object.hasOwn(data.items, key)) {
+          data.items[key].last_reported_at = now.toISOString();
+          continue;
+        }
+        data.items[key] = {
+          url: item.url || '',
+          title: item.title || '',
+          source: item.source || '',
+          published_at: item.date ? new Date(item.date).toISOString() : null,
+          tools: item.toolsMentioned || item.tools_mentioned || [],
+          content_hash: contentHash(item),
+          first_seen: now.toISOString(),
+          last_reported_at: now.toISOString(),
+        };
+      }
+    },
+
+    /** Entries first seen in [start, end), oldest first */
+    range(start, end = new Date()) {
+      return Object.values(data.items)
+        .filter(entry => new Date(entry.first_seen) >= start && new Date(entry.first_seen) < end)
+        .sort((a, b) => a.first_seen.localeCompare(b.first_seen));
+    },
+
+    /** When the last digest was sent, or null before the first */
+    lastRunTime() {
+      return data.last_run ? new Date(data.last_run) : null;
+    },
+
+    /** Note a digest sent at `now` with `items` */
+    recordRun(items, now = new Date()) {
+      data.last_run = now.toISOString();
+      data.run_history.push({
+        date: data.last_run,
+        itemCount: items.length,
+        sources: [...new Set(items.map(i => i.source))],
+      });
+      data.run_history = data.run_history.slice(-MAX_RUN_HISTORY);
+    },
+
+    /**
+     * Drop entries last reported more than `retentionDays` before `now` (0
+     * keeps everything), and the migrated IDs once the migration is that
+     * old. A dropped item is forgotten, and can be sent again as new.
+     * Returns the number of entries dropped.
+     */
+    prune(now = new Date(), retentionDays = config.seenStore.retentionDays) {
+      if (!(retentionDays > 0)) return 0;
+      const cutoff = now.getTime() - retentionDays * 86400000;
+      let dropped = 0;
+      for (const [key, entry] of Object.entries(data.items)) {
+        if (new Date(entry.last_reported_at).getTime() < cutoff) {
+          delete data.items[key];
+          dropped++;
+        }
+      }
+      if (data.legacy_ids.length > 0 && new Date(data.migrated_at).getTime() < cutoff) {
+        dropped += data.legacy_ids.length;
+        data.legacy_ids = [];
+        legacyIds.clear();
+      }
+      return dropped;
+    },
+
+    async save() {
+      const saved = await file.write(data);
+      if (saved !== data) {
+        Object.assign(data, saved);
+        legacyIds.clear();
+        for (const id of data.legacy_ids) legacyIds.add(id);
+      }
+    },
+  };
+}
+
+/**
+ * Bring a store file of any earlier version up to SEEN_STORE_VERSION.
+ * Throws on a file from a newer version, rather than lose what it holds.
+ */
+export function migrate(data, now = new Date()) {
+  let store = data;
+  if (!store.version) {
+    // 1 -> 2: digest-state.json, whose seenIds are truncated base64 of each
+    // item's guid or URL and can't be turned back into URLs
+    store = {
+      version: 2,
+      last_run: store.lastRun || null,
+      items: {},
+      legacy_ids: store.seenIds || [],
+      migrated_at: now.toISOString(),
+      run_history: store.runHistory || [],
+    };
+  }
+  if (store.version === 2) {
+    // 2 -> 3: entries were reported only when first seen
+    for (const entry of Object.values(store.items)) entry.last_reported_at = entry.first_seen;
+    store.version = 3;
+  }
+  if (store.version > SEEN_STORE_VERSION) {
+    throw new Error(`Seen-items store is version ${store.version}; this version reads up to ${SEEN_STORE_VERSION}`);
+  }
+  return store;
+}
+
+/**
+ * Whether a sent item is still kept out of digests: always with
+ * `suppressDays` 0, else until `suppressDays` have passed since it was
+ * last reported. An item exactly that old may be sent again.
+ */
+export function isSuppressed(entry, now = new Date(), suppressDays = config.seenStore.suppressDays) {
+  if (!(suppressDays > 0)) return true;
+  return now.getTime() - new Date(entry.last_reported_at).getTime() < suppressDays * 86400000;
+}
+
+/**
+ * Two copies of the store as one: every entry in either, first seen at
+ * the earlier time and last reported at the later, the later last run,
+ * and both run histories. Entries one side pruned come back from the
+ * other, to be pruned again on the next run.
+ */
+function mergeStores(theirs, ours) {
+  const items = { ...theirs.items };
+  for (const [key, entry] of Object.entries(ours.items)) {
+    const other = items[key];
+    items[key] = other
+      ? {
+          ...other,
+          ...entry,
+          first_seen: earliest(other.first_seen, entry.first_seen),
+          last_reported_at: latest(other.last_reported_at, entry.last_reported_at),
+        }
+      : entry;
+  }
+  const runs = new Map([...theirs.run_history, ...ours.run_history].map(run => [run.date, run]));
+  return {
+    ...theirs,
+    ...ours,
+    last_run: latest(theirs.last_run, ours.last_run),
+    items,
+    legacy_ids: [...new Set([...theirs.legacy_ids, ...ours.legacy_ids])],
+    migrated_at: earliest(theirs.migrated_at, ours.migrated_at),
+    run_history: [...runs.values()].sort((a, b) => a.date.localeCompare(b.date)).slice(-MAX_RUN_HISTORY),
+  };
+}
+
+/**
+ * Where the store is read from and written to: { read, write }. read()
+ * returns the stored data or null; write(data) returns what was written,
+ * which for s3 after a conflict is the merge of both writers' stores.
+ */
+function storeFile({ path, legacyPath, backend, s3 }) {
+  const readLocal = async () => await readJson(path) ?? await readJson(legacyPath);
+  if (backend === 'file') {
+    return {
+      read: readLocal,
+      async write(data) {
+        await mkdir(dirname(path), { recursive: true });
+        await writeFile(path, JSON.stringify(data, null, 2) + '\n');
+        return data;
+      },
+    };
+  }
+  if (backend !== 's3') {
+    throw new Error(`Unknown SEEN_STORE_BACKEND "${backend}" (expected file or s3)`);
+  }
+  if (!s3) throw new Error('SEEN_STORE_BACKEND=s3 needs S3_BUCKET');
+
+  // The ETag of the object as read; null while there is none
+  let etag = null;
+  const readRemote = async () => {
+    const object = await s3.getObject(S3_KEY);
+    etag = object?.etag ?? null;
+    return object ? JSON.parse(gunzipSync(object.body).toString('utf-8')) : null;
+  };
+  const put = async data => {
+    const body = gzipSync(JSON.stringify(data));
+    const condition = etag ? { ifMatch: etag } : { ifNoneMatch: '*' };
+    ({ etag } = await s3.putObject(S3_KEY, body, { contentType: 'application/gzip', ...condition }));
+  };
+
+  return {
+    read: async () => await readRemote() ?? await readLocal(),
+    async write(data) {
+      try {
+        await put(data);
+        return data;
+      } catch (err) {
+        if (err.code !== 'PreconditionFailed') throw err;
+      }
+      // Another run saved first: keep what both sent
+      const theirs = await readRemote();
+      const merged = theirs ? mergeStores(migrate(theirs), data) : data;
+      await put(merged);
+      return merged;
+    },
+  };
+}
+
+function earliest(a, b) {
+  if (!a || !b) return a || b;
+  return a < b ? a : b;
+}
+
+function latest(a, b) {
+  if (!a || !b) return a || b;
+  return a > b ? a : b;
+}
+
+function emptyStore() {
+  return { version: SEEN_STORE_VERSION, last_run: null, items: {}, legacy_ids: [], migrated_at: null, run_history: [] };
+}
+
+async function readJson(path) {
+  try {
+    return JSON.parse(await readFile(path, 'utf-8'));
+  } catch (err) {
+    if (err.code === 'ENOENT') return null;
+    throw err;
+  }
+}
+
+// Canonical URL, or source and title for an item without one
+function itemKey(item) {
+  return normalizeUrl(item.url) || `${item.source || ''}\n${item.title || ''}`.toLowerCase().trim();
+}
+
+// An item's ID as digest-state.json recorded it
+function legacyId(item) {
+  const raw = item.raw?.guid || item.url || `${item.source}-${item.title}`;
+  return Buffer.from(raw).toString('base64').substring(0, 64);
+}
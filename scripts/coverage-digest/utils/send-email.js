@@ -5,13 +5,14 @@
  *
  * Sends the digest email using multiple fallback methods:
  *   1. Resend API (if RESEND_API_KEY is set)
- *   2. SendGrid API (if SENDGRID_API_KEY is set)
- *   3. Apple Mail via .eml file (macOS fallback)
+ *   2. Amazon SES (if SES_FROM and SES_TO are set)
+ *   3. SendGrid API (if SENDGRID_API_KEY is set)
+ *   4. Apple Mail via .eml file (macOS fallback)
  *
  * Usage:
  *   node send-email.js                    # Send latest preview.html
  *   node send-email.js --preview-only     # Just open in browser, don't email
- *   node send-email.js --dry-run          # Show what would be sent
+ *   node send-email.js --dry-run          # Show what would be sent (SES: write the .eml)
  */
 
 import { readFile, writeFile } from 'fs/promises';
@@ -20,6 +21,8 @@ import { fileURLToPath } from 'url';
 import { dirname } from 'path';
 import { execSync } from 'child_process';
 import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+import { sendViaSes } from './ses-sender.js';
 
 const __filename = fileURLToPath(import.meta.url);
 const __dirname = dirname(__filename);
@@ -39,6 +42,14 @@ async function main() {
     process.exit(1);
   }
 
+  // Plain-text alternative written by the pipeline alongside preview.html
+  let text;
+  try {
+    text = await readFile(join(config.paths.root, 'preview.txt'), 'utf-8');
+  } catch {
+    text = 'Praetorian Coverage Digest - View this email in an HTML-capable client.';
+  }
+
   const today = new Date().toLocaleDateString('en-US', {
     weekday: 'long', year: 'numeric', month: 'long', day: 'numeric',
   });
@@ -64,12 +75,18 @@ async function main() {
   }
 
   if (isDryRun) {
+    if (config.ses.from && config.ses.to.length > 0) {
+      const { path } = await sendViaSes({ subject, html, text }, { dryRun: true });
+      console.log(`DRY RUN - SES message written to ${path}`);
+      return;
+    }
     console.log('DRY RUN - email would be sent with the above details.');
     return;
   }
 
   // Try send methods in order
   if (await trySendViaResend(to, subject, html)) return;
+  if (await trySendViaSes(subject, html, text)) return;
   if (await trySendViaSendGrid(to, subject, html)) return;
   if (await trySendViaMailApp(to, subject, html)) return;
 
@@ -93,7 +110,7 @@ async function trySendViaResend(to, subject, html) {
 
   console.log('Sending via Resend API...');
   try {
-    const res = await fetch('https://api.resend.com/emails', {
+    const res = await clientFor('Resend').fetch('https://api.resend.com/emails', {
       method: 'POST',
       headers: {
         'Authorization': `Bearer ${apiKey}`,
@@ -121,6 +138,26 @@ async function trySendViaResend(to, subject, html) {
   }
 }
 
+/**
+ * Send via Amazon SES (uses config.ses recipients, not DIGEST_RECIPIENT)
+ */
+async function trySendViaSes(subject, html, text) {
+  if (!config.ses.from || config.ses.to.length === 0) {
+    console.log('SES: Not configured (SES_FROM / SES_TO not set)');
+    return false;
+  }
+
+  console.log(`Sending via Amazon SES (${config.ses.region})...`);
+  try {
+    const { messageId } = await sendViaSes({ subject, html, text }, { dryRun: false });
+    console.log(`✓ Email sent via SES (ID: ${messageId})`);
+    return true;
+  } catch (err) {
+    console.log(`SES failed: ${err.message}`);
+    return false;
+  }
+}
+
 /**
  * Send via SendGrid API (legacy fallback)
  */
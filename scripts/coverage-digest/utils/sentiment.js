@@ -0,0 +1,113 @@
+import { resolve } from 'path';
+import { pathToFileURL } from 'url';
+import { config } from '../config.js';
+
+export const SENTIMENT_LABELS = ['positive', 'neutral', 'negative'];
+
+// Security-domain phrases that look negative out of context but describe
+// what our tools do ("Brutus attacks SSH", "a vulnerability scanner").
+// They are removed before scoring.
+const DOMAIN_NEUTRAL = [
+  'vulnerability scanner', 'vulnerability scanning', 'vulnerability management',
+  'vulnerability research', 'vulnerability discovery', 'find vulnerabilities',
+  'finds vulnerabilities', 'finding vulnerabilities', 'attack surface', 'attack path',
+  'found vulnerabilities', 'discovered vulnerabilities', 'uncovers vulnerabilities',
+  'uncovered vulnerabilities', 'vulnerabilities found by', 'vulnerabilities discovered by',
+  'attack simulation', 'attacks ssh', 'attack tool', 'offensive security', 'red team',
+  'exploit chain', 'exploit development', 'penetration testing',
+  'brute force', 'brute-force', 'credential testing', 'password spraying',
+  'secret scanning', 'leaked secrets', 'exposed secrets', 'threat hunting',
+];
+
+// Phrases that signal coverage is critical of us or our tools.
+const NEGATIVE = [
+  'vulnerability in', 'vulnerability found in', 'vulnerabilities in', 'flaw in',
+  'bug in', 'vulnerable to', 'security hole', 'cve-', 'backdoor', 'critical review', 'criticized', 'criticism', 'criticizes', 'lawsuit',
+  'sued', 'breach at', 'breached', 'data breach', 'layoffs', 'backlash', 'controversy',
+  'abused by', 'misused', 'weaponized', 'falls short', 'disappointing', 'unreliable',
+  'false positives', 'deprecated', 'abandoned',
+];
+
+// Phrases that signal favourable coverage.
+const POSITIVE = [
+  'award', 'wins', 'named a', 'leader', 'launches', 'introduces', 'releases',
+  'unveils', 'open-source', 'open source', 'new tool', 'helps', 'praised',
+  'recognized', 'partnership', 'partners with', 'innovative', 'best', 'top',
+  'must-have', 'powerful',
+];
+
+/**
+ * Default classifier: phrase lexicon over the title and excerpt.
+ * Negative phrases weigh double so a single critical signal isn't
+ * drowned out by launch language in the same sentence.
+ */
+export const lexiconClassifier = {
+  name: 'lexicon',
+  classify(item) {
+    let text = ` ${item.title || ''} ${item.excerpt || ''} `.toLowerCase();
+    for (const phrase of DOMAIN_NEUTRAL) {
+      text = text.split(phrase).join(' ');
+    }
+
+    const pos = countHits(text, POSITIVE);
+    const neg = countHits(text, NEGATIVE) * 2;
+    if (pos + neg === 0) return { label: 'neutral', score: 0 };
+
+    const score = Math.round(((pos - neg) / (pos + neg)) * 100) / 100;
+    const label = score <= -0.2 ? 'negative' : score >= 0.2 ? 'positive' : 'neutral';
+    return { label, score };
+  },
+};
+
+/**
+ * Load the configured classifier. SENTIMENT_CLASSIFIER may point at a
+ * module whose default export has the same shape as lexiconClassifier:
+ *
+ *   export default {
+ *     name: 'llm',
+ *     async classify({ title, excerpt }) { return { label, score }; },
+ *   };
+ *
+ * `label` must be one of SENTIMENT_LABELS; `score` is in [-1, 1].
+ */
+export async function loadClassifier(modulePath = config.sentiment.classifier) {
+  if (!modulePath) return lexiconClassifier;
+  const mod = await import(pathToFileURL(resolve(config.paths.root, modulePath)).href);
+  const classifier = mod.default;
+  if (!classifier || typeof classifier.classify !== 'function') {
+    throw new Error(`Sentiment classifier ${modulePath} must default-export { name, classify(item) }`);
+  }
+  return classifier;
+}
+
+/**
+ * Label tracker items that don't have a sentiment yet. Stores `sentiment`,
+ * `sentiment_score`, and the classifier's name. Returns the count labeled.
+ */
+export async function classifyTrackerItems(tracker, classifier = lexiconClassifier) {
+  let count = 0;
+  for (const item of tracker) {
+    if (item.sentiment) continue;
+
+    const { label, score } = await classifier.classify({ title: item.title, excerpt: item.excerpt });
+    if (!SENTIMENT_LABELS.includes(label)) {
+      throw new Error(`Classifier ${classifier.name} returned unknown label "${label}" for ${item.id}`);
+    }
+    item.sentiment = label;
+    item.sentiment_score = score;
+    item.sentiment_classifier = classifier.name;
+    count++;
+  }
+  return count;
+}
+
+/**
+ * Count phrases that occur as whole words ("top" doesn't match "laptop").
+ */
+function countHits(text, phrases) {
+  return phrases.filter(phrase => {
+    const escaped = phrase.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+    const end = /[a-z0-9]$/.test(phrase) ? '(?![a-z0-9])' : '';
+    return new RegExp(`(?<![a-z0-9])${escaped}${end}`).test(text);
+  }).length;
+}
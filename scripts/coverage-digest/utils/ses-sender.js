@@ -0,0 +1,92 @@
+import { randomBytes } from 'crypto';
+import { mkdir, writeFile } from 'fs/promises';
+import { join } from 'path';
+import { config } from '../config.js';
+import { awsCredentials, signRequest } from './aws.js';
+import { clientFor } from './http-client.js';
+
+/**
+ * Build a multipart/alternative MIME message with a plain-text part and
+ * an HTML part. Both parts are base64-encoded so long lines and non-ASCII
+ * text survive any relay.
+ */
+export function buildMimeMessage({ from, to, cc = [], replyTo = '', subject, html, text }) {
+  const boundary = `digest-${randomBytes(12).toString('hex')}`;
+  const headers = [
+    `From: ${from}`,
+    `To: ${to.join(', ')}`,
+    ...(cc.length > 0 ? [`Cc: ${cc.join(', ')}`] : []),
+    ...(replyTo ? [`Reply-To: ${replyTo}`] : []),
+    `Subject: ${encodeHeader(subject)}`,
+    `Date: ${new Date().toUTCString()}`,
+    'MIME-Version: 1.0',
+    `Content-Type: multipart/alternative; boundary="${boundary}"`,
+  ];
+  const part = (type, body) => [
+    `--${boundary}`,
+    `Content-Type: ${type}; charset=UTF-8`,
+    'Content-Transfer-Encoding: base64',
+    '',
+    base64Lines(body),
+  ];
+
+  return [
+    ...headers,
+    '',
+    ...part('text/plain', text),
+    ...part('text/html', html),
+    `--${boundary}--`,
+    '',
+  ].join('\r\n');
+}
+
+/**
+ * Send the digest through Amazon SES (v2 SendEmail, raw content), or in
+ * dry-run mode write the MIME message to config.ses.dryRunDir instead.
+ * Returns { messageId } when sent or { path } when written.
+ */
+export async function sendViaSes({ subject, html, text }, { dryRun = config.dryRun } = {}) {
+  const { from, to, replyTo, region, dryRunDir } = config.ses;
+  if (!from || to.length === 0) {
+    throw new Error('SES_FROM and SES_TO must be set to send via SES');
+  }
+
+  const mime = buildMimeMessage({ from, to, cc: config.email.cc, replyTo, subject, html, text });
+
+  if (dryRun) {
+    await mkdir(dryRunDir, { recursive: true });
+    const path = join(dryRunDir, `digest-${new Date().toISOString().replace(/[:.]/g, '-')}.eml`);
+    await writeFile(path, mime);
+    return { path };
+  }
+
+  const credentials = awsCredentials('to send via SES');
+  const host = `email.${region}.amazonaws.com`;
+  const path = '/v2/email/outbound-emails';
+  const body = JSON.stringify({
+    FromEmailAddress: from,
+    Destination: { ToAddresses: to, CcAddresses: config.email.cc },
+    ...(replyTo ? { ReplyToAddresses: [replyTo] } : {}),
+    Content: { Raw: { Data: Buffer.from(mime).toString('base64') } },
+  });
+  const headers = signRequest({
+    method: 'POST', host, path, headers: { 'content-type': 'application/json' }, body, region, service: 'ses', credentials,
+  });
+
+  const res = await clientFor('SES').fetch(`https://${host}${path}`, { method: 'POST', headers, body });
+  const data = await res.json().catch(() => ({}));
+  if (!res.ok) {
+    throw new Error(`SES error (HTTP ${res.status}): ${data.message || data.Message || 'unknown error'}`);
+  }
+  return { messageId: data.MessageId };
+}
+
+function base64Lines(body) {
+  return Buffer.from(body).toString('base64').match(/.{1,76}/g)?.join('\r\n') || '';
+}
+
+// RFC 2047 encoding for non-ASCII header values (emoji, accented names)
+function encodeHeader(value) {
+  if (/^[\x20-\x7e]*$/.test(value)) return value;
+  return `=?UTF-8?B?${Buffer.from(value).toString('base64')}?=`;
+}
@@ -0,0 +1,56 @@
+import { config } from '../config.js';
+import { normalizeUrl } from './tracker.js';
+import { canonicalTools } from './tools.js';
+
+export const SORT_ORDERS = ['date', 'source', 'tool'];
+
+/**
+ * Sort digest or tracker items deterministically. Returns a new array.
+ *
+ *   date    publish date, newest first (default)
+ *   source  source name A-Z, then newest first
+ *   tool    first tool A-Z (untagged items last), then newest first
+ *
+ * Items with a missing or unparseable date sort after every dated item in
+ * their group, and any remaining tie is broken on the normalized URL, so
+ * the same items always come out in the same order.
+ *
+ * Whatever the order, negative coverage (sentiment "negative", see
+ * utils/sentiment.js) comes first, unless `negativeFirst` is false.
+ */
+export function sortItems(items, order = config.sortOrder, { negativeFirst = true } = {}) {
+  if (!SORT_ORDERS.includes(order)) {
+    throw new Error(`Unknown sort order "${order}" (expected ${SORT_ORDERS.join(', ')})`);
+  }
+
+  const keyed = items.map(item => ({
+    item,
+    negative: negativeFirst && item.sentiment === 'negative',
+    time: item.date ? new Date(item.date).getTime() : NaN,
+    url: normalizeUrl(item.url),
+    source: (item.source || '').trim().toLowerCase(),
+    tool: (canonicalTools(item.toolsMentioned || item.tools_mentioned, item.date)[0] || '').toLowerCase(),
+  }));
+
+  const primary = {
+    date: () => 0,
+    source: (a, b) => compareText(a.source, b.source),
+    tool: (a, b) => (a.tool === '') - (b.tool === '') || compareText(a.tool, b.tool),
+  }[order];
+
+  keyed.sort((a, b) => b.negative - a.negative || primary(a, b) || compareDates(a.time, b.time) || compareText(a.url, b.url));
+  return keyed.map(({ item }) => item);
+}
+
+// Newest first; undated last
+function compareDates(a, b) {
+  const aMissing = Number.isNaN(a);
+  const bMissing = Number.isNaN(b);
+  if (aMissing || bMissing) return aMissing - bMissing;
+  return b - a;
+}
+
+// Code-unit order, so results don't depend on the runtime's locale
+function compareText(a, b) {
+  return a < b ? -1 : a > b ? 1 : 0;
+}
@@ -1,99 +1,59 @@
-import { readFile, writeFile, mkdir } from 'fs/promises';
-import { join } from 'path';
 import { config } from '../config.js';
-
-const STATE_FILE = join(config.paths.state, 'digest-state.json');
-
-/**
- * Load the current state (last run time, seen item IDs).
- */
-async function loadState() {
-  try {
-    const raw = await readFile(STATE_FILE, 'utf-8');
-    return JSON.parse(raw);
-  } catch (err) {
-    if (err.code === 'ENOENT') {
-      return {
-        lastRun: null,
-        seenIds: [],
-        runHistory: [],
-      };
-    }
-    throw err;
-  }
-}
-
-/**
- * Save state to disk.
- */
-async function saveState(state) {
-  await mkdir(config.paths.state, { recursive: true });
-  await writeFile(STATE_FILE, JSON.stringify(state, null, 2));
-}
+import { openSeenStore, isSuppressed } from './seen-store.js';
+import { shiftDays } from './timezone.js';
 
 /**
  * Get the date since which we should look for new items.
- * Returns the last successful run time, or 24 hours ago if first run.
+ * Returns the last successful run time, or this time yesterday (in the
+ * digest's time zone) if first run.
  */
-export async function getSinceDate() {
-  const state = await loadState();
-  if (state.lastRun) {
-    return new Date(state.lastRun);
+export async function getSinceDate(now = new Date(), timeZone = config.timeZone) {
+  const store = await openSeenStore();
+  const lastRun = store.lastRunTime();
+  if (lastRun) {
+    return lastRun;
   }
-  // Default: look back 24 hours on first run
-  const yesterday = new Date();
-  yesterday.setHours(yesterday.getHours() - 24);
-  return yesterday;
+  // Default: look back one day on first run. A calendar day, not 24
+  // hours, so a run on a DST change day neither repeats nor skips an hour.
+  return shiftDays(now, -1, timeZone);
 }
 
 /**
- * Filter out items we've already seen/sent.
- * Uses a combination of URL and title to deduplicate.
+ * Filter out items we've already sent, by canonical URL (see
+ * utils/seen-store.js), unless SEEN_SUPPRESS_DAYS have passed since they
+ * were last reported. Sent items that are kept are marked
+ * `previouslyCovered` with when they were last reported.
+ *
+ * @param {Array<Object>} items
+ * @param {Object} [options]
+ *   allowResurface - keep sent items however recently they were reported,
+ *                    still marked (for recaps and backfills)
+ *   now            - default: the current time
  */
-export async function filterNewItems(items) {
-  const state = await loadState();
-  const seenSet = new Set(state.seenIds);
-
+export async function filterNewItems(items, { allowResurface = false, now = new Date() } = {}) {
+  const store = await openSeenStore();
   return items.filter(item => {
-    const id = itemId(item);
-    return !seenSet.has(id);
+    const seen = store.get(item);
+    if (!seen) return true;
+    if (!allowResurface && isSuppressed(seen, now)) return false;
+    item.previouslyCovered = seen.last_reported_at;
+    return true;
   });
 }
 
 /**
- * Record that we've processed a set of items and sent a digest.
+ * Record that we've sent a digest of these items. Call only once it has
+ * gone out, so a failed send leaves its items to be tried again. Entries
+ * last reported more than SEEN_RETENTION_DAYS ago are pruned on the way.
  */
-export async function recordRun(items) {
-  const state = await loadState();
-
-  const newIds = items.map(item => itemId(item));
-  state.seenIds = [...new Set([...state.seenIds, ...newIds])];
-
-  // Keep only the last 1000 seen IDs to prevent unbounded growth
-  if (state.seenIds.length > 1000) {
-    state.seenIds = state.seenIds.slice(-1000);
-  }
-
-  state.lastRun = new Date().toISOString();
-  state.runHistory.push({
-    date: state.lastRun,
-    itemCount: items.length,
-    sources: [...new Set(items.map(i => i.source))],
-  });
-
-  // Keep only the last 90 days of run history
-  if (state.runHistory.length > 90) {
-    state.runHistory = state.runHistory.slice(-90);
+export async function recordRun(items, now = new Date()) {
+  const store = await openSeenStore();
+  // Syndicated copies were sent along with the item they were folded into
+  store.put(items.flatMap(item => [item, ...(item.alsoPublishedBy || []).map(outlet => ({ ...item, ...outlet }))]), now);
+  store.recordRun(items, now);
+  const pruned = store.prune(now);
+  if (pruned > 0) {
+    console.log(`Seen items: pruned ${pruned} item(s) past the ${config.seenStore.retentionDays}-day retention window`);
   }
-
-  await saveState(state);
-}
-
-/**
- * Generate a unique ID for a coverage item.
- */
-function itemId(item) {
-  const raw = item.raw?.guid || item.url || `${item.source}-${item.title}`;
-  // Simple hash-like string
-  return Buffer.from(raw).toString('base64').substring(0, 64);
+  await store.save();
 }
@@ -0,0 +1,156 @@
+import { mkdir, readdir, readFile, rename, unlink, writeFile } from 'fs/promises';
+import { join } from 'path';
+import { randomUUID } from 'crypto';
+import { config } from '../config.js';
+import { sharedS3 } from './s3.js';
+
+const SOURCE_TYPES = ['media', 'blog', 'event', 'podcast', 'social'];
+const CALENDAR_DATE = /^\d{4}-\d{2}-\d{2}$/;
+// Inbox entries under the S3 prefix
+const S3_PREFIX = 'webhook-inbox/';
+
+/**
+ * Validate a webhook submission against the coverage item schema (same
+ * shape as manual-submissions.json). Returns a list of errors.
+ */
+export function validateSubmission(body) {
+  const errors = [];
+  if (!body || typeof body !== 'object' || Array.isArray(body)) {
+    return ['body must be a JSON object'];
+  }
+
+  for (const field of ['title', 'url', 'source']) {
+    if (typeof body[field] !== 'string' || !body[field].trim()) {
+      errors.push(`${field} is required and must be a non-empty string`);
+    }
+  }
+  if (typeof body.url === 'string' && !/^https?:\/\/\S+$/i.test(body.url.trim())) {
+    errors.push('url must be an absolute http(s) URL');
+  }
+  if (body.date !== undefined && !isCalendarDay(body.date)) {
+    errors.push('date must be a real day in YYYY-MM-DD format');
+  }
+  if (body.source_type !== undefined && !SOURCE_TYPES.includes(body.source_type)) {
+    errors.push(`source_type must be one of: ${SOURCE_TYPES.join(', ')}`);
+  }
+  if (body.tools_mentioned !== undefined &&
+      !(Array.isArray(body.tools_mentioned) && body.tools_mentioned.every(t => typeof t === 'string'))) {
+    errors.push('tools_mentioned must be an array of strings');
+  }
+  if (body.excerpt !== undefined && typeof body.excerpt !== 'string') {
+    errors.push('excerpt must be a string');
+  }
+  if (body.embargo_until !== undefined && (typeof body.embargo_until !== 'string' || isNaN(new Date(body.embargo_until)))) {
+    errors.push('embargo_until must be an ISO timestamp');
+  }
+  return errors;
+}
+
+/**
+ * The discovered item (as mergeIntoTracker takes them) for a valid
+ * submission, stamped with an id for the inbox.
+ */
+export function submissionItem(body, now = new Date()) {
+  return {
+    submissionId: randomUUID(),
+    receivedAt: now.toISOString(),
+    source: body.source.trim(),
+    sourceType: body.source_type || 'media',
+    title: body.title.trim(),
+    url: body.url.trim(),
+    date: body.date || now.toISOString().split('T')[0],
+    excerpt: body.excerpt || '',
+    toolsMentioned: body.tools_mentioned || [],
+    embargoUntil: body.embargo_until || null,
+    discoveredBy: 'webhook',
+  };
+}
+
+/**
+ * The inbox between the webhook receiver and the pipeline. The receiver
+ * only adds to it, one entry per accepted submission, written whole (a
+ * file renamed into place, or one S3 object), so a half-written entry is
+ * never read. The pipeline lists what's there, merges it into the
+ * tracker, and only once the tracker is saved removes the entries it
+ * took. An entry that arrives meanwhile waits for the next run; one the
+ * pipeline took but didn't get to remove (it crashed) is taken again and
+ * dropped as a duplicate of the tracker item it became. So each
+ * submission reaches the tracker exactly once.
+ *
+ * Returns { add(item), list(), remove(items) }, where list() gives the
+ * entries waiting, oldest first.
+ *
+ * @param {Object} [options]
+ *   backend - file (config.paths.webhookInbox) or s3, under S3_PREFIX
+ *             (default: config.webhook.inbox)
+ *   path    - the inbox directory for the file backend
+ *   s3      - S3 client for the s3 backend (default: utils/s3.js sharedS3())
+ */
+export function openInbox({
+  backend = config.webhook.inbox,
+  path = config.paths.webhookInbox,
+  s3 = backend === 's3' ? sharedS3() : null,
+} = {}) {
+  const entryName = item => `${item.receivedAt.replace(/[:.]/g, '-')}-${item.submissionId}.json`;
+
+  if (backend === 'file') {
+    return {
+      async add(item) {
+        await mkdir(path, { recursive: true });
+        const file = join(path, entryName(item));
+        await writeFile(`${file}.tmp`, JSON.stringify(item) + '\n');
+        await rename(`${file}.tmp`, file);
+      },
+      async list() {
+        let names;
+        try {
+          names = await readdir(path);
+        } catch (err) {
+          if (err.code === 'ENOENT') return [];
+          throw err;
+        }
+        const items = [];
+        for (const name of names.filter(name => name.endsWith('.json')).sort()) {
+          items.push(JSON.parse(await readFile(join(path, name), 'utf-8')));
+        }
+        return items;
+      },
+      async remove(items) {
+        for (const item of items) {
+          await unlink(join(path, entryName(item))).catch(err => {
+            if (err.code !== 'ENOENT') throw err;
+          });
+        }
+      },
+    };
+  }
+  if (backend !== 's3') {
+    throw new Error(`Unknown WEBHOOK_INBOX "${backend}" (expected file or s3)`);
+  }
+  if (!s3) throw new Error('WEBHOOK_INBOX=s3 needs S3_BUCKET');
+
+  return {
+    async add(item) {
+      await s3.putObject(`${S3_PREFIX}${entryName(item)}`, JSON.stringify(item), { contentType: 'application/json', ifNoneMatch: '*' });
+    },
+    async list() {
+      const items = [];
+      for (const key of (await s3.listKeys(S3_PREFIX)).filter(key => key.endsWith('.json'))) {
+        const object = await s3.getObject(key);
+        // Removed by another run since it was listed
+        if (object) items.push(JSON.parse(object.body.toString('utf-8')));
+      }
+      return items;
+    },
+    async remove(items) {
+      for (const item of items) await s3.deleteObject(`${S3_PREFIX}${entryName(item)}`);
+    },
+  };
+}
+
+// A YYYY-MM-DD string naming a day that exists ("2026-13-01" doesn't)
+function isCalendarDay(value) {
+  if (typeof value !== 'string' || !CALENDAR_DATE.test(value)) return false;
+  const date = new Date(`${value}T00:00:00Z`);
+  return !isNaN(date) && date.toISOString().startsWith(value);
+}
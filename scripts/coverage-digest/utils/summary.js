@@ -0,0 +1,204 @@
+import { config } from '../config.js';
+import { normalizeUrl } from './tracker.js';
+import { canonicalTools } from './tools.js';
+import { escapeMarkdown } from './markdown.js';
+import { normalizePublisher } from './publishers.js';
+
+/**
+ * Compute the digest summary shared by the issue markdown and the JSON
+ * digest. Items are { url, source, tools }; an article syndicated under
+ * several URLs that normalize the same is counted once. Publications are
+ * compared by their canonical names (utils/publishers.js), so "Help Net
+ * Security" and "helpnetsecurity.com" are one.
+ *
+ * `history` is the full tracker. A tool (or publication) is flagged as
+ * first-seen when no tracker item outside this digest mentions it (or
+ * comes from it). Pass null when the tracker isn't available; the
+ * first-seen lists are then left empty and all-time counts are null.
+ *
+ * Returns:
+ *   {
+ *     itemCount:        number of distinct articles
+ *     uniqueSources:    number of distinct publications
+ *     toolCounts:       [{ tool, count }] by count desc, then config.tools order
+ *     sourceCounts:     [{ source, count, allTime }] by count desc, then name A-Z;
+ *                       allTime counts every tracked article from that publication
+ *     firstSeenTools:   tools appearing in coverage for the first time
+ *     firstSeenSources: publications covering us for the first time, A-Z
+ *   }
+ */
+export function summarizeDigest(items, history = null) {
+  const byUrl = new Map();
+  for (const item of items) {
+    const key = normalizeUrl(item.url) || item.url;
+    if (!byUrl.has(key)) byUrl.set(key, item);
+  }
+  const distinct = [...byUrl.values()];
+
+  const counts = new Map();
+  for (const item of distinct) {
+    for (const tool of canonicalTools(item.tools, item.date)) {
+      counts.set(tool, (counts.get(tool) || 0) + 1);
+    }
+  }
+  const rank = tool => {
+    const index = config.tools.indexOf(tool);
+    return index === -1 ? config.tools.length : index;
+  };
+  const toolCounts = [...counts.entries()]
+    .map(([tool, count]) => ({ tool, count }))
+    .sort((a, b) => b.count - a.count || rank(a.tool) - rank(b.tool) || a.tool.localeCompare(b.tool));
+
+  // Publication key -> { source (display name), count }
+  const sources = new Map();
+  for (const item of distinct) {
+    const source = normalizePublisher(item.source, item.url);
+    const key = source.toLowerCase();
+    if (!key) continue;
+    if (!sources.has(key)) sources.set(key, { source, count: 0 });
+    sources.get(key).count++;
+  }
+
+  let firstSeenTools = [];
+  let firstSeenSources = [];
+  let allTime = null;
+  if (history) {
+    const earlier = history.filter(item => !byUrl.has(normalizeUrl(item.url) || item.url));
+    const seenBefore = new Set(earlier.flatMap(item => canonicalTools(item.tools_mentioned, item.date)));
+    firstSeenTools = toolCounts.map(({ tool }) => tool).filter(tool => !seenBefore.has(tool));
+    const sourcesBefore = new Set(earlier.map(item => sourceKey(item)));
+    firstSeenSources = [...sources.entries()]
+      .filter(([key]) => !sourcesBefore.has(key))
+      .map(([, { source }]) => source)
+      .sort((a, b) => a.localeCompare(b));
+
+    // Every distinct article, this digest's included, per publication
+    allTime = new Map();
+    const counted = new Set();
+    for (const item of [...distinct, ...earlier]) {
+      const url = normalizeUrl(item.url) || item.url;
+      if (counted.has(url) || item.status === 'embargoed') continue;
+      counted.add(url);
+      allTime.set(sourceKey(item), (allTime.get(sourceKey(item)) || 0) + 1);
+    }
+  }
+
+  const sourceCounts = [...sources.entries()]
+    .map(([key, { source, count }]) => ({ source, count, allTime: allTime ? allTime.get(key) || count : null }))
+    .sort((a, b) => b.count - a.count || a.source.localeCompare(b.source));
+
+  return {
+    itemCount: distinct.length,
+    uniqueSources: sources.size,
+    toolCounts,
+    sourceCounts,
+    firstSeenTools,
+    firstSeenSources,
+  };
+}
+
+/**
+ * The summary of the digest sent before this one, from the tracker: the
+ * items stamped with the latest last_sent_at, leaving out this digest's
+ * own items (the workflow may already have stamped them) and those it
+ * left out as low-relevance. Null when no earlier digest has gone out.
+ */
+export function previousDigestSummary(history, items) {
+  const current = new Set(items.map(item => normalizeUrl(item.url) || item.url));
+  const earlier = history.filter(item =>
+    item.last_sent_at && item.status !== 'embargoed' && !item.low_relevance && !current.has(normalizeUrl(item.url) || item.url)
+  );
+  const lastSentAt = earlier.map(item => item.last_sent_at).sort().pop();
+  if (!lastSentAt) return null;
+  const previous = earlier.filter(item => item.last_sent_at === lastSentAt);
+  return summarizeDigest(previous.map(item => ({ ...item, tools: item.tools_mentioned })));
+}
+
+/**
+ * Compare a digest's summary with the previous period's for the Trends
+ * section. Both are summarizeDigest() results. Returns null when there is
+ * no previous period, so the section is left out rather than showing
+ * zeros.
+ *
+ * Returns:
+ *   {
+ *     items:      { count, previous, delta }
+ *     tools:      [{ tool, count, previous, delta }] for every tool in either
+ *                 period, this period's order first; delta is null for a
+ *                 tool the previous period didn't mention
+ *     newSources: publications covering us for the first time
+ *   }
+ */
+export function compareSummaries(current, previous) {
+  if (!previous) return null;
+  const before = new Map(previous.toolCounts.map(({ tool, count }) => [tool, count]));
+  const now = new Map(current.toolCounts.map(({ tool, count }) => [tool, count]));
+  const tools = [
+    ...current.toolCounts.map(({ tool }) => tool),
+    ...previous.toolCounts.map(({ tool }) => tool).filter(tool => !now.has(tool)),
+  ];
+  return {
+    items: { count: current.itemCount, previous: previous.itemCount, delta: current.itemCount - previous.itemCount },
+    tools: tools.map(tool => ({
+      tool,
+      count: now.get(tool) || 0,
+      previous: before.get(tool) || 0,
+      delta: before.has(tool) ? (now.get(tool) || 0) - before.get(tool) : null,
+    })),
+    newSources: current.firstSeenSources,
+  };
+}
+
+/**
+ * A trend delta as a marker: "+2", "−1" (a true minus sign), "0", or
+ * `newLabel` for a null delta.
+ */
+export function formatDelta(delta, newLabel = 'new') {
+  if (delta === null) return newLabel;
+  if (delta > 0) return `+${delta}`;
+  return delta < 0 ? `\u2212${-delta}` : '0';
+}
+
+/**
+ * The Trends section as markdown, for the digest issue and the rollup.
+ * `period` names what the previous column is, e.g. "previous digest".
+ */
+export function renderTrendsMarkdown(trends, period) {
+  let md = `## Trends\n\n`;
+  md += `Compared with the ${period}:\n\n`;
+  md += `| Metric | Now | Before | Change |\n|--------|-----|--------|--------|\n`;
+  md += `| Items | ${trends.items.count} | ${trends.items.previous} | ${formatDelta(trends.items.delta)} |\n`;
+  for (const { tool, count, previous, delta } of trends.tools) {
+    md += `| ${escapeMarkdown(tool)} | ${count} | ${previous} | ${formatDelta(delta)} |\n`;
+  }
+  if (trends.newSources.length > 0) {
+    md += `\nNew publications: ${trends.newSources.map(source => `**${escapeMarkdown(source)}**`).join(', ')}\n`;
+  }
+  return md + `\n`;
+}
+
+/**
+ * The Sources section as markdown: each publication in the period with its
+ * item count and, when known, its all-time count. At most `limit` rows;
+ * the rest are summed up in an "and N more" line.
+ */
+export function renderSourcesMarkdown(sourceCounts, { limit = config.digestIssue.sourceRows } = {}) {
+  const withAllTime = sourceCounts.some(row => row.allTime !== null);
+  let md = `## Sources\n\n`;
+  md += withAllTime
+    ? `| Publication | Items | All Time |\n|-------------|-------|----------|\n`
+    : `| Publication | Items |\n|-------------|-------|\n`;
+  for (const { source, count, allTime } of sourceCounts.slice(0, limit)) {
+    md += withAllTime
+      ? `| ${escapeMarkdown(source)} | ${count} | ${allTime} |\n`
+      : `| ${escapeMarkdown(source)} | ${count} |\n`;
+  }
+  const more = sourceCounts.length - limit;
+  if (more > 0) md += `\n_and ${more} more_\n`;
+  return md + `\n`;
+}
+
+// Canonical publication of an item, lowercased
+function sourceKey(item) {
+  return normalizePublisher(item.source, item.url).toLowerCase();
+}
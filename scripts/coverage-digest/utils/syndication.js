@@ -0,0 +1,167 @@
+import { config } from '../config.js';
+import { sharedNormalizer } from './publishers.js';
+import { matchableText } from './tools.js';
+import { normalizeUrl } from './tracker.js';
+
+// Sites that republish other outlets' articles. A copy on one of these is
+// never taken for the original while an outlet's own copy is at hand.
+const AGGREGATOR_DOMAINS = [
+  'news.google.com', 'msn.com', 'news.yahoo.com', 'yahoo.com', 'flipboard.com', 'newsbreak.com',
+  'ground.news', 'inkl.com', 'headtopics.com', 'newsnow.co.uk', 'bignewsnetwork.com',
+  'securityboulevard.com', 'feedly.com', 'newsnow.com', 'muckrack.com',
+];
+// Words too common to tell two headlines apart
+const STOPWORDS = new Set([
+  'a', 'an', 'the', 'of', 'to', 'in', 'for', 'on', 'and', 'or', 'with', 'by', 'at', 'from',
+  'is', 'are', 'as', 'its', 'it', 'this', 'that', 'new', 'how', 'why', 'what',
+]);
+// Words of a headline its lookalikes are looked up by: its rarest few in
+// the batch, since alike headlines share most of their words, and rare
+// ones ("praetorian" is in every headline) pick out very few others
+const LOOKUP_WORDS = 3;
+// Separators before an outlet's name at the end of a headline
+const SUFFIX_SEPARATOR = /\s+(?:[|–—-]|::)\s+/;
+
+/**
+ * A headline reduced to what syndicated copies share: lowercased (see
+ * matchableText), an outlet's name after a final " | ", " - " or " — "
+ * dropped ("... | SecurityWeek"), and punctuation removed. `source` is the
+ * item's own outlet, which is dropped whether or not it's a publisher we
+ * know.
+ */
+export function normalizeTitle(title, source = '') {
+  let text = String(title ?? '').trim();
+  const parts = text.split(SUFFIX_SEPARATOR);
+  if (parts.length > 1 && isOutletName(parts[parts.length - 1], source)) {
+    text = parts.slice(0, -1).join(' ');
+  }
+  return matchableText(text).replace(/[^\p{L}\p{N}\s]+/gu, ' ').replace(/\s+/g, ' ').trim();
+}
+
+/**
+ * How alike two normalized headlines are, from 0 to 1: the Dice
+ * coefficient of their sets of words, leaving out stopwords. Word order
+ * and repeated words don't count, so "Praetorian releases Brutus, a
+ * credential tool" and "Brutus credential tool released by Praetorian"
+ * come out close.
+ */
+export function titleSimilarity(a, b) {
+  return wordSimilarity(new Set(significantWords(a)), new Set(significantWords(b)));
+}
+
+function wordSimilarity(left, right) {
+  if (left.size === 0 || right.size === 0) return 0;
+  let shared = 0;
+  for (const word of left) if (right.has(word)) shared++;
+  return (2 * shared) / (left.size + right.size);
+}
+
+/**
+ * Collapse syndicated copies of one article into a single item. Items
+ * published within `windowHours` of each other whose normalized titles
+ * are at least `threshold` alike are one story: the item kept is the
+ * earliest published, preferring an outlet's own copy to an aggregator's,
+ * and the others are listed on it as `alsoPublishedBy` ([{ source, url }],
+ * outlets' own copies before aggregators', each earliest first), with
+ * their tools added to its own.
+ *
+ * Items with the same canonical URL are left for URL dedup; only the
+ * first of them is compared. Items kept are indexed by their words, and
+ * each item is compared only with those sharing one of its rarest words,
+ * so a large backfill is compared a handful of items at a time rather
+ * than every pair.
+ *
+ * @param {Array<Object>} items - discovered items (changed in place)
+ * @param {Object} [options]
+ *   threshold   - least title similarity, 0-1 (default: config.syndication.threshold)
+ *   windowHours - most time between copies (default: config.syndication.windowHours)
+ * @returns {{ items: Array<Object>, collapsed: number }} the items left,
+ *   in their original order, and how many were folded into another
+ */
+export function collapseSyndicated(items, {
+  threshold = config.syndication.threshold,
+  windowHours = config.syndication.windowHours,
+} = {}) {
+  const seenUrls = new Set();
+  const candidates = items
+    .map((item, order) => ({ item, order }))
+    .filter(({ item }) => {
+      const key = normalizeUrl(item.url);
+      if (!key) return true;
+      if (seenUrls.has(key)) return false;
+      seenUrls.add(key);
+      return true;
+    })
+    .map(entry => ({
+      ...entry,
+      words: new Set(significantWords(normalizeTitle(entry.item.title, entry.item.source))),
+      time: Date.parse(entry.item.date) || 0,
+      aggregator: isAggregator(entry.item.url),
+    }))
+    .sort((a, b) => a.aggregator - b.aggregator || a.time - b.time || a.order - b.order);
+
+  const frequency = new Map();
+  for (const { words } of candidates) {
+    for (const word of words) frequency.set(word, (frequency.get(word) || 0) + 1);
+  }
+
+  const windowMs = windowHours * 3600000;
+  const index = new Map();
+  const folded = new Set();
+  for (const candidate of candidates) {
+    const lookup = [...candidate.words].sort((a, b) => frequency.get(a) - frequency.get(b)).slice(0, LOOKUP_WORDS);
+    const compared = new Set();
+    const primary = lookup
+      .flatMap(word => index.get(word) || [])
+      .find(other => {
+        if (compared.has(other)) return false;
+        compared.add(other);
+        return Math.abs(other.time - candidate.time) <= windowMs && wordSimilarity(other.words, candidate.words) >= threshold;
+      });
+
+    if (primary) {
+      fold(primary.item, candidate.item);
+      folded.add(candidate.item);
+      continue;
+    }
+    for (const word of candidate.words) {
+      if (!index.has(word)) index.set(word, []);
+      index.get(word).push(candidate);
+    }
+  }
+  return { items: items.filter(item => !folded.has(item)), collapsed: folded.size };
+}
+
+// Whether a URL is on one of the aggregators
+export function isAggregator(url) {
+  let host;
+  try {
+    host = new URL(url).hostname.toLowerCase().replace(/^www\./, '');
+  } catch {
+    return false;
+  }
+  return AGGREGATOR_DOMAINS.some(domain => host === domain || host.endsWith(`.${domain}`));
+}
+
+function fold(primary, copy) {
+  primary.alsoPublishedBy = [...(primary.alsoPublishedBy || []), { source: copy.source, url: copy.url }];
+  const tools = [...new Set([...(primary.toolsMentioned || []), ...(copy.toolsMentioned || [])])];
+  if (tools.length > (primary.toolsMentioned || []).length) {
+    primary.toolsMentioned = tools;
+    primary.untagged = false;
+  }
+}
+
+function isOutletName(text, source) {
+  const squashed = squash(text);
+  if (!squashed || text.split(/\s+/).length > 5) return false;
+  return squashed === squash(source) || Boolean(sharedNormalizer().nameForSource(text));
+}
+
+function significantWords(title) {
+  return title.split(' ').filter(word => word && !STOPWORDS.has(word));
+}
+
+function squash(str) {
+  return (str || '').toLowerCase().replace(/[^a-z0-9]/g, '');
+}
@@ -0,0 +1,183 @@
+import { config } from '../config.js';
+import { clientFor } from './http-client.js';
+import { parseRetryAfter, retry } from './retry.js';
+import { summarizeDigest } from './summary.js';
+import { escapeLinkUrl, escapeMarkdown } from './markdown.js';
+import { chatItem, selectChatItems, truncate } from './chat-digest.js';
+
+const CARD_SCHEMA = 'http://adaptivecards.io/schemas/adaptive-card.json';
+// Teams renders Adaptive Cards up to this version in webhook messages
+const CARD_VERSION = '1.4';
+
+/**
+ * Render a digest as Teams messages, each an Adaptive Card:
+ *
+ *   header     "Coverage Digest - <date>", then "5 items from 3 publications"
+ *   fact set   Items, Publications, Tools Mentioned, and Negative, Needs
+ *              Review and Low Relevance when there are any
+ *   items      a container per item: linked title (⚠️ when negative),
+ *              source · date · tools, excerpt
+ *   footer     how many items weren't listed
+ *
+ * Items are picked as for every chat message (see selectChatItems). A
+ * message that would pass `maxBytes` is split: later cards repeat the
+ * header, marked "continued", and carry on with the items. An item is
+ * never split across cards.
+ *
+ * @param {Array<Object>} items - the digest's tracker items
+ * @param {Object} [options]
+ *   date         - the digest's date, for the header
+ *   maxItems     - items listed (default: config.teams.maxItems)
+ *   excerptChars - excerpt length, cut at a sentence end (default: config.teams.excerptChars)
+ *   maxBytes     - largest message (default: config.teams.maxPayloadBytes)
+ * @returns {Array<Object>} message payloads, in order
+ */
+export function renderTeamsMessages(items, {
+  date,
+  maxItems = config.teams.maxItems,
+  excerptChars = config.teams.excerptChars,
+  maxBytes = config.teams.maxPayloadBytes,
+} = {}) {
+  const { confirmed, shown, more, review, lowRelevance } = selectChatItems(items, { maxItems });
+  const summary = summarizeDigest(confirmed.map(item => ({ ...item, tools: item.tools_mentioned || item.toolsMentioned })));
+  const negative = confirmed.filter(item => item.sentiment === 'negative').length;
+
+  const facts = [
+    { title: 'Items', value: String(confirmed.length) },
+    { title: 'Publications', value: String(summary.uniqueSources) },
+    { title: 'Tools Mentioned', value: summary.toolCounts.map(({ tool, count }) => `${tool} (${count})`).join(', ') || 'None' },
+  ];
+  if (negative > 0) facts.push({ title: 'Negative', value: `⚠️ ${negative}` });
+  if (review > 0) facts.push({ title: 'Needs Review', value: String(review) });
+  if (lowRelevance > 0) facts.push({ title: 'Low Relevance', value: `${lowRelevance} excluded` });
+
+  const header = part => [
+    {
+      type: 'TextBlock',
+      text: `Coverage Digest - ${date}${part > 1 ? ` (continued, part ${part})` : ''}`,
+      size: 'Large',
+      weight: 'Bolder',
+      wrap: true,
+    },
+    ...(part === 1
+      ? [
+          {
+            type: 'TextBlock',
+            text: `${confirmed.length} item${confirmed.length === 1 ? '' : 's'} from ${summary.uniqueSources} publication${summary.uniqueSources === 1 ? '' : 's'}`,
+            isSubtle: true,
+            spacing: 'None',
+            wrap: true,
+          },
+          { type: 'FactSet', facts },
+        ]
+      : []),
+  ];
+
+  const blocks = shown.map(item => itemContainer(chatItem(item, { excerptChars })));
+  if (more > 0) {
+    blocks.push({
+      type: 'TextBlock',
+      text: `_${more} more item${more === 1 ? '' : 's'} not listed here._`,
+      isSubtle: true,
+      separator: true,
+      wrap: true,
+    });
+  }
+
+  const messages = [];
+  let body = header(1);
+  for (const block of blocks) {
+    const isFirst = body.length === header(messages.length + 1).length;
+    if (!isFirst && messageBytes([...body, block]) > maxBytes) {
+      messages.push(teamsMessage(body));
+      body = header(messages.length + 1);
+    }
+    body.push(block);
+  }
+  messages.push(teamsMessage(body));
+  return messages;
+}
+
+/**
+ * POST one message to a Teams incoming webhook. Throttling (HTTP 429) is
+ * retried with exponential backoff from config.teams.retry.baseDelayMs,
+ * or after Retry-After when Teams sends one, as Microsoft's connector
+ * docs ask. Other failures throw at once, so a card isn't posted twice.
+ *
+ * Teams' classic webhooks answer 200 with "1" in the body when the
+ * message is accepted, but also 200 with an error message in the body
+ * (e.g. "Microsoft Teams endpoint returned HTTP error 413 ..."); that is
+ * treated as the failure it describes. Workflow webhooks answer 202 with
+ * no body.
+ */
+export async function postTeamsMessage(url, message, {
+  client = clientFor('Teams'),
+  attempts = config.teams.retry.attempts,
+  baseDelayMs = config.teams.retry.baseDelayMs,
+  maxDelayMs = config.teams.retry.maxDelayMs,
+  sleep,
+} = {}) {
+  const body = JSON.stringify(message);
+  await retry(async () => {
+    const res = await client.fetch(url, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body });
+    const text = (await res.text()).trim();
+    const embedded = res.status === 200 ? text.match(/HTTP error (\d{3})/) : null;
+    const status = embedded ? parseInt(embedded[1], 10) : res.status;
+    if (status === 429) {
+      throw Object.assign(new Error('Teams webhook is throttling requests (HTTP 429)'), {
+        status,
+        retryAfterMs: parseRetryAfter(res.headers['retry-after']),
+      });
+    }
+    if (!res.ok) throw Object.assign(new Error(`Teams webhook returned HTTP ${res.status}`), { status: res.status });
+    if (res.status === 200 && text !== '1') {
+      throw Object.assign(new Error(`Teams webhook didn't accept the message: ${truncate(text || '(empty response)', 200)}`), { status });
+    }
+  }, { attempts, baseDelayMs, maxDelayMs, retryable: err => err.status === 429, ...(sleep ? { sleep } : {}) });
+}
+
+/**
+ * Render the digest (renderTeamsMessages) and post each message in
+ * order. Stops at the first message Teams doesn't accept.
+ *
+ * @returns {Promise<number>} the number of messages posted
+ */
+export async function publishTeamsDigest(items, { url = config.teams.webhookUrl, client, sleep, ...renderOptions } = {}) {
+  const messages = renderTeamsMessages(items, renderOptions);
+  for (const [i, message] of messages.entries()) {
+    try {
+      await postTeamsMessage(url, message, { client, sleep });
+    } catch (err) {
+      throw new Error(`${err.message} (message ${i + 1} of ${messages.length})`, { cause: err });
+    }
+  }
+  return messages.length;
+}
+
+function itemContainer({ title, url, source, date, tools, excerpt, negative }) {
+  const meta = [source, date && String(date).slice(0, 10), tools.join(', ')].filter(Boolean).join(' · ');
+  return {
+    type: 'Container',
+    separator: true,
+    items: [
+      { type: 'TextBlock', text: `${negative ? '⚠️ ' : ''}[${escapeMarkdown(title)}](${escapeLinkUrl(url)})`, weight: 'Bolder', wrap: true },
+      ...(meta ? [{ type: 'TextBlock', text: escapeMarkdown(meta), isSubtle: true, spacing: 'None', wrap: true }] : []),
+      ...(excerpt ? [{ type: 'TextBlock', text: escapeMarkdown(excerpt), wrap: true }] : []),
+    ],
+  };
+}
+
+function teamsMessage(body) {
+  return {
+    type: 'message',
+    attachments: [{
+      contentType: 'application/vnd.microsoft.card.adaptive',
+      contentUrl: null,
+      content: { $schema: CARD_SCHEMA, type: 'AdaptiveCard', version: CARD_VERSION, msteams: { width: 'Full' }, body },
+    }],
+  };
+}
+
+function messageBytes(body) {
+  return Buffer.byteLength(JSON.stringify(teamsMessage(body)));
+}
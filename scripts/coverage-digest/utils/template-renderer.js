@@ -1,12 +1,20 @@
 import { readFile } from 'fs/promises';
 import { join } from 'path';
 import { config } from '../config.js';
+import { createTranslator, translateTemplate, formatDate } from './i18n.js';
+import { clientFor } from './http-client.js';
+import { canonicalTools, detectTools, isDeprecated } from './tools.js';
+import { excerpt } from './excerpt.js';
+import { assertForeignMode, isForeign, languageName } from './language.js';
+import { assertTimeZone, calendarDaysBetween } from './timezone.js';
+import { formatDelta } from './summary.js';
 
 /**
  * Render the interactive marketing dashboard HTML.
  * Unlike the email, this is a full web page with CSS/JS interactivity.
  */
 export async function renderDashboard(items) {
+  items = withCanonicalTools(items);
   const templatePath = join(config.paths.templates, 'dashboard-template.html');
   let template = await readFile(templatePath, 'utf-8');
 
@@ -14,10 +22,7 @@ export async function renderDashboard(items) {
   const blogItems = items.filter(i => isBlog(i));
   const allTools = [...new Set(items.flatMap(i => i.toolsMentioned || []))];
 
-  const today = new Date();
-  const dateStr = today.toLocaleDateString('en-US', {
-    weekday: 'long', year: 'numeric', month: 'long', day: 'numeric',
-  });
+  const dateStr = formatDate(new Date(), 'en-US', { style: 'header' });
 
   // Quarterly data (fetches from praetorian.com)
   const q = await calculateQuarterlyProgress(items);
@@ -29,12 +34,11 @@ export async function renderDashboard(items) {
       toolCounts[t] = (toolCounts[t] || 0) + 1;
     }
   }
-  // Also count blog post tool mentions by title keywords
+  // Also count blog post tool mentions by title keywords; the blog is
+  // Praetorian's own, so a name alone is a mention
   for (const post of q.blogPosts) {
-    for (const tool of config.tools) {
-      if (post.title.toLowerCase().includes(tool.toLowerCase())) {
-        toolCounts[tool] = (toolCounts[tool] || 0) + 1;
-      }
+    for (const tool of detectTools(post.title, { assumeContext: true })) {
+      toolCounts[tool] = (toolCounts[tool] || 0) + 1;
     }
   }
   const toolData = Object.entries(toolCounts)
@@ -55,7 +59,8 @@ export async function renderDashboard(items) {
 
   // Action items
   const actionData = [];
-  const toolStr = allTools.slice(0, 3).join(', ');
+  const activeTools = allTools.filter(tool => !isDeprecated(tool));
+  const toolStr = activeTools.slice(0, 3).join(', ');
   if (mediaItems.length > 0) {
     actionData.push({ priority: 'high', text: `Post ${mediaItems[0].source} coverage to LinkedIn company page` });
     actionData.push({ priority: 'high', text: `Send reshare template to #amplification-crew (10-15 key voices)` });
@@ -63,7 +68,7 @@ export async function renderDashboard(items) {
   if (blogItems.length > 0) {
     actionData.push({ priority: 'medium', text: `Promote "${blogItems[0].title}" with 3 key takeaways on LinkedIn` });
   }
-  if (allTools.length > 0) {
+  if (activeTools.length > 0) {
     actionData.push({ priority: 'medium', text: `Brief sales team: ${toolStr} featured in publications` });
   }
   actionData.push({ priority: 'normal', text: `Update praetorian.com/news "In the News" page` });
@@ -90,71 +95,134 @@ export async function renderDashboard(items) {
 
 /**
  * Render the digest email HTML from template + items.
+ *
+ * Options:
+ *   locale       - message catalog + date format for the chrome (default: config.locale).
+ *                  Item titles and excerpts are rendered as-is.
+ *   excerptChars - excerpt length, cut at a sentence end (default: config.email.excerptChars)
+ *   timeZone     - IANA zone for the header date and "today"/"yesterday" (default: config.timeZone)
+ *   now          - the digest's date (default: the current time)
+ *   compact      - with no items, render only the "no new coverage" note, naming
+ *                  options.lastItem (see utils/empty-digest.js)
+ *   lastItem     - the most recent earlier item, for the compact note
+ *   trends       - comparison with the previous digest (compareSummaries in
+ *                  utils/summary.js); the Trends section is left out without it
+ *   maxItems     - items rendered in full, in the order given; the rest are listed
+ *                  by title under "Additional Coverage", 0 for no cap
+ *                  (default: config.email.maxItems). Counts include every item.
+ *   foreignItems - "section" lists items in a language other than the primary one
+ *                  under "Other Languages" instead of their usual section
+ *                  (default: config.language.foreign)
+ *   pendingTools - names of tools found announced on the blog but not yet
+ *                  configured (utils/tool-discovery.js); the playbook asks
+ *                  for each to be confirmed
+ *
+ * Items flagged needsReview (see utils/confidence.js) are listed under
+ * "Needs Review", each with a box to tick and its confidence, and left
+ * out of the counts and the playbook, which asks for them to be confirmed.
+ * Items flagged lowRelevance (see utils/relevance.js) are left out
+ * altogether, save for a note counting them.
  */
-export async function renderDigest(items) {
+export async function renderDigest(items, options = {}) {
+  const { lowRelevance, relevant } = splitRelevance(withCanonicalTools(items));
+  const { reviewItems, confirmed } = splitReview(relevant);
+  items = confirmed;
   const templatePath = join(config.paths.templates, 'email-template.html');
   const itemTemplatePath = join(config.paths.templates, 'email-item-template.html');
-
-  let template = await readFile(templatePath, 'utf-8');
-  const itemTemplate = await readFile(itemTemplatePath, 'utf-8');
-
-  // Categorize items into three buckets
-  const mediaItems = items.filter(i => isMedia(i));
-  const blogItems = items.filter(i => isBlog(i));
-  const manualItems = items.filter(i => isManualOrEvent(i));
+  const t = createTranslator(options.locale || config.locale);
+  const excerptChars = options.excerptChars ?? config.email.excerptChars;
+  const clock = { now: options.now || new Date(), timeZone: assertTimeZone(options.timeZone || config.timeZone) };
+  const { shown, more } = capItems(items, options.maxItems ?? config.email.maxItems);
+  const inOwnSection = languageSection(options.foreignItems);
+
+  let template = translateTemplate(await readFile(templatePath, 'utf-8'), t);
+  const itemTemplate = translateTemplate(await readFile(itemTemplatePath, 'utf-8'), t);
+
+  // Categorize items into three buckets, plus rewrites of earlier coverage
+  const updatedItems = items.filter(i => i.update);
+  const freshItems = items.filter(i => !i.update);
+  const mediaItems = freshItems.filter(i => isMedia(i));
+  const blogItems = freshItems.filter(i => isBlog(i));
+  const manualItems = freshItems.filter(i => isManualOrEvent(i));
 
   // Collect all unique tools mentioned
   const allTools = [...new Set(items.flatMap(i => i.toolsMentioned || []))];
 
   // Format date
-  const today = new Date();
-  const dateStr = today.toLocaleDateString('en-US', {
-    weekday: 'long',
-    year: 'numeric',
-    month: 'long',
-    day: 'numeric',
-  });
+  const dateStr = formatDate(clock.now, t.locale, { timeZone: clock.timeZone, style: 'header' });
 
   // Logo URL (hosted on GitHub raw)
   const logoUrl = config.logoUrl || 'https://raw.githubusercontent.com/LeoDPraetorian/praetorian-coverage-digest/main/scripts/coverage-digest/assets/logo-white.png';
 
   // Replace summary stats (replaceAll to handle multiple occurrences)
+  template = template.replaceAll('{{LOCALE}}', t.locale);
   template = template.replaceAll('{{DATE}}', dateStr);
+  template = template.replaceAll('{{NEED_ATTENTION}}', t.html('summary.needAttention', { count: items.length }));
   template = template.replaceAll('{{TOTAL_ITEMS}}', String(items.length));
   template = template.replaceAll('{{MEDIA_COUNT}}', String(mediaItems.length));
   template = template.replaceAll('{{TOOLS_MENTIONED}}', String(allTools.length));
   template = template.replaceAll('{{BLOG_COUNT}}', String(blogItems.length));
   template = template.replaceAll('{{ACTION_COUNT}}', String(items.length));
-  template = template.replaceAll('{{SOURCE_COUNT}}', String(config.rssFeeds.length + config.googleAlertsFeeds.length));
+  template = template.replaceAll('{{PLAYBOOK_SUBTITLE}}', t.html('playbook.subtitle', { count: items.length }));
+  template = template.replaceAll('{{FOOTER_MONITORING}}', t.html('footer.monitoring', {
+    count: config.rssFeeds.length + config.googleAlertsFeeds.length,
+  }));
   template = template.replaceAll('{{LOGO_URL}}', logoUrl);
 
   // Dashboard URL (for the CTA button in the email)
   const dashboardUrl = process.env.DASHBOARD_URL || 'https://praetorian-inc.github.io/people-operations/';
   template = template.replaceAll('{{DASHBOARD_URL}}', dashboardUrl);
 
-  // Quarterly progress tracker (fetches blog count from praetorian.com)
-  const quarterlyData = await calculateQuarterlyProgress(items);
-  template = template.replaceAll('{{Q_LABEL}}', quarterlyData.label);
-  template = template.replaceAll('{{BLOG_PROGRESS}}', String(quarterlyData.blogCount));
-  template = template.replaceAll('{{BLOG_GOAL}}', String(quarterlyData.blogGoal));
-  template = template.replaceAll('{{BLOG_PCT}}', String(quarterlyData.blogPct));
-  template = template.replaceAll('{{MEDIA_PROGRESS}}', String(quarterlyData.mediaCount));
-  template = template.replaceAll('{{MEDIA_GOAL}}', String(quarterlyData.mediaGoal));
-  template = template.replaceAll('{{MEDIA_PCT}}', String(quarterlyData.mediaPct));
-
-  // Show quarterly section if we have data
-  if (quarterlyData.blogCount > 0 || quarterlyData.mediaCount > 0) {
-    template = renderSection(template, 'IF_QUARTERLY', '');
+  // A compact digest is just the note: no stats, dashboard, or quarterly
+  const compact = options.compact && items.length === 0;
+  if (compact) {
+    template = removeSection(template, 'IF_STATS');
+  } else {
+    template = renderSection(template, 'IF_STATS', '');
+  }
+  // Trends against the previous digest, when there was one
+  if (options.trends && !compact) {
+    template = renderSection(template, 'IF_TRENDS', '');
+    template = template.replaceAll('{{TREND_ROWS}}', renderTrendRows(options.trends, t));
+    const { newSources } = options.trends;
+    if (newSources.length > 0) {
+      template = renderSection(template, 'IF_NEW_PUBLICATIONS', '');
+      template = template.replaceAll('{{NEW_PUBLICATIONS}}', escapeHtml(t('trends.newPublications', { names: newSources.join(', ') })));
+    } else {
+      template = removeSection(template, 'IF_NEW_PUBLICATIONS');
+    }
   } else {
-    template = removeSection(template, 'IF_QUARTERLY');
+    template = removeSection(template, 'IF_TRENDS');
+  }
+  template = template.replaceAll('{{EMPTY_NOTE}}', compact
+    ? escapeHtml(lastItemNote(options.lastItem, t, clock.timeZone))
+    : t.html('empty.subtitle'));
+
+  // Quarterly progress tracker (fetches blog count from praetorian.com)
+  if (!compact) {
+    const quarterlyData = await calculateQuarterlyProgress(items);
+    template = template.replaceAll('{{QUARTERLY_TITLE}}', t.html('quarterly.title', { label: quarterlyData.label }));
+    template = template.replaceAll('{{BLOG_PROGRESS}}', String(quarterlyData.blogCount));
+    template = template.replaceAll('{{BLOG_GOAL}}', String(quarterlyData.blogGoal));
+    template = template.replaceAll('{{BLOG_PCT}}', String(quarterlyData.blogPct));
+    template = template.replaceAll('{{MEDIA_PROGRESS}}', String(quarterlyData.mediaCount));
+    template = template.replaceAll('{{MEDIA_GOAL}}', String(quarterlyData.mediaGoal));
+    template = template.replaceAll('{{MEDIA_PCT}}', String(quarterlyData.mediaPct));
+
+    // Show quarterly section if we have data
+    if (quarterlyData.blogCount > 0 || quarterlyData.mediaCount > 0) {
+      template = renderSection(template, 'IF_QUARTERLY', '');
+    } else {
+      template = removeSection(template, 'IF_QUARTERLY');
+    }
   }
 
   // Generate smart action items
-  const actionItems = generateActionItems(mediaItems, blogItems, manualItems, allTools);
+  const actionItems = generateActionItems(mediaItems, blogItems, manualItems, allTools, reviewItems.length, options.pendingTools || [], t);
   template = template.replaceAll('{{ACTION_ITEMS}}', actionItems);
 
   // Generate LinkedIn drafts
-  const linkedInDrafts = generateLinkedInDrafts(items);
+  const linkedInDrafts = generateLinkedInDrafts(freshItems.filter(shown), t);
   template = template.replaceAll('{{LINKEDIN_DRAFTS}}', linkedInDrafts);
 
   // Render sections
@@ -162,6 +230,9 @@ export async function renderDigest(items) {
     template = removeSection(template, 'IF_MEDIA');
     template = removeSection(template, 'IF_BLOG');
     template = removeSection(template, 'IF_MANUAL');
+    template = removeSection(template, 'IF_OTHER_LANGUAGES');
+    template = removeSection(template, 'IF_UPDATED');
+    template = removeSection(template, 'IF_MORE');
     template = removeSection(template, 'IF_ACTION_NEEDED');
     template = removeSection(template, 'IF_LINKEDIN');
     template = renderSection(template, 'IF_EMPTY', '');
@@ -169,8 +240,9 @@ export async function renderDigest(items) {
     template = removeSection(template, 'IF_EMPTY');
 
     // External Media Coverage
-    if (mediaItems.length > 0) {
-      const renderedMedia = mediaItems.map(item => renderItem(itemTemplate, item)).join('');
+    const shownMedia = mediaItems.filter(i => shown(i) && !inOwnSection(i));
+    if (shownMedia.length > 0) {
+      const renderedMedia = shownMedia.map(item => renderItem(itemTemplate, item, t, excerptChars, clock)).join('');
       template = renderSection(template, 'IF_MEDIA', '');
       template = template.replaceAll('{{MEDIA_ITEMS}}', renderedMedia);
     } else {
@@ -178,8 +250,9 @@ export async function renderDigest(items) {
     }
 
     // Blog & Publications
-    if (blogItems.length > 0) {
-      const renderedBlog = blogItems.map(item => renderItem(itemTemplate, item)).join('');
+    const shownBlog = blogItems.filter(i => shown(i) && !inOwnSection(i));
+    if (shownBlog.length > 0) {
+      const renderedBlog = shownBlog.map(item => renderItem(itemTemplate, item, t, excerptChars, clock)).join('');
       template = renderSection(template, 'IF_BLOG', '');
       template = template.replaceAll('{{BLOG_ITEMS}}', renderedBlog);
     } else {
@@ -187,68 +260,319 @@ export async function renderDigest(items) {
     }
 
     // Events & Submissions
-    if (manualItems.length > 0) {
-      const renderedManual = manualItems.map(item => renderItem(itemTemplate, item)).join('');
+    const shownManual = manualItems.filter(i => shown(i) && !inOwnSection(i));
+    if (shownManual.length > 0) {
+      const renderedManual = shownManual.map(item => renderItem(itemTemplate, item, t, excerptChars, clock)).join('');
       template = renderSection(template, 'IF_MANUAL', '');
       template = template.replaceAll('{{MANUAL_ITEMS}}', renderedManual);
     } else {
       template = removeSection(template, 'IF_MANUAL');
     }
 
+    // Other Languages, with FOREIGN_LANGUAGE_ITEMS=section
+    const shownForeign = freshItems.filter(i => shown(i) && inOwnSection(i));
+    if (shownForeign.length > 0) {
+      const renderedForeign = shownForeign.map(item => renderItem(itemTemplate, item, t, excerptChars, clock)).join('');
+      template = renderSection(template, 'IF_OTHER_LANGUAGES', '');
+      template = template.replaceAll('{{OTHER_LANGUAGE_ITEMS}}', renderedForeign);
+    } else {
+      template = removeSection(template, 'IF_OTHER_LANGUAGES');
+    }
+
+    // Updated Coverage: articles we reported that have since been rewritten
+    const shownUpdated = updatedItems.filter(shown);
+    if (shownUpdated.length > 0) {
+      const renderedUpdated = shownUpdated.map(item => renderItem(itemTemplate, item, t, excerptChars, clock)).join('');
+      template = renderSection(template, 'IF_UPDATED', '');
+      template = template.replaceAll('{{UPDATED_ITEMS}}', renderedUpdated);
+    } else {
+      template = removeSection(template, 'IF_UPDATED');
+    }
+
+    // Additional Coverage: past maxItems, just the links
+    if (more.length > 0) {
+      template = renderSection(template, 'IF_MORE', '');
+      template = template.replaceAll('{{MORE_SUBTITLE}}', t.html('more.subtitle', { count: more.length }));
+      template = template.replaceAll('{{MORE_ITEMS}}', more.map(item => renderMoreRow(item, t, clock.timeZone)).join('\n                '));
+    } else {
+      template = removeSection(template, 'IF_MORE');
+    }
+
     // Action needed banner
     template = renderSection(template, 'IF_ACTION_NEEDED', '');
 
     // LinkedIn drafts section
-    if (items.length > 0) {
+    if (freshItems.some(shown)) {
       template = renderSection(template, 'IF_LINKEDIN', '');
     } else {
       template = removeSection(template, 'IF_LINKEDIN');
     }
   }
 
+  // Needs Review: items the monitors weren't sure are about Praetorian,
+  // listed even on a day with nothing else
+  if (reviewItems.length > 0) {
+    const renderedReview = reviewItems.map(item => renderItem(itemTemplate, item, t, excerptChars, clock)).join('');
+    template = renderSection(template, 'IF_NEEDS_REVIEW', '');
+    template = template.replaceAll('{{REVIEW_SUBTITLE}}', t.html('review.subtitle', { count: reviewItems.length }));
+    template = template.replaceAll('{{REVIEW_ITEMS}}', renderedReview);
+  } else {
+    template = removeSection(template, 'IF_NEEDS_REVIEW');
+  }
+
+  if (lowRelevance.length > 0) {
+    template = renderSection(template, 'IF_LOW_RELEVANCE', '');
+    template = template.replaceAll('{{LOW_RELEVANCE_NOTE}}', t.html('lowRelevance.note', { count: lowRelevance.length }));
+  } else {
+    template = removeSection(template, 'IF_LOW_RELEVANCE');
+  }
+
   return template;
 }
 
+/**
+ * Render the plain-text alternative to the digest email: the summary,
+ * then each section's items with title, source, date, tools, excerpt,
+ * and link. Takes the same options as renderDigest.
+ */
+export function renderDigestText(items, options = {}) {
+  const { lowRelevance, relevant } = splitRelevance(withCanonicalTools(items));
+  const { reviewItems, confirmed } = splitReview(relevant);
+  items = confirmed;
+  const t = createTranslator(options.locale || config.locale);
+  const excerptChars = options.excerptChars ?? config.email.excerptChars;
+  const timeZone = assertTimeZone(options.timeZone || config.timeZone);
+  const dateStr = formatDate(options.now || new Date(), t.locale, { timeZone, style: 'header' });
+  const allTools = [...new Set(items.flatMap(i => i.toolsMentioned || []))].sort();
+
+  // A review item's title gets a box to tick in place of the bullet, and
+  // negative coverage a warning sign
+  const itemLines = item => {
+    const meta = [item.source, estimated(item, formatDate(item.date, t.locale, { timeZone, style: 'item' }))];
+    if (item.embargoLifted) meta.push(t('item.embargoLifted'));
+    if (item.needsReview) meta.push(t('item.confidence', { percent: confidencePercent(item) }));
+    if (item.previouslyCovered) meta.push(t('item.previouslyCovered', { date: formatDate(item.previouslyCovered, t.locale, { timeZone, style: 'item' }) }));
+    if (isForeign(item)) meta.push(languageName(item.language, t.locale));
+    if (item.toolsMentioned?.length) meta.push(item.toolsMentioned.join(', '));
+    const lines = [
+      `${item.needsReview ? '[ ]' : '*'} ${item.sentiment === 'negative' ? '⚠️ ' : ''}${item.title}${item.paywalled ? ' 🔒' : ''}`,
+      `  ${meta.join(' · ')}`,
+    ];
+    if (item.update) lines.push(`  ${updateNote(item, t)}`);
+    if (item.alsoPublishedBy?.length) {
+      lines.push(`  ${t('item.alsoPublishedBy', { outlets: item.alsoPublishedBy.map(outlet => outlet.source).join(', ') })}`);
+    }
+    if (item.excerpt) lines.push(`  ${excerpt(item.excerpt, excerptChars)}`);
+    if (item.mentionContext) lines.push(`  ${t('item.context', { text: item.mentionContext })}`);
+    lines.push(`  ${item.url}`);
+    if (item.archiveUrl) lines.push(`  (${t('item.archive')}) ${item.archiveUrl}`);
+    if (item.hackerNews) lines.push(`  (${t('item.hackerNews', item.hackerNews)}) ${item.hackerNews.url}`);
+    lines.push('');
+    return lines;
+  };
+
+  const lines = [t('meta.title'), dateStr, ''];
+  if (items.length === 0 && options.compact) {
+    lines.push(`${t('empty.title')} ${lastItemNote(options.lastItem, t, timeZone)}`, '');
+  } else if (items.length === 0) {
+    lines.push(t('empty.title'), '');
+  } else {
+    lines.push(t('summary.needAttention', { count: items.length }));
+    lines.push(`${t('stats.toolsCited')}: ${allTools.join(', ') || '-'}`, '');
+
+    if (options.trends) {
+      const { items: total, tools, newSources } = options.trends;
+      const heading = `${t('trends.title')} (${t('trends.compared')})`;
+      lines.push(heading, '-'.repeat(heading.length));
+      lines.push(`${t('trends.items')}: ${total.count} (${formatDelta(total.delta, t('trends.new'))})`);
+      for (const { tool, count, delta } of tools) {
+        lines.push(`${tool}: ${count} (${formatDelta(delta, t('trends.new'))})`);
+      }
+      if (newSources.length > 0) lines.push(t('trends.newPublications', { names: newSources.join(', ') }));
+      lines.push('');
+    }
+
+    const { shown, more } = capItems(items, options.maxItems ?? config.email.maxItems);
+    const inOwnSection = languageSection(options.foreignItems);
+    const freshItems = items.filter(i => !i.update && shown(i));
+    const homeItems = freshItems.filter(i => !inOwnSection(i));
+    const sections = [
+      [t('section.media'), homeItems.filter(i => isMedia(i))],
+      [t('section.blog'), homeItems.filter(i => isBlog(i))],
+      [t('section.events'), homeItems.filter(i => isManualOrEvent(i))],
+      [t('section.otherLanguages'), freshItems.filter(inOwnSection)],
+      [t('section.updated'), items.filter(i => i.update && shown(i))],
+    ];
+    for (const [heading, sectionItems] of sections) {
+      if (sectionItems.length === 0) continue;
+      lines.push(heading, '='.repeat(heading.length), '');
+      for (const item of sectionItems) lines.push(...itemLines(item));
+    }
+    if (more.length > 0) {
+      const heading = t('section.more');
+      lines.push(heading, '='.repeat(heading.length), t('more.subtitle', { count: more.length }), '');
+      for (const item of more) {
+        const date = estimated(item, formatDate(item.date, t.locale, { timeZone, style: 'item' }));
+        lines.push(`* ${item.title} (${item.source} · ${date})`, `  ${item.url}`);
+      }
+      lines.push('');
+    }
+  }
+  if (reviewItems.length > 0) {
+    const heading = t('section.needsReview');
+    lines.push(heading, '='.repeat(heading.length), t('review.subtitle', { count: reviewItems.length }), '');
+    for (const item of reviewItems) lines.push(...itemLines(item));
+  }
+  if (lowRelevance.length > 0) lines.push(t('lowRelevance.note', { count: lowRelevance.length }), '');
+  lines.push('--', t('footer.generated'));
+  return lines.join('\n') + '\n';
+}
+
+// Whether an item goes under "Other Languages" rather than its usual section
+function languageSection(mode = config.language.foreign) {
+  return assertForeignMode(mode) === 'section' ? item => isForeign(item) : () => false;
+}
+
+// Items for Needs Review, and the rest
+function splitReview(items) {
+  return { reviewItems: items.filter(i => i.needsReview), confirmed: items.filter(i => !i.needsReview) };
+}
+
+// Items left out as low-relevance, and the rest
+function splitRelevance(items) {
+  return { lowRelevance: items.filter(i => i.lowRelevance), relevant: items.filter(i => !i.lowRelevance) };
+}
+
+// An item's confidence as a whole percentage
+function confidencePercent(item) {
+  return Math.round((item.confidence ?? 0) * 100);
+}
+
+/**
+ * Split items at `maxItems` (0 for no cap): `shown(item)` tests whether an
+ * item is among the first maxItems, rendered in full; `more` is the rest.
+ */
+function capItems(items, maxItems) {
+  const cap = maxItems > 0 ? maxItems : items.length;
+  const shownSet = new Set(items.slice(0, cap));
+  return { shown: item => shownSet.has(item), more: items.slice(cap) };
+}
+
+/**
+ * One row of the email's Additional Coverage list: linked title, source, date.
+ */
+function renderMoreRow(item, t, timeZone) {
+  const date = estimated(item, formatDate(item.date, t.locale, { timeZone, style: 'item' }));
+  return `<tr>
+                  <td style="padding:8px 0;border-bottom:1px solid #3A4044;font-size:13px;line-height:1.4;">
+                    <a href="${escapeHtml(item.url || '#')}" style="font-weight:600;color:#FFFFFF;text-decoration:none;">${escapeHtml(item.title)}</a>
+                    <span style="color:#535B61;">&nbsp;&middot;&nbsp;</span><span style="font-size:12px;color:#A0A4A8;">${escapeHtml(item.source)}</span>
+                    <span style="color:#535B61;">&nbsp;&middot;&nbsp;</span><span style="font-size:12px;color:#535B61;">${escapeHtml(date)}</span>
+                  </td>
+                </tr>`;
+}
+
+/**
+ * Rows of the email's Trends table: total items, then one per tool.
+ */
+function renderTrendRows({ items, tools }, t) {
+  const rows = [[t('trends.items'), items], ...tools.map(row => [row.tool, row])];
+  return rows.map(([label, { count, previous, delta }]) => {
+    const color = delta === null || delta > 0 ? '#11C3DB' : delta < 0 ? '#E63948' : '#A0A4A8';
+    return `<tr>
+                        <td style="padding:4px 0;color:#FFFFFF;">${escapeHtml(label)}</td>
+                        <td align="right" style="padding:4px 0;">${count}</td>
+                        <td align="right" style="padding:4px 0;">${previous}</td>
+                        <td align="right" style="padding:4px 0;font-weight:700;color:${color};">${escapeHtml(formatDelta(delta, t('trends.new')))}</td>
+                      </tr>`;
+  }).join('\n                      ');
+}
+
+/**
+ * The compact digest's pointer to the last coverage, e.g. 'The last item
+ * was "Title" (Source) on Feb 10, 2026.' Unescaped.
+ */
+function lastItemNote(lastItem, t, timeZone) {
+  if (!lastItem) return t('empty.noHistory');
+  return t('empty.lastItem', {
+    title: lastItem.title,
+    source: lastItem.source || '-',
+    date: formatDate(lastItem.date, t.locale, { timeZone, style: 'item' }),
+  });
+}
+
 /**
  * Generate smart, contextual action items based on coverage types.
  * Returns rendered HTML for the action items list.
  */
-function generateActionItems(mediaItems, blogItems, manualItems, allTools) {
+function generateActionItems(mediaItems, blogItems, manualItems, allTools, reviewCount, pendingTools, t) {
   const actions = [];
-  const toolStr = allTools.slice(0, 3).join(', ');
+  // Retired tools are still tagged on items but never suggested for promotion
+  const activeTools = allTools.filter(tool => !isDeprecated(tool));
+  const toolStr = activeTools.slice(0, 3).join(', ');
+
+  // Priority 0: items under Needs Review, confirmed before anything else
+  if (reviewCount > 0) {
+    actions.push({
+      priority: 'high',
+      emoji: '1',
+      text: t.html('action.review', { count: reviewCount }),
+    });
+  }
+  // Then new tools found on the blog, to be tracked from now on
+  for (const tool of pendingTools) {
+    actions.push({
+      priority: 'high',
+      emoji: String(actions.length + 1),
+      text: t.html('action.newTool', { tool: `<strong>${escapeHtml(tool)}</strong>` }),
+    });
+  }
 
   // Priority 1: Media coverage actions (highest value - third party validation)
   if (mediaItems.length > 0) {
     const topMedia = mediaItems[0]; // Most recent
     actions.push({
       priority: 'high',
-      emoji: '1',
-      text: `Post ${escapeHtml(topMedia.source)} coverage of <strong>${escapeHtml((topMedia.toolsMentioned || [])[0] || 'Praetorian')}</strong> to LinkedIn company page — third-party validation drives 3x more engagement than self-promotion`,
+      emoji: String(actions.length + 1),
+      text: t.html('action.postMedia', {
+        source: escapeHtml(topMedia.source),
+        tool: `<strong>${escapeHtml(firstActiveTool(topMedia) || 'Praetorian')}</strong>`,
+      }),
     });
     actions.push({
       priority: 'high',
-      emoji: '2',
-      text: `Send pre-written reshare template to <strong>#amplification-crew</strong> — employee reshares are the highest-ROI amplification action (10-15 key voices)`,
+      emoji: String(actions.length + 1),
+      text: t.html('action.reshare', { channel: '<strong>#amplification-crew</strong>' }),
     });
   }
 
   // Priority 2: Blog post actions
   if (blogItems.length > 0) {
     const topBlog = blogItems[0];
-    const blogTool = (topBlog.toolsMentioned || [])[0] || '';
+    const blogTool = firstActiveTool(topBlog);
     actions.push({
       priority: 'medium',
       emoji: String(actions.length + 1),
-      text: `Promote <strong>${escapeHtml(topBlog.title)}</strong> with 3 key takeaways on LinkedIn — ${blogTool ? `position ${escapeHtml(blogTool)} as the go-to solution in this space` : 'drive organic traffic to the blog'}`,
+      text: t.html('action.promoteBlog', {
+        title: `<strong>${escapeHtml(topBlog.title)}</strong>`,
+        angle: blogTool
+          ? t.html('action.promoteBlogTool', { tool: escapeHtml(blogTool) })
+          : t.html('action.promoteBlogDefault'),
+      }),
     });
   }
 
   // Priority 3: Sales enablement (when tools are mentioned)
-  if (allTools.length > 0) {
+  if (activeTools.length > 0) {
     actions.push({
       priority: 'medium',
       emoji: String(actions.length + 1),
-      text: `Brief sales team: <strong>${escapeHtml(toolStr)}</strong> ${mediaItems.length > 0 ? 'featured in ' + mediaItems.length + ' publications' : 'covered this week'} — add links to prospect outreach for social proof`,
+      text: t.html('action.briefSales', {
+        tools: `<strong>${escapeHtml(toolStr)}</strong>`,
+        context: mediaItems.length > 0
+          ? t.html('action.briefSalesFeatured', { count: mediaItems.length })
+          : t.html('action.briefSalesCovered'),
+      }),
     });
   }
 
@@ -256,7 +580,7 @@ function generateActionItems(mediaItems, blogItems, manualItems, allTools) {
   actions.push({
     priority: 'normal',
     emoji: String(actions.length + 1),
-    text: `Update <strong>praetorian.com/news</strong> "In the News" page with this week's coverage — keeps SEO fresh and gives prospects confidence`,
+    text: t.html('action.updateWebsite', { page: '<strong>praetorian.com/news</strong>' }),
   });
 
   // Cap at 5 items
@@ -282,7 +606,7 @@ function generateActionItems(mediaItems, blogItems, manualItems, allTools) {
  * Returns rendered HTML with copy-paste-ready posts optimized for
  * LinkedIn's algorithm: strong hook, value in middle, CTA at end.
  */
-function generateLinkedInDrafts(items) {
+function generateLinkedInDrafts(items, t) {
   if (items.length === 0) return '';
 
   return items.map((item, idx) => {
@@ -300,7 +624,9 @@ function generateLinkedInDrafts(items) {
 
     draft += `\n\n${hashtags}`;
 
-    const typeLabel = isBlog(item) ? '📝 BLOG' : isMedia(item) ? '📰 MEDIA' : '🎤 EVENT';
+    const typeLabel = isBlog(item)
+      ? `📝 ${t.html('linkedin.type.blog')}`
+      : isMedia(item) ? `📰 ${t.html('linkedin.type.media')}` : `🎤 ${t.html('linkedin.type.event')}`;
     const typeColor = isBlog(item) ? '#D4AF37' : isMedia(item) ? '#E63948' : '#11C3DB';
     const typeEmoji = isBlog(item) ? '💡' : isMedia(item) ? '🔒' : '🎯';
 
@@ -310,7 +636,7 @@ function generateLinkedInDrafts(items) {
           <table role="presentation" width="100%" cellpadding="0" cellspacing="0">
             <tr>
               <td>
-                <span style="display:inline-block;font-size:10px;font-weight:700;color:${typeColor};text-transform:uppercase;letter-spacing:1.5px;background-color:rgba(0,0,0,0.3);padding:4px 10px;border-radius:4px;">${typeEmoji} ${typeLabel} POST ${idx + 1}</span>
+                <span style="display:inline-block;font-size:10px;font-weight:700;color:${typeColor};text-transform:uppercase;letter-spacing:1.5px;background-color:rgba(0,0,0,0.3);padding:4px 10px;border-radius:4px;">${typeEmoji} ${t.html('linkedin.postLabel', { type: typeLabel, number: idx + 1 })}</span>
                 <div style="font-size:12px;color:#6B7280;margin-top:8px;margin-bottom:14px;font-style:italic;">${escapeHtml(item.title)}</div>
               </td>
             </tr>
@@ -323,10 +649,10 @@ function generateLinkedInDrafts(items) {
               <td style="padding-top:12px;">
                 <table role="presentation" cellpadding="0" cellspacing="0"><tr>
                   <td style="padding-right:16px;">
-                    <a href="${item.url || '#'}" style="display:inline-block;font-size:11px;font-weight:600;color:#11C3DB;text-decoration:none;padding:6px 12px;border:1px solid #11C3DB;border-radius:4px;">Open article &rarr;</a>
+                    <a href="${item.url || '#'}" style="display:inline-block;font-size:11px;font-weight:600;color:#11C3DB;text-decoration:none;padding:6px 12px;border:1px solid #11C3DB;border-radius:4px;">${t.html('linkedin.openArticle')}</a>
                   </td>
                   <td>
-                    <span style="font-size:10px;color:#535B61;">Ready to copy &amp; paste to LinkedIn</span>
+                    <span style="font-size:10px;color:#535B61;">${t.html('linkedin.readyToCopy')}</span>
                   </td>
                 </tr></table>
               </td>
@@ -540,31 +866,33 @@ function isManualOrEvent(item) {
   return type === 'manual' || type === 'event' || type === 'podcast';
 }
 
+// "~" before a date that's only when the item was found
+function estimated(item, date) {
+  return item.dateEstimated ? `~${date}` : date;
+}
+
 /**
  * Render a single item using the item template.
  */
-function renderItem(template, item) {
+function renderItem(template, item, t, excerptChars, { now, timeZone }) {
   let html = template;
 
-  const itemDate = new Date(item.date);
-  const now = new Date();
-  const daysAgo = Math.floor((now - itemDate) / (1000 * 60 * 60 * 24));
+  // Calendar days in the digest's zone, so "yesterday" means the local
+  // day before, not 24-48 hours ago
+  const daysAgo = calendarDaysBetween(item.date, now, timeZone);
 
   // Show relative time for recent items, absolute date for older
   let dateStr;
-  if (daysAgo === 0) {
-    dateStr = 'Today';
+  if (daysAgo <= 0) {
+    dateStr = t.html('date.today');
   } else if (daysAgo === 1) {
-    dateStr = 'Yesterday';
+    dateStr = t.html('date.yesterday');
   } else if (daysAgo <= 7) {
-    dateStr = `${daysAgo} days ago`;
+    dateStr = t.html('date.daysAgo', { count: daysAgo });
   } else {
-    dateStr = itemDate.toLocaleDateString('en-US', {
-      month: 'short',
-      day: 'numeric',
-      year: 'numeric',
-    });
+    dateStr = formatDate(item.date, t.locale, { timeZone, style: 'item' });
   }
+  dateStr = estimated(item, dateStr);
 
   // Determine accent color based on item type
   let accentColor;
@@ -577,27 +905,122 @@ function renderItem(template, item) {
   }
 
   html = html.replaceAll('{{ITEM_TITLE}}', escapeHtml(item.title));
-  html = html.replaceAll('{{ITEM_URL}}', item.url || '#');
+  html = html.replaceAll('{{ITEM_URL}}', escapeHtml(item.url || '#'));
   html = html.replaceAll('{{ITEM_SOURCE}}', escapeHtml(item.source));
   html = html.replaceAll('{{ITEM_DATE}}', dateStr);
   html = html.replaceAll('{{ITEM_ACCENT_COLOR}}', accentColor);
 
-  // Tools mentioned
+  // Embargo lifted marker
+  if (item.embargoLifted) {
+    html = renderSection(html, 'IF_EMBARGO_LIFTED', '');
+  } else {
+    html = removeSection(html, 'IF_EMBARGO_LIFTED');
+  }
+
+  // Negative coverage (utils/sentiment.js), marked ahead of the title
+  if (item.sentiment === 'negative') {
+    html = renderSection(html, 'IF_NEGATIVE', '');
+  } else {
+    html = removeSection(html, 'IF_NEGATIVE');
+  }
+
+  // Found by a monitor that wasn't sure it's about Praetorian: a box to
+  // tick, and the score
+  if (item.needsReview) {
+    html = renderSection(html, 'IF_REVIEW', '');
+    html = html.replaceAll('{{ITEM_CONFIDENCE}}', t.html('item.confidence', { percent: confidencePercent(item) }));
+  } else {
+    html = removeSection(html, 'IF_REVIEW');
+  }
+
+  // Sent in an earlier digest, and back after SEEN_SUPPRESS_DAYS or with
+  // --allow-resurface
+  if (item.previouslyCovered) {
+    const date = formatDate(item.previouslyCovered, t.locale, { timeZone, style: 'item' });
+    html = renderSection(html, 'IF_PREVIOUSLY_COVERED', '');
+    html = html.replaceAll('{{ITEM_PREVIOUSLY_COVERED}}', t.html('item.previouslyCovered', { date }));
+  } else {
+    html = removeSection(html, 'IF_PREVIOUSLY_COVERED');
+  }
+
+  // Tools mentioned, one tag each
   if (item.toolsMentioned && item.toolsMentioned.length > 0) {
     html = renderSection(html, 'IF_TOOLS', '');
-    html = html.replaceAll('{{ITEM_TOOLS}}', item.toolsMentioned.join(', '));
+    html = repeatSection(html, 'EACH_TOOL', item.toolsMentioned, (section, tool) =>
+      section.replaceAll('{{ITEM_TOOL}}', escapeHtml(tool))
+    );
   } else {
     html = removeSection(html, 'IF_TOOLS');
   }
 
+  // What changed, for rewrites of earlier coverage
+  if (item.update) {
+    html = renderSection(html, 'IF_UPDATE_NOTE', '');
+    html = html.replaceAll('{{ITEM_UPDATE_NOTE}}', escapeHtml(updateNote(item, t)));
+  } else {
+    html = removeSection(html, 'IF_UPDATE_NOTE');
+  }
+
+  // Other outlets that ran the same article
+  if (item.alsoPublishedBy?.length) {
+    const outlets = item.alsoPublishedBy
+      .map(outlet => `<a href="${escapeHtml(outlet.url || '#')}" style="color:#A0A4A8;">${escapeHtml(outlet.source)}</a>`)
+      .join(', ');
+    html = renderSection(html, 'IF_ALSO_PUBLISHED', '');
+    html = html.replaceAll('{{ITEM_ALSO_PUBLISHED}}', t.html('item.alsoPublishedBy', { outlets }));
+  } else {
+    html = removeSection(html, 'IF_ALSO_PUBLISHED');
+  }
+
+  // Language badge, for items not in the digest's primary language
+  if (isForeign(item)) {
+    html = renderSection(html, 'IF_LANGUAGE', '');
+    html = html.replaceAll('{{ITEM_LANGUAGE}}', escapeHtml(item.language.toUpperCase()));
+    html = html.replaceAll('{{ITEM_LANGUAGE_NAME}}', escapeHtml(languageName(item.language, t.locale)));
+  } else {
+    html = removeSection(html, 'IF_LANGUAGE');
+  }
+
+  // Padlock on articles behind a paywall
+  if (item.paywalled) {
+    html = renderSection(html, 'IF_PAYWALLED', '');
+  } else {
+    html = removeSection(html, 'IF_PAYWALLED');
+  }
+
+  // Wayback Machine copy, when the item has been archived
+  if (item.archiveUrl) {
+    html = renderSection(html, 'IF_ARCHIVE', '');
+    html = html.replaceAll('{{ITEM_ARCHIVE_URL}}', escapeHtml(item.archiveUrl));
+  } else {
+    html = removeSection(html, 'IF_ARCHIVE');
+  }
+
+  // Hacker News discussion, when the item has one
+  if (item.hackerNews) {
+    html = renderSection(html, 'IF_HACKER_NEWS', '');
+    html = html.replaceAll('{{ITEM_HN_URL}}', escapeHtml(item.hackerNews.url));
+    html = html.replaceAll('{{ITEM_HN_NOTE}}', escapeHtml(t('item.hackerNews', item.hackerNews)));
+  } else {
+    html = removeSection(html, 'IF_HACKER_NEWS');
+  }
+
   // Excerpt
   if (item.excerpt) {
     html = renderSection(html, 'IF_EXCERPT', '');
-    html = html.replaceAll('{{ITEM_EXCERPT}}', escapeHtml(item.excerpt));
+    html = html.replaceAll('{{ITEM_EXCERPT}}', escapeHtml(excerpt(item.excerpt, excerptChars)));
   } else {
     html = removeSection(html, 'IF_EXCERPT');
   }
 
+  // The sentence that mentions us, when the excerpt doesn't
+  if (item.mentionContext) {
+    html = renderSection(html, 'IF_CONTEXT', '');
+    html = html.replaceAll('{{ITEM_CONTEXT}}', t.html('item.context', { text: escapeHtml(item.mentionContext) }));
+  } else {
+    html = removeSection(html, 'IF_CONTEXT');
+  }
+
   return html;
 }
 
@@ -610,6 +1033,14 @@ function renderSection(html, sectionName, _placeholder) {
   return html;
 }
 
+/**
+ * Replace a section with one copy of its content per value.
+ */
+function repeatSection(html, sectionName, values, renderOne) {
+  const regex = new RegExp(`{{#${sectionName}}}\\n?([\\s\\S]*?){{/${sectionName}}}\\n?`, 'g');
+  return html.replace(regex, (_, section) => values.map(value => renderOne(section, value)).join(''));
+}
+
 /**
  * Remove a conditional section entirely (markers + content between them).
  */
@@ -644,8 +1075,9 @@ async function calculateQuarterlyProgress(items) {
       'https://www.praetorian.com/blog/feed/',
       'https://www.praetorian.com/blog/feed/?paged=2',
     ];
+    const blogClient = clientFor('Praetorian Blog');
     for (const feedUrl of feedUrls) {
-      const res = await fetch(feedUrl, { signal: AbortSignal.timeout(10000) });
+      const res = await blogClient.fetch(feedUrl);
       if (!res.ok) continue;
       const xml = await res.text();
       // Extract individual <item> blocks
@@ -702,6 +1134,36 @@ async function calculateQuarterlyProgress(items) {
   };
 }
 
+/**
+ * Copy items with tool names mapped to their current names, so renamed
+ * tools render (and count) as one.
+ */
+function withCanonicalTools(items) {
+  return items.map(item => ({ ...item, toolsMentioned: canonicalTools(item.toolsMentioned, item.date) }));
+}
+
+/**
+ * One line on what changed in an updated item, e.g. the old title.
+ */
+function updateNote(item, t) {
+  const { changed = [], previousTitle = '', previousUrl = '' } = item.update;
+  if (changed.includes('title') && previousTitle) {
+    return t(changed.includes('excerpt') ? 'update.titleAndExcerpt' : 'update.title', { title: previousTitle });
+  }
+  if (changed.includes('url') && previousUrl) {
+    return t('update.url', { url: previousUrl });
+  }
+  return t('update.excerpt');
+}
+
+/**
+ * First tool on an item that hasn't been retired, or ''.
+ */
+function firstActiveTool(item) {
+  return (item.toolsMentioned || []).find(tool => !isDeprecated(tool)) || '';
+}
+
+
 /**
  * Basic HTML escaping.
  */
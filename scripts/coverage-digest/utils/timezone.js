@@ -0,0 +1,109 @@
+import { config } from '../config.js';
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+const CALENDAR_DATE = /^\d{4}-\d{2}-\d{2}$/;
+const formatters = new Map();
+
+/**
+ * Throw unless `timeZone` is an IANA zone name this runtime knows.
+ */
+export function assertTimeZone(timeZone) {
+  try {
+    partsFormatter(timeZone);
+  } catch {
+    throw new Error(`Unknown time zone "${timeZone}" (expected an IANA name such as UTC or America/New_York)`);
+  }
+  return timeZone;
+}
+
+/**
+ * Whether a value is a bare YYYY-MM-DD date (as the tracker stores), which
+ * names a calendar day rather than an instant.
+ */
+export function isCalendarDate(value) {
+  return typeof value === 'string' && CALENDAR_DATE.test(value);
+}
+
+/**
+ * The calendar date and wall-clock time of an instant in a zone:
+ * { year, month (1-12), day, hour, minute, second }.
+ */
+export function zonedParts(date, timeZone = config.timeZone) {
+  const parts = {};
+  for (const { type, value } of partsFormatter(timeZone).formatToParts(new Date(date))) {
+    if (type !== 'literal') parts[type] = parseInt(value, 10);
+  }
+  // Some runtimes render midnight as hour 24
+  if (parts.hour === 24) parts.hour = 0;
+  return parts;
+}
+
+/**
+ * The instant `days` calendar days from `date` at the same wall-clock time
+ * in the zone. Unlike adding multiples of 24 hours, this stays on the
+ * same local time across a DST change, so "one day back" from 09:00 is
+ * always 09:00 the day before (23 or 25 hours on transition days). A wall
+ * time that falls in a spring-forward gap resolves to just after it.
+ */
+export function shiftDays(date, days, timeZone = config.timeZone) {
+  const p = zonedParts(date, timeZone);
+  const ms = new Date(date).getTime() % 1000;
+  const wall = Date.UTC(p.year, p.month - 1, p.day + days, p.hour, p.minute, p.second, ms);
+  return fromWallTime(wall, timeZone);
+}
+
+/**
+ * Midnight at the start of the calendar day `date` falls on in the zone.
+ */
+export function startOfDay(date, timeZone = config.timeZone) {
+  const p = zonedParts(date, timeZone);
+  return fromWallTime(Date.UTC(p.year, p.month - 1, p.day), timeZone);
+}
+
+/**
+ * Whole calendar days from `from` to `to` in the zone (0 on the same
+ * local day, 1 for "yesterday", regardless of the hours between them).
+ * A bare YYYY-MM-DD counts as that day in any zone. NaN if either date
+ * is invalid.
+ */
+export function calendarDaysBetween(from, to, timeZone = config.timeZone) {
+  return dayNumber(to, timeZone) - dayNumber(from, timeZone);
+}
+
+function dayNumber(date, timeZone) {
+  if (isCalendarDate(date)) return Date.parse(date) / DAY_MS;
+  if (Number.isNaN(new Date(date).getTime())) return NaN;
+  const p = zonedParts(date, timeZone);
+  return Date.UTC(p.year, p.month - 1, p.day) / DAY_MS;
+}
+
+// Zone offset (ms ahead of UTC) in effect at an instant
+function offsetAt(instant, timeZone) {
+  const p = zonedParts(instant, timeZone);
+  const wall = Date.UTC(p.year, p.month - 1, p.day, p.hour, p.minute, p.second);
+  return wall - Math.floor(instant / 1000) * 1000;
+}
+
+// The instant a wall-clock time (given as if it were UTC) happens in the zone
+function fromWallTime(wall, timeZone) {
+  const first = wall - offsetAt(wall, timeZone);
+  const offset = offsetAt(first, timeZone);
+  if (first + offset === wall) return new Date(first);
+  // Near a transition the first guess used the other side's offset
+  const second = wall - offset;
+  if (second + offsetAt(second, timeZone) === wall) return new Date(second);
+  // Neither works: the wall time is skipped by a spring-forward gap
+  return new Date(Math.max(first, second));
+}
+
+function partsFormatter(timeZone) {
+  if (!formatters.has(timeZone)) {
+    formatters.set(timeZone, new Intl.DateTimeFormat('en-US', {
+      timeZone,
+      hourCycle: 'h23',
+      year: 'numeric', month: 'numeric', day: 'numeric',
+      hour: 'numeric', minute: 'numeric', second: 'numeric',
+    }));
+  }
+  return formatters.get(timeZone);
+}
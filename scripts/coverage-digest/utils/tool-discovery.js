@@ -0,0 +1,229 @@
+import { mkdir, readFile, writeFile } from 'fs/promises';
+import { dirname } from 'path';
+import { config } from '../config.js';
+import { detectTools, formerNames, isPendingTool, setPendingTools } from './tools.js';
+import { mapSourceType } from './tracker.js';
+
+// Blog titles that announce a tool, the name in the first group
+const ANNOUNCEMENTS = [
+  // "Introducing Julius: LLM Fingerprinting", "Introducing Nosey Parker, ..."
+  /^introducing\s+(.+)$/i,
+  // "Julius: Open Source LLM Fingerprinting", "Gato-X: An Open-Source ..."
+  /^(.+?)\s*:\s*(?:(?:an?|the|our)\s+)?open[\s-]?sourc/i,
+];
+// Where a name ends: a colon, comma, bracket, a dash between words, or
+// the end of a sentence
+const NAME_END = /\s*(?:[:;,!?()[\]|]|\s[-–—]\s|[–—]|\.(?:\s|$))/;
+// Words in a name, at most
+const MAX_NAME_WORDS = 3;
+// Words that are never a tool's name, or part of it: articles, our own
+// name, and what a tool is rather than what it's called
+const STOPWORDS = new Set([
+  'a', 'an', 'the', 'our', 'new', 'and', 'for', 'with', 'to', 'of', 'in', 'on',
+  'praetorian', 'introducing', 'announcing',
+  'open', 'source', 'open-source', 'opensource', 'free',
+  'tool', 'tools', 'tooling', 'toolkit', 'framework', 'library', 'platform', 'project', 'utility',
+  'scanner', 'fuzzer', 'plugin', 'extension', 'cli', 'sdk', 'api', 'app',
+  'release', 'released', 'version', 'update', 'beta', 'alpha', 'preview',
+  'security', 'offensive', 'defensive', 'red', 'team', 'llm', 'ai',
+]);
+
+/**
+ * The tool a blog post's title announces ("Introducing Julius: ...",
+ * "Julius: Open Source ..."), or null. The name is the capitalized words
+ * after the announcement's leading articles and our own name ("Introducing
+ * Praetorian's Julius"), up to the first punctuation, lowercase word or
+ * stopword, three words at most. Quotes are dropped, and a possessive
+ * ends the name ("Introducing Julius's ..." is Julius).
+ *
+ * @param {string} title
+ * @param {Object} [options]
+ *   stopwords - words never in a name, besides the built-in ones
+ *               (default: config.toolDiscovery.stopwords)
+ * @returns {string|null}
+ */
+export function extractToolName(title, { stopwords = config.toolDiscovery.stopwords } = {}) {
+  const text = String(title || '').normalize('NFKC').replace(/[‘’]/g, "'").replace(/[“”]/g, '"').trim();
+  const stop = stopwordSet(stopwords);
+  for (const pattern of ANNOUNCEMENTS) {
+    const match = text.match(pattern);
+    if (!match) continue;
+    const words = match[1].split(NAME_END)[0].split(/\s+/).map(cleanWord).filter(({ word }) => word);
+    while (words.length > 0 && stop.has(words[0].word.toLowerCase())) words.shift();
+    const name = [];
+    for (const { word, possessive } of words) {
+      if (name.length === MAX_NAME_WORDS || stop.has(word.toLowerCase()) || !isNameWord(word)) break;
+      name.push(word);
+      if (possessive) break;
+    }
+    if (name.length > 0) return name.join(' ');
+  }
+  return null;
+}
+
+/**
+ * Whether a name is already a configured tool: in config.tools, a key of
+ * config.toolRules or config.toolRegistry, an alias or a former name, in
+ * any case, with or without spaces and hyphens. A name that starts with a
+ * configured one ("Brutus Credential Tester") counts as it.
+ */
+export function isConfiguredTool(name) {
+  const words = nameWords(name);
+  return configuredNames().some(known => {
+    const knownWords = nameWords(known);
+    return knownWords.every((word, i) => words[i] === word) || compactName(known) === compactName(name);
+  });
+}
+
+/**
+ * Open the list of pending tools: tools found announced on the Praetorian
+ * Blog that aren't configured yet, by name. The file sits beside the
+ * coverage tracker and is committed with it, so the list outlives the
+ * CI checkout. Returns { pendingTools, addPendingTool, removePendingTool,
+ * save }; only save() writes the file.
+ */
+export async function openPendingTools(path = config.paths.pendingTools) {
+  let entries = [];
+  try {
+    entries = JSON.parse(await readFile(path, 'utf-8'));
+  } catch (err) {
+    if (err.code !== 'ENOENT') throw err;
+  }
+  const byName = new Map(entries.map(entry => [entry.name.toLowerCase(), entry]));
+
+  return {
+    /** Pending tools ({ name, title, url, first_seen }), first found first */
+    pendingTools() {
+      return [...byName.values()].sort((a, b) => a.first_seen.localeCompare(b.first_seen) || a.name.localeCompare(b.name));
+    },
+
+    /**
+     * Note a tool found in a blog post (`item`) as pending. Returns false
+     * if the name (in any case) already is.
+     */
+    addPendingTool(name, item, now = new Date()) {
+      const key = name.toLowerCase();
+      if (byName.has(key)) return false;
+      byName.set(key, { name, title: item.title || '', url: item.url || '', first_seen: now.toISOString() });
+      return true;
+    },
+
+    /** Stop listing a tool as pending, once it is configured or dismissed */
+    removePendingTool(name) {
+      return byName.delete(name.toLowerCase());
+    },
+
+    async save() {
+      await mkdir(dirname(path), { recursive: true });
+      await writeFile(path, JSON.stringify(this.pendingTools(), null, 2) + '\n');
+    },
+  };
+}
+
+/**
+ * Find tools announced on the Praetorian Blog among `items` (see
+ * extractToolName) and keep the ones not configured as pending. Pending
+ * tools configured or dismissed (a stopword) since are taken off the list.
+ *
+ * @param {Array<Object>} items - discovered items; only the blog's are read
+ * @param {Object} store - the open pending tools (openPendingTools)
+ * @param {Object} [options] - now, and extractToolName's options
+ * @returns {{ found: Array<Object>, removed: Array<Object>, pending: Array<Object> }}
+ *   the tools newly pending, those taken off, and every pending tool
+ *   ({ name, title, url, first_seen })
+ */
+export function discoverTools(items, store, { now = new Date(), stopwords = config.toolDiscovery.stopwords } = {}) {
+  const stop = stopwordSet(stopwords);
+  const removed = store.pendingTools().filter(entry =>
+    isConfiguredTool(entry.name) || entry.name.split(/\s+/).some(word => stop.has(word.toLowerCase()))
+  );
+  for (const entry of removed) store.removePendingTool(entry.name);
+
+  const found = [];
+  for (const item of items) {
+    if (mapSourceType(item) !== 'blog') continue;
+    const name = extractToolName(item.title, { stopwords });
+    if (!name || isConfiguredTool(name)) continue;
+    if (store.addPendingTool(name, item, now)) {
+      found.push(store.pendingTools().find(entry => entry.name === name));
+    }
+  }
+  return { found, removed, pending: store.pendingTools() };
+}
+
+/**
+ * Tag items with the pending tools they mention, as a configured tool's
+ * mentions are (see utils/tools.js createToolDetector): by name, alongside
+ * "Praetorian", or anywhere in a post on our own blog. Returns how many
+ * items were tagged.
+ */
+export function tagPendingTools(items) {
+  let tagged = 0;
+  for (const item of items) {
+    const text = [item.title, item.excerpt, item.bodyText].filter(Boolean).join(' ');
+    const tools = detectTools(text, { assumeContext: mapSourceType(item) === 'blog' })
+      .filter(tool => isPendingTool(tool) && !(item.toolsMentioned || []).includes(tool));
+    if (tools.length === 0) continue;
+    item.toolsMentioned = [...(item.toolsMentioned || []), ...tools];
+    item.untagged = false;
+    tagged++;
+  }
+  return tagged;
+}
+
+/**
+ * Run tool discovery over a run's items against the pending tools
+ * (openPendingTools), saving them when the list changed. Nothing is saved
+ * with `save` false, for previews and --dry-run, or with DRY_RUN=true.
+ * With MATCH_PENDING_TOOLS, every pending name is matched from here on and
+ * items mentioning one are tagged.
+ *
+ * @returns {Promise<{ found, removed, pending, tagged }>} see discoverTools;
+ *   `tagged` is the number of items tagged with a pending tool
+ */
+export async function applyToolDiscovery(items, {
+  store = null,
+  save = true,
+  now = new Date(),
+  matchPending = config.toolDiscovery.matchPending,
+} = {}) {
+  const pending = store || await openPendingTools();
+  const result = discoverTools(items, pending, { now });
+  if (save && !config.dryRun && (result.found.length > 0 || result.removed.length > 0)) await pending.save();
+  let tagged = 0;
+  if (matchPending) {
+    setPendingTools(result.pending.map(entry => entry.name));
+    tagged = tagPendingTools(items);
+  }
+  return { ...result, tagged };
+}
+
+function configuredNames() {
+  const tools = [...new Set([...config.tools, ...Object.keys(config.toolRules), ...Object.keys(config.toolRegistry)])];
+  return tools.flatMap(tool => [tool, ...(config.toolRules[tool]?.aliases || []), ...formerNames(tool).map(entry => entry.name)]);
+}
+
+function stopwordSet(extra) {
+  return new Set([...STOPWORDS, ...extra.map(word => word.toLowerCase())]);
+}
+
+// A word of a title without quotes around it, and whether it was
+// possessive ("Julius's", "Julius'")
+function cleanWord(raw) {
+  const unquoted = raw.replace(/^["`*]+|["`*]+$/g, '').replace(/^'+/, '');
+  const possessive = /'s?$/i.test(unquoted);
+  return { word: unquoted.replace(/'s?$/i, '').replace(/["'`*]+$/g, ''), possessive };
+}
+
+// Capitalized somewhere ("Julius", "GoKart", "gRPCurl"), and not a number
+function isNameWord(word) {
+  return /\p{Lu}/u.test(word) && /\p{L}/u.test(word);
+}
+
+function nameWords(name) {
+  return name.trim().toLowerCase().split(/\s+/);
+}
+
+function compactName(name) {
+  return name.toLowerCase().replace(/[^\p{L}\p{N}]/gu, '');
+}
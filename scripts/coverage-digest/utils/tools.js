@@ -0,0 +1,298 @@
+import { config } from '../config.js';
+
+// Names this short match only as written or in capitals, since they are
+// also ordinary words ("Gato", not the Spanish "gato")
+const SHORT_NAME_CHARS = 6;
+// Where a name or term may start and end: not against a letter or digit
+// of a script written with spaces between words. Japanese and Chinese copy
+// sets a Latin name right against the next word ("Augustusは")
+const WORD_CHARACTER = '[\\p{Script=Latin}\\p{Script=Greek}\\p{Script=Cyrillic}\\p{N}]';
+const BOUNDARY_BEFORE = `(?<!${WORD_CHARACTER})`;
+const BOUNDARY_AFTER = `(?!${WORD_CHARACTER})`;
+
+let shared = null;
+// Names found announced on the blog and matched before they are
+// configured (see utils/tool-discovery.js)
+let pending = [];
+
+/**
+ * Return the current name for a tool: a tool's name or alias
+ * (config.toolRules aliases) gives the tool, and a former name (toolRules
+ * formerNames, toolRegistry renamed_from) the tool it was renamed to.
+ * Matching is case-insensitive; unknown names are returned unchanged.
+ *
+ * A former name another tool now goes by means the renamed tool only
+ * before the rename (its `until`), so `date`, the date of the item the
+ * name is stored on, decides; without one it means the tool going by it
+ * now. An undated former name always means the renamed tool.
+ *
+ * @param {string} name - Tool name as stored on an item
+ * @param {string|Date} [date] - the item's date
+ * @returns {string}
+ */
+export function canonicalTool(name, date = null) {
+  const key = nameKey(name);
+  const former = allTools().flatMap(tool => formerNames(tool)
+    .filter(entry => nameKey(entry.name) === key)
+    .map(entry => ({ tool, until: entry.until })));
+  const when = date ? new Date(date) : null;
+  const renamed = former.find(entry => !entry.until || (when && when < new Date(entry.until)));
+  if (renamed) return renamed.tool;
+  const current = currentNames().get(key);
+  if (current) return current;
+  return former.length > 0 ? former[0].tool : (name || '').trim();
+}
+
+/**
+ * Canonicalize and dedupe a list of tool names, keeping first-seen order.
+ * `date` is the date of the item they are stored on (see canonicalTool).
+ */
+export function canonicalTools(names, date = null) {
+  return [...new Set((names || []).map(name => canonicalTool(name, date)).filter(Boolean))];
+}
+
+/**
+ * A tool's former names, [{ name, until }]: config.toolRules formerNames,
+ * then config.toolRegistry renamed_from (undated, `until` null).
+ */
+export function formerNames(tool, { rules = config.toolRules, registry = config.toolRegistry } = {}) {
+  return [
+    ...(rules[tool]?.formerNames || []).map(entry => ({ name: entry.name, until: entry.until || null })),
+    ...(registry[tool]?.renamed_from || []).map(name => ({ name, until: null })),
+  ];
+}
+
+/**
+ * Whether a tool has been retired as of `now`.
+ */
+export function isDeprecated(tool, now = new Date()) {
+  const since = config.toolRegistry[canonicalTool(tool)]?.deprecated_since;
+  return Boolean(since) && new Date(since) <= now;
+}
+
+/**
+ * The tool detector for this run: config.tools, config.toolRules and
+ * config.toolContext, and any pending tools (setPendingTools).
+ */
+export function sharedToolDetector() {
+  if (!shared) shared = createToolDetector({ tools: [...config.tools, ...pending] });
+  return shared;
+}
+
+/**
+ * Match these not-yet-configured tool names from now on, as tools with no
+ * rule of their own (MATCH_PENDING_TOOLS).
+ */
+export function setPendingTools(names) {
+  pending = [...new Set(names)];
+  shared = null;
+}
+
+/**
+ * Whether a tool is matched only because it is pending (setPendingTools).
+ */
+export function isPendingTool(tool) {
+  return pending.includes(tool);
+}
+
+/**
+ * Create a detector of tool mentions from rules (see config.toolRules).
+ * A tool is mentioned when:
+ *
+ *   1. Its name, an alias, or a former name (formerNames, toolRegistry
+ *      renamed_from) no other tool now goes by appears as whole words:
+ *      "Brutus" in "Brutus's", not in "Brutuses". A name inside a longer
+ *      tool's name ("Gato" in "Gato-X") doesn't count. Names of up to six
+ *      characters, and any with caseSensitive, match only as written or
+ *      in capitals.
+ *   2. The same item also has one of `context` ("Praetorian") or of the
+ *      tool's own context terms ("brute force" for Brutus), as whole
+ *      words in any case; unless the rule has requireContext: false, for
+ *      a name nothing else goes by.
+ *
+ * Returns { detect(text, options), proximity(text, tool) }: detect is
+ * detectTools, and proximity the fewest words between one of the tool's
+ * names and one of its context terms (common or its own) in the text, or
+ * null where either is missing.
+ *
+ * @param {Object} [options] - default: config.tools, config.toolRegistry,
+ *   config.toolRules, config.toolContext
+ */
+export function createToolDetector({
+  tools = config.tools,
+  registry = config.toolRegistry,
+  rules = config.toolRules,
+  context = config.toolContext,
+} = {}) {
+  const common = context.map(termPattern);
+  const all = [...new Set([...tools, ...Object.keys(registry), ...Object.keys(rules)])];
+  const current = currentNames({ tools: all, rules });
+  const detectors = all.map(tool => {
+    const rule = rules[tool] || {};
+    // Text is read as of now, so a former name another tool goes by is that tool's
+    const former = formerNames(tool, { rules, registry })
+      .map(entry => entry.name)
+      .filter(name => (current.get(nameKey(name)) ?? tool) === tool);
+    const names = [tool, ...(rule.aliases || []), ...former];
+    return {
+      tool,
+      names: names.map(name => ({ name, pattern: namePattern(name, rule.caseSensitive ?? name.length <= SHORT_NAME_CHARS) })),
+      terms: [...common, ...(rule.context || []).map(termPattern)],
+      required: rule.requireContext !== false,
+    };
+  });
+  // Longest names first, so a shorter one inside a match is seen as such
+  const names = detectors
+    .flatMap(detector => detector.names.map(({ name, pattern }) => ({ detector, name, pattern })))
+    .sort((a, b) => b.name.length - a.name.length);
+
+  function detect(text, { assumeContext = false } = {}) {
+    const folded = foldText(text);
+    const spans = [];
+    const mentioned = new Set();
+    for (const { detector, pattern } of names) {
+      for (const match of folded.matchAll(pattern)) {
+        const start = match.index;
+        const end = start + match[0].length;
+        if (spans.some(span => span.detector !== detector && start >= span.start && end <= span.end)) continue;
+        spans.push({ detector, start, end });
+        mentioned.add(detector);
+      }
+    }
+    return canonicalTools(detectors
+      .filter(detector => mentioned.has(detector))
+      .filter(detector => assumeContext || !detector.required || detector.terms.some(pattern => folded.search(pattern) !== -1))
+      .map(detector => detector.tool));
+  }
+
+  function proximity(text, tool) {
+    const detector = detectors.find(candidate => candidate.tool === canonicalTool(tool));
+    if (!detector) return null;
+    const folded = foldText(text);
+    const spans = patterns => patterns.flatMap(pattern => [...folded.matchAll(pattern)])
+      .map(match => ({ start: match.index, end: match.index + match[0].length }));
+    const names = spans(detector.names.map(({ pattern }) => pattern));
+    const terms = spans(detector.terms);
+    let fewest = null;
+    for (const name of names) {
+      for (const term of terms) {
+        const [first, second] = name.start <= term.start ? [name, term] : [term, name];
+        const words = first.end >= second.start
+          ? 0
+          : folded.slice(first.end, second.start).split(/\s+/).filter(word => /[\p{L}\p{N}]/u.test(word)).length;
+        if (fewest === null || words < fewest) fewest = words;
+      }
+    }
+    return fewest;
+  }
+
+  return { detect, proximity };
+}
+
+/**
+ * Identify which tools an item mentions, with the run's detector (see
+ * createToolDetector). `text` should be all of the item there is (title,
+ * description, body), since the context that makes a name a mention can
+ * be anywhere in it. Former names count as mentions of the current name,
+ * and retired tools are still detected so incidental coverage is tagged.
+ * Tool names are proper nouns, so the text is searched as written
+ * whatever its language (see matchableText).
+ *
+ * @param {string} text
+ * @param {Object} [options]
+ *   assumeContext - the text is known to be about Praetorian's tools (its
+ *                   own blog), so no context term is needed
+ * @returns {string[]} Canonical tool names
+ */
+export function detectTools(text, options) {
+  return sharedToolDetector().detect(text, options);
+}
+
+/**
+ * Whether text names one of `terms` (default: config.toolContext, e.g.
+ * "Praetorian") as whole words, in any case.
+ */
+export function hasContextTerm(text, terms = config.toolContext) {
+  const folded = foldText(text);
+  return terms.some(term => folded.search(termPattern(term)) !== -1);
+}
+
+/**
+ * Text as names are matched in it: lowercased, with full-width letters
+ * (as Japanese copy sets Latin names) folded to ASCII, and soft hyphens
+ * and zero-width characters (which German and Japanese typesetting
+ * inserts) removed.
+ */
+export function matchableText(text) {
+  return foldText(text).toLowerCase();
+}
+
+// matchableText without the lowercasing, for names matched by case
+function foldText(text) {
+  return String(text || '')
+    .normalize('NFKC')
+    .replace(/[\u00AD\u200B-\u200D\u2060\uFEFF]/g, '');
+}
+
+// A tool name as whole words: as written or in capitals when
+// `caseSensitive`, else in any case
+function namePattern(name, caseSensitive) {
+  const forms = caseSensitive ? [...new Set([name, name.toUpperCase()])] : [name];
+  return new RegExp(`${BOUNDARY_BEFORE}(?:${forms.map(wordsPattern).join('|')})${BOUNDARY_AFTER}`, caseSensitive ? 'gu' : 'giu');
+}
+
+// A context term as whole words, in any case
+function termPattern(term) {
+  return new RegExp(`${BOUNDARY_BEFORE}${wordsPattern(term)}${BOUNDARY_AFTER}`, 'giu');
+}
+
+// Words separated by any run of whitespace, otherwise literal
+function wordsPattern(text) {
+  return foldText(text).trim().split(/\s+/).map(word => word.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')).join('\\s+');
+}
+
+/**
+ * Search terms to actively query, without those for retired tools.
+ */
+export function activeSearchTerms(now = new Date()) {
+  const retired = Object.keys(config.toolRegistry).filter(tool => isDeprecated(tool, now));
+  if (retired.length === 0) return config.searchTerms;
+
+  const retiredNames = retired.flatMap(tool => [tool, ...formerNames(tool).map(entry => entry.name)]);
+  return config.searchTerms.filter(term => !retiredNames.some(name => termTargetsTool(term, name)));
+}
+
+// Every tool named in the config
+function allTools() {
+  return [...new Set([...config.tools, ...Object.keys(config.toolRules), ...Object.keys(config.toolRegistry)])];
+}
+
+// Tools by the names and aliases they go by now, keyed by nameKey
+function currentNames({ tools = allTools(), rules = config.toolRules } = {}) {
+  const names = new Map();
+  for (const tool of tools) {
+    for (const name of [tool, ...(rules[tool]?.aliases || [])]) {
+      if (!names.has(nameKey(name))) names.set(nameKey(name), tool);
+    }
+  }
+  return names;
+}
+
+// A name as compared: lowercased, runs of whitespace as one space
+function nameKey(name) {
+  return (name || '').trim().toLowerCase().replace(/\s+/g, ' ');
+}
+
+/**
+ * A search term targets a tool if it contains the tool's name as whole
+ * words ("nosey parker secret") or is the tool's name run together
+ * ("noseyparker"). "gato-x" does not target "Gato".
+ */
+function termTargetsTool(term, name) {
+  const termWords = term.toLowerCase().split(/\s+/);
+  const nameWords = name.toLowerCase().split(/\s+/);
+  for (let i = 0; i + nameWords.length <= termWords.length; i++) {
+    if (nameWords.every((word, j) => termWords[i + j] === word)) return true;
+  }
+  return term.toLowerCase().replace(/\s+/g, '') === name.toLowerCase().replace(/\s+/g, '');
+}
@@ -0,0 +1,428 @@
+import { createHash } from 'crypto';
+import { readFile, writeFile } from 'fs/promises';
+import { config } from '../config.js';
+import { cleanUrl } from './canonical-url.js';
+import { itemLanguage } from './language.js';
+import { normalizePublisher } from './publishers.js';
+import { shiftDays, startOfDay } from './timezone.js';
+
+// Query parameters that only track clicks; ignored when hashing content
+const TRACKING_PARAM = /^(utm_\w+|fbclid|gclid|dclid|msclkid|mc_cid|mc_eid|igshid|_hsenc|_hsmi|ref_src)$/i;
+// Characters of article text the article hash covers
+const ARTICLE_HASH_BODY_CHARS = 500;
+
+/**
+ * Load coverage tracker from JSON file.
+ */
+export async function loadTracker(path) {
+  try {
+    const raw = await readFile(path, 'utf-8');
+    return JSON.parse(raw);
+  } catch (err) {
+    if (err.code === 'ENOENT') {
+      console.log('No existing tracker found - starting fresh');
+      return [];
+    }
+    throw err;
+  }
+}
+
+/**
+ * Save coverage tracker back to JSON file.
+ */
+export async function saveTracker(path, tracker) {
+  await writeFile(path, JSON.stringify(tracker, null, 2) + '\n');
+}
+
+/**
+ * Find an existing tracker item that a discovered item duplicates
+ * (same normalized URL, including the URLs of syndicated copies and the
+ * URLs a moved article had before, or exact title). Returns null if none.
+ */
+export function findDuplicate(tracker, item) {
+  const url = normalizeUrl(item.url);
+  const title = (item.title || '').toLowerCase().trim();
+  return tracker.find(existing =>
+    (url && trackedUrls(existing).includes(url)) ||
+    existing.title.toLowerCase().trim() === title
+  ) || null;
+}
+
+/**
+ * Merge newly discovered items into the tracker (dedup by URL).
+ * Returns count of genuinely new items added.
+ *
+ * Syndicated copies folded into an item (`alsoPublishedBy`, see
+ * utils/syndication.js) are stored with it, and their URLs count as
+ * tracked from then on; copies found of an item already tracked are added
+ * to it.
+ *
+ * Pass `{ absorb: true }` to store items as already-seen (status "archived")
+ * so they never reach a digest, e.g. the history of a bootstrapped feed.
+ */
+export function mergeIntoTracker(tracker, discovered, options = {}) {
+  const byUrl = new Map(tracker.flatMap(item => trackedUrls(item).map(url => [url, item])));
+  const existingTitles = new Set(tracker.map(item => item.title.toLowerCase().trim()));
+  let added = 0;
+
+  for (const item of discovered) {
+    const url = normalizeUrl(item.url);
+    const title = item.title.toLowerCase().trim();
+
+    // Skip if we already have this URL or exact title
+    if (byUrl.has(url) || existingTitles.has(title)) {
+      const existing = byUrl.get(url);
+      if (existing && item.alsoPublishedBy?.length) {
+        existing.also_published_by = mergeOutlets(existing, item.alsoPublishedBy);
+        for (const outlet of item.alsoPublishedBy) byUrl.set(normalizeUrl(outlet.url), existing);
+      }
+      continue;
+    }
+
+    // Generate a new tracker ID
+    const nextId = tracker.length + 1;
+    const paddedId = String(nextId).padStart(3, '0');
+
+    const source = normalizePublisher(item.source, item.url);
+    const sourceOriginal = item.sourceOriginal || item.source;
+    const embargo = item.embargoUntil ? { embargo_until: new Date(item.embargoUntil).toISOString() } : {};
+    const language = item.language || itemLanguage(item);
+
+    tracker.push({
+      id: `cov-${paddedId}`,
+      date: item.date ? item.date.split('T')[0] : new Date().toISOString().split('T')[0],
+      source,
+      ...(source !== sourceOriginal ? { source_original: sourceOriginal } : {}),
+      source_type: mapSourceType(item),
+      title: item.title,
+      url: item.url,
+      // The link as the source gave it, before canonicalization
+      ...(item.originalUrl && item.originalUrl !== item.url ? { url_original: item.originalUrl } : {}),
+      tools_mentioned: item.toolsMentioned || [],
+      excerpt: item.excerpt || '',
+      // The article's sentence naming us, when its page was read
+      ...(item.mentionContext ? { mention_context: item.mentionContext } : {}),
+      content_hash: contentHash(item),
+      // Knows the article again if it moves to a new URL
+      ...(articleHash(item) ? { article_hash: articleHash(item) } : {}),
+      ...(language ? { language } : {}),
+      ...(item.hackerNews ? { hacker_news: item.hackerNews } : {}),
+      // Syndicated copies of the same article elsewhere
+      ...(item.alsoPublishedBy?.length ? { also_published_by: item.alsoPublishedBy } : {}),
+      // robots.txt kept us from the page: title and URL only
+      ...(item.unfetched ? { unfetched: true } : {}),
+      ...(item.paywalled ? { paywalled: true } : {}),
+      // Mention confidence, 0-1 (utils/confidence.js), and whether it
+      // goes under Needs Review
+      ...(item.confidence !== undefined ? { confidence: item.confidence } : {}),
+      ...(item.needsReview ? { needs_review: true } : {}),
+      // Relevance, 0-1 with its signals (utils/relevance.js), and whether
+      // it's left out of the digest's list
+      ...(item.relevance ? { relevance: item.relevance } : {}),
+      ...(item.lowRelevance ? { low_relevance: true } : {}),
+      // No publish date found: `date` is when the item was found
+      ...(item.dateEstimated || item.undated ? { date_estimated: true } : {}),
+      status: options.absorb ? 'archived' : isEmbargoed(embargo) ? 'embargoed' : 'new',
+      ...embargo,
+      amplification: {
+        linkedin_post: null,
+        slack_message: null,
+        employee_shares: [],
+        added_to_website: false,
+      },
+      discovered_by: options.absorb
+        ? 'bootstrap'
+        : item.discoveredBy || (item.sourceType === 'rss' ? 'rss-monitor' : 'manual'),
+      discovered_at: new Date().toISOString(),
+    });
+
+    const stored = tracker[tracker.length - 1];
+    for (const trackedUrl of trackedUrls(stored)) byUrl.set(trackedUrl, stored);
+    existingTitles.add(title);
+    added++;
+  }
+
+  return added;
+}
+
+// A tracked item's URL, those it moved from, and those of its syndicated
+// copies, normalized
+function trackedUrls(item) {
+  return [item.url, ...(item.previous_urls || []), ...(item.also_published_by || []).map(outlet => outlet.url)].map(normalizeUrl);
+}
+
+// A tracked item's syndicated copies with `outlets` added, once per URL
+function mergeOutlets(item, outlets) {
+  const known = new Set(trackedUrls(item));
+  const merged = [...(item.also_published_by || [])];
+  for (const outlet of outlets) {
+    const url = normalizeUrl(outlet.url);
+    if (known.has(url)) continue;
+    known.add(url);
+    merged.push(outlet);
+  }
+  return merged;
+}
+
+/**
+ * Compare rediscovered items with what the tracker stored, by URL. When
+ * the content hash changed on an item that already went out in a digest,
+ * its title and excerpt are refreshed and it goes back to "new" with an
+ * `update` record ({ detected_at, changed, previous_title }) so the next
+ * digest lists it under Updated Coverage. Items that haven't been sent
+ * yet are refreshed silently, and items stored before hashing existed
+ * just take the rediscovered hash as their baseline.
+ *
+ * Returns the number of items flagged as updated.
+ */
+export function refreshTrackedItems(tracker, discovered, now = new Date()) {
+  const byUrl = new Map(tracker.map(item => [normalizeUrl(item.url), item]));
+  let updated = 0;
+
+  for (const found of discovered) {
+    const item = byUrl.get(normalizeUrl(found.url));
+    if (!item) continue;
+    if (found.hackerNews) item.hacker_news = found.hackerNews;
+
+    const hash = contentHash(found);
+    if (!item.content_hash) item.content_hash = hash;
+    if (item.content_hash === hash) continue;
+
+    const changed = ['title', 'excerpt'].filter(field => normalizeContent(found[field]) !== normalizeContent(item[field]));
+    if (changed.length > 0 && item.last_sent_at && item.status !== 'embargoed') {
+      // A second rewrite before the update goes out keeps the title readers saw
+      const pending = isPendingUpdate(item) ? item.update : null;
+      const previousTitle = pending ? pending.previous_title : changed.includes('title') ? item.title : undefined;
+      item.update = {
+        detected_at: now.toISOString(),
+        changed: [...new Set([...(pending?.changed || []), ...changed])],
+        ...(previousTitle !== undefined ? { previous_title: previousTitle } : {}),
+      };
+      item.status = 'new';
+      if (!pending) updated++;
+    }
+    item.title = found.title;
+    item.excerpt = found.excerpt || '';
+    item.content_hash = hash;
+  }
+
+  return updated;
+}
+
+/**
+ * Follow tracked articles that moved to a new URL (a slug changed after
+ * publishing): a discovered item at a URL we don't track, whose article
+ * hash (see articleHash) is a tracked item's, is that item. Its URL is
+ * updated, and the old one kept in `previous_urls` so it still counts as
+ * tracked; no new item is added. Run before refreshTrackedItems(), which
+ * then finds the item at its new URL.
+ *
+ * With `note` (NOTE_MOVED_URLS), a move of an item already sent puts it
+ * back in the digest under Updated Coverage, with `update.changed`
+ * including "url" and `update.previous_url`.
+ *
+ * Returns the number of items moved.
+ */
+export function relocateMovedItems(tracker, discovered, now = new Date(), { note = config.noteMovedUrls } = {}) {
+  const tracked = new Set(tracker.flatMap(trackedUrls));
+  const byHash = new Map(tracker.filter(item => item.article_hash).map(item => [item.article_hash, item]));
+  let moved = 0;
+
+  for (const found of discovered) {
+    const url = normalizeUrl(found.url);
+    if (!url || tracked.has(url)) continue;
+    const hash = articleHash(found);
+    const item = hash && byHash.get(hash);
+    if (!item) continue;
+
+    const previousUrl = item.url;
+    item.previous_urls = [...new Set([...(item.previous_urls || []), previousUrl])];
+    item.url = found.url;
+    tracked.add(url);
+    moved++;
+
+    if (note && item.last_sent_at && item.status !== 'embargoed') {
+      const pending = isPendingUpdate(item) ? item.update : null;
+      item.update = {
+        ...pending,
+        detected_at: now.toISOString(),
+        changed: [...new Set([...(pending?.changed || []), 'url'])],
+        // Readers saw the URL from before any earlier move
+        previous_url: pending?.previous_url || previousUrl,
+      };
+      item.status = 'new';
+    }
+  }
+
+  return moved;
+}
+
+/**
+ * Whether an item has an update that hasn't gone out in a digest yet.
+ */
+export function isPendingUpdate(item) {
+  return Boolean(item.update) && !(item.last_sent_at && new Date(item.last_sent_at) > new Date(item.update.detected_at));
+}
+
+/**
+ * Fingerprint of an item's title and excerpt. Whitespace and tracking
+ * parameters on embedded links are normalized first, so only real edits
+ * change it.
+ */
+export function contentHash(item) {
+  const text = `${normalizeContent(item.title)}\n${normalizeContent(item.excerpt)}`;
+  return createHash('sha256').update(text).digest('hex').slice(0, 16);
+}
+
+/**
+ * Fingerprint of an article's text, to know it again at another URL: its
+ * title and the first 500 characters of its body text (`bodyText`, read
+ * from its page by enrichItems in monitors/html-article.js), each
+ * NFC-normalized, lowercased and with whitespace runs collapsed to one
+ * space and trimmed; the body is cut after normalizing, by code point.
+ * null for an item without body text, since a title alone says too little.
+ *
+ * Stored hashes are compared with new ones on later runs, so these rules
+ * must not change: any drift makes every tracked article look new.
+ * test/article-hash.test.js pins them with known URL and hash pairs.
+ */
+export function articleHash(item) {
+  if (!item.bodyText) return null;
+  const fold = text => String(text || '').normalize('NFC').toLowerCase().replace(/\s+/g, ' ').trim();
+  const body = Array.from(fold(item.bodyText)).slice(0, ARTICLE_HASH_BODY_CHARS).join('');
+  return createHash('sha256').update(`${fold(item.title)}\n${body}`).digest('hex').slice(0, 16);
+}
+
+function normalizeContent(text) {
+  return String(text || '')
+    .normalize('NFC')
+    .replace(/https?:\/\/[^\s"'<>)\]]+/g, stripTrackingParams)
+    .replace(/\s+/g, ' ')
+    .trim();
+}
+
+function stripTrackingParams(link) {
+  let url;
+  try {
+    url = new URL(link);
+  } catch {
+    return link;
+  }
+  for (const key of [...url.searchParams.keys()]) {
+    if (TRACKING_PARAM.test(key)) url.searchParams.delete(key);
+  }
+  return url.toString();
+}
+
+/**
+ * Split discovered items into those young enough for the digest and those
+ * published before the cutoff: the earlier of the window start and the
+ * start of the day maxAgeDays before now (when the item is first seen),
+ * counted in calendar days in the digest's time zone. An item with
+ * `extraLookbackDays` (from a source whose listings lag, like podcasts)
+ * gets that many more days.
+ */
+export function splitByAge(items, windowStart, maxAgeDays, now = new Date(), timeZone = config.timeZone) {
+  const ageLimit = startOfDay(shiftDays(now, -maxAgeDays, timeZone), timeZone);
+  const cutoff = ageLimit < windowStart ? ageLimit : windowStart;
+
+  const fresh = [];
+  const historical = [];
+  for (const item of items) {
+    // Embargoed items count from their release, not their original date
+    const published = new Date(item.embargoUntil || item.date || now);
+    const itemCutoff = item.extraLookbackDays ? shiftDays(cutoff, -item.extraLookbackDays, timeZone) : cutoff;
+    (published < itemCutoff ? historical : fresh).push(item);
+  }
+  return { fresh, historical };
+}
+
+/**
+ * Check whether an item is still under embargo. Embargoed items are stored
+ * with status "embargoed" so every path that selects status "new" (digest,
+ * dashboard, GitHub issue, exports) holds them automatically.
+ */
+export function isEmbargoed(item, now = new Date()) {
+  return Boolean(item.embargo_until) && new Date(item.embargo_until) > now;
+}
+
+/**
+ * Move embargoed items whose embargo has passed back to "new" so the next
+ * digest includes them. Returns the number of items released.
+ */
+export function liftExpiredEmbargoes(tracker, now = new Date()) {
+  let count = 0;
+  for (const item of tracker) {
+    if (item.status === 'embargoed' && !isEmbargoed(item, now)) {
+      item.status = 'new';
+      item.embargo_lifted_at = now.toISOString();
+      count++;
+    }
+  }
+  return count;
+}
+
+/**
+ * Record that an item was amplified on `channels` (linkedin, slack,
+ * website), moving it to "queued", or "amplified" once all three are
+ * done. An embargoed item is refused: its status is what holds it out of
+ * every output, so amplifying it would publish it early. Returns the
+ * channels marked.
+ */
+export function markAmplified(item, channels, now = new Date()) {
+  if (item.status === 'embargoed') {
+    throw new Error(`${item.id} is embargoed until ${item.embargo_until}; amplify it once the embargo lifts`);
+  }
+  const amp = item.amplification;
+  const marked = [];
+  if (channels.linkedin) {
+    amp.linkedin_post = now.toISOString();
+    marked.push('linkedin');
+  }
+  if (channels.slack) {
+    amp.slack_message = now.toISOString();
+    marked.push('slack');
+  }
+  if (channels.website) {
+    amp.added_to_website = true;
+    marked.push('website');
+  }
+
+  if (amp.linkedin_post && amp.slack_message && amp.added_to_website) {
+    item.status = 'amplified';
+  } else if (amp.linkedin_post || amp.slack_message || amp.added_to_website) {
+    item.status = 'queued';
+  }
+  return marked;
+}
+
+/**
+ * Normalize a URL for dedup comparison: cleaned of tracking parameters
+ * and AMP wrappers (cleanUrl), then compared without scheme or case.
+ */
+export function normalizeUrl(url) {
+  if (!url) return '';
+  return cleanUrl(url).toLowerCase().replace(/\/+$/, '').replace(/^https?:\/\//, '');
+}
+
+/**
+ * Map discovered item to tracker source_type. A type given with a
+ * submission (manual, webhook or CLI) is kept; feed items are media.
+ */
+export function mapSourceType(item) {
+  const source = (item.source || '').toLowerCase();
+  if (source.includes('praetorian blog') || source.includes('praetorian.com/blog')) return 'blog';
+  if (['manual', 'event', 'blog', 'podcast', 'social'].includes(item.sourceType)) return item.sourceType;
+  return 'media';
+}
+
+/**
+ * Count items by status.
+ */
+export function countByStatus(tracker) {
+  const counts = {};
+  for (const item of tracker) {
+    counts[item.status] = (counts[item.status] || 0) + 1;
+  }
+  return counts;
+}
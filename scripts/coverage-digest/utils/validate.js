@@ -0,0 +1,124 @@
+import { config } from '../config.js';
+import { normalizeUrl } from './tracker.js';
+
+// Anything dated before this is a parsing error, not old coverage
+const EARLIEST_DATE = new Date('2000-01-01T00:00:00Z');
+// Allow for feeds that publish in a timezone ahead of ours
+const FUTURE_SKEW_MS = 24 * 60 * 60 * 1000;
+
+/**
+ * Check a digest-format item against the invariants the renderer relies on.
+ * Returns a list of violations (empty when the item is valid).
+ */
+export function validateItem(item, now = new Date()) {
+  const errors = [];
+
+  if (typeof item.title !== 'string' || !item.title.trim()) {
+    errors.push('title is empty');
+  }
+
+  if (!isAbsoluteUrl(item.url)) {
+    errors.push(`url is not an absolute http(s) URL (${JSON.stringify(item.url ?? null)})`);
+  }
+
+  const date = new Date(item.date);
+  if (!item.date || isNaN(date)) {
+    errors.push(`date is missing or unparseable (${JSON.stringify(item.date ?? null)})`);
+  } else if (date > new Date(now.getTime() + FUTURE_SKEW_MS)) {
+    errors.push(`date ${item.date} is in the future`);
+  } else if (date < EARLIEST_DATE) {
+    errors.push(`date ${item.date} is before ${EARLIEST_DATE.toISOString().split('T')[0]}`);
+  }
+
+  if (!Array.isArray(item.toolsMentioned)) {
+    errors.push('toolsMentioned is not an array');
+  } else if (item.toolsMentioned.length === 0 && item.untagged !== true) {
+    errors.push('no tools tagged and not marked untagged');
+  }
+
+  const maxExcerpt = config.validation.maxExcerptLength;
+  if (item.excerpt && item.excerpt.length > maxExcerpt) {
+    errors.push(`excerpt is ${item.excerpt.length} chars (max ${maxExcerpt})`);
+  }
+
+  return errors;
+}
+
+/**
+ * Validate an assembled digest: every item, plus no two items sharing a key
+ * (normalized URL). Returns { items, rejected } where `items` are the valid
+ * items in their original order and `rejected` lists { item, errors }.
+ */
+export function validateDigest(items, now = new Date()) {
+  const valid = [];
+  const rejected = [];
+  const seen = new Set();
+
+  for (const item of items) {
+    const errors = validateItem(item, now);
+    const key = normalizeUrl(item.url);
+    if (key && seen.has(key)) {
+      errors.push(`duplicate item key ${key}`);
+    }
+    if (errors.length > 0) {
+      rejected.push({ item, errors });
+      continue;
+    }
+    seen.add(key);
+    valid.push(item);
+  }
+
+  return { items: valid, rejected };
+}
+
+/**
+ * Validate a digest right before it is rendered. In best-effort mode
+ * (default) invalid items are logged and dropped; in strict mode any
+ * violation throws so the run fails instead of publishing.
+ */
+export function assertPublishable(items, mode = config.validation.mode, now = new Date()) {
+  const { items: valid, rejected } = validateDigest(items, now);
+  if (rejected.length === 0) return valid;
+
+  const lines = rejected.map(({ item, errors }) => `  - "${item.title || '(untitled)'}": ${errors.join('; ')}`);
+  if (mode === 'strict') {
+    throw new Error(`Digest failed validation (${rejected.length} item(s)):\n${lines.join('\n')}`);
+  }
+  console.warn(`  Warning: Dropped ${rejected.length} invalid item(s) from the digest:\n${lines.join('\n')}`);
+  return valid;
+}
+
+/**
+ * assertPublishable for tracker-format items, for the outputs that read
+ * the tracker (the pipeline before it converts items, the issue and its
+ * JSON and template renderings, the feed, webhooks, Teams). Each item is
+ * checked as the digest would see it; the tracker items that pass are
+ * returned as they are.
+ */
+export function publishableTrackerItems(items, mode = config.validation.mode, now = new Date()) {
+  const views = new Map(items.map(item => [digestView(item), item]));
+  return assertPublishable([...views.keys()], mode, now).map(view => views.get(view));
+}
+
+// The fields validateItem checks, mapped from a tracker item as
+// run-digest-pipeline.js's trackerToDigestFormat maps them
+function digestView(item) {
+  return {
+    title: item.title,
+    url: item.url,
+    date: item.date,
+    toolsMentioned: item.tools_mentioned || [],
+    untagged: Array.isArray(item.tools_mentioned) && item.tools_mentioned.length === 0,
+    excerpt: item.excerpt || '',
+  };
+}
+
+function isAbsoluteUrl(url) {
+  if (typeof url !== 'string') return false;
+  try {
+    const parsed = new URL(url);
+    return (parsed.protocol === 'http:' || parsed.protocol === 'https:') && Boolean(parsed.hostname);
+  } catch {
+    return false;
+  }
+}
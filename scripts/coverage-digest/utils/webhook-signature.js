@@ -0,0 +1,64 @@
+import { createHmac, timingSafeEqual } from 'crypto';
+
+// How far a request's timestamp may be from the receiver's clock
+export const DEFAULT_TOLERANCE_SECONDS = 300;
+
+/**
+ * Sign a webhook body sent at `timestamp` (Unix seconds). The HMAC covers
+ * "<timestamp>.<body>", so a captured request can't be passed off later
+ * under a new timestamp:
+ *
+ *   X-Digest-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<raw body>">
+ */
+export function signBody(secret, body, timestamp) {
+  return `sha256=${createHmac('sha256', secret).update(`${timestamp}.${body}`).digest('hex')}`;
+}
+
+/**
+ * The headers to send with a webhook body: X-Digest-Timestamp, the time
+ * of sending in Unix seconds, and the X-Digest-Signature over both.
+ */
+export function signatureHeaders(secret, body, now = Date.now()) {
+  const timestamp = Math.floor(now / 1000);
+  return { 'X-Digest-Timestamp': String(timestamp), 'X-Digest-Signature': signBody(secret, body, timestamp) };
+}
+
+/**
+ * Verify a webhook request from its raw body and headers (as Node gives
+ * them, e.g. req.headers). Consumers should call this before parsing the
+ * JSON. Throws if either header is missing or malformed, if the
+ * timestamp is more than `toleranceSeconds` from now (a replayed request,
+ * or a clock badly off), or if the signature doesn't match.
+ *
+ *   import { verifySignature } from './utils/webhook-signature.js';
+ *   verifySignature(process.env.DIGEST_WEBHOOK_SECRET, rawBody, req.headers);
+ */
+export function verifySignature(secret, body, headers, { toleranceSeconds = DEFAULT_TOLERANCE_SECONDS, now = Date.now() } = {}) {
+  if (!secret) {
+    throw new Error('webhook secret is not configured');
+  }
+  const header = headerValue(headers, 'x-digest-signature');
+  const timestamp = headerValue(headers, 'x-digest-timestamp');
+  if (typeof timestamp !== 'string' || !/^\d{1,12}$/.test(timestamp)) {
+    throw new Error('missing or malformed X-Digest-Timestamp header');
+  }
+  if (Math.abs(now / 1000 - Number(timestamp)) > toleranceSeconds) {
+    throw new Error(`X-Digest-Timestamp is more than ${toleranceSeconds}s from now`);
+  }
+  if (typeof header !== 'string' || !/^sha256=[0-9a-f]{64}$/.test(header)) {
+    throw new Error('missing or malformed X-Digest-Signature header');
+  }
+  const expected = Buffer.from(signBody(secret, body, timestamp));
+  const provided = Buffer.from(header);
+  if (provided.length !== expected.length || !timingSafeEqual(provided, expected)) {
+    throw new Error('X-Digest-Signature does not match body');
+  }
+}
+
+// Header names are case-insensitive; Node lower-cases them, fetch's
+// Headers has get()
+function headerValue(headers = {}, name) {
+  if (typeof headers.get === 'function') return headers.get(name) ?? undefined;
+  const key = Object.keys(headers).find(key => key.toLowerCase() === name);
+  return key === undefined ? undefined : headers[key];
+}
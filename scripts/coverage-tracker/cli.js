@@ -6,18 +6,30 @@
  * Usage:
  *   node cli.js add --source "Help Net Security" --url "https://..." --title "Article Title"
  *   node cli.js add --source "Black Hat" --type event --title "Talk Title" --tools "Chariot,Brutus"
+ *   node cli.js add --title "..." --embargo 2026-02-14T13:00:00Z   # Hold until embargo lifts
  *   node cli.js list                          # List all items
  *   node cli.js list --status new             # Filter by status
  *   node cli.js list --tool Brutus            # Filter by tool
  *   node cli.js amplify <id> --linkedin       # Mark as amplified on LinkedIn
  *   node cli.js stats                         # Show summary statistics
+ *   node cli.js monthly --month 2026-02       # Monthly rollup with sentiment trend
+ *   node cli.js weekly --from 2026-02-16      # Weekly rollup of the daily digests (markdown)
+ *   node cli.js weekly --from-archive         # ... read from the digests archived in S3
  *   node cli.js export --format csv           # Export to CSV
+ *   node cli.js migrate-publishers            # Rewrite sources to canonical publisher names
+ *   node cli.js migrate-tools                 # Re-attribute renamed tools to their current names
  */
 
 import { readFile, writeFile } from 'fs/promises';
 import { randomUUID } from 'crypto';
 import { fileURLToPath } from 'url';
 import { dirname, join } from 'path';
+import { normalizePublisher } from '../coverage-digest/utils/publishers.js';
+import { markAmplified } from '../coverage-digest/utils/tracker.js';
+import { canonicalTool, canonicalTools } from '../coverage-digest/utils/tools.js';
+import { loadClassifier, classifyTrackerItems, SENTIMENT_LABELS } from '../coverage-digest/utils/sentiment.js';
+import { buildRollup, renderRollupMarkdown } from '../coverage-digest/utils/rollup.js';
+import { archivedItems, listDigests } from '../coverage-digest/utils/digest-archive.js';
 
 const __filename = fileURLToPath(import.meta.url);
 const __dirname = dirname(__filename);
@@ -71,19 +83,34 @@ async function cmdAdd(args) {
     process.exit(1);
   }
 
+  let embargoUntil = null;
+  if (args.embargo) {
+    const parsed = new Date(args.embargo);
+    if (isNaN(parsed)) {
+      console.error(`Error: --embargo must be an ISO timestamp (got "${args.embargo}")`);
+      process.exit(1);
+    }
+    embargoUntil = parsed.toISOString();
+  }
+
   const items = await loadTracker();
   const id = `cov-${String(items.length + 1).padStart(3, '0')}`;
 
+  const rawSource = args.source || 'Unknown';
+  const source = normalizePublisher(rawSource, args.url);
+
   const item = {
     id,
     date: args.date || new Date().toISOString().split('T')[0],
-    source: args.source || 'Unknown',
+    source,
+    ...(source !== rawSource ? { source_original: rawSource } : {}),
     source_type: args.type || 'media',
     title: args.title,
     url: args.url || '',
     tools_mentioned: args.tools ? args.tools.split(',').map(t => t.trim()) : [],
     excerpt: args.excerpt || '',
-    status: 'new',
+    status: embargoUntil && new Date(embargoUntil) > new Date() ? 'embargoed' : 'new',
+    ...(embargoUntil ? { embargo_until: embargoUntil } : {}),
     amplification: {
       linkedin_post: null,
       slack_message: null,
@@ -97,6 +124,9 @@ async function cmdAdd(args) {
   items.push(item);
   await saveTracker(items);
   console.log(`Added: ${id} - "${item.title}" (${item.source})`);
+  if (item.status === 'embargoed') {
+    console.log(`  Embargoed until ${embargoUntil} - held out of all digests and exports until then`);
+  }
 }
 
 async function cmdList(args) {
@@ -107,9 +137,9 @@ async function cmdList(args) {
     filtered = filtered.filter(i => i.status === args.status);
   }
   if (args.tool) {
-    const tool = args.tool.toLowerCase();
+    const tool = canonicalTool(args.tool).toLowerCase();
     filtered = filtered.filter(i =>
-      (i.tools_mentioned || []).some(t => t.toLowerCase().includes(tool))
+      canonicalTools(i.tools_mentioned, i.date).some(t => t.toLowerCase().includes(tool))
     );
   }
   if (args.source) {
@@ -153,31 +183,17 @@ async function cmdAmplify(args) {
     process.exit(1);
   }
 
-  let changed = false;
-  if (args.linkedin) {
-    item.amplification.linkedin_post = new Date().toISOString();
-    changed = true;
-    console.log(`  Marked LinkedIn amplified: ${id}`);
-  }
-  if (args.slack) {
-    item.amplification.slack_message = new Date().toISOString();
-    changed = true;
-    console.log(`  Marked Slack amplified: ${id}`);
-  }
-  if (args.website) {
-    item.amplification.added_to_website = true;
-    changed = true;
-    console.log(`  Marked website added: ${id}`);
+  let marked;
+  try {
+    marked = markAmplified(item, { linkedin: args.linkedin, slack: args.slack, website: args.website });
+  } catch (err) {
+    console.error(`Error: ${err.message}`);
+    process.exit(1);
   }
+  const labels = { linkedin: 'LinkedIn amplified', slack: 'Slack amplified', website: 'website added' };
+  for (const channel of marked) console.log(`  Marked ${labels[channel]}: ${id}`);
 
-  if (changed) {
-    // Auto-update status if all channels are amplified
-    const amp = item.amplification;
-    if (amp.linkedin_post && amp.slack_message && amp.added_to_website) {
-      item.status = 'amplified';
-    } else if (amp.linkedin_post || amp.slack_message || amp.added_to_website) {
-      item.status = 'queued';
-    }
+  if (marked.length > 0) {
     await saveTracker(items);
   } else {
     console.log('No amplification flags set. Use --linkedin, --slack, or --website');
@@ -194,10 +210,11 @@ async function cmdStats() {
 
   for (const item of items) {
     byStatus[item.status] = (byStatus[item.status] || 0) + 1;
-    bySource[item.source] = (bySource[item.source] || 0) + 1;
+    const source = normalizePublisher(item.source, item.url);
+    bySource[source] = (bySource[source] || 0) + 1;
     const month = item.date.substring(0, 7);
     byMonth[month] = (byMonth[month] || 0) + 1;
-    for (const tool of item.tools_mentioned || []) {
+    for (const tool of canonicalTools(item.tools_mentioned, item.date)) {
       byTool[tool] = (byTool[tool] || 0) + 1;
     }
   }
@@ -226,8 +243,109 @@ async function cmdStats() {
   }
 }
 
+async function cmdMonthly(args) {
+  const month = args.month || new Date().toISOString().substring(0, 7);
+  if (!/^\d{4}-\d{2}$/.test(month)) {
+    console.error('Error: --month must be in YYYY-MM format');
+    process.exit(1);
+  }
+
+  const items = (await loadTracker()).filter(item => item.status !== 'embargoed');
+  // Label anything the pipeline hasn't classified yet (not saved)
+  await classifyTrackerItems(items, await loadClassifier());
+
+  const months = [...new Set([...items.map(i => i.date.substring(0, 7)), month])].sort();
+  const idx = months.indexOf(month);
+  const prevMonth = idx > 0 ? months[idx - 1] : null;
+  const inMonth = m => items.filter(i => i.date.startsWith(m));
+  const current = inMonth(month);
+  const previous = prevMonth ? inMonth(prevMonth) : [];
+
+  const countBy = (list, key) => {
+    const counts = {};
+    for (const item of list) {
+      for (const value of [].concat(key(item))) counts[value] = (counts[value] || 0) + 1;
+    }
+    return counts;
+  };
+  const delta = (now, before) => (now - before >= 0 ? `+${now - before}` : String(now - before));
+
+  console.log(`\n=== Coverage Rollup: ${formatMonth(month)} ===\n`);
+  console.log(`Items: ${current.length}${prevMonth ? ` (${delta(current.length, previous.length)} vs ${formatMonth(prevMonth)})` : ''}`);
+
+  console.log('\nBy Type:');
+  for (const [type, count] of Object.entries(countBy(current, i => i.source_type || 'media')).sort((a, b) => b[1] - a[1])) {
+    console.log(`  ${type.padEnd(12)} ${count}`);
+  }
+
+  console.log('\nBy Tool:');
+  for (const [tool, count] of Object.entries(countBy(current, i => canonicalTools(i.tools_mentioned, i.date))).sort((a, b) => b[1] - a[1])) {
+    console.log(`  ${tool.padEnd(18)} ${count}`);
+  }
+
+  const sentimentNow = countBy(current, i => i.sentiment);
+  const sentimentBefore = countBy(previous, i => i.sentiment);
+  console.log('\nSentiment:');
+  console.log('  ' + SENTIMENT_LABELS.map(label => `${label} ${sentimentNow[label] || 0}`).join(' | '));
+  if (prevMonth) {
+    console.log(`  vs ${formatMonth(prevMonth)}: ` + SENTIMENT_LABELS
+      .map(label => `${label} ${delta(sentimentNow[label] || 0, sentimentBefore[label] || 0)}`)
+      .join(' | '));
+  }
+
+  console.log('\nSentiment Trend:');
+  console.log(`  ${'month'.padEnd(9)}${SENTIMENT_LABELS.map(label => label.padStart(10)).join('')}`);
+  for (const m of months.slice(Math.max(0, idx - 5), idx + 1)) {
+    const counts = countBy(inMonth(m), i => i.sentiment);
+    console.log(`  ${m.padEnd(9)}${SENTIMENT_LABELS.map(label => String(counts[label] || 0).padStart(10)).join('')}`);
+  }
+}
+
+async function cmdWeekly(args) {
+  // Default: the last full Monday–Sunday week
+  let from = args.from;
+  if (!from) {
+    const monday = new Date();
+    monday.setUTCDate(monday.getUTCDate() - ((monday.getUTCDay() + 6) % 7) - 7);
+    from = monday.toISOString().split('T')[0];
+  }
+  let to = args.to;
+  if (!to) {
+    const sunday = new Date(`${from}T00:00:00Z`);
+    sunday.setUTCDate(sunday.getUTCDate() + 6);
+    to = sunday.toISOString().split('T')[0];
+  }
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(from) || !/^\d{4}-\d{2}-\d{2}$/.test(to)) {
+    console.error('Error: --from and --to must be in YYYY-MM-DD format');
+    process.exit(1);
+  }
+
+  // From the S3 archive, the period before `from` too, for the comparison
+  let history;
+  if (args['from-archive']) {
+    const days = Math.round((new Date(`${to}T00:00:00Z`) - new Date(`${from}T00:00:00Z`)) / 86400000) + 1;
+    const priorFrom = new Date(new Date(`${from}T00:00:00Z`).getTime() - days * 86400000).toISOString().split('T')[0];
+    history = archivedItems(await listDigests(priorFrom, to));
+  } else {
+    history = await loadTracker();
+  }
+  const markdown = renderRollupMarkdown(buildRollup(history, from, to));
+  if (args.out) {
+    await writeFile(args.out, markdown);
+    console.log(`Rollup for ${from} .. ${to} saved to ${args.out}`);
+  } else {
+    console.log(markdown);
+  }
+}
+
+function formatMonth(yyyyMm) {
+  const [year, m] = yyyyMm.split('-').map(Number);
+  return new Date(Date.UTC(year, m - 1, 1)).toLocaleDateString('en-US', { month: 'long', year: 'numeric', timeZone: 'UTC' });
+}
+
 async function cmdExport(args) {
-  const items = await loadTracker();
+  // Embargoed items must never leave the tracker before their embargo lifts
+  const items = (await loadTracker()).filter(item => item.status !== 'embargoed');
   const format = args.format || 'csv';
 
   if (format === 'csv') {
@@ -259,6 +377,63 @@ async function cmdExport(args) {
   }
 }
 
+async function cmdMigratePublishers(args) {
+  const items = await loadTracker();
+  let changed = 0;
+
+  for (const item of items) {
+    const canonical = normalizePublisher(item.source, item.url);
+    if (canonical === item.source) continue;
+
+    console.log(`  ${item.id.padEnd(10)} ${item.source} -> ${canonical}`);
+    if (!item.source_original) {
+      item.source_original = item.source;
+    }
+    item.source = canonical;
+    changed++;
+  }
+
+  if (changed === 0) {
+    console.log('All sources already use canonical publisher names.');
+    return;
+  }
+  if (args['dry-run']) {
+    console.log(`\nDry run: ${changed} item(s) would be rewritten`);
+    return;
+  }
+  await saveTracker(items);
+  console.log(`\nRewrote ${changed} item(s); original names kept in source_original`);
+}
+
+async function cmdMigrateTools(args) {
+  const items = await loadTracker();
+  let changed = 0;
+
+  for (const item of items) {
+    const current = item.tools_mentioned || [];
+    const canonical = canonicalTools(current, item.date);
+    if (canonical.join('\0') === current.join('\0')) continue;
+
+    console.log(`  ${item.id.padEnd(10)} ${current.join(', ')} -> ${canonical.join(', ')}`);
+    if (!item.tools_original) {
+      item.tools_original = current;
+    }
+    item.tools_mentioned = canonical;
+    changed++;
+  }
+
+  if (changed === 0) {
+    console.log('All items already use current tool names.');
+    return;
+  }
+  if (args['dry-run']) {
+    console.log(`\nDry run: ${changed} item(s) would be rewritten`);
+    return;
+  }
+  await saveTracker(items);
+  console.log(`\nRewrote ${changed} item(s); original names kept in tools_original`);
+}
+
 function cmdHelp() {
   console.log(`
 Praetorian Coverage Tracker CLI
@@ -268,9 +443,10 @@ Commands:
              --title "..." (required)
              --source "..." --url "..." --type media|event|podcast|social
              --tools "Brutus,Augustus" --excerpt "..." --date "2026-02-13"
+             --embargo "2026-02-14T13:00:00Z" (hold out of digests until then)
 
   list     List coverage items
-             --status new|queued|amplified|archived
+             --status new|embargoed|queued|amplified|archived
              --tool Brutus --source "Help Net"
 
   amplify  Mark item as amplified on a channel
@@ -278,9 +454,32 @@ Commands:
 
   stats    Show summary statistics
 
+  monthly  Monthly rollup: counts by type and tool, sentiment
+           distribution, and change vs the previous month
+             --month 2026-02 (default: current month)
+
+  weekly   Roll the daily digests sent in a date range into one
+           markdown digest with per-day counts and the change vs the
+           previous period
+             --from 2026-02-16 (default: Monday of last week)
+             --to 2026-02-22 (default: six days after --from)
+             --out rollup.md
+             --from-archive (read the digests archived in S3_BUCKET
+               rather than the tracker)
+
   export   Export tracker data
              --format csv
 
+  migrate-publishers
+           Rewrite historical sources to canonical publisher names
+           (original kept in source_original)
+             --dry-run
+
+  migrate-tools
+           Re-attribute items tagged with a renamed tool to its current
+           name (see toolRegistry in config.js; original kept in tools_original)
+             --dry-run
+
   help     Show this help message
 `);
 }
@@ -295,6 +494,10 @@ switch (command) {
   case 'list': await cmdList(args); break;
   case 'amplify': await cmdAmplify(args); break;
   case 'stats': await cmdStats(args); break;
+  case 'monthly': await cmdMonthly(args); break;
+  case 'weekly': await cmdWeekly(args); break;
   case 'export': await cmdExport(args); break;
+  case 'migrate-publishers': await cmdMigratePublishers(args); break;
+  case 'migrate-tools': await cmdMigrateTools(args); break;
   case 'help': default: cmdHelp(); break;
 }